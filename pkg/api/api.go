@@ -34,17 +34,26 @@ func (hs *HTTPServer) registerRoutes() {
 	redirectFromLegacyPanelEditURL := middleware.RedirectFromLegacyPanelEditURL(hs.Cfg)
 	authorize := acmiddleware.Middleware(hs.AccessControl)
 	quota := middleware.Quota(hs.QuotaService)
+	rateLimit := middleware.RateLimitGroup(hs.Cfg.RateLimiting, hs.RemoteCacheService, time.Now)
 	bind := binding.Bind
 
 	r := hs.RouteRegister
 
 	// not logged in views
 	r.Get("/logout", hs.Logout)
-	r.Post("/login", quota("session"), bind(dtos.LoginCommand{}), routing.Wrap(hs.LoginPost))
-	r.Get("/login/:name", quota("session"), hs.OAuthLogin)
+	r.Post("/login", rateLimit("login"), quota("session"), bind(dtos.LoginCommand{}), routing.Wrap(hs.LoginPost))
+	r.Get("/login/saml", rateLimit("login"), quota("session"), hs.SAMLLogin)
+	r.Get("/login/:name", rateLimit("login"), quota("session"), hs.OAuthLogin)
 	r.Get("/login", hs.LoginView)
 	r.Get("/invite/:code", hs.Index)
 
+	// SAML service provider
+	r.Get("/saml/metadata", routing.Wrap(hs.SAMLMetadata))
+	r.Post("/saml/acs", rateLimit("login"), quota("session"), hs.SAMLACS)
+	r.Get("/logout/saml", hs.SAMLLogout)
+	r.Get("/saml/slo", hs.SAMLSingleLogout)
+	r.Post("/saml/slo", hs.SAMLSingleLogout)
+
 	// authed views
 	r.Get("/", reqSignedIn, hs.Index)
 	r.Get("/profile/", reqSignedInNoAnonymous, hs.Index)
@@ -119,8 +128,8 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/user/password/send-reset-email", hs.Index)
 	r.Get("/user/password/reset", hs.Index)
 
-	r.Post("/api/user/password/send-reset-email", bind(dtos.SendResetPasswordEmailForm{}), routing.Wrap(SendResetPasswordEmail))
-	r.Post("/api/user/password/reset", bind(dtos.ResetUserPasswordForm{}), routing.Wrap(ResetPassword))
+	r.Post("/api/user/password/send-reset-email", rateLimit("login"), bind(dtos.SendResetPasswordEmailForm{}), routing.Wrap(SendResetPasswordEmail))
+	r.Post("/api/user/password/reset", rateLimit("login"), bind(dtos.ResetUserPasswordForm{}), routing.Wrap(ResetPassword))
 
 	// dashboard snapshots
 	r.Get("/dashboard/snapshot/*", reqNoAuth, hs.Index)
@@ -181,6 +190,10 @@ func (hs *HTTPServer) registerRoutes() {
 			teamsRoute.Post("/:teamId/members", bind(models.AddTeamMemberCommand{}), routing.Wrap(hs.AddTeamMember))
 			teamsRoute.Put("/:teamId/members/:userId", bind(models.UpdateTeamMemberCommand{}), routing.Wrap(hs.UpdateTeamMember))
 			teamsRoute.Delete("/:teamId/members/:userId", routing.Wrap(hs.RemoveTeamMember))
+			teamsRoute.Get("/:teamId/groups", routing.Wrap(hs.GetTeamGroups))
+			teamsRoute.Post("/:teamId/groups", bind(models.AddTeamGroupCommand{}), routing.Wrap(hs.AddTeamGroup))
+			teamsRoute.Delete("/:teamId/groups", routing.Wrap(hs.RemoveTeamGroup))
+			teamsRoute.Delete("/:teamId/groups/:groupId", routing.Wrap(hs.RemoveTeamGroup))
 			teamsRoute.Get("/:teamId/preferences", routing.Wrap(hs.GetTeamPreferences))
 			teamsRoute.Put("/:teamId/preferences", bind(dtos.UpdatePrefsCmd{}), routing.Wrap(hs.UpdateTeamPreferences))
 		}, reqCanAccessTeams)
@@ -256,6 +269,21 @@ func (hs *HTTPServer) registerRoutes() {
 			keysRoute.Delete("/:id", routing.Wrap(DeleteAPIKey))
 		}, reqOrgAdmin)
 
+		// service accounts
+		apiRoute.Group("/serviceaccounts", func(saRoute routing.RouteRegister) {
+			const serviceAccountIDScope = `serviceaccounts:{{ index . ":serviceAccountId" }}`
+			saRoute.Get("/", authorize(reqOrgAdmin, accesscontrol.ActionServiceAccountsRead, accesscontrol.ScopeServiceAccountsAll), routing.Wrap(hs.GetServiceAccounts))
+			saRoute.Post("/", authorize(reqOrgAdmin, accesscontrol.ActionServiceAccountsCreate, accesscontrol.ScopeServiceAccountsAll), quota("user"), bind(models.CreateServiceAccountCommand{}), routing.Wrap(hs.CreateServiceAccount))
+			saRoute.Post("/migrate/:keyId", authorize(reqOrgAdmin, accesscontrol.ActionServiceAccountsCreate, accesscontrol.ScopeServiceAccountsAll), quota("user"), routing.Wrap(hs.ConvertAPIKeyToServiceAccount))
+			saRoute.Get("/:serviceAccountId", authorize(reqOrgAdmin, accesscontrol.ActionServiceAccountsRead, serviceAccountIDScope), routing.Wrap(hs.GetServiceAccount))
+			saRoute.Patch("/:serviceAccountId", authorize(reqOrgAdmin, accesscontrol.ActionServiceAccountsWrite, serviceAccountIDScope), bind(models.UpdateServiceAccountCommand{}), routing.Wrap(hs.UpdateServiceAccount))
+			saRoute.Delete("/:serviceAccountId", authorize(reqOrgAdmin, accesscontrol.ActionServiceAccountsDelete, serviceAccountIDScope), routing.Wrap(hs.DeleteServiceAccount))
+			saRoute.Get("/:serviceAccountId/tokens", authorize(reqOrgAdmin, accesscontrol.ActionServiceAccountsRead, serviceAccountIDScope), routing.Wrap(hs.GetServiceAccountTokens))
+			saRoute.Post("/:serviceAccountId/tokens", authorize(reqOrgAdmin, accesscontrol.ActionServiceAccountsWrite, serviceAccountIDScope), quota("api_key"), bind(models.AddServiceAccountTokenCommand{}), routing.Wrap(hs.AddServiceAccountToken))
+			saRoute.Post("/:serviceAccountId/tokens/:tokenId/rotate", authorize(reqOrgAdmin, accesscontrol.ActionServiceAccountsWrite, serviceAccountIDScope), bind(models.RotateServiceAccountTokenCommand{}), routing.Wrap(hs.RotateServiceAccountToken))
+			saRoute.Delete("/:serviceAccountId/tokens/:tokenId", authorize(reqOrgAdmin, accesscontrol.ActionServiceAccountsWrite, serviceAccountIDScope), routing.Wrap(hs.DeleteServiceAccountToken))
+		})
+
 		// Preferences
 		apiRoute.Group("/preferences", func(prefRoute routing.RouteRegister) {
 			prefRoute.Post("/set-home-dash", bind(models.SavePreferencesCommand{}), routing.Wrap(SetHomeDashboard))
@@ -291,10 +319,10 @@ func (hs *HTTPServer) registerRoutes() {
 		}, reqOrgAdmin)
 
 		apiRoute.Get("/frontend/settings/", hs.GetFrontendSettings)
-		apiRoute.Any("/datasources/proxy/:id/*", reqSignedIn, hs.ProxyDataSourceRequest)
-		apiRoute.Any("/datasources/proxy/:id", reqSignedIn, hs.ProxyDataSourceRequest)
-		apiRoute.Any("/datasources/:id/resources", hs.CallDatasourceResource)
-		apiRoute.Any("/datasources/:id/resources/*", hs.CallDatasourceResource)
+		apiRoute.Any("/datasources/proxy/:id/*", reqSignedIn, rateLimit("query"), hs.ProxyDataSourceRequest)
+		apiRoute.Any("/datasources/proxy/:id", reqSignedIn, rateLimit("query"), hs.ProxyDataSourceRequest)
+		apiRoute.Any("/datasources/:id/resources", rateLimit("query"), hs.CallDatasourceResource)
+		apiRoute.Any("/datasources/:id/resources/*", rateLimit("query"), hs.CallDatasourceResource)
 		apiRoute.Any("/datasources/:id/health", routing.Wrap(hs.CheckDatasourceHealth))
 
 		// Folders
@@ -361,15 +389,15 @@ func (hs *HTTPServer) registerRoutes() {
 
 		// Search
 		apiRoute.Get("/search/sorting", routing.Wrap(hs.ListSortOptions))
-		apiRoute.Get("/search/", routing.Wrap(Search))
+		apiRoute.Get("/search/", rateLimit("search"), routing.Wrap(Search))
 
 		// metrics
-		apiRoute.Post("/tsdb/query", bind(dtos.MetricRequest{}), routing.Wrap(hs.QueryMetrics))
+		apiRoute.Post("/tsdb/query", rateLimit("query"), bind(dtos.MetricRequest{}), routing.Wrap(hs.QueryMetrics))
 		apiRoute.Get("/tsdb/testdata/gensql", reqGrafanaAdmin, routing.Wrap(GenerateSQLTestData))
 		apiRoute.Get("/tsdb/testdata/random-walk", routing.Wrap(hs.GetTestDataRandomWalk))
 
 		// DataSource w/ expressions
-		apiRoute.Post("/ds/query", bind(dtos.MetricRequest{}), routing.Wrap(hs.QueryMetricsV2))
+		apiRoute.Post("/ds/query", rateLimit("query"), bind(dtos.MetricRequest{}), routing.Wrap(hs.QueryMetricsV2))
 
 		apiRoute.Group("/alerts", func(alertsRoute routing.RouteRegister) {
 			alertsRoute.Post("/test", bind(dtos.AlertTestCommand{}), routing.Wrap(hs.AlertTest))
@@ -441,11 +469,12 @@ func (hs *HTTPServer) registerRoutes() {
 		adminRoute.Post("/provisioning/plugins/reload", reqGrafanaAdmin, routing.Wrap(hs.AdminProvisioningReloadPlugins))
 		adminRoute.Post("/provisioning/datasources/reload", reqGrafanaAdmin, routing.Wrap(hs.AdminProvisioningReloadDatasources))
 		adminRoute.Post("/provisioning/notifications/reload", reqGrafanaAdmin, routing.Wrap(hs.AdminProvisioningReloadNotifications))
+		adminRoute.Post("/provisioning/alerting/reload", reqGrafanaAdmin, routing.Wrap(hs.AdminProvisioningReloadAlerting))
 		adminRoute.Post("/ldap/reload", reqGrafanaAdmin, routing.Wrap(hs.ReloadLDAPCfg))
 		adminRoute.Post("/ldap/sync/:id", authorize(reqGrafanaAdmin, accesscontrol.ActionLDAPUsersSync), routing.Wrap(hs.PostSyncUserWithLDAP))
 		adminRoute.Get("/ldap/:username", authorize(reqGrafanaAdmin, accesscontrol.ActionLDAPUsersRead), routing.Wrap(hs.GetUserFromLDAP))
 		adminRoute.Get("/ldap/status", authorize(reqGrafanaAdmin, accesscontrol.ActionLDAPStatusRead), routing.Wrap(hs.GetLDAPStatus))
-	})
+	}, rateLimit("admin"))
 
 	// Administering users
 	r.Group("/api/admin/users", func(adminUserRoute routing.RouteRegister) {
@@ -462,10 +491,10 @@ func (hs *HTTPServer) registerRoutes() {
 		adminUserRoute.Post("/:id/logout", authorize(reqGrafanaAdmin, accesscontrol.ActionUsersLogout, userIDScope), routing.Wrap(hs.AdminLogoutUser))
 		adminUserRoute.Get("/:id/auth-tokens", authorize(reqGrafanaAdmin, accesscontrol.ActionUsersAuthTokenList, userIDScope), routing.Wrap(hs.AdminGetUserAuthTokens))
 		adminUserRoute.Post("/:id/revoke-auth-token", authorize(reqGrafanaAdmin, accesscontrol.ActionUsersAuthTokenUpdate, userIDScope), bind(models.RevokeAuthTokenCmd{}), routing.Wrap(hs.AdminRevokeUserAuthToken))
-	})
+	}, rateLimit("admin"))
 
 	// rendering
-	r.Get("/render/*", reqSignedIn, hs.RenderToPng)
+	r.Get("/render/*", reqSignedIn, rateLimit("render"), hs.RenderToPng)
 
 	// grafana.net proxy
 	r.Any("/api/gnet/*", reqSignedIn, ProxyGnetRequest)
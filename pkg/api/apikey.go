@@ -30,6 +30,7 @@ func GetAPIKeys(c *models.ReqContext) response.Response {
 			Name:       t.Name,
 			Role:       t.Role,
 			Expiration: expiration,
+			LastUsedAt: t.LastUsedAt,
 		}
 	}
 
@@ -60,13 +61,8 @@ func (hs *HTTPServer) AddAPIKey(c *models.ReqContext, cmd models.AddApiKeyComman
 		return response.Error(400, "Invalid role specified", nil)
 	}
 
-	if hs.Cfg.ApiKeyMaxSecondsToLive != -1 {
-		if cmd.SecondsToLive == 0 {
-			return response.Error(400, "Number of seconds before expiration should be set", nil)
-		}
-		if cmd.SecondsToLive > hs.Cfg.ApiKeyMaxSecondsToLive {
-			return response.Error(400, "Number of seconds before expiration is greater than the global limit", nil)
-		}
+	if resp := hs.validateAPIKeySecondsToLive(cmd.SecondsToLive); resp != nil {
+		return resp
 	}
 	cmd.OrgId = c.OrgId
 
@@ -95,3 +91,16 @@ func (hs *HTTPServer) AddAPIKey(c *models.ReqContext, cmd models.AddApiKeyComman
 
 	return response.JSON(200, result)
 }
+
+// validateAPIKeySecondsToLive returns an error response if the lifetime of a new key breaks the global limit.
+func (hs *HTTPServer) validateAPIKeySecondsToLive(secondsToLive int64) response.Response {
+	if hs.Cfg.ApiKeyMaxSecondsToLive != -1 {
+		if secondsToLive == 0 {
+			return response.Error(400, "Number of seconds before expiration should be set", nil)
+		}
+		if secondsToLive > hs.Cfg.ApiKeyMaxSecondsToLive {
+			return response.Error(400, "Number of seconds before expiration is greater than the global limit", nil)
+		}
+	}
+	return nil
+}
@@ -96,7 +96,7 @@ func (hs *HTTPServer) LoginView(c *models.ReqContext) {
 	}
 
 	viewData.Settings["oauth"] = enabledOAuths
-	viewData.Settings["samlEnabled"] = hs.samlEnabled()
+	viewData.Settings["samlEnabled"] = hs.SAMLService.IsEnabled()
 
 	if loginError, ok := tryGetEncryptedCookie(c, loginErrorCookieName); ok {
 		// this cookie is only set whenever an OAuth login fails
@@ -278,17 +278,17 @@ func (hs *HTTPServer) loginUserWithUser(user *models.User, c *models.ReqContext)
 }
 
 func (hs *HTTPServer) Logout(c *models.ReqContext) {
-	if hs.samlSingleLogoutEnabled() {
+	if hs.SAMLService.IsSingleLogoutEnabled() {
 		c.Redirect(hs.Cfg.AppSubURL + "/logout/saml")
 		return
 	}
 
-	err := hs.AuthTokenService.RevokeToken(c.Req.Context(), c.UserToken, false)
-	if err != nil && !errors.Is(err, models.ErrUserTokenNotFound) {
-		hs.log.Error("failed to revoke auth token", "error", err)
-	}
+	hs.logout(c)
+}
 
-	cookies.WriteSessionCookie(c, hs.Cfg, "", -1)
+// logout ends the session of the user, and redirects them to the login page or to the signout_redirect_url.
+func (hs *HTTPServer) logout(c *models.ReqContext) {
+	hs.revokeSession(c)
 
 	if setting.SignoutRedirectUrl != "" {
 		c.Redirect(setting.SignoutRedirectUrl)
@@ -298,6 +298,15 @@ func (hs *HTTPServer) Logout(c *models.ReqContext) {
 	}
 }
 
+func (hs *HTTPServer) revokeSession(c *models.ReqContext) {
+	err := hs.AuthTokenService.RevokeToken(c.Req.Context(), c.UserToken, false)
+	if err != nil && !errors.Is(err, models.ErrUserTokenNotFound) {
+		hs.log.Error("failed to revoke auth token", "error", err)
+	}
+
+	cookies.WriteSessionCookie(c, hs.Cfg, "", -1)
+}
+
 func tryGetEncryptedCookie(ctx *models.ReqContext, cookieName string) (string, bool) {
 	cookie := ctx.GetCookie(cookieName)
 	if cookie == "" {
@@ -342,14 +351,6 @@ func (hs *HTTPServer) RedirectResponseWithError(ctx *models.ReqContext, err erro
 	return response.Redirect(hs.Cfg.AppSubURL + "/login")
 }
 
-func (hs *HTTPServer) samlEnabled() bool {
-	return hs.SettingsProvider.KeyValue("auth.saml", "enabled").MustBool(false) && hs.License.HasValidLicense()
-}
-
-func (hs *HTTPServer) samlSingleLogoutEnabled() bool {
-	return hs.SettingsProvider.KeyValue("auth.saml", "single_logout").MustBool(false) && hs.samlEnabled()
-}
-
 func getLoginExternalError(err error) string {
 	var createTokenErr *models.CreateTokenErr
 	if errors.As(err, &createTokenErr) {
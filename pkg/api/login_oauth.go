@@ -174,7 +174,7 @@ func (hs *HTTPServer) OAuthLogin(ctx *models.ReqContext) {
 	}
 
 	loginInfo.ExternalUser = *buildExternalUserInfo(token, userInfo, name)
-	loginInfo.User, err = syncUser(ctx, &loginInfo.ExternalUser, connect)
+	loginInfo.User, err = syncUser(ctx, &loginInfo.ExternalUser, connect.IsSignupAllowed())
 	if err != nil {
 		hs.handleOAuthLoginErrorWithRedirect(ctx, loginInfo, err)
 		return
@@ -238,18 +238,18 @@ func buildExternalUserInfo(token *oauth2.Token, userInfo *social.BasicUserInfo,
 	return extUser
 }
 
-// syncUser syncs a Grafana user profile with the corresponding OAuth profile.
+// syncUser syncs a Grafana user profile with the corresponding OAuth or SAML profile.
 func syncUser(
 	ctx *models.ReqContext,
 	extUser *models.ExternalUserInfo,
-	connect social.SocialConnector,
+	signupAllowed bool,
 ) (*models.User, error) {
-	oauthLogger.Debug("Syncing Grafana user with corresponding OAuth profile")
+	oauthLogger.Debug("Syncing Grafana user with corresponding external profile", "authModule", extUser.AuthModule)
 	// add/update user in Grafana
 	cmd := &models.UpsertUserCommand{
 		ReqContext:    ctx,
 		ExternalUser:  extUser,
-		SignupAllowed: connect.IsSignupAllowed(),
+		SignupAllowed: signupAllowed,
 	}
 	if err := bus.Dispatch(cmd); err != nil {
 		return nil, err
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/middleware/cookies"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/saml"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// SAMLLogin starts a login with the SAML identity provider.
+func (hs *HTTPServer) SAMLLogin(c *models.ReqContext) {
+	if !hs.SAMLService.IsEnabled() {
+		c.Handle(hs.Cfg, http.StatusNotFound, "SAML authentication is not enabled", nil)
+		return
+	}
+
+	// the IdP posts the response cross-site, so the redirect_to cookie is kept with the request instead
+	var redirectTo string
+	if cookie, err := url.QueryUnescape(c.GetCookie("redirect_to")); err == nil && len(cookie) > 0 {
+		if err := hs.ValidateRedirectTo(cookie); err == nil {
+			redirectTo = cookie
+		} else {
+			log.Debugf("Ignored invalid redirect_to cookie value: %v", cookie)
+		}
+		cookies.DeleteCookie(c.Resp, "redirect_to", hs.CookieOptionsFromCfg)
+	}
+
+	msg, err := hs.SAMLService.AuthnRequest(c.Req.Context(), redirectTo)
+	if err != nil {
+		c.Handle(hs.Cfg, http.StatusInternalServerError, "Failed to create SAML authentication request", err)
+		return
+	}
+	hs.writeSAMLMessage(c, msg)
+}
+
+// SAMLMetadata returns the metadata of Grafana as a SAML service provider.
+func (hs *HTTPServer) SAMLMetadata(c *models.ReqContext) response.Response {
+	if !hs.SAMLService.IsEnabled() {
+		return response.Error(http.StatusNotFound, "SAML authentication is not enabled", nil)
+	}
+
+	metadata, err := hs.SAMLService.Metadata()
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to create SAML metadata", err)
+	}
+	return response.Respond(http.StatusOK, metadata).SetHeader("Content-Type", "application/xml")
+}
+
+// SAMLACS is the assertion consumer service, which logs in the user asserted by the response of the IdP.
+func (hs *HTTPServer) SAMLACS(c *models.ReqContext) {
+	loginInfo := models.LoginInfo{AuthModule: models.AuthModuleSAML}
+	if !hs.SAMLService.IsEnabled() {
+		hs.handleOAuthLoginError(c, loginInfo, LoginError{
+			HttpStatus:    http.StatusNotFound,
+			PublicMessage: "SAML authentication is not enabled",
+		})
+		return
+	}
+
+	extUser, redirectTo, err := hs.SAMLService.ParseResponse(c.Req.Context(), c.Req.Request)
+	if err != nil {
+		hs.handleOAuthLoginErrorWithRedirect(c, loginInfo, err)
+		return
+	}
+
+	loginInfo.ExternalUser = *extUser
+	loginInfo.User, err = syncUser(c, &loginInfo.ExternalUser, hs.Cfg.SAML.AllowSignUp)
+	if err != nil {
+		hs.handleOAuthLoginErrorWithRedirect(c, loginInfo, err)
+		return
+	}
+
+	if err := hs.loginUserWithUser(loginInfo.User, c); err != nil {
+		hs.handleOAuthLoginErrorWithRedirect(c, loginInfo, err)
+		return
+	}
+
+	loginInfo.HTTPStatus = http.StatusOK
+	hs.HooksService.RunLoginHook(&loginInfo, c)
+	metrics.MApiLoginSAML.Inc()
+
+	if redirectTo != "" {
+		c.Redirect(redirectTo)
+		return
+	}
+	c.Redirect(hs.Cfg.AppSubURL + "/")
+}
+
+// SAMLLogout logs the user out of Grafana and then of the IdP, which sends its response to SAMLSingleLogout.
+// Users who didn't log in with SAML are only logged out of Grafana.
+func (hs *HTTPServer) SAMLLogout(c *models.ReqContext) {
+	if !hs.SAMLService.IsSingleLogoutEnabled() || !c.IsSignedIn {
+		hs.logout(c)
+		return
+	}
+
+	msg, err := hs.SAMLService.LogoutRequest(c.Req.Context(), c.UserId)
+	if err != nil {
+		if !errors.Is(err, models.ErrUserNotFound) {
+			hs.log.Error("Failed to create SAML logout request", "error", err)
+		}
+		hs.logout(c)
+		return
+	}
+
+	hs.revokeSession(c)
+	hs.log.Info("Successful Logout", "User", c.Email)
+	hs.writeSAMLMessage(c, msg)
+}
+
+// SAMLSingleLogout is the single logout service. It receives the logout requests of the IdP,
+// and its responses to the logout requests of Grafana.
+func (hs *HTTPServer) SAMLSingleLogout(c *models.ReqContext) {
+	if !hs.SAMLService.IsSingleLogoutEnabled() {
+		c.Handle(hs.Cfg, http.StatusNotFound, "SAML single logout is not enabled", nil)
+		return
+	}
+	if err := c.Req.ParseForm(); err != nil {
+		c.Handle(hs.Cfg, http.StatusBadRequest, "Invalid SAML logout message", err)
+		return
+	}
+
+	if c.Req.Form.Get("SAMLRequest") != "" {
+		msg, err := hs.SAMLService.HandleLogoutRequest(c.Req.Context(), c.Req.Request)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, saml.ErrInvalidLogoutMessage) {
+				status = http.StatusBadRequest
+			}
+			c.Handle(hs.Cfg, status, "Failed to handle SAML logout request", err)
+			return
+		}
+		hs.writeSAMLMessage(c, msg)
+		return
+	}
+
+	// the user is already logged out of Grafana, a failed logout only needs to be logged
+	if err := hs.SAMLService.ValidateLogoutResponse(c.Req.Context(), c.Req.Request); err != nil {
+		hs.log.Warn("SAML logout did not succeed", "error", err)
+	}
+
+	if setting.SignoutRedirectUrl != "" {
+		c.Redirect(setting.SignoutRedirectUrl)
+		return
+	}
+	c.Redirect(hs.Cfg.AppSubURL + "/login")
+}
+
+// writeSAMLMessage sends the browser to the IdP with the message, by redirecting it or by having it post a form.
+func (hs *HTTPServer) writeSAMLMessage(c *models.ReqContext, msg *saml.Message) {
+	if msg.RedirectURL != "" {
+		c.Redirect(msg.RedirectURL)
+		return
+	}
+
+	c.Resp.Header().Set("Content-Type", "text/html; charset=utf-8")
+	c.Resp.WriteHeader(http.StatusOK)
+	if _, err := c.Resp.Write(msg.Form); err != nil {
+		hs.log.Error("Failed to write SAML message", "error", err)
+	}
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	crewjam "github.com/crewjam/saml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/hooks"
+	"github.com/grafana/grafana/pkg/services/saml"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// setupSAMLService returns an enabled SAML service, whose IdP has the SAML auth info of the user with ID 2.
+func setupSAMLService(t *testing.T) *saml.SAMLService {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "grafana.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	idpMetadata, err := xml.Marshal(&crewjam.EntityDescriptor{
+		EntityID: "https://idp.example.com/metadata",
+		IDPSSODescriptors: []crewjam.IDPSSODescriptor{{
+			SSODescriptor: crewjam.SSODescriptor{
+				RoleDescriptor: crewjam.RoleDescriptor{
+					KeyDescriptors: []crewjam.KeyDescriptor{{
+						Use:     "signing",
+						KeyInfo: crewjam.KeyInfo{Certificate: base64.StdEncoding.EncodeToString(certDER)},
+					}},
+				},
+				SingleLogoutServices: []crewjam.Endpoint{{Binding: crewjam.HTTPRedirectBinding, Location: "https://idp.example.com/slo"}},
+			},
+			SingleSignOnServices: []crewjam.Endpoint{{Binding: crewjam.HTTPRedirectBinding, Location: "https://idp.example.com/sso"}},
+		}},
+	})
+	require.NoError(t, err)
+
+	cfg := setting.NewCfg()
+	cfg.AppURL = "https://grafana.example.com/"
+	cfg.SAML = setting.SAMLSettings{
+		Enabled:      true,
+		SingleLogout: true,
+		Certificate: base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{
+			Type: "CERTIFICATE", Bytes: certDER,
+		})),
+		PrivateKey: base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{
+			Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key),
+		})),
+		IdPMetadata: base64.StdEncoding.EncodeToString(idpMetadata),
+	}
+
+	b := bus.New()
+	b.AddHandler(func(query *models.GetAuthInfoQuery) error {
+		if query.UserId != 2 {
+			return models.ErrUserNotFound
+		}
+		query.Result = &models.UserAuth{UserId: 2, AuthModule: models.AuthModuleSAML, AuthId: "alice-name-id"}
+		return nil
+	})
+
+	s := &saml.SAMLService{Cfg: cfg, Bus: b, RemoteCache: remotecache.NewFakeStore(t)}
+	require.NoError(t, s.Init())
+	return s
+}
+
+func TestSAMLLogin(t *testing.T) {
+	t.Run("redirects to the IdP and keeps the redirect_to cookie with the request", func(t *testing.T) {
+		sc := setupScenarioContext(t, "/login/saml")
+		hs := &HTTPServer{Cfg: sc.cfg, SAMLService: setupSAMLService(t), log: log.New("test")}
+		sc.defaultHandler = routing.Wrap(hs.SAMLLogin)
+		sc.m.Get(sc.url, sc.defaultHandler)
+
+		sc.fakeReqNoAssertionsWithCookie("GET", sc.url, http.Cookie{Name: "redirect_to", Value: "/d/abc"}).exec()
+
+		require.Equal(t, http.StatusFound, sc.resp.Code)
+		location := sc.resp.Header().Get("Location")
+		assert.True(t, strings.HasPrefix(location, "https://idp.example.com/sso?"), location)
+		assert.Contains(t, location, "SAMLRequest=")
+		assert.Contains(t, location, "RelayState=")
+		assert.Contains(t, sc.resp.Header()["Set-Cookie"], "redirect_to=; Path=/; Max-Age=0; HttpOnly")
+	})
+}
+
+func TestSAMLMetadata(t *testing.T) {
+	t.Run("returns the metadata of the service provider", func(t *testing.T) {
+		sc := setupScenarioContext(t, "/saml/metadata")
+		hs := &HTTPServer{Cfg: sc.cfg, SAMLService: setupSAMLService(t), log: log.New("test")}
+		sc.defaultHandler = routing.Wrap(hs.SAMLMetadata)
+		sc.m.Get(sc.url, sc.defaultHandler)
+
+		sc.fakeReqNoAssertions("GET", sc.url).exec()
+
+		require.Equal(t, http.StatusOK, sc.resp.Code)
+		assert.Equal(t, "application/xml", sc.resp.Header().Get("Content-Type"))
+		assert.Contains(t, sc.resp.Body.String(), `entityID="https://grafana.example.com/saml/metadata"`)
+	})
+
+	t.Run("returns not found when SAML is disabled", func(t *testing.T) {
+		sc := setupScenarioContext(t, "/saml/metadata")
+		hs := &HTTPServer{Cfg: sc.cfg, SAMLService: &saml.SAMLService{Cfg: setting.NewCfg()}, log: log.New("test")}
+		sc.defaultHandler = routing.Wrap(hs.SAMLMetadata)
+		sc.m.Get(sc.url, sc.defaultHandler)
+
+		sc.fakeReqNoAssertions("GET", sc.url).exec()
+
+		assert.Equal(t, http.StatusNotFound, sc.resp.Code)
+	})
+}
+
+func TestSAMLACS(t *testing.T) {
+	sc := setupScenarioContext(t, "/saml/acs")
+	hookService := &hooks.HooksService{}
+	testHook := loginHookTest{}
+	hookService.AddLoginHook(testHook.LoginHook)
+	hs := &HTTPServer{Cfg: sc.cfg, SAMLService: setupSAMLService(t), HooksService: hookService, log: log.New("test")}
+	sc.defaultHandler = routing.Wrap(hs.SAMLACS)
+	sc.m.Post(sc.url, sc.defaultHandler)
+
+	sc.resp = httptest.NewRecorder()
+	sc.req = httptest.NewRequest("POST", sc.url, strings.NewReader("SAMLResponse=PFJlc3BvbnNlLz4%3D&RelayState=unknown"))
+	sc.req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	sc.exec()
+
+	require.Equal(t, http.StatusFound, sc.resp.Code)
+	assert.Equal(t, "/login", sc.resp.Header().Get("Location"))
+	assert.Contains(t, sc.resp.Header().Get("Set-Cookie"), loginErrorCookieName)
+	require.NotNil(t, testHook.info)
+	assert.Equal(t, models.AuthModuleSAML, testHook.info.AuthModule)
+	assert.ErrorIs(t, testHook.info.Error, saml.ErrUnsolicitedResponse)
+}
+
+func TestSAMLLogout(t *testing.T) {
+	t.Run("logout redirects to the SAML logout with single logout", func(t *testing.T) {
+		sc := setupScenarioContext(t, "/logout")
+		hs := &HTTPServer{Cfg: sc.cfg, SAMLService: setupSAMLService(t), log: log.New("test")}
+		sc.defaultHandler = routing.Wrap(hs.Logout)
+		sc.m.Get(sc.url, sc.defaultHandler)
+
+		sc.fakeReqNoAssertions("GET", sc.url).exec()
+
+		require.Equal(t, http.StatusFound, sc.resp.Code)
+		assert.Equal(t, "/logout/saml", sc.resp.Header().Get("Location"))
+	})
+
+	for _, tc := range []struct {
+		desc     string
+		userID   int64
+		location string
+	}{
+		{desc: "logs out of Grafana and of the IdP", userID: 2, location: "https://idp.example.com/slo?SAMLRequest="},
+		{desc: "only logs out of Grafana users who didn't log in with SAML", userID: 3, location: "/login"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			sc := setupScenarioContext(t, "/logout/saml")
+			var revoked *models.UserToken
+			tokenService := auth.NewFakeUserAuthTokenService()
+			tokenService.RevokeTokenProvider = func(ctx context.Context, token *models.UserToken, soft bool) error {
+				revoked = token
+				return nil
+			}
+			hs := &HTTPServer{Cfg: sc.cfg, SAMLService: setupSAMLService(t), AuthTokenService: tokenService, log: log.New("test")}
+			token := &models.UserToken{Id: 1, UserId: tc.userID}
+			sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) {
+				c.IsSignedIn = true
+				c.SignedInUser = &models.SignedInUser{UserId: tc.userID}
+				c.UserToken = token
+				hs.SAMLLogout(c)
+			})
+			sc.m.Get(sc.url, sc.defaultHandler)
+
+			sc.fakeReqNoAssertions("GET", sc.url).exec()
+
+			require.Equal(t, http.StatusFound, sc.resp.Code)
+			assert.True(t, strings.HasPrefix(sc.resp.Header().Get("Location"), tc.location), sc.resp.Header().Get("Location"))
+			assert.Equal(t, token, revoked)
+		})
+	}
+}
@@ -26,6 +26,11 @@ func SendResetPasswordEmail(c *models.ReqContext, form dtos.SendResetPasswordEma
 		return response.Error(200, "Email sent", err)
 	}
 
+	if userQuery.Result.IsServiceAccount {
+		c.Logger.Info("Requested password reset for service account", "user", userQuery.LoginOrEmail)
+		return response.Error(200, "Email sent", nil)
+	}
+
 	emailCmd := models.SendResetPasswordEmailCommand{User: userQuery.Result}
 	if err := bus.Dispatch(&emailCmd); err != nil {
 		return response.Error(500, "Failed to send email", err)
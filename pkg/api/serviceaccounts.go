@@ -0,0 +1,193 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/apikeygen"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GET /api/serviceaccounts
+func (hs *HTTPServer) GetServiceAccounts(c *models.ReqContext) response.Response {
+	query := models.GetServiceAccountsQuery{OrgId: c.OrgId}
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		return response.Error(500, "Failed to list service accounts", err)
+	}
+	return response.JSON(200, query.Result)
+}
+
+// GET /api/serviceaccounts/:serviceAccountId
+func (hs *HTTPServer) GetServiceAccount(c *models.ReqContext) response.Response {
+	query := models.GetServiceAccountByIdQuery{OrgId: c.OrgId, Id: c.ParamsInt64(":serviceAccountId")}
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		return serviceAccountErrorResponse(err, "Failed to get service account")
+	}
+	return response.JSON(200, query.Result)
+}
+
+// POST /api/serviceaccounts
+func (hs *HTTPServer) CreateServiceAccount(c *models.ReqContext, cmd models.CreateServiceAccountCommand) response.Response {
+	if !cmd.Role.IsValid() {
+		return response.Error(400, "Invalid role specified", nil)
+	}
+
+	cmd.OrgId = c.OrgId
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		return serviceAccountErrorResponse(err, "Failed to create service account")
+	}
+	return response.JSON(201, cmd.Result)
+}
+
+// PATCH /api/serviceaccounts/:serviceAccountId
+func (hs *HTTPServer) UpdateServiceAccount(c *models.ReqContext, cmd models.UpdateServiceAccountCommand) response.Response {
+	if cmd.Role != "" && !cmd.Role.IsValid() {
+		return response.Error(400, "Invalid role specified", nil)
+	}
+
+	cmd.OrgId = c.OrgId
+	cmd.Id = c.ParamsInt64(":serviceAccountId")
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		return serviceAccountErrorResponse(err, "Failed to update service account")
+	}
+	return response.JSON(200, cmd.Result)
+}
+
+// DELETE /api/serviceaccounts/:serviceAccountId
+func (hs *HTTPServer) DeleteServiceAccount(c *models.ReqContext) response.Response {
+	cmd := models.DeleteServiceAccountCommand{OrgId: c.OrgId, Id: c.ParamsInt64(":serviceAccountId")}
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		return serviceAccountErrorResponse(err, "Failed to delete service account")
+	}
+	return response.Success("Service account deleted")
+}
+
+// POST /api/serviceaccounts/migrate/:keyId
+func (hs *HTTPServer) ConvertAPIKeyToServiceAccount(c *models.ReqContext) response.Response {
+	cmd := models.ConvertApiKeyToServiceAccountCommand{OrgId: c.OrgId, KeyId: c.ParamsInt64(":keyId")}
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		return serviceAccountErrorResponse(err, "Failed to migrate API key to service account")
+	}
+	return response.JSON(200, cmd.Result)
+}
+
+// GET /api/serviceaccounts/:serviceAccountId/tokens
+func (hs *HTTPServer) GetServiceAccountTokens(c *models.ReqContext) response.Response {
+	query := models.GetServiceAccountTokensQuery{OrgId: c.OrgId, ServiceAccountId: c.ParamsInt64(":serviceAccountId")}
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		return serviceAccountErrorResponse(err, "Failed to list service account tokens")
+	}
+
+	now := time.Now()
+	result := make([]*models.ServiceAccountTokenDTO, len(query.Result))
+	for i, key := range query.Result {
+		var expiration *time.Time
+		if key.Expires != nil {
+			v := time.Unix(*key.Expires, 0)
+			expiration = &v
+		}
+		result[i] = &models.ServiceAccountTokenDTO{
+			Id:         key.Id,
+			Name:       key.Name,
+			Created:    key.Created,
+			Expiration: expiration,
+			LastUsedAt: key.LastUsedAt,
+			HasExpired: key.HasExpired(now),
+		}
+	}
+	return response.JSON(200, result)
+}
+
+// POST /api/serviceaccounts/:serviceAccountId/tokens
+func (hs *HTTPServer) AddServiceAccountToken(c *models.ReqContext, cmd models.AddServiceAccountTokenCommand) response.Response {
+	if resp := hs.validateAPIKeySecondsToLive(cmd.SecondsToLive); resp != nil {
+		return resp
+	}
+
+	cmd.OrgId = c.OrgId
+	cmd.ServiceAccountId = c.ParamsInt64(":serviceAccountId")
+	newKeyInfo, err := apikeygen.New(cmd.OrgId, cmd.Name)
+	if err != nil {
+		return response.Error(500, "Generating service account token failed", err)
+	}
+	cmd.Key = newKeyInfo.HashedKey
+
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		return serviceAccountErrorResponse(err, "Failed to add service account token")
+	}
+
+	return response.JSON(200, &dtos.NewApiKeyResult{
+		ID:   cmd.Result.Id,
+		Name: cmd.Result.Name,
+		Key:  newKeyInfo.ClientSecret,
+	})
+}
+
+// POST /api/serviceaccounts/:serviceAccountId/tokens/:tokenId/rotate
+func (hs *HTTPServer) RotateServiceAccountToken(c *models.ReqContext, cmd models.RotateServiceAccountTokenCommand) response.Response {
+	if resp := hs.validateAPIKeySecondsToLive(cmd.SecondsToLive); resp != nil {
+		return resp
+	}
+
+	cmd.OrgId = c.OrgId
+	cmd.ServiceAccountId = c.ParamsInt64(":serviceAccountId")
+	cmd.Id = c.ParamsInt64(":tokenId")
+
+	// the secret encodes the name of the key, so the name is needed before the new secret is generated
+	query := models.GetApiKeyByIdQuery{ApiKeyId: cmd.Id}
+	if err := hs.Bus.Dispatch(&query); err != nil && !errors.Is(err, models.ErrInvalidApiKey) {
+		return response.Error(500, "Failed to rotate service account token", err)
+	}
+	key := query.Result
+	if key == nil || key.OrgId != cmd.OrgId || key.ServiceAccountId == nil || *key.ServiceAccountId != cmd.ServiceAccountId {
+		return serviceAccountErrorResponse(models.ErrServiceAccountTokenNotFound, "Failed to rotate service account token")
+	}
+
+	newKeyInfo, err := apikeygen.New(cmd.OrgId, key.Name)
+	if err != nil {
+		return response.Error(500, "Generating service account token failed", err)
+	}
+	cmd.Key = newKeyInfo.HashedKey
+
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		return serviceAccountErrorResponse(err, "Failed to rotate service account token")
+	}
+
+	return response.JSON(200, &dtos.NewApiKeyResult{
+		ID:   cmd.Result.Id,
+		Name: cmd.Result.Name,
+		Key:  newKeyInfo.ClientSecret,
+	})
+}
+
+// DELETE /api/serviceaccounts/:serviceAccountId/tokens/:tokenId
+func (hs *HTTPServer) DeleteServiceAccountToken(c *models.ReqContext) response.Response {
+	cmd := models.DeleteServiceAccountTokenCommand{
+		OrgId:            c.OrgId,
+		ServiceAccountId: c.ParamsInt64(":serviceAccountId"),
+		Id:               c.ParamsInt64(":tokenId"),
+	}
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		return serviceAccountErrorResponse(err, "Failed to delete service account token")
+	}
+	return response.Success("Service account token deleted")
+}
+
+func serviceAccountErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, models.ErrServiceAccountNotFound),
+		errors.Is(err, models.ErrServiceAccountTokenNotFound),
+		errors.Is(err, models.ErrApiKeyNotFound):
+		return response.Error(404, err.Error(), nil)
+	case errors.Is(err, models.ErrServiceAccountAlreadyExists),
+		errors.Is(err, models.ErrDuplicateApiKey),
+		errors.Is(err, models.ErrApiKeyAlreadyMigrated):
+		return response.Error(409, err.Error(), nil)
+	case errors.Is(err, models.ErrInvalidApiKeyExpiration):
+		return response.Error(400, err.Error(), nil)
+	default:
+		return response.Error(500, message, err)
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestServiceAccountsAPIEndpoint_userLoggedIn(t *testing.T) {
+	// the bus is replaced at the end of each scenario
+	newHTTPServer := func() *HTTPServer {
+		cfg := setting.NewCfg()
+		cfg.ApiKeyMaxSecondsToLive = -1
+		return &HTTPServer{Cfg: cfg, Bus: bus.GetBus()}
+	}
+
+	loggedInUserScenarioWithRole(t, "When creating a service account with an invalid role", "POST", "api/serviceaccounts",
+		"api/serviceaccounts", models.ROLE_ADMIN, func(sc *scenarioContext) {
+			sc.handlerFunc = func(c *models.ReqContext) response.Response {
+				return newHTTPServer().CreateServiceAccount(c, models.CreateServiceAccountCommand{Name: "CI", Role: "Owner"})
+			}
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+			require.Equal(t, http.StatusBadRequest, sc.resp.Code)
+		})
+
+	loggedInUserScenarioWithRole(t, "When getting an unknown service account", "GET", "api/serviceaccounts/2",
+		"api/serviceaccounts/:serviceAccountId", models.ROLE_ADMIN, func(sc *scenarioContext) {
+			bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetServiceAccountByIdQuery) error {
+				return models.ErrServiceAccountNotFound
+			})
+
+			sc.handlerFunc = newHTTPServer().GetServiceAccount
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+
+			require.Equal(t, http.StatusNotFound, sc.resp.Code)
+		})
+
+	loggedInUserScenarioWithRole(t, "When listing the tokens of a service account", "GET", "api/serviceaccounts/2/tokens",
+		"api/serviceaccounts/:serviceAccountId/tokens", models.ROLE_ADMIN, func(sc *scenarioContext) {
+			expired := time.Now().Add(-time.Hour).Unix()
+			bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetServiceAccountTokensQuery) error {
+				query.Result = []*models.ApiKey{
+					{Id: 1, OrgId: query.OrgId, Name: "old", Expires: &expired, ServiceAccountId: &query.ServiceAccountId},
+					{Id: 2, OrgId: query.OrgId, Name: "new", ServiceAccountId: &query.ServiceAccountId},
+				}
+				return nil
+			})
+
+			sc.handlerFunc = newHTTPServer().GetServiceAccountTokens
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+
+			require.Equal(t, http.StatusOK, sc.resp.Code)
+
+			var resp []models.ServiceAccountTokenDTO
+			require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &resp))
+			require.Len(t, resp, 2)
+			assert.True(t, resp[0].HasExpired)
+			assert.NotNil(t, resp[0].Expiration)
+			assert.False(t, resp[1].HasExpired)
+			assert.Nil(t, resp[1].Expiration)
+		})
+
+	loggedInUserScenarioWithRole(t, "When rotating a token", "POST", "api/serviceaccounts/2/tokens/3/rotate",
+		"api/serviceaccounts/:serviceAccountId/tokens/:tokenId/rotate", models.ROLE_ADMIN, func(sc *scenarioContext) {
+			saID := int64(2)
+			bus.AddHandler("test", func(query *models.GetApiKeyByIdQuery) error {
+				query.Result = &models.ApiKey{Id: query.ApiKeyId, OrgId: testOrgID, Name: "deploy", ServiceAccountId: &saID}
+				return nil
+			})
+			var rotated *models.RotateServiceAccountTokenCommand
+			bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.RotateServiceAccountTokenCommand) error {
+				rotated = cmd
+				cmd.Result = &models.ApiKey{Id: cmd.Id, OrgId: cmd.OrgId, Name: "deploy", Key: cmd.Key}
+				return nil
+			})
+
+			sc.handlerFunc = func(c *models.ReqContext) response.Response {
+				return newHTTPServer().RotateServiceAccountToken(c, models.RotateServiceAccountTokenCommand{})
+			}
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+			require.Equal(t, http.StatusOK, sc.resp.Code)
+			require.NotNil(t, rotated)
+			assert.Equal(t, saID, rotated.ServiceAccountId)
+			assert.Equal(t, int64(3), rotated.Id)
+
+			var resp dtos.NewApiKeyResult
+			require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &resp))
+			assert.Equal(t, "deploy", resp.Name)
+			assert.NotEmpty(t, resp.Key)
+			assert.NotEqual(t, rotated.Key, resp.Key, "only the hash of the secret is stored")
+		})
+
+	loggedInUserScenarioWithRole(t, "When rotating a token of another service account", "POST", "api/serviceaccounts/2/tokens/3/rotate",
+		"api/serviceaccounts/:serviceAccountId/tokens/:tokenId/rotate", models.ROLE_ADMIN, func(sc *scenarioContext) {
+			otherID := int64(4)
+			bus.AddHandler("test", func(query *models.GetApiKeyByIdQuery) error {
+				query.Result = &models.ApiKey{Id: query.ApiKeyId, OrgId: testOrgID, Name: "deploy", ServiceAccountId: &otherID}
+				return nil
+			})
+			bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.RotateServiceAccountTokenCommand) error {
+				t.Fatal("the token must not be rotated")
+				return nil
+			})
+
+			sc.handlerFunc = func(c *models.ReqContext) response.Response {
+				return newHTTPServer().RotateServiceAccountToken(c, models.RotateServiceAccountTokenCommand{})
+			}
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+			require.Equal(t, http.StatusNotFound, sc.resp.Code)
+		})
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/teamguardian"
+)
+
+// GET /api/teams/:teamId/groups
+func (hs *HTTPServer) GetTeamGroups(c *models.ReqContext) response.Response {
+	query := models.GetTeamGroupsQuery{OrgId: c.OrgId, TeamId: c.ParamsInt64(":teamId")}
+
+	if err := teamguardian.CanAdmin(hs.Bus, query.OrgId, query.TeamId, c.SignedInUser); err != nil {
+		return response.Error(403, "Not allowed to list team groups", err)
+	}
+
+	if err := hs.Bus.Dispatch(&query); err != nil {
+		return response.Error(500, "Failed to get Team Groups", err)
+	}
+
+	return response.JSON(200, query.Result)
+}
+
+// POST /api/teams/:teamId/groups
+func (hs *HTTPServer) AddTeamGroup(c *models.ReqContext, cmd models.AddTeamGroupCommand) response.Response {
+	cmd.OrgId = c.OrgId
+	cmd.TeamId = c.ParamsInt64(":teamId")
+
+	if err := teamguardian.CanAdmin(hs.Bus, cmd.OrgId, cmd.TeamId, c.SignedInUser); err != nil {
+		return response.Error(403, "Not allowed to add team group", err)
+	}
+
+	if err := hs.Bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrTeamNotFound) {
+			return response.Error(404, "Team not found", nil)
+		}
+
+		if errors.Is(err, models.ErrTeamGroupAlreadyAdded) {
+			return response.Error(400, "Group is already added to this team", nil)
+		}
+
+		return response.Error(500, "Failed to add Group to Team", err)
+	}
+
+	return response.Success("Group added to Team")
+}
+
+// DELETE /api/teams/:teamId/groups/:groupId
+// DELETE /api/teams/:teamId/groups?groupId=
+// The group can be given as a query parameter since external groups, such as GitLab groups, can contain slashes.
+func (hs *HTTPServer) RemoveTeamGroup(c *models.ReqContext) response.Response {
+	cmd := models.RemoveTeamGroupCommand{OrgId: c.OrgId, TeamId: c.ParamsInt64(":teamId"), GroupId: c.Params(":groupId")}
+	if cmd.GroupId == "" {
+		cmd.GroupId = c.Query("groupId")
+	}
+
+	if err := teamguardian.CanAdmin(hs.Bus, cmd.OrgId, cmd.TeamId, c.SignedInUser); err != nil {
+		return response.Error(403, "Not allowed to remove team group", err)
+	}
+
+	if cmd.GroupId == "" {
+		return response.Error(400, "Missing groupId parameter", nil)
+	}
+
+	if err := hs.Bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrTeamNotFound) {
+			return response.Error(404, "Team not found", nil)
+		}
+
+		if errors.Is(err, models.ErrTeamGroupNotFound) {
+			return response.Error(404, "Team group not found", nil)
+		}
+
+		return response.Error(500, "Failed to remove Group from Team", err)
+	}
+	return response.Success("Team Group removed")
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestTeamGroupsAPIEndpoint_userLoggedIn(t *testing.T) {
+	// the bus is replaced at the end of each scenario
+	newHTTPServer := func() *HTTPServer {
+		return &HTTPServer{Cfg: setting.NewCfg(), Bus: bus.GetBus()}
+	}
+
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "api/teams/1/groups",
+		"api/teams/:teamId/groups", models.ROLE_ADMIN, func(sc *scenarioContext) {
+			bus.AddHandler("test", func(query *models.GetTeamGroupsQuery) error {
+				query.Result = []*models.TeamGroupDTO{
+					{OrgId: query.OrgId, TeamId: query.TeamId, GroupId: "cn=admins,ou=groups,dc=grafana,dc=org"},
+				}
+				return nil
+			})
+
+			sc.handlerFunc = newHTTPServer().GetTeamGroups
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+
+			require.Equal(t, http.StatusOK, sc.resp.Code)
+
+			var resp []models.TeamGroupDTO
+			err := json.Unmarshal(sc.resp.Body.Bytes(), &resp)
+			require.NoError(t, err)
+			require.Len(t, resp, 1)
+			assert.Equal(t, int64(1), resp[0].TeamId)
+			assert.Equal(t, "cn=admins,ou=groups,dc=grafana,dc=org", resp[0].GroupId)
+		})
+
+	loggedInUserScenarioWithRole(t, "When calling DELETE on", "DELETE", "api/teams/1/groups",
+		"api/teams/:teamId/groups", models.ROLE_ADMIN, func(sc *scenarioContext) {
+			var removed *models.RemoveTeamGroupCommand
+			bus.AddHandler("test", func(cmd *models.RemoveTeamGroupCommand) error {
+				removed = cmd
+				return nil
+			})
+
+			sc.handlerFunc = newHTTPServer().RemoveTeamGroup
+			sc.fakeReqWithParams("DELETE", sc.url, map[string]string{"groupId": "grafana/admins"}).exec()
+
+			require.Equal(t, http.StatusOK, sc.resp.Code)
+			require.NotNil(t, removed)
+			assert.Equal(t, "grafana/admins", removed.GroupId)
+			assert.Equal(t, int64(1), removed.TeamId)
+		})
+
+	loggedInUserScenarioWithRole(t, "When calling DELETE on", "DELETE", "api/teams/1/groups/cn=admins,ou=groups,dc=grafana,dc=org",
+		"api/teams/:teamId/groups/:groupId", models.ROLE_ADMIN, func(sc *scenarioContext) {
+			var removed *models.RemoveTeamGroupCommand
+			bus.AddHandler("test", func(cmd *models.RemoveTeamGroupCommand) error {
+				removed = cmd
+				return nil
+			})
+
+			sc.handlerFunc = newHTTPServer().RemoveTeamGroup
+			sc.fakeReqWithParams("DELETE", sc.url, map[string]string{}).exec()
+
+			require.Equal(t, http.StatusOK, sc.resp.Code)
+			require.NotNil(t, removed)
+			assert.Equal(t, "cn=admins,ou=groups,dc=grafana,dc=org", removed.GroupId)
+		})
+
+	loggedInUserScenarioWithRole(t, "When calling DELETE without group on", "DELETE", "api/teams/1/groups",
+		"api/teams/:teamId/groups", models.ROLE_ADMIN, func(sc *scenarioContext) {
+			sc.handlerFunc = newHTTPServer().RemoveTeamGroup
+			sc.fakeReqWithParams("DELETE", sc.url, map[string]string{}).exec()
+
+			require.Equal(t, http.StatusBadRequest, sc.resp.Code)
+		})
+}
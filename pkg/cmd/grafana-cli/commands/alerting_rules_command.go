@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const rulesAPIPath = "/api/ruler/grafana/api/v1"
+
+// rulesClient calls the rule file endpoints of a Grafana server with an API key.
+type rulesClient struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func newRulesClient(c utils.CommandLine) (*rulesClient, error) {
+	grafanaURL := strings.TrimSuffix(c.String("url"), "/")
+	if grafanaURL == "" {
+		return nil, fmt.Errorf("missing Grafana URL, use --url")
+	}
+	return &rulesClient{
+		url:    grafanaURL,
+		apiKey: c.String("api-key"),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (rc *rulesClient) do(method string, path string, query url.Values, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequest(method, rc.url+rulesAPIPath+path+"?"+query.Encode(), body)
+	if err != nil {
+		return nil, err
+	}
+	if rc.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+rc.apiKey)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/yaml")
+	}
+
+	resp, err := rc.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(b, &errResp); err == nil && errResp.Message != "" {
+			return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, errResp.Message)
+		}
+		return nil, fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+	return b, nil
+}
+
+// exportRulesCommand writes the alert rules of the organization of the API key to a file, or to the standard output.
+func exportRulesCommand(c utils.CommandLine) error {
+	rc, err := newRulesClient(c)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	if namespace := c.String("namespace"); namespace != "" {
+		query.Set("namespace", namespace)
+	}
+	ruleFile, err := rc.do(http.MethodGet, "/export", query, nil)
+	if err != nil {
+		return fmt.Errorf("failed to export alert rules: %w", err)
+	}
+
+	path := c.Args().First()
+	if path == "" {
+		_, err := os.Stdout.Write(ruleFile)
+		return err
+	}
+	if err := ioutil.WriteFile(path, ruleFile, 0600); err != nil {
+		return fmt.Errorf("failed to write rule file: %w", err)
+	}
+	logger.Infof("%s Alert rules exported to %s\n", color.GreenString("✔"), path)
+	return nil
+}
+
+// importRulesCommand applies a rule file to the organization of the API key and prints the changes.
+func importRulesCommand(c utils.CommandLine) error {
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("missing rule file path")
+	}
+	// We can ignore gosec G304 here since the rule file is chosen by the user running the command
+	// nolint:gosec
+	ruleFile, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read rule file: %w", err)
+	}
+
+	rc, err := newRulesClient(c)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	if c.Bool("dry-run") {
+		query.Set("dry_run", "true")
+	}
+	b, err := rc.do(http.MethodPost, "/import", query, bytes.NewReader(ruleFile))
+	if err != nil {
+		return fmt.Errorf("failed to import alert rules: %w", err)
+	}
+
+	var result apimodels.RuleImportResult
+	if err := json.Unmarshal(b, &result); err != nil {
+		return fmt.Errorf("failed to parse import result: %w", err)
+	}
+	logger.Info(formatRuleImportResult(result))
+	return nil
+}
+
+// formatRuleImportResult formats the changes of a rule import like a diff, followed by their count.
+func formatRuleImportResult(result apimodels.RuleImportResult) string {
+	var sb strings.Builder
+	counts := make(map[apimodels.RuleImportAction]int)
+	for _, change := range result.Changes {
+		counts[change.Action]++
+		name := fmt.Sprintf("%s/%s/%s", change.Namespace, change.Group, change.Title)
+		switch change.Action {
+		case apimodels.RuleImportCreate:
+			sb.WriteString(color.GreenString("+ %s\n", name))
+		case apimodels.RuleImportUpdate:
+			sb.WriteString(color.YellowString("~ %s (%s)\n", name, strings.Join(change.Fields, ", ")))
+		case apimodels.RuleImportDelete:
+			sb.WriteString(color.RedString("- %s\n", name))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("%d to create, %d to update, %d to delete, %d unchanged\n",
+		counts[apimodels.RuleImportCreate], counts[apimodels.RuleImportUpdate], counts[apimodels.RuleImportDelete], counts[apimodels.RuleImportUnchanged]))
+	if result.DryRun {
+		sb.WriteString("Dry run, no change was applied\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func newAlertingCommandLine(t *testing.T, flags map[string]string, args ...string) utils.CommandLine {
+	t.Helper()
+
+	flagSet := flag.NewFlagSet("Test", 0)
+	for name, value := range flags {
+		flagSet.String(name, "", "")
+		require.NoError(t, flagSet.Set(name, value))
+	}
+	require.NoError(t, flagSet.Parse(args))
+	return &utils.ContextCommandLine{Context: cli.NewContext(&cli.App{Name: "Test"}, flagSet, nil)}
+}
+
+func TestImportRulesCommand(t *testing.T) {
+	ruleFile := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, ioutil.WriteFile(ruleFile, []byte("namespaces: []\n"), 0600))
+
+	var gotQuery, gotAuth, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/ruler/grafana/api/v1/import", r.URL.Path)
+		gotQuery = r.URL.RawQuery
+		gotAuth = r.Header.Get("Authorization")
+		b, _ := ioutil.ReadAll(r.Body)
+		gotBody = string(b)
+		_ = json.NewEncoder(w).Encode(apimodels.RuleImportResult{DryRun: true})
+	}))
+	t.Cleanup(server.Close)
+
+	c := newAlertingCommandLine(t, map[string]string{"url": server.URL, "api-key": "key", "dry-run": "true"}, ruleFile)
+	require.NoError(t, importRulesCommand(c))
+	require.Equal(t, "dry_run=true", gotQuery)
+	require.Equal(t, "Bearer key", gotAuth)
+	require.Equal(t, "namespaces: []\n", gotBody)
+}
+
+func TestImportRulesCommandError(t *testing.T) {
+	ruleFile := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, ioutil.WriteFile(ruleFile, []byte("namespaces: []\n"), 0600))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message":"failed to parse rule file"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c := newAlertingCommandLine(t, map[string]string{"url": server.URL}, ruleFile)
+	require.EqualError(t, importRulesCommand(c), "failed to import alert rules: request failed with status 400: failed to parse rule file")
+}
+
+func TestFormatRuleImportResult(t *testing.T) {
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = false })
+
+	result := apimodels.RuleImportResult{
+		DryRun: true,
+		Changes: []apimodels.RuleImportChange{
+			{Namespace: "folder", Group: "group", Title: "rule1", Action: apimodels.RuleImportCreate},
+			{Namespace: "folder", Group: "group", Title: "rule2", Action: apimodels.RuleImportUpdate, Fields: []string{"data", "labels"}},
+			{Namespace: "folder", Group: "group", Title: "rule3", Action: apimodels.RuleImportDelete},
+			{Namespace: "folder", Group: "group", Title: "rule4", Action: apimodels.RuleImportUnchanged},
+		},
+	}
+	require.Equal(t, `+ folder/group/rule1
+~ folder/group/rule2 (data, labels)
+- folder/group/rule3
+1 to create, 1 to update, 1 to delete, 1 unchanged
+Dry run, no change was applied
+`, formatRuleImportResult(result))
+}
@@ -64,6 +64,13 @@ func runPluginCommand(command func(commandLine utils.CommandLine) error) func(co
 	}
 }
 
+func runAlertingCommand(command func(commandLine utils.CommandLine) error) func(context *cli.Context) error {
+	return func(context *cli.Context) error {
+		cmd := &utils.ContextCommandLine{Context: context}
+		return command(cmd)
+	}
+}
+
 // Command contains command state.
 type Command struct {
 	Client utils.ApiClient
@@ -134,6 +141,46 @@ var adminCommands = []*cli.Command{
 	},
 }
 
+var alertingServerFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "url",
+		Usage:   "URL of the Grafana server",
+		Value:   "http://localhost:3000",
+		EnvVars: []string{"GRAFANA_URL"},
+	},
+	&cli.StringFlag{
+		Name:    "api-key",
+		Usage:   "API key of the organization of the alert rules",
+		EnvVars: []string{"GRAFANA_API_KEY"},
+	},
+}
+
+var alertingCommands = []*cli.Command{
+	{
+		Name:   "export-rules",
+		Usage:  "export-rules <rule file (optional, default is stdout)>",
+		Action: runAlertingCommand(exportRulesCommand),
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "namespace",
+				Usage: "Export only the alert rules of this folder",
+			},
+		}, alertingServerFlags...),
+	},
+	{
+		Name:   "import-rules",
+		Usage:  "import-rules <rule file>",
+		Action: runAlertingCommand(importRulesCommand),
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Print the changes without applying them",
+				Value: false,
+			},
+		}, alertingServerFlags...),
+	},
+}
+
 var Commands = []*cli.Command{
 	{
 		Name:        "plugins",
@@ -145,4 +192,9 @@ var Commands = []*cli.Command{
 		Usage:       "Grafana admin commands",
 		Subcommands: adminCommands,
 	},
+	{
+		Name:        "alerting",
+		Usage:       "Manage the alert rules of a Grafana server",
+		Subcommands: alertingCommands,
+	},
 }
@@ -0,0 +1,109 @@
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+)
+
+// queryCacheCleanupInterval is how often expired datasource results are removed from the cache.
+const queryCacheCleanupInterval = time.Minute
+
+var (
+	queryCacheOnce sync.Once
+	queryCache     *localcache.CacheService
+)
+
+// getQueryCache returns the process wide cache of datasource node results. It is
+// shared between Service instances, since those are usually created per request.
+func getQueryCache() *localcache.CacheService {
+	queryCacheOnce.Do(func() {
+		queryCache = localcache.New(0, queryCacheCleanupInterval)
+	})
+	return queryCache
+}
+
+func (s *Service) queryCacheTTL() time.Duration {
+	if s.Cfg == nil || s.Cfg.ExpressionsQueryCacheTTL < 0 {
+		return 0
+	}
+	return s.Cfg.ExpressionsQueryCacheTTL
+}
+
+// cacheKey returns the key under which the results of the node are cached. The refId
+// is not part of the key so that the same query used by different requests, such as
+// alert rules sharing a base query, resolves to the same entry.
+func (dn *DSNode) cacheKey() (string, error) {
+	model := make(map[string]interface{})
+	if err := json.Unmarshal(dn.query, &model); err != nil {
+		return "", err
+	}
+	delete(model, "refId")
+	encodedModel, err := json.Marshal(model)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d/%d/%s/%s/%d/%d/%d/%d/%s",
+		dn.orgID,
+		dn.datasourceID,
+		dn.datasourceUID,
+		dn.queryType,
+		dn.intervalMS,
+		dn.maxDP,
+		dn.timeRange.From.UnixNano(),
+		dn.timeRange.To.UnixNano(),
+		encodedModel,
+	), nil
+}
+
+// copyResults returns a copy of res which values point to deep copies of the underlying frames,
+// so that callers modifying frames, fields or labels do not modify cached results.
+func copyResults(res mathexp.Results) mathexp.Results {
+	vals := make(mathexp.Values, 0, len(res.Values))
+	for _, v := range res.Values {
+		frame := copyFrame(v.AsDataFrame())
+		switch v := v.(type) {
+		case mathexp.Series:
+			v.Frame = frame
+			vals = append(vals, v)
+		case mathexp.Number:
+			vals = append(vals, mathexp.Number{Frame: frame})
+		case mathexp.Scalar:
+			vals = append(vals, mathexp.Scalar{Frame: frame})
+		default:
+			vals = append(vals, v)
+		}
+	}
+	return mathexp.Results{Values: vals}
+}
+
+// copyFrame returns a copy of the frame with copies of its fields, their values and labels.
+func copyFrame(f *data.Frame) *data.Frame {
+	if f == nil {
+		return nil
+	}
+
+	c := f.EmptyCopy()
+	if f.Meta != nil {
+		meta := *f.Meta
+		c.Meta = &meta
+	}
+	for i, field := range f.Fields {
+		fieldCopy := c.Fields[i]
+		if field.Config != nil {
+			config := *field.Config
+			fieldCopy.Config = &config
+		}
+		fieldCopy.Extend(field.Len())
+		for j := 0; j < field.Len(); j++ {
+			fieldCopy.Set(j, field.CopyAt(j))
+		}
+	}
+	return c
+}
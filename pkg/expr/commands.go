@@ -69,19 +69,24 @@ func (gm *MathCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.
 
 // ReduceCommand is an expression command for reduction of a timeseries such as a min, mean, or max.
 type ReduceCommand struct {
-	Reducer     string
-	VarToReduce string
-	refID       string
+	Reducer      string
+	VarToReduce  string
+	refID        string
+	seriesMapper mathexp.ReduceMapper
 }
 
-// NewReduceCommand creates a new ReduceCMD.
-func NewReduceCommand(refID, reducer, varToReduce string) *ReduceCommand {
-	// TODO: validate reducer here, before execution
-	return &ReduceCommand{
-		Reducer:     reducer,
-		VarToReduce: varToReduce,
-		refID:       refID,
+// NewReduceCommand creates a new ReduceCMD. mapper may be nil, in which case the values
+// of each series are reduced as they are.
+func NewReduceCommand(refID, reducer, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
+	if !mathexp.ValidReduceFunc(reducer) {
+		return nil, fmt.Errorf("reducer '%v' is not implemented", reducer)
 	}
+	return &ReduceCommand{
+		Reducer:      reducer,
+		VarToReduce:  varToReduce,
+		refID:        refID,
+		seriesMapper: mapper,
+	}, nil
 }
 
 // UnmarshalReduceCommand creates a MathCMD from Grafana's frontend query.
@@ -105,7 +110,52 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 		return nil, fmt.Errorf("expected reducer to be a string, got %T for refId %v", rawReducer, rn.RefID)
 	}
 
-	return NewReduceCommand(rn.RefID, redFunc, varToReduce), nil
+	var mapper mathexp.ReduceMapper
+	if rawSettings, ok := rn.Query["settings"]; ok {
+		var err error
+		mapper, err = unmarshalReduceSettings(rawSettings)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reduce settings for refId %v: %w", rn.RefID, err)
+		}
+	}
+
+	return NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
+}
+
+// unmarshalReduceSettings returns the ReduceMapper for the reduce mode in the settings
+// of a reduce command. The mode is one of "strict" (the default), "dropNN" to drop
+// non-numeric values and "replaceNN" to replace them with "replaceWithValue".
+func unmarshalReduceSettings(rawSettings interface{}) (mathexp.ReduceMapper, error) {
+	settings, ok := rawSettings.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected settings to be an object, got %T", rawSettings)
+	}
+	rawMode, ok := settings["mode"]
+	if !ok {
+		return nil, nil
+	}
+	mode, ok := rawMode.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected mode to be a string, got %T", rawMode)
+	}
+	switch mode {
+	case "", "strict":
+		return nil, nil
+	case "dropNN":
+		return mathexp.DropNonNumber, nil
+	case "replaceNN":
+		rawValue, ok := settings["replaceWithValue"]
+		if !ok {
+			return nil, fmt.Errorf("no replaceWithValue specified for mode %v", mode)
+		}
+		value, ok := rawValue.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected replaceWithValue to be a number, got %T", rawValue)
+		}
+		return mathexp.ReplaceNonNumberWithValue(value), nil
+	default:
+		return nil, fmt.Errorf("reduce mode '%v' is not implemented", mode)
+	}
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -123,7 +173,7 @@ func (gr *ReduceCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathex
 		if !ok {
 			return newRes, fmt.Errorf("can only reduce type series, got type %v", val.Type())
 		}
-		num, err := series.Reduce(gr.refID, gr.Reducer)
+		num, err := series.Reduce(gr.refID, gr.Reducer, gr.seriesMapper)
 		if err != nil {
 			return newRes, err
 		}
@@ -240,6 +290,8 @@ const (
 	TypeResample
 	// TypeClassicConditions is the CMDType for the classic condition operation.
 	TypeClassicConditions
+	// TypeThreshold is the CMDType for a threshold expression.
+	TypeThreshold
 )
 
 func (gt CommandType) String() string {
@@ -252,6 +304,8 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeThreshold:
+		return "threshold"
 	default:
 		return "unknown"
 	}
@@ -268,6 +322,8 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeResample, nil
 	case "classic_conditions":
 		return TypeClassicConditions, nil
+	case "threshold":
+		return TypeThreshold, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -0,0 +1,86 @@
+package expr
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalReduceCommand(t *testing.T) {
+	series := mathexp.NewSeries("A", nil, 0, false, 1, true, 3)
+	require.NoError(t, series.SetPoint(0, utp(1), fp(2)))
+	require.NoError(t, series.SetPoint(1, utp(2), nil))
+	require.NoError(t, series.SetPoint(2, utp(3), fp(math.NaN())))
+	vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{series}}}
+
+	var tests = []struct {
+		name     string
+		query    map[string]interface{}
+		errIs    require.ErrorAssertionFunc
+		expected *float64
+	}{
+		{
+			name:     "strict mode by default",
+			query:    map[string]interface{}{"expression": "$A", "reducer": "sum"},
+			errIs:    require.NoError,
+			expected: fp(math.NaN()),
+		},
+		{
+			name: "drop non-numbers",
+			query: map[string]interface{}{"expression": "$A", "reducer": "last", "settings": map[string]interface{}{
+				"mode": "dropNN",
+			}},
+			errIs:    require.NoError,
+			expected: fp(2),
+		},
+		{
+			name: "replace non-numbers",
+			query: map[string]interface{}{"expression": "$A", "reducer": "sum", "settings": map[string]interface{}{
+				"mode":             "replaceNN",
+				"replaceWithValue": float64(1),
+			}},
+			errIs:    require.NoError,
+			expected: fp(4),
+		},
+		{
+			name: "replace non-numbers without a value",
+			query: map[string]interface{}{"expression": "$A", "reducer": "sum", "settings": map[string]interface{}{
+				"mode": "replaceNN",
+			}},
+			errIs: require.Error,
+		},
+		{
+			name: "unknown mode",
+			query: map[string]interface{}{"expression": "$A", "reducer": "sum", "settings": map[string]interface{}{
+				"mode": "foo",
+			}},
+			errIs: require.Error,
+		},
+		{
+			name:  "unknown reducer",
+			query: map[string]interface{}{"expression": "$A", "reducer": "foo"},
+			errIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := UnmarshalReduceCommand(&rawNode{RefID: "B", Query: tt.query})
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			res, err := cmd.Execute(context.Background(), vars)
+			require.NoError(t, err)
+			require.Len(t, res.Values, 1)
+			f := res.Values[0].(mathexp.Number).GetFloat64Value()
+			if math.IsNaN(*tt.expected) {
+				require.True(t, math.IsNaN(*f))
+				return
+			}
+			require.Equal(t, *tt.expected, *f)
+		})
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"golang.org/x/sync/errgroup"
 
 	"gonum.org/v1/gonum/graph"
 	"gonum.org/v1/gonum/graph/simple"
@@ -46,10 +48,47 @@ type Node interface {
 type DataPipeline []Node
 
 // execute runs all the command/datasource requests in the pipeline return a
-// map of the refId of the of each command
+// map of the refId of the of each command. Datasource nodes do not depend on
+// other nodes, so they are all queried concurrently first, bounded by
+// ExpressionsMaxConcurrentQueries. Command nodes are then executed in order.
 func (dp *DataPipeline) execute(c context.Context, s *Service) (mathexp.Vars, error) {
 	vars := make(mathexp.Vars)
+
+	var mu sync.Mutex
+	g, ctx := errgroup.WithContext(c)
+	sem := make(chan struct{}, s.maxConcurrentQueries())
+	for _, node := range *dp {
+		if node.NodeType() != TypeDatasourceNode {
+			continue
+		}
+		node := node
+		g.Go(func() error {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			defer func() { <-sem }()
+
+			res, err := node.Execute(ctx, nil, s)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			vars[node.RefID()] = res
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
 	for _, node := range *dp {
+		if node.NodeType() == TypeDatasourceNode {
+			continue
+		}
 		res, err := node.Execute(c, vars, s)
 		if err != nil {
 			return nil, err
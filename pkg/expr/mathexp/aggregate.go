@@ -0,0 +1,252 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// aggregateGroup holds the items of an aggregation that share the same grouping labels.
+type aggregateGroup struct {
+	Labels data.Labels
+	Values Values
+}
+
+// groupValues splits vals into groups based on the by() or without() clause of node.
+// Groups are returned in the order they are first seen.
+func groupValues(vals Values, node *parse.AggregateNode) []*aggregateGroup {
+	groups := []*aggregateGroup{}
+	bySignature := make(map[string]*aggregateGroup)
+	for _, v := range vals {
+		labels := filterLabels(v.GetLabels(), node.Grouping, !node.Without)
+		signature := labels.String()
+		g, ok := bySignature[signature]
+		if !ok {
+			g = &aggregateGroup{Labels: labels}
+			bySignature[signature] = g
+			groups = append(groups, g)
+		}
+		g.Values = append(g.Values, v)
+	}
+	return groups
+}
+
+// walkAggregate evaluates an aggregation such as sum by(host) ($A) or topk(3, $A).
+func (e *State) walkAggregate(node *parse.AggregateNode) (Results, error) {
+	res := Results{Values{}}
+	ar, err := e.walk(node.Arg)
+	if err != nil {
+		return res, err
+	}
+	k := 0
+	if node.Param != nil {
+		pr, err := e.walk(node.Param)
+		if err != nil {
+			return res, err
+		}
+		kF, err := scalarArg(node.Op, pr)
+		if err != nil {
+			return res, err
+		}
+		if kF < 0 || kF != math.Trunc(kF) {
+			return res, fmt.Errorf("%s: parameter must be a non-negative integer, got %v", node.Op, kF)
+		}
+		k = int(kF)
+	}
+	for _, g := range groupValues(ar.Values, node) {
+		switch node.Op {
+		case "topk", "bottomk":
+			vals, err := e.topK(g, k, node.Op == "bottomk")
+			if err != nil {
+				return res, err
+			}
+			res.Values = append(res.Values, vals...)
+		default:
+			v, err := e.aggregate(node.Op, g)
+			if err != nil {
+				return res, err
+			}
+			res.Values = append(res.Values, v)
+		}
+	}
+	return res, nil
+}
+
+// aggregate combines all items of the group into a single item with the group's labels.
+// Numbers are combined into a Number, and Series are combined point by point for each
+// time that exists in any of the Series.
+func (e *State) aggregate(op string, g *aggregateGroup) (Value, error) {
+	switch g.Values[0].(type) {
+	case Number:
+		vals := make([]*float64, 0, len(g.Values))
+		for _, v := range g.Values {
+			n, ok := v.(Number)
+			if !ok {
+				return nil, fmt.Errorf("%s: can not aggregate %v and %v", op, parse.TypeNumberSet, v.Type())
+			}
+			vals = append(vals, n.GetFloat64Value())
+		}
+		f, err := aggregateFloats(op, vals)
+		if err != nil {
+			return nil, err
+		}
+		n := NewNumber(e.RefID, g.Labels)
+		n.SetValue(f)
+		return n, nil
+	case Series:
+		var times []time.Time
+		points := make(map[int64][]*float64)
+		for _, v := range g.Values {
+			s, ok := v.(Series)
+			if !ok {
+				return nil, fmt.Errorf("%s: can not aggregate %v and %v", op, parse.TypeSeriesSet, v.Type())
+			}
+			for i := 0; i < s.Len(); i++ {
+				t, f := s.GetPoint(i)
+				if t == nil {
+					continue
+				}
+				key := t.UnixNano()
+				if _, ok := points[key]; !ok {
+					times = append(times, *t)
+				}
+				points[key] = append(points[key], f)
+			}
+		}
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+		newSeries := NewSeries(e.RefID, g.Labels, 0, false, 1, true, len(times))
+		for i := range times {
+			f, err := aggregateFloats(op, points[times[i].UnixNano()])
+			if err != nil {
+				return nil, err
+			}
+			if err := newSeries.SetPoint(i, &times[i], f); err != nil {
+				return nil, err
+			}
+		}
+		return newSeries, nil
+	default:
+		return nil, fmt.Errorf("%s: can not aggregate type %v", op, g.Values[0].Type())
+	}
+}
+
+// aggregateFloats reduces vals with the reduction that matches the aggregation operator.
+func aggregateFloats(op string, vals []*float64) (*float64, error) {
+	fVec := data.NewField("", nil, vals)
+	ff := Float64Field(*fVec)
+	switch op {
+	case "sum":
+		return Sum(&ff), nil
+	case "avg":
+		return Avg(&ff), nil
+	case "min":
+		return Min(&ff), nil
+	case "max":
+		return Max(&ff), nil
+	case "count":
+		return Count(&ff), nil
+	default:
+		return nil, fmt.Errorf("aggregation %v not implemented", op)
+	}
+}
+
+// topK returns the k items of the group with the largest values, or the smallest if bottom
+// is true. Series are ranked by the mean of their non-null values. Null and NaN values are
+// ranked last. The items keep their own labels.
+func (e *State) topK(g *aggregateGroup, k int, bottom bool) (Values, error) {
+	type ranked struct {
+		value Value
+		rank  float64
+	}
+	items := make([]ranked, 0, len(g.Values))
+	for _, v := range g.Values {
+		var r *float64
+		switch vt := v.(type) {
+		case Number:
+			r = vt.GetFloat64Value()
+		case Series:
+			r = nonNullMean(vt)
+		default:
+			return nil, fmt.Errorf("can not rank type %v", v.Type())
+		}
+		rank := math.NaN()
+		if r != nil {
+			rank = *r
+		}
+		items = append(items, ranked{value: v, rank: rank})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].rank, items[j].rank
+		if math.IsNaN(b) {
+			return !math.IsNaN(a)
+		}
+		if math.IsNaN(a) {
+			return false
+		}
+		if bottom {
+			return a < b
+		}
+		return a > b
+	})
+	if k > len(items) {
+		k = len(items)
+	}
+	vals := make(Values, 0, k)
+	for _, item := range items[:k] {
+		v, err := e.copyValue(item.value)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+// nonNullMean returns the mean of the non-null and non-NaN values of the series,
+// or nil if there are none.
+func nonNullMean(s Series) *float64 {
+	var sum float64
+	var count int
+	for i := 0; i < s.Len(); i++ {
+		f := s.GetValue(i)
+		if f == nil || math.IsNaN(*f) {
+			continue
+		}
+		sum += *f
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+	mean := sum / float64(count)
+	return &mean
+}
+
+// copyValue returns a copy of a Number or Series that belongs to the state's RefID.
+func (e *State) copyValue(v Value) (Value, error) {
+	var labels data.Labels
+	if v.GetLabels() != nil {
+		labels = v.GetLabels().Copy()
+	}
+	switch vt := v.(type) {
+	case Number:
+		n := NewNumber(e.RefID, labels)
+		n.SetValue(vt.GetFloat64Value())
+		return n, nil
+	case Series:
+		newSeries := NewSeries(e.RefID, labels, vt.TimeIdx, vt.TimeIsNullable, vt.ValueIdx, vt.ValueIsNullable, vt.Len())
+		for i := 0; i < vt.Len(); i++ {
+			t, f := vt.GetPoint(i)
+			if err := newSeries.SetPoint(i, t, f); err != nil {
+				return nil, err
+			}
+		}
+		return newSeries, nil
+	default:
+		return nil, fmt.Errorf("can not copy type %v", v.Type())
+	}
+}
@@ -0,0 +1,237 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+)
+
+var numbersByHost = Vars{
+	"A": Results{
+		[]Value{
+			makeNumber("", data.Labels{"host": "a", "pod": "1"}, float64Pointer(1)),
+			makeNumber("", data.Labels{"host": "a", "pod": "2"}, float64Pointer(5)),
+			makeNumber("", data.Labels{"host": "b", "pod": "3"}, float64Pointer(3)),
+		},
+	},
+}
+
+func TestAggregate(t *testing.T) {
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  assert.ErrorAssertionFunc
+		execErrIs assert.ErrorAssertionFunc
+		resultIs  assert.ComparisonAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "sum without grouping",
+			expr:      "sum($A)",
+			vars:      numbersByHost,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{}, float64Pointer(9)),
+			}},
+		},
+		{
+			name:      "sum by host",
+			expr:      "sum by(host) ($A)",
+			vars:      numbersByHost,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(6)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(3)),
+			}},
+		},
+		{
+			name:      "count without pod with trailing grouping",
+			expr:      "count($A) without(pod)",
+			vars:      numbersByHost,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(2)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(1)),
+			}},
+		},
+		{
+			name: "avg without pod on series",
+			expr: "avg without(pod) ($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", data.Labels{"host": "a", "pod": "1"}, tp{
+							time.Unix(5, 0), float64Pointer(2),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(4),
+						}),
+						makeSeries("", data.Labels{"host": "a", "pod": "2"}, tp{
+							time.Unix(10, 0), float64Pointer(6),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"}, tp{
+					time.Unix(5, 0), float64Pointer(2),
+				}, tp{
+					time.Unix(10, 0), float64Pointer(5),
+				}),
+			}},
+		},
+		{
+			name:      "topk",
+			expr:      "topk(2, $A)",
+			vars:      numbersByHost,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a", "pod": "2"}, float64Pointer(5)),
+				makeNumber("", data.Labels{"host": "b", "pod": "3"}, float64Pointer(3)),
+			}},
+		},
+		{
+			name:      "bottomk by host",
+			expr:      "bottomk by(host) (1, $A)",
+			vars:      numbersByHost,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a", "pod": "1"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"host": "b", "pod": "3"}, float64Pointer(3)),
+			}},
+		},
+		{
+			name:      "topk with negative parameter - should error",
+			expr:      "topk(-1, $A)",
+			vars:      numbersByHost,
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{}},
+		},
+		{
+			name:     "topk without parameter - should error",
+			expr:     "topk($A)",
+			vars:     Vars{},
+			newErrIs: assert.Error,
+		},
+		{
+			name:     "sum with parameter - should error",
+			expr:     "sum(1, $A)",
+			vars:     Vars{},
+			newErrIs: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars)
+				tt.execErrIs(t, err)
+				tt.resultIs(t, tt.results, res)
+			}
+		})
+	}
+}
+
+func TestVectorMatching(t *testing.T) {
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  assert.ErrorAssertionFunc
+		execErrIs assert.ErrorAssertionFunc
+		resultIs  assert.ComparisonAssertionFunc
+		results   Results
+	}{
+		{
+			name: "on joins on the listed labels",
+			expr: "$A + on(host) $B",
+			vars: Vars{
+				"A": Results{[]Value{
+					makeNumber("", data.Labels{"host": "a", "dc": "x"}, float64Pointer(1)),
+					makeNumber("", data.Labels{"host": "b", "dc": "x"}, float64Pointer(2)),
+				}},
+				"B": Results{[]Value{
+					makeNumber("", data.Labels{"host": "a", "source": "y"}, float64Pointer(10)),
+					makeNumber("", data.Labels{"host": "c", "source": "y"}, float64Pointer(20)),
+				}},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(11)),
+			}},
+		},
+		{
+			name: "ignoring joins on all labels except the listed ones",
+			expr: "$A * ignoring(source) $B",
+			vars: Vars{
+				"A": Results{[]Value{
+					makeNumber("", data.Labels{"host": "a", "source": "x"}, float64Pointer(2)),
+				}},
+				"B": Results{[]Value{
+					makeNumber("", data.Labels{"host": "a", "source": "y"}, float64Pointer(3)),
+					makeNumber("", data.Labels{"host": "b", "source": "y"}, float64Pointer(4)),
+				}},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(6)),
+			}},
+		},
+		{
+			name: "more than one match - should error",
+			expr: "$A + on() $B",
+			vars: Vars{
+				"A": Results{[]Value{
+					makeNumber("", data.Labels{"host": "a"}, float64Pointer(1)),
+				}},
+				"B": Results{[]Value{
+					makeNumber("", data.Labels{"host": "a"}, float64Pointer(1)),
+					makeNumber("", data.Labels{"host": "b"}, float64Pointer(1)),
+				}},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{}},
+		},
+		{
+			name:     "on with a scalar - should error",
+			expr:     "$A + on(host) 1",
+			vars:     Vars{},
+			newErrIs: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars)
+				tt.execErrIs(t, err)
+				tt.resultIs(t, tt.results, res)
+			}
+		})
+	}
+}
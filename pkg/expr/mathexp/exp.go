@@ -88,6 +88,8 @@ func (e *State) walk(node parse.Node) (res Results, err error) {
 		res, err = e.walkUnary(node)
 	case *parse.FuncNode:
 		res, err = e.walkFunc(node)
+	case *parse.AggregateNode:
+		res, err = e.walkAggregate(node)
 	default:
 		return res, fmt.Errorf("expr: can not walk node type: %s", node.Type())
 	}
@@ -242,6 +244,57 @@ func union(aResults, bResults Results) []*Union {
 	return unions
 }
 
+// matchUnion creates Union objects for the binary operations that use an on() or ignoring()
+// modifier. Items of A and B are joined when their labels are equal after the modifier
+// is applied, and those labels become the labels of the Union. Each item may join at most
+// one item from the other side.
+func matchUnion(aResults, bResults Results, matching *parse.VectorMatching) ([]*Union, error) {
+	unions := []*Union{}
+	bBySignature := make(map[string]Value, len(bResults.Values))
+	for _, b := range bResults.Values {
+		signature := filterLabels(b.GetLabels(), matching.Labels, matching.On).String()
+		if _, ok := bBySignature[signature]; ok {
+			return unions, fmt.Errorf("found more than one item on the right side of %s for labels {%s}", matching, signature)
+		}
+		bBySignature[signature] = b
+	}
+	seen := make(map[string]bool, len(aResults.Values))
+	for _, a := range aResults.Values {
+		labels := filterLabels(a.GetLabels(), matching.Labels, matching.On)
+		signature := labels.String()
+		b, ok := bBySignature[signature]
+		if !ok {
+			continue
+		}
+		if seen[signature] {
+			return unions, fmt.Errorf("found more than one item on the left side of %s for labels {%s}", matching, signature)
+		}
+		seen[signature] = true
+		unions = append(unions, &Union{
+			Labels: labels,
+			A:      a,
+			B:      b,
+		})
+	}
+	return unions, nil
+}
+
+// filterLabels returns a copy of labels that only holds the given names if keep
+// is true, or all labels except the given names if keep is false.
+func filterLabels(labels data.Labels, names []string, keep bool) data.Labels {
+	filtered := data.Labels{}
+	listed := make(map[string]bool, len(names))
+	for _, name := range names {
+		listed[name] = true
+	}
+	for k, v := range labels {
+		if listed[k] == keep {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
 func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	res := Results{Values{}}
 	ar, err := e.walk(node.Args[0])
@@ -252,7 +305,15 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := union(ar, br)
+	var unions []*Union
+	if node.Matching != nil {
+		unions, err = matchUnion(ar, br, node.Matching)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		unions = union(ar, br)
+	}
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
@@ -515,6 +576,8 @@ func (e *State) walkFunc(node *parse.FuncNode) (Results, error) {
 			v, err = e.walkUnary(t)
 		case *parse.BinaryNode:
 			v, err = e.walkBinary(t)
+		case *parse.AggregateNode:
+			v, err = e.walkAggregate(t)
 		default:
 			return res, fmt.Errorf("expr: unknown func arg type: %T", t)
 		}
@@ -1,8 +1,12 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
+	"strings"
+	"time"
 
+	"github.com/grafana/grafana/pkg/components/gtime"
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
 
@@ -17,6 +21,68 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             log,
 	},
+	"round": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             round,
+	},
+	"ceil": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             ceil,
+	},
+	"floor": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             floor,
+	},
+	"sqrt": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             sqrt,
+	},
+	"exp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             exp,
+	},
+	"clamp_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMin,
+	},
+	"clamp_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMax,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"derivative": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      derivative,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+		Check:  checkDurationArg(1),
+	},
+	"timeShift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      timeShift,
+		Check:  checkDurationArg(1),
+	},
 	"nan": {
 		Return: parse.TypeScalar,
 		F:      nan,
@@ -33,28 +99,168 @@ var builtins = map[string]parse.Func{
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
 func abs(e *State, varSet Results) (Results, error) {
-	newRes := Results{}
-	for _, res := range varSet.Values {
-		newVal, err := perFloat(e, res, math.Abs)
-		if err != nil {
-			return newRes, err
-		}
-		newRes.Values = append(newRes.Values, newVal)
-	}
-	return newRes, nil
+	return perFloatResults(e, varSet, math.Abs)
 }
 
 // log returns the natural logarithm value for each result in NumberSet, SeriesSet, or Scalar
 func log(e *State, varSet Results) (Results, error) {
-	newRes := Results{}
-	for _, res := range varSet.Values {
-		newVal, err := perFloat(e, res, math.Log)
-		if err != nil {
-			return newRes, err
+	return perFloatResults(e, varSet, math.Log)
+}
+
+// round returns the nearest integer, rounding half away from zero, for each result in NumberSet, SeriesSet, or Scalar
+func round(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Round)
+}
+
+// ceil returns the least integer value greater than or equal to each result in NumberSet, SeriesSet, or Scalar
+func ceil(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Ceil)
+}
+
+// floor returns the greatest integer value less than or equal to each result in NumberSet, SeriesSet, or Scalar
+func floor(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Floor)
+}
+
+// sqrt returns the square root of each result in NumberSet, SeriesSet, or Scalar
+func sqrt(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Sqrt)
+}
+
+// exp returns e raised to the power of each result in NumberSet, SeriesSet, or Scalar
+func exp(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Exp)
+}
+
+// clampMin replaces every value in NumberSet, SeriesSet, or Scalar that is lower than min with min
+func clampMin(e *State, varSet Results, min Results) (Results, error) {
+	m, err := scalarArg("clamp_min", min)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatResults(e, varSet, func(x float64) float64 {
+		return math.Max(x, m)
+	})
+}
+
+// clampMax replaces every value in NumberSet, SeriesSet, or Scalar that is greater than max with max
+func clampMax(e *State, varSet Results, max Results) (Results, error) {
+	m, err := scalarArg("clamp_max", max)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatResults(e, varSet, func(x float64) float64 {
+		return math.Min(x, m)
+	})
+}
+
+// rate returns the per-second rate of increase between consecutive points of each series.
+// A decrease in value is treated as a counter reset.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeriesResults(e, "rate", varSet, func(s Series) (Series, error) {
+		return consecutivePoints(e, s, func(prev, cur float64, elapsed time.Duration) float64 {
+			if elapsed <= 0 {
+				return math.NaN()
+			}
+			increase := cur - prev
+			if increase < 0 {
+				increase = cur
+			}
+			return increase / elapsed.Seconds()
+		})
+	})
+}
+
+// delta returns the difference between consecutive points of each series.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeriesResults(e, "delta", varSet, func(s Series) (Series, error) {
+		return consecutivePoints(e, s, func(prev, cur float64, _ time.Duration) float64 {
+			return cur - prev
+		})
+	})
+}
+
+// derivative returns the per-second change between consecutive points of each series.
+func derivative(e *State, varSet Results) (Results, error) {
+	return perSeriesResults(e, "derivative", varSet, func(s Series) (Series, error) {
+		return consecutivePoints(e, s, func(prev, cur float64, elapsed time.Duration) float64 {
+			if elapsed <= 0 {
+				return math.NaN()
+			}
+			return (cur - prev) / elapsed.Seconds()
+		})
+	})
+}
+
+// movingAvg returns, for each point of each series, the mean of the non-null values
+// within the trailing window ending at (and including) that point.
+func movingAvg(e *State, varSet Results, rawWindow string) (Results, error) {
+	window, err := parseSignedDuration(rawWindow)
+	if err != nil {
+		return Results{}, fmt.Errorf("moving_avg: failed to parse window %q: %w", rawWindow, err)
+	}
+	if window <= 0 {
+		return Results{}, fmt.Errorf("moving_avg: window must be positive, got %q", rawWindow)
+	}
+	return perSeriesResults(e, "moving_avg", varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.TimeIdx, s.TimeIsNullable, s.ValueIdx, true, 0)
+		start := 0
+		for i := 0; i < s.Len(); i++ {
+			t := s.GetTime(i)
+			if t == nil {
+				continue
+			}
+			var sum float64
+			var count int
+			for j := start; j <= i; j++ {
+				jt, f := s.GetPoint(j)
+				if jt == nil {
+					continue
+				}
+				if !jt.After(t.Add(-window)) {
+					start = j + 1
+					continue
+				}
+				if f == nil || math.IsNaN(*f) {
+					continue
+				}
+				sum += *f
+				count++
+			}
+			var avg *float64
+			if count > 0 {
+				v := sum / float64(count)
+				avg = &v
+			}
+			if err := newSeries.AppendPoint(i, t, avg); err != nil {
+				return newSeries, err
+			}
 		}
-		newRes.Values = append(newRes.Values, newVal)
+		return newSeries, nil
+	})
+}
+
+// timeShift moves every point of each series forward in time by the given duration.
+// A negative duration moves points backward.
+func timeShift(e *State, varSet Results, rawShift string) (Results, error) {
+	shift, err := parseSignedDuration(rawShift)
+	if err != nil {
+		return Results{}, fmt.Errorf("timeShift: failed to parse duration %q: %w", rawShift, err)
 	}
-	return newRes, nil
+	return perSeriesResults(e, "timeShift", varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.TimeIdx, s.TimeIsNullable, s.ValueIdx, s.ValueIsNullable, s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if t != nil {
+				shifted := t.Add(shift)
+				t = &shifted
+			}
+			if err := newSeries.SetPoint(i, t, f); err != nil {
+				return newSeries, err
+			}
+		}
+		return newSeries, nil
+	})
 }
 
 // nan returns a scalar nan value
@@ -74,6 +280,19 @@ func null(e *State) Results {
 	return NewScalarResults(e.RefID, nil)
 }
 
+// perFloatResults applies floatF to every value in varSet.
+func perFloatResults(e *State, varSet Results, floatF func(x float64) float64) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, floatF)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
 func perFloat(e *State, val Value, floatF func(x float64) float64) (Value, error) {
 	var newVal Value
 	switch val.Type() {
@@ -116,3 +335,83 @@ func perFloat(e *State, val Value, floatF func(x float64) float64) (Value, error
 
 	return newVal, nil
 }
+
+// perSeriesResults applies seriesF to every value in varSet, which must all be series.
+func perSeriesResults(e *State, name string, varSet Results, seriesF func(s Series) (Series, error)) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		s, ok := res.(Series)
+		if !ok {
+			return newRes, fmt.Errorf("%s: expected %v, got %v", name, parse.TypeSeriesSet, res.Type())
+		}
+		newSeries, err := seriesF(s)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newSeries)
+	}
+	return newRes, nil
+}
+
+// consecutivePoints creates a new series where each point is the result of pointF applied to
+// a point of s and the point before it. The first point of s has no predecessor, so it is dropped.
+// If either point is null the resulting point is null. Points are expected to be sorted by time.
+func consecutivePoints(e *State, s Series, pointF func(prev, cur float64, elapsed time.Duration) float64) (Series, error) {
+	newSeries := NewSeries(e.RefID, s.GetLabels(), s.TimeIdx, s.TimeIsNullable, s.ValueIdx, true, 0)
+	for i := 1; i < s.Len(); i++ {
+		prevT, prevF := s.GetPoint(i - 1)
+		curT, curF := s.GetPoint(i)
+		if curT == nil {
+			continue
+		}
+		var value *float64
+		if prevT != nil && prevF != nil && curF != nil {
+			v := pointF(*prevF, *curF, curT.Sub(*prevT))
+			value = &v
+		}
+		if err := newSeries.AppendPoint(i, curT, value); err != nil {
+			return newSeries, err
+		}
+	}
+	return newSeries, nil
+}
+
+// scalarArg returns the float value of a scalar function argument.
+func scalarArg(name string, res Results) (float64, error) {
+	if len(res.Values) != 1 {
+		return 0, fmt.Errorf("%s: expected a single scalar argument", name)
+	}
+	s, ok := res.Values[0].(Scalar)
+	if !ok {
+		return 0, fmt.Errorf("%s: expected %v argument, got %v", name, parse.TypeScalar, res.Values[0].Type())
+	}
+	f := s.GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("%s: argument must not be null", name)
+	}
+	return *f, nil
+}
+
+// parseSignedDuration parses a duration such as "1h" or "-5m" using gtime.ParseDuration.
+func parseSignedDuration(raw string) (time.Duration, error) {
+	if strings.HasPrefix(raw, "-") {
+		d, err := gtime.ParseDuration(strings.TrimPrefix(raw, "-"))
+		return -d, err
+	}
+	return gtime.ParseDuration(raw)
+}
+
+// checkDurationArg returns a parse time check that the argument at idx
+// is a string that can be parsed as a duration.
+func checkDurationArg(idx int) func(*parse.Tree, *parse.FuncNode) error {
+	return func(t *parse.Tree, f *parse.FuncNode) error {
+		arg, ok := f.Args[idx].(*parse.StringNode)
+		if !ok {
+			return fmt.Errorf("parse: %s expects a duration string as argument %v", f.Name, idx)
+		}
+		if _, err := parseSignedDuration(arg.Text); err != nil {
+			return fmt.Errorf("parse: invalid duration %s for %s: %w", arg.Quoted, f.Name, err)
+		}
+		return nil
+	}
+}
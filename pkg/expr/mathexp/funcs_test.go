@@ -2,6 +2,7 @@ package mathexp
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -67,6 +68,217 @@ func TestFunc(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:      "round on scalar",
+			expr:      "round(2.5)",
+			vars:      Vars{},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{NewScalar("", float64Pointer(3))}},
+		},
+		{
+			name: "ceil and floor on number",
+			expr: "ceil($A) + floor($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", nil, float64Pointer(1.5)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(3))}},
+		},
+		{
+			name:      "sqrt of exp on scalar",
+			expr:      "sqrt(exp(0))",
+			vars:      Vars{},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   Results{[]Value{NewScalar("", float64Pointer(1))}},
+		},
+		{
+			name: "clamp_min and clamp_max on series",
+			expr: "clamp_max(clamp_min($A, 0), 5)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeriesNullableTime("", nil, nullTimeTP{
+							unixTimePointer(5, 0), float64Pointer(-2),
+						}, nullTimeTP{
+							unixTimePointer(10, 0), float64Pointer(3),
+						}, nullTimeTP{
+							unixTimePointer(15, 0), float64Pointer(7),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeriesNullableTime("", nil, nullTimeTP{
+						unixTimePointer(5, 0), float64Pointer(0),
+					}, nullTimeTP{
+						unixTimePointer(10, 0), float64Pointer(3),
+					}, nullTimeTP{
+						unixTimePointer(15, 0), float64Pointer(5),
+					}),
+				},
+			},
+		},
+		{
+			name:     "clamp_min with series bound - should error",
+			expr:     "clamp_min($A, $B)",
+			vars:     Vars{},
+			newErrIs: assert.Error,
+		},
+		{
+			name: "rate on series with counter reset",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(10),
+						}, tp{
+							time.Unix(10, 0), float64Pointer(30),
+						}, tp{
+							time.Unix(20, 0), float64Pointer(5),
+						}, tp{
+							time.Unix(30, 0), nil,
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(10, 0), float64Pointer(2),
+					}, tp{
+						time.Unix(20, 0), float64Pointer(0.5),
+					}, tp{
+						time.Unix(30, 0), nil,
+					}),
+				},
+			},
+		},
+		{
+			name: "delta and derivative on series",
+			expr: "delta($A) - derivative($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(10),
+						}, tp{
+							time.Unix(2, 0), float64Pointer(4),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(2, 0), float64Pointer(-3),
+					}),
+				},
+			},
+		},
+		{
+			name: "delta on number - should error",
+			expr: "delta($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeNumber("", nil, float64Pointer(1)),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			resultIs:  assert.Equal,
+			results:   Results{},
+		},
+		{
+			name: "moving_avg on series",
+			expr: `moving_avg($A, "10s")`,
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(0, 0), float64Pointer(2),
+						}, tp{
+							time.Unix(5, 0), float64Pointer(4),
+						}, tp{
+							time.Unix(10, 0), nil,
+						}, tp{
+							time.Unix(15, 0), float64Pointer(9),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(0, 0), float64Pointer(2),
+					}, tp{
+						time.Unix(5, 0), float64Pointer(3),
+					}, tp{
+						time.Unix(10, 0), float64Pointer(4),
+					}, tp{
+						time.Unix(15, 0), float64Pointer(9),
+					}),
+				},
+			},
+		},
+		{
+			name:     "moving_avg with invalid window - should error",
+			expr:     `moving_avg($A, "abc")`,
+			vars:     Vars{},
+			newErrIs: assert.Error,
+		},
+		{
+			name: "timeShift on series",
+			expr: `timeShift($A, "-1m")`,
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil, tp{
+							time.Unix(60, 0), float64Pointer(1),
+						}, tp{
+							time.Unix(120, 0), float64Pointer(2),
+						}),
+					},
+				},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: Results{
+				[]Value{
+					makeSeries("", nil, tp{
+						time.Unix(0, 0), float64Pointer(1),
+					}, tp{
+						time.Unix(60, 0), float64Pointer(2),
+					}),
+				},
+			},
+		},
 		{
 			name:     "abs on string - should error",
 			expr:     `abs("hi")`,
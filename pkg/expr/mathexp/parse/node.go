@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -62,6 +63,8 @@ const (
 	NodeNumber
 	// NodeVar is variable: $A
 	NodeVar
+	// NodeAggregate is an aggregation across items: sum by(host) ($A)
+	NodeAggregate
 )
 
 // String returns the string representation of the NodeType
@@ -77,6 +80,10 @@ func (t NodeType) String() string {
 		return "NodeString"
 	case NodeNumber:
 		return "NodeNumber"
+	case NodeVar:
+		return "NodeVar"
+	case NodeAggregate:
+		return "NodeAggregate"
 	default:
 		return "NodeUnknown"
 	}
@@ -298,6 +305,7 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	Matching *VectorMatching // optional on() or ignoring() modifier
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -306,16 +314,32 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s(%s, %s)", b.Operator.val, b.Matching, b.Args[0], b.Args[1])
+	}
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
 }
 
 // Check performs parse time checking on the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) Check(t *Tree) error {
+	for _, arg := range b.Args {
+		if b.Matching != nil {
+			if rt := arg.Return(); rt != TypeNumberSet && rt != TypeSeriesSet {
+				return fmt.Errorf("parse: %s can only be used between %v or %v, got %v", b.Matching, TypeNumberSet, TypeSeriesSet, rt)
+			}
+		}
+		if err := arg.Check(t); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -329,6 +353,112 @@ func (b *BinaryNode) Return() ReturnType {
 	return t0
 }
 
+// VectorMatching describes how the items of the two sides of a binary
+// operation are joined when the on() or ignoring() modifier is used.
+type VectorMatching struct {
+	// On is true for on(), which joins on the listed labels only,
+	// and false for ignoring(), which joins on all labels except the listed ones.
+	On     bool
+	Labels []string
+}
+
+// String returns the string representation of the VectorMatching.
+func (m *VectorMatching) String() string {
+	if m.On {
+		return fmt.Sprintf("on(%s)", strings.Join(m.Labels, ", "))
+	}
+	return fmt.Sprintf("ignoring(%s)", strings.Join(m.Labels, ", "))
+}
+
+// AggregateNode holds an aggregation across the items of its argument,
+// optionally grouped by labels.
+type AggregateNode struct {
+	NodeType
+	Pos
+	Op       string   // sum, avg, min, max, count, topk or bottomk
+	Grouping []string // label names of the by() or without() clause
+	Without  bool     // Grouping lists the labels to remove instead of the labels to keep
+	Grouped  bool     // a by() or without() clause was given
+	Param    Node     // the k of topk and bottomk, nil for other operators
+	Arg      Node
+}
+
+func newAggregate(pos Pos, op string) *AggregateNode {
+	return &AggregateNode{NodeType: NodeAggregate, Pos: pos, Op: op}
+}
+
+// aggregateOps holds the operators that can be used for an AggregateNode
+// and whether they take a parameter.
+var aggregateOps = map[string]bool{
+	"sum":     false,
+	"avg":     false,
+	"min":     false,
+	"max":     false,
+	"count":   false,
+	"topk":    true,
+	"bottomk": true,
+}
+
+// IsAggregateOp returns true if op is the name of an aggregation operator.
+func IsAggregateOp(op string) bool {
+	_, ok := aggregateOps[op]
+	return ok
+}
+
+func (a *AggregateNode) grouping() string {
+	if !a.Grouped {
+		return ""
+	}
+	if a.Without {
+		return fmt.Sprintf(" without(%s)", strings.Join(a.Grouping, ", "))
+	}
+	return fmt.Sprintf(" by(%s)", strings.Join(a.Grouping, ", "))
+}
+
+// String returns the string representation of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) String() string {
+	if a.Param != nil {
+		return fmt.Sprintf("%s%s(%s, %s)", a.Op, a.grouping(), a.Param, a.Arg)
+	}
+	return fmt.Sprintf("%s%s(%s)", a.Op, a.grouping(), a.Arg)
+}
+
+// StringAST returns the string representation of abstract syntax tree of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) StringAST() string {
+	if a.Param != nil {
+		return fmt.Sprintf("%s%s(%s, %s)", a.Op, a.grouping(), a.Param.StringAST(), a.Arg.StringAST())
+	}
+	return fmt.Sprintf("%s%s(%s)", a.Op, a.grouping(), a.Arg.StringAST())
+}
+
+// Check performs parse time checking on the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) Check(t *Tree) error {
+	takesParam := aggregateOps[a.Op]
+	switch {
+	case takesParam && a.Param == nil:
+		return fmt.Errorf("parse: %s requires a parameter", a.Op)
+	case !takesParam && a.Param != nil:
+		return fmt.Errorf("parse: %s does not take a parameter", a.Op)
+	}
+	if a.Param != nil {
+		if rt := a.Param.Return(); rt != TypeScalar {
+			return fmt.Errorf("parse: expected %v parameter for %s, got %v", TypeScalar, a.Op, rt)
+		}
+		if err := a.Param.Check(t); err != nil {
+			return err
+		}
+	}
+	if rt := a.Arg.Return(); rt != TypeNumberSet && rt != TypeSeriesSet {
+		return fmt.Errorf("parse: expected %v or %v for %s, got %v", TypeNumberSet, TypeSeriesSet, a.Op, rt)
+	}
+	return a.Arg.Check(t)
+}
+
+// Return returns the result type of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) Return() ReturnType {
+	return a.Arg.Return()
+}
+
 // UnaryNode holds one argument and an operator.
 type UnaryNode struct {
 	NodeType
@@ -378,7 +508,12 @@ func Walk(n Node, f func(Node)) {
 		for _, a := range n.Args {
 			Walk(a, f)
 		}
-	case *ScalarNode, *StringNode:
+	case *AggregateNode:
+		if n.Param != nil {
+			Walk(n.Param, f)
+		}
+		Walk(n.Arg, f)
+	case *ScalarNode, *StringNode, *VarNode:
 		// Ignore since these node types have no sub nodes.
 	case *UnaryNode:
 		Walk(n.Arg, f)
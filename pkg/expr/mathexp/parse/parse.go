@@ -165,16 +165,21 @@ func (t *Tree) parse() {
 }
 
 /* Grammar:
-O -> A {"||" A}
-A -> C {"&&" C}
-C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") P}
-P -> M {( "+" | "-" ) M}
-M -> E {( "*" | "/" ) F}
-E -> F {( "**" ) F}
+O -> A {"||" [matching] A}
+A -> C {"&&" [matching] C}
+C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") [matching] P}
+P -> M {( "+" | "-" ) [matching] M}
+M -> E {( "*" | "/" ) [matching] F}
+E -> F {( "**" ) [matching] F}
 F -> v | "(" O ")" | "!" O | "-" O
-v -> number | func(..) | queryVar
+v -> number | func(..) | aggregate(..) | queryVar
 Func -> name "(" param {"," param} ")"
 param -> number | "string" | queryVar
+Aggregate -> aggOp [grouping] "(" [O ","] O ")" [grouping]
+grouping -> ( "by" | "without" ) labels
+matching -> ( "on" | "ignoring" ) labels
+labels -> "(" [label {"," label}] ")"
+label -> name | "string"
 */
 
 // expr:
@@ -185,7 +190,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(t.next(), n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +203,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(t.next(), n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +216,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(t.next(), n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +229,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(t.next(), n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +242,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(t.next(), n, t.E)
 		default:
 			return n
 		}
@@ -250,7 +255,7 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(t.next(), n, t.F)
 		default:
 			return n
 		}
@@ -286,6 +291,9 @@ func (t *Tree) v() Node {
 		return n
 	case itemFunc:
 		t.backup()
+		if IsAggregateOp(token.val) {
+			return t.Aggregate()
+		}
 		return t.Func()
 	case itemVar:
 		t.backup()
@@ -332,6 +340,99 @@ func (t *Tree) Func() (f *FuncNode) {
 		case itemRightParen:
 			return
 		}
+		switch token = t.next(); token.typ {
+		case itemComma:
+			// continue with the next argument
+		case itemRightParen:
+			return
+		default:
+			t.unexpected(token, "func")
+		}
+	}
+}
+
+// binary parses the optional matching modifier and the right hand side of a
+// binary operator into a BinaryNode.
+func (t *Tree) binary(operator item, left Node, right func() Node) *BinaryNode {
+	matching := t.matching()
+	b := newBinary(operator, left, right())
+	b.Matching = matching
+	return b
+}
+
+// matching parses an optional on() or ignoring() modifier of a binary operator.
+func (t *Tree) matching() *VectorMatching {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "on" && token.val != "ignoring") {
+		return nil
+	}
+	t.next()
+	return &VectorMatching{
+		On:     token.val == "on",
+		Labels: t.labels("matching"),
+	}
+}
+
+// Aggregate parses an AggregateNode.
+func (t *Tree) Aggregate() (a *AggregateNode) {
+	token := t.next()
+	a = newAggregate(token.pos, token.val)
+	t.grouping(a)
+	t.expect(itemLeftParen, "aggregate")
+	if aggregateOps[a.Op] {
+		a.Param = t.O()
+		t.expect(itemComma, "aggregate")
+	}
+	a.Arg = t.O()
+	t.expect(itemRightParen, "aggregate")
+	if !a.Grouped {
+		t.grouping(a)
+	}
+	return a
+}
+
+// grouping parses an optional by() or without() clause of an aggregation.
+func (t *Tree) grouping(a *AggregateNode) {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "by" && token.val != "without") {
+		return
+	}
+	t.next()
+	a.Grouped = true
+	a.Without = token.val == "without"
+	a.Grouping = t.labels("grouping")
+}
+
+// labels parses a parenthesized list of label names.
+func (t *Tree) labels(context string) []string {
+	labels := []string{}
+	t.expect(itemLeftParen, context)
+	for {
+		switch token := t.next(); token.typ {
+		case itemFunc:
+			labels = append(labels, token.val)
+		case itemString:
+			s, err := strconv.Unquote(token.val)
+			if err != nil {
+				t.errorf("Unquoting error: %s", err)
+			}
+			labels = append(labels, s)
+		case itemRightParen:
+			if len(labels) == 0 {
+				return labels
+			}
+			t.unexpected(token, context)
+		default:
+			t.unexpected(token, context)
+		}
+		switch token := t.next(); token.typ {
+		case itemComma:
+			// continue with the next label
+		case itemRightParen:
+			return labels
+		default:
+			t.unexpected(token, context)
+		}
 	}
 }
 
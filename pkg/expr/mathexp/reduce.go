@@ -3,6 +3,9 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -69,8 +72,170 @@ func Count(fv *Float64Field) *float64 {
 	return &f
 }
 
-// Reduce turns the Series into a Number based on the given reduction function
-func (s Series) Reduce(refID, rFunc string) (Number, error) {
+// Last returns the last value of the field. If the field is empty, NaN is returned.
+func Last(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	v := fv.GetValue(fv.Len() - 1)
+	if v == nil {
+		nan := math.NaN()
+		return &nan
+	}
+	f := *v
+	return &f
+}
+
+// Percentile returns the p-th percentile (0 <= p <= 100) of the values of the field,
+// interpolating linearly between the closest ranks. If any value is nil or NaN,
+// or if the field is empty, NaN is returned.
+func Percentile(fv *Float64Field, p float64) *float64 {
+	values, ok := sortedValues(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	f := values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+	return &f
+}
+
+// Median returns the 50th percentile of the values of the field.
+func Median(fv *Float64Field) *float64 {
+	return Percentile(fv, 50)
+}
+
+// Stddev returns the population standard deviation of the values of the field.
+// If any value is nil or NaN, or if the field is empty, NaN is returned.
+func Stddev(fv *Float64Field) *float64 {
+	avg := Avg(fv)
+	if math.IsNaN(*avg) {
+		return avg
+	}
+	var sum float64
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - *avg
+		sum += d * d
+	}
+	f := math.Sqrt(sum / float64(fv.Len()))
+	return &f
+}
+
+// Diff returns the difference between the last and the first value of the field.
+// If either value is nil or NaN, or if the field is empty, NaN is returned.
+func Diff(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	first, last := fv.GetValue(0), fv.GetValue(fv.Len()-1)
+	if first == nil || last == nil {
+		nan := math.NaN()
+		return &nan
+	}
+	f := *last - *first
+	return &f
+}
+
+// Range returns the difference between the largest and the smallest value of the field.
+// If any value is nil or NaN, or if the field is empty, NaN is returned.
+func Range(fv *Float64Field) *float64 {
+	f := *Max(fv) - *Min(fv)
+	return &f
+}
+
+// CountNonNull returns the number of values of the field that are neither nil nor NaN.
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
+// sortedValues returns the values of the field in ascending order. ok is false
+// if any of the values is nil or NaN.
+func sortedValues(fv *Float64Field) (values []float64, ok bool) {
+	values = make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		values = append(values, *v)
+	}
+	sort.Float64s(values)
+	return values, true
+}
+
+// parsePercentile returns the percentile of a reduction function such as "p95".
+// ok is false if rFunc is not a percentile reduction function.
+func parsePercentile(rFunc string) (p float64, ok bool) {
+	if !strings.HasPrefix(rFunc, "p") {
+		return 0, false
+	}
+	i, err := strconv.Atoi(strings.TrimPrefix(rFunc, "p"))
+	if err != nil || i < 1 || i > 99 {
+		return 0, false
+	}
+	return float64(i), true
+}
+
+// ReduceMapper maps a value of a series before it is reduced.
+// If nil is returned, the value is dropped.
+type ReduceMapper func(f *float64) *float64
+
+// DropNonNumber is a ReduceMapper that drops nil, NaN and infinite values.
+func DropNonNumber(f *float64) *float64 {
+	if f == nil || math.IsNaN(*f) || math.IsInf(*f, 0) {
+		return nil
+	}
+	return f
+}
+
+// ReplaceNonNumberWithValue returns a ReduceMapper that replaces nil, NaN and
+// infinite values with value.
+func ReplaceNonNumberWithValue(value float64) ReduceMapper {
+	return func(f *float64) *float64 {
+		if f == nil || math.IsNaN(*f) || math.IsInf(*f, 0) {
+			v := value
+			return &v
+		}
+		return f
+	}
+}
+
+// mapValues returns a field that holds the values of fv after applying mapper.
+func mapValues(fv *data.Field, mapper ReduceMapper) *data.Field {
+	ff := Float64Field(*fv)
+	values := make([]*float64, 0, ff.Len())
+	for i := 0; i < ff.Len(); i++ {
+		if f := mapper(ff.GetValue(i)); f != nil {
+			values = append(values, f)
+		}
+	}
+	return data.NewField(fv.Name, fv.Labels, values)
+}
+
+// ValidReduceFunc returns true if rFunc is the name of a supported reduction function.
+func ValidReduceFunc(rFunc string) bool {
+	switch rFunc {
+	case "sum", "mean", "min", "max", "count", "last", "median", "stddev", "diff", "count_non_null", "range":
+		return true
+	}
+	_, ok := parsePercentile(rFunc)
+	return ok
+}
+
+// Reduce turns the Series into a Number based on the given reduction function.
+// If mapper is not nil, it is applied to each value of the Series before the reduction.
+func (s Series) Reduce(refID, rFunc string, mapper ReduceMapper) (Number, error) {
 	var l data.Labels
 	if s.GetLabels() != nil {
 		l = s.GetLabels().Copy()
@@ -78,6 +243,9 @@ func (s Series) Reduce(refID, rFunc string) (Number, error) {
 	number := NewNumber(refID, l)
 	var f *float64
 	fVec := s.Frame.Fields[s.ValueIdx]
+	if mapper != nil {
+		fVec = mapValues(fVec, mapper)
+	}
 	floatField := Float64Field(*fVec)
 	switch rFunc {
 	case "sum":
@@ -90,8 +258,24 @@ func (s Series) Reduce(refID, rFunc string) (Number, error) {
 		f = Max(&floatField)
 	case "count":
 		f = Count(&floatField)
+	case "last":
+		f = Last(&floatField)
+	case "median":
+		f = Median(&floatField)
+	case "stddev":
+		f = Stddev(&floatField)
+	case "diff":
+		f = Diff(&floatField)
+	case "count_non_null":
+		f = CountNonNull(&floatField)
+	case "range":
+		f = Range(&floatField)
 	default:
-		return number, fmt.Errorf("reduction %v not implemented", rFunc)
+		p, ok := parsePercentile(rFunc)
+		if !ok {
+			return number, fmt.Errorf("reduction %v not implemented", rFunc)
+		}
+		f = Percentile(&floatField, p)
 	}
 	number.SetValue(f)
 
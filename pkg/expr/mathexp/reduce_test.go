@@ -22,6 +22,24 @@ var seriesWithNil = Vars{
 	},
 }
 
+var seriesFivePoints = Vars{
+	"A": Results{
+		[]Value{
+			makeSeries("temp", nil, tp{
+				time.Unix(5, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(15, 0), float64Pointer(3),
+			}, tp{
+				time.Unix(20, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(25, 0), float64Pointer(5),
+			}),
+		},
+	},
+}
+
 var seriesEmpty = Vars{
 	"A": Results{
 		[]Value{
@@ -36,6 +54,7 @@ func TestSeriesReduce(t *testing.T) {
 		red         string
 		vars        Vars
 		varToReduce string
+		mapper      ReduceMapper
 		errIs       require.ErrorAssertionFunc
 		resultsIs   require.ComparisonAssertionFunc
 		results     Results
@@ -227,6 +246,146 @@ func TestSeriesReduce(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:        "last series",
+			red:         "last",
+			varToReduce: "A",
+			vars:        seriesFivePoints,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(5)),
+				},
+			},
+		},
+		{
+			name:        "median series",
+			red:         "median",
+			varToReduce: "A",
+			vars:        seriesFivePoints,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(3)),
+				},
+			},
+		},
+		{
+			name:        "p75 series",
+			red:         "p75",
+			varToReduce: "A",
+			vars:        seriesFivePoints,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(4)),
+				},
+			},
+		},
+		{
+			name:        "p100 will error",
+			red:         "p100",
+			varToReduce: "A",
+			vars:        seriesFivePoints,
+			errIs:       require.Error,
+			resultsIs:   require.Equal,
+		},
+		{
+			name:        "stddev series",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        seriesFivePoints,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(math.Sqrt2)),
+				},
+			},
+		},
+		{
+			name:        "diff series",
+			red:         "diff",
+			varToReduce: "A",
+			vars:        seriesFivePoints,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(1)),
+				},
+			},
+		},
+		{
+			name:        "range series",
+			red:         "range",
+			varToReduce: "A",
+			vars:        seriesFivePoints,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(4)),
+				},
+			},
+		},
+		{
+			name:        "count_non_null series with a nil value",
+			red:         "count_non_null",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(1)),
+				},
+			},
+		},
+		{
+			name:        "last series with a nil value",
+			red:         "last",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, NaN),
+				},
+			},
+		},
+		{
+			name:        "last series with a nil value dropping non-numbers",
+			red:         "last",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			mapper:      DropNonNumber,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(2)),
+				},
+			},
+		},
+		{
+			name:        "sum series with a nil value replacing non-numbers",
+			red:         "sum",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			mapper:      ReplaceNonNumberWithValue(3),
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(5)),
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -234,7 +393,7 @@ func TestSeriesReduce(t *testing.T) {
 			results := Results{}
 			seriesSet := tt.vars[tt.varToReduce]
 			for _, series := range seriesSet.Values {
-				ns, err := series.Value().(*Series).Reduce("", tt.red)
+				ns, err := series.Value().(*Series).Reduce("", tt.red, tt.mapper)
 				tt.errIs(t, err)
 				if err != nil {
 					return
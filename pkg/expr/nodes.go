@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/expr/classic"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/plugins"
 
 	"gonum.org/v1/gonum/graph/simple"
 )
@@ -110,6 +111,8 @@ func buildCMDNode(dp *simple.DirectedGraph, rn *rawNode) (*CMDNode, error) {
 		node.Command, err = UnmarshalResampleCommand(rn)
 	case TypeClassicConditions:
 		node.Command, err = classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
+	case TypeThreshold:
+		node.Command, err = UnmarshalThresholdCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in '%v' not implemented", commandType, rn.RefID)
 	}
@@ -201,6 +204,30 @@ func (s *Service) buildDSNode(dp *simple.DirectedGraph, rn *rawNode, orgID int64
 // other nodes they must have already been executed and their results must
 // already by in vars.
 func (dn *DSNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (mathexp.Results, error) {
+	ttl := s.queryCacheTTL()
+	if ttl == 0 {
+		return dn.execute(ctx, s)
+	}
+
+	key, err := dn.cacheKey()
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	cache := getQueryCache()
+	if cached, ok := cache.Get(key); ok {
+		backend.Logger.Debug("expression datasource query (cached)", "query", dn.refID)
+		return copyResults(cached.(mathexp.Results)), nil
+	}
+
+	res, err := dn.execute(ctx, s)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	cache.Set(key, copyResults(res), ttl)
+	return res, nil
+}
+
+func (dn *DSNode) execute(ctx context.Context, s *Service) (mathexp.Results, error) {
 	pc := backend.PluginContext{
 		OrgID: dn.orgID,
 		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
@@ -238,8 +265,16 @@ func (dn *DSNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (m
 			return mathexp.Results{}, fmt.Errorf("failed to execute query %v: %w", refID, qr.Error)
 		}
 
-		if len(qr.Frames) == 1 {
-			frame := qr.Frames[0]
+		// exemplars are returned for display only and are not evaluated
+		frames := make(data.Frames, 0, len(qr.Frames))
+		for _, frame := range qr.Frames {
+			if !plugins.IsExemplarFrame(frame) {
+				frames = append(frames, frame)
+			}
+		}
+
+		if len(frames) == 1 {
+			frame := frames[0]
 			if frame.TimeSeriesSchema().Type == data.TimeSeriesTypeNot && isNumberTable(frame) {
 				backend.Logger.Debug("expression datasource query (numberSet)", "query", refID)
 				numberSet, err := extractNumberSet(frame)
@@ -256,7 +291,7 @@ func (dn *DSNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (m
 			}
 		}
 
-		for _, frame := range qr.Frames {
+		for _, frame := range frames {
 			backend.Logger.Debug("expression datasource query (seriesSet)", "query", refID)
 			series, err := WideToMany(frame)
 			if err != nil {
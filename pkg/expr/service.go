@@ -33,6 +33,17 @@ func (s *Service) isDisabled() bool {
 	return !s.Cfg.ExpressionsEnabled
 }
 
+// defaultMaxConcurrentQueries is used when the configuration does not limit
+// the number of concurrent datasource queries.
+const defaultMaxConcurrentQueries = 10
+
+func (s *Service) maxConcurrentQueries() int {
+	if s.Cfg == nil || s.Cfg.ExpressionsMaxConcurrentQueries <= 0 {
+		return defaultMaxConcurrentQueries
+	}
+	return s.Cfg.ExpressionsMaxConcurrentQueries
+}
+
 // BuildPipeline builds a pipeline from a request.
 func (s *Service) BuildPipeline(req *Request) (DataPipeline, error) {
 	return s.buildPipeline(req)
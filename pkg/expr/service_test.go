@@ -3,7 +3,9 @@ package expr
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"sort"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -15,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/plugins/manager"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
 	"github.com/stretchr/testify/require"
 )
@@ -90,6 +93,192 @@ func TestService(t *testing.T) {
 	}
 }
 
+// nolint:staticcheck // plugins.DataPlugin deprecated
+func TestServiceQueryCache(t *testing.T) {
+	dsDF := data.NewFrame("test",
+		data.NewField("time", nil, []*time.Time{utp(1)}),
+		data.NewField("value", nil, []*float64{fp(2)}))
+
+	dataSvc := tsdb.NewService()
+	dataSvc.PluginManager = &manager.PluginManager{
+		BackendPluginManager: fakeBackendPM{},
+	}
+	s := Service{
+		Cfg:         &setting.Cfg{ExpressionsEnabled: true, ExpressionsQueryCacheTTL: time.Minute},
+		DataService: &dataSvc,
+	}
+	me := &mockEndpoint{
+		Frames: []*data.Frame{dsDF},
+	}
+	s.DataService.RegisterQueryHandler("test", func(*models.DataSource) (plugins.DataPlugin, error) {
+		return me, nil
+	})
+	bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+		query.Result = &models.DataSource{Id: 1, OrgId: 1, Type: "test"}
+		return nil
+	})
+
+	timeRange := TimeRange{From: time.Unix(1000, 0), To: time.Unix(2000, 0)}
+	execute := func(refID string, timeRange TimeRange) *backend.QueryDataResponse {
+		req := &Request{
+			OrgId: 1,
+			Queries: []Query{
+				{
+					RefID:     refID,
+					TimeRange: timeRange,
+					JSON:      json.RawMessage(`{ "datasource": "test", "datasourceId": 1, "orgId": 1, "intervalMs": 1000, "maxDataPoints": 1000, "expr": "cached" }`),
+				},
+			},
+		}
+		pl, err := s.BuildPipeline(req)
+		require.NoError(t, err)
+		res, err := s.ExecutePipeline(context.Background(), pl)
+		require.NoError(t, err)
+		return res
+	}
+
+	first := execute("A", timeRange)
+	require.Equal(t, int32(1), atomic.LoadInt32(&me.calls))
+
+	// Same query with another refId is served from the cache.
+	second := execute("B", timeRange)
+	require.Equal(t, int32(1), atomic.LoadInt32(&me.calls))
+	require.Equal(t, "A", first.Responses["A"].Frames[0].RefID)
+	require.Equal(t, "B", second.Responses["B"].Frames[0].RefID)
+
+	// Modifying the returned frames does not modify the cached results.
+	valueField := first.Responses["A"].Frames[0].Fields[1]
+	valueField.Set(0, fp(5))
+	valueField.Labels = data.Labels{"modified": "true"}
+	third := execute("C", timeRange)
+	require.Equal(t, int32(1), atomic.LoadInt32(&me.calls))
+	require.Equal(t, fp(2), third.Responses["C"].Frames[0].Fields[1].At(0))
+	require.Empty(t, third.Responses["C"].Frames[0].Fields[1].Labels)
+
+	// Another time range is queried again.
+	execute("A", TimeRange{From: time.Unix(1000, 0), To: time.Unix(3000, 0)})
+	require.Equal(t, int32(2), atomic.LoadInt32(&me.calls))
+}
+
+// nolint:staticcheck // plugins.DataPlugin deprecated
+func TestServiceSkipsExemplars(t *testing.T) {
+	dsDF := data.NewFrame("test",
+		data.NewField("time", nil, []*time.Time{utp(1)}),
+		data.NewField("value", nil, []*float64{fp(2)}))
+	exemplarDF := data.NewFrame("exemplar",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+		data.NewField("value", nil, []float64{3}),
+		data.NewField("traceID", nil, []string{"abc"})).
+		SetMeta(plugins.ExemplarFrameMeta())
+
+	dataSvc := tsdb.NewService()
+	dataSvc.PluginManager = &manager.PluginManager{
+		BackendPluginManager: fakeBackendPM{},
+	}
+	s := Service{
+		Cfg:         &setting.Cfg{ExpressionsEnabled: true},
+		DataService: &dataSvc,
+	}
+	me := &mockEndpoint{
+		Frames: []*data.Frame{dsDF, exemplarDF},
+	}
+	s.DataService.RegisterQueryHandler("test", func(*models.DataSource) (plugins.DataPlugin, error) {
+		return me, nil
+	})
+	bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+		query.Result = &models.DataSource{Id: 1, OrgId: 1, Type: "test"}
+		return nil
+	})
+
+	req := &Request{
+		OrgId: 1,
+		Queries: []Query{
+			{
+				RefID: "A",
+				JSON:  json.RawMessage(`{ "datasource": "test", "datasourceId": 1, "orgId": 1, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+			},
+			{
+				RefID: "B",
+				JSON:  json.RawMessage(`{ "datasource": "__expr__", "datasourceId": -100, "type": "math", "expression": "$A * 2" }`),
+			},
+		},
+	}
+	pl, err := s.BuildPipeline(req)
+	require.NoError(t, err)
+	res, err := s.ExecutePipeline(context.Background(), pl)
+	require.NoError(t, err)
+
+	require.Len(t, res.Responses["A"].Frames, 1)
+	require.Len(t, res.Responses["B"].Frames, 1)
+	require.Equal(t, fp(4), res.Responses["B"].Frames[0].Fields[1].At(0))
+}
+
+// nolint:staticcheck // plugins.DataPlugin deprecated
+func TestServiceConcurrentQueries(t *testing.T) {
+	dsDF := data.NewFrame("test",
+		data.NewField("time", nil, []*time.Time{utp(1)}),
+		data.NewField("value", nil, []*float64{fp(2)}))
+
+	dataSvc := tsdb.NewService()
+	dataSvc.PluginManager = &manager.PluginManager{
+		BackendPluginManager: fakeBackendPM{},
+	}
+	s := Service{
+		Cfg:         &setting.Cfg{ExpressionsEnabled: true, ExpressionsMaxConcurrentQueries: 2},
+		DataService: &dataSvc,
+	}
+	// Each query blocks until the other one has started, which only
+	// succeeds if both are executed concurrently.
+	started := make(chan struct{}, 2)
+	me := &mockEndpoint{
+		Frames: []*data.Frame{dsDF},
+		onQuery: func() error {
+			started <- struct{}{}
+			deadline := time.Now().Add(5 * time.Second)
+			for len(started) < 2 {
+				if time.Now().After(deadline) {
+					return fmt.Errorf("queries were not executed concurrently")
+				}
+				time.Sleep(time.Millisecond)
+			}
+			return nil
+		},
+	}
+	s.DataService.RegisterQueryHandler("test", func(*models.DataSource) (plugins.DataPlugin, error) {
+		return me, nil
+	})
+	bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+		query.Result = &models.DataSource{Id: 1, OrgId: 1, Type: "test"}
+		return nil
+	})
+
+	queries := []Query{
+		{
+			RefID: "A",
+			JSON:  json.RawMessage(`{ "datasource": "test", "datasourceId": 1, "orgId": 1, "intervalMs": 1000, "maxDataPoints": 1000, "expr": "a" }`),
+		},
+		{
+			RefID: "B",
+			JSON:  json.RawMessage(`{ "datasource": "test", "datasourceId": 1, "orgId": 1, "intervalMs": 1000, "maxDataPoints": 1000, "expr": "b" }`),
+		},
+		{
+			RefID: "C",
+			JSON:  json.RawMessage(`{ "datasource": "__expr__", "datasourceId": -100, "type": "math", "expression": "$A + $B" }`),
+		},
+	}
+
+	pl, err := s.BuildPipeline(&Request{Queries: queries})
+	require.NoError(t, err)
+
+	res, err := s.ExecutePipeline(context.Background(), pl)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&me.calls))
+	require.Len(t, res.Responses, 3)
+
+	cVal := res.Responses["C"].Frames[0].Fields[1].At(0).(*float64)
+	require.Equal(t, 4.0, *cVal)
+}
+
 func utp(sec int64) *time.Time {
 	t := time.Unix(sec, 0)
 	return &t
@@ -100,12 +289,20 @@ func fp(f float64) *float64 {
 }
 
 type mockEndpoint struct {
-	Frames data.Frames
+	Frames  data.Frames
+	calls   int32
+	onQuery func() error
 }
 
 // nolint:staticcheck // plugins.DataQueryResult deprecated
 func (me *mockEndpoint) DataQuery(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (
 	plugins.DataResponse, error) {
+	atomic.AddInt32(&me.calls, 1)
+	if me.onQuery != nil {
+		if err := me.onQuery(); err != nil {
+			return plugins.DataResponse{}, err
+		}
+	}
 	return plugins.DataResponse{
 		Results: map[string]plugins.DataQueryResult{
 			"A": {
@@ -0,0 +1,232 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// ThresholdCommand is an expression command that compares each value of a number
+// or time series against a threshold and returns 1 if it matches, or 0 if it does not.
+//
+// If RecoveryEvaluator is set, values whose labels are in LoadedDimensions (the
+// alert instances that are currently firing) keep matching until they satisfy the
+// RecoveryEvaluator, which allows separate firing and recovery thresholds.
+type ThresholdCommand struct {
+	ReferenceVar      string
+	Evaluator         ThresholdEvaluator
+	RecoveryEvaluator *ThresholdEvaluator
+	LoadedDimensions  []data.Labels
+	refID             string
+}
+
+// ThresholdEvaluator is a comparison against one or two threshold values.
+type ThresholdEvaluator struct {
+	Type   string    `json:"type"`
+	Params []float64 `json:"params"`
+}
+
+// thresholdParamCount holds the number of params each threshold evaluator type takes.
+var thresholdParamCount = map[string]int{
+	"gt":            1,
+	"lt":            1,
+	"within_range":  2,
+	"outside_range": 2,
+}
+
+// Validate returns an error if the evaluator type is unknown or has the wrong number of params.
+func (te ThresholdEvaluator) Validate() error {
+	count, ok := thresholdParamCount[te.Type]
+	if !ok {
+		return fmt.Errorf("threshold type '%v' is not implemented", te.Type)
+	}
+	if len(te.Params) != count {
+		return fmt.Errorf("threshold type '%v' requires %d params, got %d", te.Type, count, len(te.Params))
+	}
+	return nil
+}
+
+// Eval returns true if f matches the threshold. Range bounds are inclusive
+// and may be given in any order.
+func (te ThresholdEvaluator) Eval(f float64) bool {
+	switch te.Type {
+	case "gt":
+		return f > te.Params[0]
+	case "lt":
+		return f < te.Params[0]
+	case "within_range", "outside_range":
+		lower, upper := math.Min(te.Params[0], te.Params[1]), math.Max(te.Params[0], te.Params[1])
+		within := f >= lower && f <= upper
+		if te.Type == "within_range" {
+			return within
+		}
+		return !within
+	default:
+		return false
+	}
+}
+
+// NewThresholdCommand creates a new ThresholdCommand. recovery may be nil.
+func NewThresholdCommand(refID, referenceVar string, evaluator ThresholdEvaluator, recovery *ThresholdEvaluator, loadedDimensions []data.Labels) (*ThresholdCommand, error) {
+	if err := evaluator.Validate(); err != nil {
+		return nil, err
+	}
+	if recovery != nil {
+		if err := recovery.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid recovery threshold: %w", err)
+		}
+	}
+	return &ThresholdCommand{
+		ReferenceVar:      referenceVar,
+		Evaluator:         evaluator,
+		RecoveryEvaluator: recovery,
+		LoadedDimensions:  loadedDimensions,
+		refID:             refID,
+	}, nil
+}
+
+// thresholdCondition is the JSON model of a single threshold condition.
+type thresholdCondition struct {
+	Evaluator       ThresholdEvaluator  `json:"evaluator"`
+	UnloadEvaluator *ThresholdEvaluator `json:"unloadEvaluator"`
+}
+
+// UnmarshalThresholdCommand creates a ThresholdCommand from Grafana's frontend query.
+func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to reference for refId %v", rn.RefID)
+	}
+	referenceVar, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected threshold variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	referenceVar = strings.TrimPrefix(referenceVar, "$")
+
+	rawConditions, ok := rn.Query["conditions"]
+	if !ok {
+		return nil, fmt.Errorf("no conditions specified for threshold in refId %v", rn.RefID)
+	}
+	var conditions []thresholdCondition
+	if err := remarshal(rawConditions, &conditions); err != nil {
+		return nil, fmt.Errorf("failed to parse threshold conditions in refId %v: %w", rn.RefID, err)
+	}
+	if len(conditions) != 1 {
+		return nil, fmt.Errorf("threshold in refId %v requires exactly one condition, got %d", rn.RefID, len(conditions))
+	}
+
+	var loadedDimensions []data.Labels
+	if rawDimensions, ok := rn.Query["loadedDimensions"]; ok {
+		if err := remarshal(rawDimensions, &loadedDimensions); err != nil {
+			return nil, fmt.Errorf("failed to parse loaded dimensions in refId %v: %w", rn.RefID, err)
+		}
+	}
+
+	cmd, err := NewThresholdCommand(rn.RefID, referenceVar, conditions[0].Evaluator, conditions[0].UnloadEvaluator, loadedDimensions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold command in refId %v: %w", rn.RefID, err)
+	}
+	return cmd, nil
+}
+
+// remarshal converts a value of a query model into the type of v.
+func remarshal(raw interface{}, v interface{}) error {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// SetLoadedDimensions returns the query model with the labels of the currently firing
+// alert instances added when the model is a threshold command. Other models are
+// returned unchanged.
+func SetLoadedDimensions(model json.RawMessage, dimensions []data.Labels) (json.RawMessage, error) {
+	var props map[string]interface{}
+	if err := json.Unmarshal(model, &props); err != nil {
+		return nil, err
+	}
+	if t, ok := props["type"].(string); !ok || t != TypeThreshold.String() {
+		return model, nil
+	}
+	props["loadedDimensions"] = dimensions
+	return json.Marshal(props)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (tc *ThresholdCommand) NeedsVars() []string {
+	return []string{tc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (tc *ThresholdCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[tc.ReferenceVar].Values {
+		evaluator := tc.evaluatorFor(val.GetLabels())
+		switch v := val.(type) {
+		case mathexp.Scalar:
+			newRes.Values = append(newRes.Values, mathexp.NewScalar(tc.refID, evalThreshold(evaluator, v.GetFloat64Value())))
+		case mathexp.Number:
+			n := mathexp.NewNumber(tc.refID, v.GetLabels())
+			n.SetValue(evalThreshold(evaluator, v.GetFloat64Value()))
+			newRes.Values = append(newRes.Values, n)
+		case mathexp.Series:
+			s := mathexp.NewSeries(tc.refID, v.GetLabels(), v.TimeIdx, v.TimeIsNullable, v.ValueIdx, true, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				if err := s.SetPoint(i, t, evalThreshold(evaluator, f)); err != nil {
+					return newRes, err
+				}
+			}
+			newRes.Values = append(newRes.Values, s)
+		default:
+			return newRes, fmt.Errorf("can not apply a threshold to type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// evaluatorFor returns the function that decides if a value with the given labels matches.
+// Currently firing dimensions match until their recovery threshold is met.
+func (tc *ThresholdCommand) evaluatorFor(labels data.Labels) func(float64) bool {
+	if tc.RecoveryEvaluator == nil || !tc.isLoaded(labels) {
+		return tc.Evaluator.Eval
+	}
+	return func(f float64) bool {
+		return !tc.RecoveryEvaluator.Eval(f)
+	}
+}
+
+// isLoaded returns true if labels belong to a currently firing dimension. The labels of
+// a firing alert instance may hold more labels than the value it was created from,
+// so a dimension matches if it contains all of the labels.
+func (tc *ThresholdCommand) isLoaded(labels data.Labels) bool {
+	for _, dim := range tc.LoadedDimensions {
+		if dim.Contains(labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// evalThreshold returns 1 if f matches, 0 if it does not, NaN if f is NaN and nil if f is nil.
+func evalThreshold(match func(float64) bool, f *float64) *float64 {
+	if f == nil {
+		return nil
+	}
+	r := math.NaN()
+	if !math.IsNaN(*f) {
+		r = 0
+		if match(*f) {
+			r = 1
+		}
+	}
+	return &r
+}
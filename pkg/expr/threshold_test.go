@@ -0,0 +1,151 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThresholdEvaluator(t *testing.T) {
+	var tests = []struct {
+		name      string
+		evaluator ThresholdEvaluator
+		value     float64
+		expected  bool
+	}{
+		{name: "gt above", evaluator: ThresholdEvaluator{Type: "gt", Params: []float64{80}}, value: 81, expected: true},
+		{name: "gt equal", evaluator: ThresholdEvaluator{Type: "gt", Params: []float64{80}}, value: 80, expected: false},
+		{name: "lt below", evaluator: ThresholdEvaluator{Type: "lt", Params: []float64{80}}, value: 79, expected: true},
+		{name: "within_range inclusive", evaluator: ThresholdEvaluator{Type: "within_range", Params: []float64{10, 20}}, value: 20, expected: true},
+		{name: "within_range reversed params", evaluator: ThresholdEvaluator{Type: "within_range", Params: []float64{20, 10}}, value: 15, expected: true},
+		{name: "outside_range inside", evaluator: ThresholdEvaluator{Type: "outside_range", Params: []float64{10, 20}}, value: 15, expected: false},
+		{name: "outside_range outside", evaluator: ThresholdEvaluator{Type: "outside_range", Params: []float64{10, 20}}, value: 25, expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.evaluator.Validate())
+			require.Equal(t, tt.expected, tt.evaluator.Eval(tt.value))
+		})
+	}
+}
+
+func TestUnmarshalThresholdCommand(t *testing.T) {
+	var tests = []struct {
+		name  string
+		query string
+		errIs require.ErrorAssertionFunc
+	}{
+		{
+			name:  "valid",
+			query: `{"expression": "$A", "conditions": [{"evaluator": {"type": "gt", "params": [80]}}]}`,
+			errIs: require.NoError,
+		},
+		{
+			name:  "valid with recovery threshold",
+			query: `{"expression": "$A", "conditions": [{"evaluator": {"type": "gt", "params": [80]}, "unloadEvaluator": {"type": "lt", "params": [70]}}]}`,
+			errIs: require.NoError,
+		},
+		{
+			name:  "unknown type",
+			query: `{"expression": "$A", "conditions": [{"evaluator": {"type": "foo", "params": [80]}}]}`,
+			errIs: require.Error,
+		},
+		{
+			name:  "missing range param",
+			query: `{"expression": "$A", "conditions": [{"evaluator": {"type": "within_range", "params": [80]}}]}`,
+			errIs: require.Error,
+		},
+		{
+			name:  "invalid recovery threshold",
+			query: `{"expression": "$A", "conditions": [{"evaluator": {"type": "gt", "params": [80]}, "unloadEvaluator": {"type": "lt", "params": []}}]}`,
+			errIs: require.Error,
+		},
+		{
+			name:  "no conditions",
+			query: `{"expression": "$A"}`,
+			errIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rn := &rawNode{RefID: "B"}
+			require.NoError(t, json.Unmarshal([]byte(tt.query), &rn.Query))
+			cmd, err := UnmarshalThresholdCommand(rn)
+			tt.errIs(t, err)
+			if err == nil {
+				require.Equal(t, []string{"A"}, cmd.NeedsVars())
+			}
+		})
+	}
+}
+
+func TestThresholdCommandExecute(t *testing.T) {
+	numbers := func() mathexp.Vars {
+		a := mathexp.NewNumber("A", data.Labels{"host": "a"})
+		a.SetValue(fp(75))
+		b := mathexp.NewNumber("A", data.Labels{"host": "b"})
+		b.SetValue(fp(75))
+		c := mathexp.NewNumber("A", data.Labels{"host": "c"})
+		c.SetValue(nil)
+		return mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{a, b, c}}}
+	}
+
+	t.Run("without recovery threshold", func(t *testing.T) {
+		cmd, err := NewThresholdCommand("B", "A", ThresholdEvaluator{Type: "gt", Params: []float64{80}}, nil, nil)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), numbers())
+		require.NoError(t, err)
+		require.Equal(t, []*float64{fp(0), fp(0), nil}, numberValues(res))
+	})
+
+	t.Run("firing dimensions keep firing until recovery threshold is met", func(t *testing.T) {
+		recovery := &ThresholdEvaluator{Type: "lt", Params: []float64{70}}
+		loaded := []data.Labels{{"host": "a", "alertname": "test"}}
+		cmd, err := NewThresholdCommand("B", "A", ThresholdEvaluator{Type: "gt", Params: []float64{80}}, recovery, loaded)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), numbers())
+		require.NoError(t, err)
+		require.Equal(t, []*float64{fp(1), fp(0), nil}, numberValues(res))
+	})
+
+	t.Run("series", func(t *testing.T) {
+		series := mathexp.NewSeries("A", nil, 0, false, 1, true, 3)
+		require.NoError(t, series.SetPoint(0, utp(1), fp(90)))
+		require.NoError(t, series.SetPoint(1, utp(2), fp(math.NaN())))
+		require.NoError(t, series.SetPoint(2, utp(3), fp(10)))
+		cmd, err := NewThresholdCommand("B", "A", ThresholdEvaluator{Type: "outside_range", Params: []float64{0, 50}}, nil, nil)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{series}}})
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		s := res.Values[0].(mathexp.Series)
+		require.Equal(t, 1.0, *s.GetValue(0))
+		require.True(t, math.IsNaN(*s.GetValue(1)))
+		require.Equal(t, 0.0, *s.GetValue(2))
+	})
+}
+
+func TestSetLoadedDimensions(t *testing.T) {
+	dims := []data.Labels{{"host": "a"}}
+
+	model, err := SetLoadedDimensions(json.RawMessage(`{"type": "threshold", "expression": "$A"}`), dims)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type": "threshold", "expression": "$A", "loadedDimensions": [{"host": "a"}]}`, string(model))
+
+	model, err = SetLoadedDimensions(json.RawMessage(`{"type": "math", "expression": "$A"}`), dims)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type": "math", "expression": "$A"}`, string(model))
+}
+
+func numberValues(res mathexp.Results) []*float64 {
+	values := make([]*float64, 0, len(res.Values))
+	for _, v := range res.Values {
+		values = append(values, v.(mathexp.Number).GetFloat64Value())
+	}
+	return values
+}
@@ -29,6 +29,11 @@ var loginUsingGrafanaDB = func(query *models.LoginUserQuery) error {
 
 	user := userQuery.Result
 
+	// service accounts have no password and only authenticate with their tokens
+	if user.IsServiceAccount {
+		return ErrInvalidCredentials
+	}
+
 	if user.IsDisabled {
 		return ErrUserDisabled
 	}
@@ -75,28 +75,61 @@ func (s *SocialBase) httpGet(client *http.Client, url string) (response httpGetR
 }
 
 func (s *SocialBase) searchJSONForAttr(attributePath string, data []byte) (string, error) {
+	val, err := s.searchJSON(attributePath, data)
+	if err != nil {
+		return "", err
+	}
+
+	strVal, ok := val.(string)
+	if ok {
+		return strVal, nil
+	}
+
+	return "", nil
+}
+
+// searchJSONForStringArrayAttr returns the strings found at the attribute path, which can point
+// to a single string or to an array.
+func (s *SocialBase) searchJSONForStringArrayAttr(attributePath string, data []byte) ([]string, error) {
+	val, err := s.searchJSON(attributePath, data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := val.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if strVal, ok := item.(string); ok {
+				result = append(result, strVal)
+			}
+		}
+		return result, nil
+	}
+
+	return nil, nil
+}
+
+func (s *SocialBase) searchJSON(attributePath string, data []byte) (interface{}, error) {
 	if attributePath == "" {
-		return "", errors.New("no attribute path specified")
+		return nil, errors.New("no attribute path specified")
 	}
 
 	if len(data) == 0 {
-		return "", errors.New("empty user info JSON response provided")
+		return nil, errors.New("empty user info JSON response provided")
 	}
 
 	var buf interface{}
 	if err := json.Unmarshal(data, &buf); err != nil {
-		return "", errutil.Wrap("failed to unmarshal user info JSON response", err)
+		return nil, errutil.Wrap("failed to unmarshal user info JSON response", err)
 	}
 
 	val, err := jmespath.Search(attributePath, buf)
 	if err != nil {
-		return "", errutil.Wrapf(err, "failed to search user info JSON response with provided path: %q", attributePath)
+		return nil, errutil.Wrapf(err, "failed to search user info JSON response with provided path: %q", attributePath)
 	}
 
-	strVal, ok := val.(string)
-	if ok {
-		return strVal, nil
-	}
-
-	return "", nil
+	return val, nil
 }
@@ -27,6 +27,7 @@ type SocialGenericOAuth struct {
 	nameAttributePath    string
 	roleAttributePath    string
 	roleAttributeStrict  bool
+	groupsAttributePath  string
 	idTokenAttributeName string
 	teamIds              []int
 }
@@ -151,6 +152,16 @@ func (s *SocialGenericOAuth) UserInfo(client *http.Client, token *oauth2.Token)
 				userInfo.Role = role
 			}
 		}
+
+		if len(userInfo.Groups) == 0 {
+			groups, err := s.extractGroups(data)
+			if err != nil {
+				s.log.Error("Failed to extract groups", "error", err)
+			} else if len(groups) > 0 {
+				s.log.Debug("Setting user info groups from extracted groups")
+				userInfo.Groups = groups
+			}
+		}
 	}
 
 	if userInfo.Email == "" {
@@ -347,6 +358,14 @@ func (s *SocialGenericOAuth) extractRole(data *UserInfoJson) (string, error) {
 	return role, nil
 }
 
+func (s *SocialGenericOAuth) extractGroups(data *UserInfoJson) ([]string, error) {
+	if s.groupsAttributePath == "" {
+		return nil, nil
+	}
+
+	return s.searchJSONForStringArrayAttr(s.groupsAttributePath, data.rawJSON)
+}
+
 func (s *SocialGenericOAuth) FetchPrivateEmail(client *http.Client) (string, error) {
 	type Record struct {
 		Email       string `json:"email"`
@@ -541,6 +541,79 @@ func TestUserInfoSearchesForName(t *testing.T) {
 	})
 }
 
+func TestUserInfoSearchesForGroups(t *testing.T) {
+	t.Run("Given a generic OAuth provider", func(t *testing.T) {
+		provider := SocialGenericOAuth{
+			SocialBase: &SocialBase{
+				log: newLogger("generic_oauth_test", log15.LvlDebug),
+			},
+		}
+
+		tests := []struct {
+			Name                string
+			ResponseBody        interface{}
+			GroupsAttributePath string
+			ExpectedGroups      []string
+		}{
+			{
+				Name: "Given a valid groups path, a valid API response with an array, use API response",
+				ResponseBody: map[string]interface{}{
+					"email": "john.doe@example.com",
+					"info":  map[string]interface{}{"groups": []string{"admins", "editors"}},
+				},
+				GroupsAttributePath: "info.groups[*]",
+				ExpectedGroups:      []string{"admins", "editors"},
+			},
+			{
+				Name: "Given a valid groups path, a valid API response with a string, use API response",
+				ResponseBody: map[string]interface{}{
+					"email": "john.doe@example.com",
+					"group": "admins",
+				},
+				GroupsAttributePath: "group",
+				ExpectedGroups:      []string{"admins"},
+			},
+			{
+				Name: "Given no groups path, a valid API response with groups, no groups",
+				ResponseBody: map[string]interface{}{
+					"email":  "john.doe@example.com",
+					"groups": []string{"admins"},
+				},
+				GroupsAttributePath: "",
+				ExpectedGroups:      nil,
+			},
+			{
+				Name: "Given a groups path, a valid API response without groups, no groups",
+				ResponseBody: map[string]interface{}{
+					"email": "john.doe@example.com",
+				},
+				GroupsAttributePath: "groups",
+				ExpectedGroups:      nil,
+			},
+		}
+
+		for _, test := range tests {
+			provider.groupsAttributePath = test.GroupsAttributePath
+			t.Run(test.Name, func(t *testing.T) {
+				body, err := json.Marshal(test.ResponseBody)
+				require.NoError(t, err)
+				ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+					w.Header().Set("Content-Type", "application/json")
+					_, err = w.Write(body)
+					require.NoError(t, err)
+				}))
+				provider.apiUrl = ts.URL
+				token := &oauth2.Token{Expiry: time.Now()}
+
+				actualResult, err := provider.UserInfo(ts.Client(), token)
+				require.NoError(t, err)
+				require.Equal(t, test.ExpectedGroups, actualResult.Groups)
+			})
+		}
+	})
+}
+
 func TestPayloadCompression(t *testing.T) {
 	provider := SocialGenericOAuth{
 		SocialBase: &SocialBase{
@@ -186,6 +186,7 @@ func NewOAuthService() {
 				nameAttributePath:    sec.Key("name_attribute_path").String(),
 				roleAttributePath:    info.RoleAttributePath,
 				roleAttributeStrict:  info.RoleAttributeStrict,
+				groupsAttributePath:  sec.Key("groups_attribute_path").String(),
 				loginAttributePath:   sec.Key("login_attribute_path").String(),
 				idTokenAttributeName: sec.Key("id_token_attribute_name").String(),
 				teamIds:              sec.Key("team_ids").Ints(","),
@@ -197,6 +197,110 @@ func TestMiddlewareContext(t *testing.T) {
 		assert.Equal(t, "Expired API key", sc.respJson["message"])
 	})
 
+	middlewareScenario(t, "Valid API key updates its last use", func(t *testing.T, sc *scenarioContext) {
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			query.Result = &models.ApiKey{Id: 3, OrgId: 12, Role: models.ROLE_EDITOR, Key: keyhash}
+			return nil
+		})
+		var updated *models.UpdateApiKeyLastUsedCommand
+		bus.AddHandler("test", func(cmd *models.UpdateApiKeyLastUsedCommand) error {
+			updated = cmd
+			return nil
+		})
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		require.Equal(t, 200, sc.resp.Code)
+		require.NotNil(t, updated)
+		assert.Equal(t, int64(3), updated.Id)
+		assert.WithinDuration(t, time.Now(), updated.LastUsedAt, time.Minute)
+	})
+
+	middlewareScenario(t, "Valid service account token", func(t *testing.T, sc *scenarioContext) {
+		const orgID int64 = 12
+		const serviceAccountID int64 = 7
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			saID := serviceAccountID
+			query.Result = &models.ApiKey{Id: 3, OrgId: orgID, Role: models.ROLE_VIEWER, Key: keyhash, ServiceAccountId: &saID}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetUserByIdQuery) error {
+			query.Result = &models.User{Id: serviceAccountID}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetSignedInUserQuery) error {
+			if query.UserId != serviceAccountID || query.OrgId != orgID {
+				return models.ErrUserNotFound
+			}
+			query.Result = &models.SignedInUser{OrgId: orgID, UserId: serviceAccountID, OrgRole: models.ROLE_EDITOR, IsServiceAccount: true}
+			return nil
+		})
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		require.Equal(t, 200, sc.resp.Code)
+		assert.True(t, sc.context.IsSignedIn)
+		assert.True(t, sc.context.IsServiceAccount)
+		assert.Equal(t, serviceAccountID, sc.context.UserId)
+		assert.Equal(t, orgID, sc.context.OrgId)
+		assert.Equal(t, models.ROLE_EDITOR, sc.context.OrgRole)
+		assert.Equal(t, int64(3), sc.context.ApiKeyId)
+	})
+
+	middlewareScenario(t, "Valid service account token, but the service account left the organization", func(t *testing.T, sc *scenarioContext) {
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			saID := int64(7)
+			query.Result = &models.ApiKey{OrgId: 12, Role: models.ROLE_VIEWER, Key: keyhash, ServiceAccountId: &saID}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetUserByIdQuery) error {
+			query.Result = &models.User{Id: 7}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetSignedInUserQuery) error {
+			query.Result = &models.SignedInUser{OrgId: -1, UserId: 7, IsServiceAccount: true}
+			return nil
+		})
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		assert.Equal(t, 401, sc.resp.Code)
+		assert.Equal(t, contexthandler.InvalidAPIKey, sc.respJson["message"])
+	})
+
+	middlewareScenario(t, "Valid service account token, but the service account is disabled", func(t *testing.T, sc *scenarioContext) {
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			saID := int64(7)
+			query.Result = &models.ApiKey{OrgId: 12, Role: models.ROLE_VIEWER, Key: keyhash, ServiceAccountId: &saID}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetUserByIdQuery) error {
+			query.Result = &models.User{Id: 7, IsDisabled: true}
+			return nil
+		})
+		bus.AddHandler("test", func(query *models.GetSignedInUserQuery) error {
+			query.Result = &models.SignedInUser{OrgId: 12, UserId: 7, IsServiceAccount: true}
+			return nil
+		})
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		assert.Equal(t, 401, sc.resp.Code)
+		assert.Equal(t, contexthandler.InvalidAPIKey, sc.respJson["message"])
+	})
+
 	middlewareScenario(t, "Non-expired auth token in cookie which is not being rotated", func(
 		t *testing.T, sc *scenarioContext) {
 		const userID int64 = 12
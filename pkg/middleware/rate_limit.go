@@ -1,16 +1,30 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/time/rate"
 	"gopkg.in/macaron.v1"
 
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 type getTimeFn func() time.Time
 
+// rateLimitLockStripes is the number of locks the rate limit counters are spread over.
+const rateLimitLockStripes = 256
+
 // RateLimit is a very basic rate limiter.
 // Will allow average of "rps" requests per second over an extended period of time, with max "burst" requests at the same time.
 // getTime should return the current time. For non-testing purposes use time.Now
@@ -23,3 +37,114 @@ func RateLimit(rps, burst int, getTime getTimeFn) macaron.Handler {
 		}
 	}
 }
+
+// RateLimitGroup returns a function that returns a handler limiting the requests of each user, API key or client IP
+// to a route group. Requests are counted in fixed windows stored in the remote cache, so that all instances sharing
+// the cache enforce the same limits. Counting is approximate when instances update the same counter concurrently.
+// getTime should return the current time. For non-testing purposes use time.Now
+func RateLimitGroup(settings setting.RateLimitSettings, cache remotecache.CacheStorage, getTime getTimeFn) func(string) macaron.Handler {
+	// serializes the updates of each counter within this instance, without blocking the updates of other counters
+	var locks [rateLimitLockStripes]sync.Mutex
+	lockFor := func(key string) *sync.Mutex {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(key))
+		return &locks[h.Sum32()%rateLimitLockStripes]
+	}
+
+	return func(group string) macaron.Handler {
+		limit := settings.Limits[group]
+		return func(c *models.ReqContext) {
+			if !settings.Enabled || limit <= 0 || settings.Window <= 0 {
+				return
+			}
+
+			now := getTime()
+			windowStart := now.Truncate(settings.Window)
+			key := fmt.Sprintf("rate-limit-%s-%s-%d", group, rateLimitIdentity(c, settings.TrustedProxies), windowStart.Unix())
+
+			mu := lockFor(key)
+			mu.Lock()
+			count, err := incrementRateLimitCounter(cache, key, settings.Window)
+			mu.Unlock()
+			if err != nil {
+				// the API stays available when the cache isn't
+				c.Logger.Warn("Failed to update rate limit counter", "group", group, "error", err)
+				return
+			}
+
+			if count > limit {
+				retryAfter := windowStart.Add(settings.Window).Sub(now)
+				c.Resp.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(retryAfter.Seconds())), 10))
+				c.JsonApiErr(429, "Rate limit reached", nil)
+				return
+			}
+		}
+	}
+}
+
+// rateLimitIdentity returns who the request is counted for. Service account tokens are counted per token.
+func rateLimitIdentity(c *models.ReqContext, trustedProxies []*net.IPNet) string {
+	switch {
+	case c.ApiKeyId != 0:
+		return fmt.Sprintf("apikey-%d", c.ApiKeyId)
+	case c.IsSignedIn && c.UserId != 0:
+		return fmt.Sprintf("user-%d", c.UserId)
+	default:
+		return "ip-" + rateLimitClientIP(c.Req.Request, trustedProxies)
+	}
+}
+
+// rateLimitClientIP returns the IP of the client of the request. Clients can set any X-Forwarded-For header,
+// so it is only read when the request comes from a trusted proxy, from the right until an untrusted address.
+func rateLimitClientIP(req *http.Request, trustedProxies []*net.IPNet) string {
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		ip = req.RemoteAddr
+	}
+	if !isTrustedProxy(ip, trustedProxies) {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(addr) == nil {
+			break
+		}
+		ip = addr
+		if !isTrustedProxy(addr, trustedProxies) {
+			break
+		}
+	}
+	return ip
+}
+
+func isTrustedProxy(ip string, trustedProxies []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+func incrementRateLimitCounter(cache remotecache.CacheStorage, key string, window time.Duration) (int64, error) {
+	var count int64
+	value, err := cache.Get(key)
+	if err != nil && !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return 0, err
+	}
+	if err == nil {
+		count, _ = value.(int64)
+	}
+
+	count++
+	if err := cache.Set(key, count, window); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/setting"
 
@@ -89,3 +91,147 @@ func TestRateLimitMiddleware(t *testing.T) {
 		}
 	})
 }
+
+type groupExecFunc func(remoteAddr string, userID int64) *httptest.ResponseRecorder
+type rateLimitGroupScenarioFunc func(c groupExecFunc, t advanceTimeFunc)
+
+func rateLimitGroupScenario(t *testing.T, desc string, settings setting.RateLimitSettings, fn rateLimitGroupScenarioFunc) {
+	t.Helper()
+
+	t.Run(desc, func(t *testing.T) {
+		defaultHandler := func(c *models.ReqContext) {
+			resp := make(map[string]interface{})
+			resp["message"] = "OK"
+			c.JSON(200, resp)
+		}
+		currentTime := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+		var userID int64
+
+		cfg := setting.NewCfg()
+
+		m := macaron.New()
+		m.Use(macaron.Renderer(macaron.RenderOptions{
+			Directory: "",
+			Delims:    macaron.Delims{Left: "[[", Right: "]]"},
+		}))
+		m.Use(getContextHandler(t, cfg).Middleware)
+		rateLimit := RateLimitGroup(settings, remotecache.NewFakeStore(t), func() time.Time { return currentTime })
+		signIn := func(c *models.ReqContext) {
+			if userID != 0 {
+				c.IsSignedIn = true
+				c.UserId = userID
+			}
+		}
+		m.Get("/query", signIn, rateLimit("query"), defaultHandler)
+
+		fn(func(remoteAddr string, id int64) *httptest.ResponseRecorder {
+			userID = id
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/query", nil)
+			require.NoError(t, err)
+			req.RemoteAddr = remoteAddr
+			m.ServeHTTP(resp, req)
+			return resp
+		}, func(deltaTime time.Duration) {
+			currentTime = currentTime.Add(deltaTime)
+		})
+	})
+}
+
+func TestRateLimitGroupMiddleware(t *testing.T) {
+	settings := setting.RateLimitSettings{
+		Enabled: true,
+		Window:  time.Minute,
+		Limits:  map[string]int64{"query": 2},
+	}
+
+	rateLimitGroupScenario(t, "limits each client IP separately", settings, func(doReq groupExecFunc, advanceTime advanceTimeFunc) {
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, 200, doReq("10.0.0.1:5000", 0).Code)
+		}
+
+		advanceTime(15 * time.Second)
+		resp := doReq("10.0.0.1:5000", 0)
+		assert.Equal(t, 429, resp.Code)
+		assert.Equal(t, "45", resp.Header().Get("Retry-After"))
+
+		assert.Equal(t, 200, doReq("10.0.0.2:5000", 0).Code)
+
+		// requests are accepted again in the next window
+		advanceTime(45 * time.Second)
+		assert.Equal(t, 200, doReq("10.0.0.1:5000", 0).Code)
+	})
+
+	rateLimitGroupScenario(t, "limits signed in users regardless of their IP", settings, func(doReq groupExecFunc, advanceTime advanceTimeFunc) {
+		assert.Equal(t, 200, doReq("10.0.0.1:5000", 1).Code)
+		assert.Equal(t, 200, doReq("10.0.0.2:5000", 1).Code)
+		assert.Equal(t, 429, doReq("10.0.0.3:5000", 1).Code)
+
+		assert.Equal(t, 200, doReq("10.0.0.3:5000", 2).Code)
+		assert.Equal(t, 200, doReq("10.0.0.3:5000", 0).Code)
+	})
+
+	settings.Limits = map[string]int64{"query": 0}
+	rateLimitGroupScenario(t, "does not limit groups without a limit", settings, func(doReq groupExecFunc, advanceTime advanceTimeFunc) {
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, 200, doReq("10.0.0.1:5000", 0).Code)
+		}
+	})
+}
+
+func TestRateLimitClientIP(t *testing.T) {
+	_, proxies, err := net.ParseCIDR("10.0.0.0/24")
+	require.NoError(t, err)
+	trustedProxies := []*net.IPNet{proxies}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		expectedIP   string
+	}{
+		{
+			name:       "uses the peer address",
+			remoteAddr: "192.168.1.10:5000",
+			expectedIP: "192.168.1.10",
+		},
+		{
+			name:         "ignores the forwarded header of untrusted peers",
+			remoteAddr:   "192.168.1.10:5000",
+			forwardedFor: []string{"203.0.113.7"},
+			expectedIP:   "192.168.1.10",
+		},
+		{
+			name:         "uses the forwarded address of trusted proxies",
+			remoteAddr:   "10.0.0.2:5000",
+			forwardedFor: []string{"203.0.113.7"},
+			expectedIP:   "203.0.113.7",
+		},
+		{
+			name:         "ignores the forwarded addresses set by the client",
+			remoteAddr:   "10.0.0.2:5000",
+			forwardedFor: []string{"198.51.100.1, 203.0.113.7", "10.0.0.3"},
+			expectedIP:   "203.0.113.7",
+		},
+		{
+			name:         "uses the last trusted proxy when the forwarded header is invalid",
+			remoteAddr:   "10.0.0.2:5000",
+			forwardedFor: []string{"unknown"},
+			expectedIP:   "10.0.0.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/query", nil)
+			require.NoError(t, err)
+			req.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			req.Header.Set("X-Real-IP", "198.51.100.2")
+
+			assert.Equal(t, tt.expectedIP, rateLimitClientIP(req, trustedProxies))
+		})
+	}
+}
@@ -21,6 +21,14 @@ type ApiKey struct {
 	Created time.Time
 	Updated time.Time
 	Expires *int64
+	// ServiceAccountId is set for the tokens of service accounts, which authenticate as the service account.
+	ServiceAccountId *int64
+	LastUsedAt       *time.Time
+}
+
+// HasExpired returns true if the key has an expiration that was reached at now.
+func (k *ApiKey) HasExpired(now time.Time) bool {
+	return k.Expires != nil && *k.Expires <= now.Unix()
 }
 
 // ---------------------
@@ -40,6 +48,11 @@ type DeleteApiKeyCommand struct {
 	OrgId int64 `json:"-"`
 }
 
+type UpdateApiKeyLastUsedCommand struct {
+	Id         int64
+	LastUsedAt time.Time
+}
+
 // ----------------------
 // QUERIES
 
@@ -68,4 +81,5 @@ type ApiKeyDTO struct {
 	Name       string     `json:"name"`
 	Role       RoleType   `json:"role"`
 	Expiration *time.Time `json:"expiration,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
 }
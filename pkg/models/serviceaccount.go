@@ -0,0 +1,115 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrServiceAccountNotFound      = errors.New("service account not found")
+	ErrServiceAccountAlreadyExists = errors.New("service account with the same name already exists")
+	ErrServiceAccountTokenNotFound = errors.New("service account token not found")
+	ErrApiKeyAlreadyMigrated       = errors.New("API key already belongs to a service account")
+)
+
+// ---------------------
+// COMMANDS
+
+type CreateServiceAccountCommand struct {
+	Name  string   `json:"name" binding:"Required"`
+	Role  RoleType `json:"role" binding:"Required"`
+	OrgId int64    `json:"-"`
+
+	Result *ServiceAccountDTO `json:"-"`
+}
+
+type UpdateServiceAccountCommand struct {
+	Name  string   `json:"name"`
+	Role  RoleType `json:"role"`
+	Id    int64    `json:"-"`
+	OrgId int64    `json:"-"`
+
+	Result *ServiceAccountDTO `json:"-"`
+}
+
+type DeleteServiceAccountCommand struct {
+	Id    int64
+	OrgId int64
+}
+
+type AddServiceAccountTokenCommand struct {
+	Name             string `json:"name" binding:"Required"`
+	SecondsToLive    int64  `json:"secondsToLive"`
+	OrgId            int64  `json:"-"`
+	ServiceAccountId int64  `json:"-"`
+	Key              string `json:"-"`
+
+	Result *ApiKey `json:"-"`
+}
+
+// RotateServiceAccountTokenCommand replaces the secret of a token, which keeps its name.
+type RotateServiceAccountTokenCommand struct {
+	SecondsToLive    int64  `json:"secondsToLive"`
+	Id               int64  `json:"-"`
+	OrgId            int64  `json:"-"`
+	ServiceAccountId int64  `json:"-"`
+	Key              string `json:"-"`
+
+	Result *ApiKey `json:"-"`
+}
+
+type DeleteServiceAccountTokenCommand struct {
+	Id               int64
+	OrgId            int64
+	ServiceAccountId int64
+}
+
+// ConvertApiKeyToServiceAccountCommand creates a service account with the name and role of an API key,
+// which becomes the token of the service account and keeps working with the same secret.
+type ConvertApiKeyToServiceAccountCommand struct {
+	KeyId int64
+	OrgId int64
+
+	Result *ServiceAccountDTO
+}
+
+// ----------------------
+// QUERIES
+
+type GetServiceAccountsQuery struct {
+	OrgId  int64
+	Result []*ServiceAccountDTO
+}
+
+type GetServiceAccountByIdQuery struct {
+	Id     int64
+	OrgId  int64
+	Result *ServiceAccountDTO
+}
+
+type GetServiceAccountTokensQuery struct {
+	OrgId            int64
+	ServiceAccountId int64
+	Result           []*ApiKey
+}
+
+// ------------------------
+// DTO & Projections
+
+type ServiceAccountDTO struct {
+	Id     int64    `json:"id"`
+	OrgId  int64    `json:"orgId"`
+	Name   string   `json:"name"`
+	Login  string   `json:"login"`
+	Role   RoleType `json:"role"`
+	Tokens int64    `json:"tokens"`
+}
+
+type ServiceAccountTokenDTO struct {
+	Id         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Created    time.Time  `json:"created"`
+	Expiration *time.Time `json:"expiration,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	HasExpired bool       `json:"hasExpired"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Typed errors
+var (
+	ErrTeamGroupAlreadyAdded = errors.New("group is already added to this team")
+	ErrTeamGroupNotFound     = errors.New("team group not found")
+)
+
+// TeamGroup maps a group of an external authentication provider, such as an LDAP group DN
+// or an OAuth group, to a team. Users in the group are added to the team when they log in.
+type TeamGroup struct {
+	Id      int64
+	OrgId   int64
+	TeamId  int64
+	GroupId string
+
+	Created time.Time
+	Updated time.Time
+}
+
+// ---------------------
+// COMMANDS
+
+type AddTeamGroupCommand struct {
+	GroupId string `json:"groupId" binding:"Required"`
+	OrgId   int64  `json:"-"`
+	TeamId  int64  `json:"-"`
+}
+
+type RemoveTeamGroupCommand struct {
+	GroupId string
+	OrgId   int64
+	TeamId  int64
+}
+
+// ----------------------
+// QUERIES
+
+type GetTeamGroupsQuery struct {
+	OrgId  int64
+	TeamId int64
+	Result []*TeamGroupDTO
+}
+
+// GetTeamGroupsByGroupIdsQuery returns the team groups, of all organizations, mapping any of the given groups.
+type GetTeamGroupsByGroupIdsQuery struct {
+	GroupIds []string
+	Result   []*TeamGroupDTO
+}
+
+// ----------------------
+// Projections and DTOs
+
+type TeamGroupDTO struct {
+	OrgId   int64  `json:"orgId"`
+	TeamId  int64  `json:"teamId"`
+	GroupId string `json:"groupId"`
+}
@@ -32,6 +32,8 @@ type User struct {
 	Theme         string
 	HelpFlags1    HelpFlags1
 	IsDisabled    bool
+	// IsServiceAccount is true for the users used by automation, which can only authenticate with their tokens.
+	IsServiceAccount bool
 
 	IsAdmin bool
 	OrgId   int64
@@ -165,20 +167,21 @@ type GetUserOrgListQuery struct {
 // DTO & Projections
 
 type SignedInUser struct {
-	UserId         int64
-	OrgId          int64
-	OrgName        string
-	OrgRole        RoleType
-	Login          string
-	Name           string
-	Email          string
-	ApiKeyId       int64
-	OrgCount       int
-	IsGrafanaAdmin bool
-	IsAnonymous    bool
-	HelpFlags1     HelpFlags1
-	LastSeenAt     time.Time
-	Teams          []int64
+	UserId           int64
+	OrgId            int64
+	OrgName          string
+	OrgRole          RoleType
+	Login            string
+	Name             string
+	Email            string
+	ApiKeyId         int64
+	OrgCount         int
+	IsGrafanaAdmin   bool
+	IsAnonymous      bool
+	IsServiceAccount bool
+	HelpFlags1       HelpFlags1
+	LastSeenAt       time.Time
+	Teams            []int64
 }
 
 func (u *SignedInUser) ShouldUpdateLastSeenAt() bool {
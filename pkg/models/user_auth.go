@@ -9,6 +9,7 @@ import (
 
 const (
 	AuthModuleLDAP = "ldap"
+	AuthModuleSAML = "auth.saml"
 )
 
 type UserAuth struct {
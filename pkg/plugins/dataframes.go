@@ -20,6 +20,30 @@ type DataFrames interface {
 	Decoded() (data.Frames, error)
 }
 
+// exemplarResultType is the result type in the custom frame metadata of frames of exemplars.
+const exemplarResultType = "exemplar"
+
+// ExemplarFrameMeta returns the metadata of a frame of exemplars. Such frames are returned next to
+// the samples of a query for display only, and are skipped by expressions and alerting.
+func ExemplarFrameMeta() *data.FrameMeta {
+	return &data.FrameMeta{Custom: map[string]string{"resultType": exemplarResultType}}
+}
+
+// IsExemplarFrame returns whether the frame holds exemplars rather than samples.
+func IsExemplarFrame(frame *data.Frame) bool {
+	if frame == nil || frame.Meta == nil {
+		return false
+	}
+	// the custom metadata is a generic map once the frame has been encoded and decoded
+	switch custom := frame.Meta.Custom.(type) {
+	case map[string]string:
+		return custom["resultType"] == exemplarResultType
+	case map[string]interface{}:
+		return custom["resultType"] == exemplarResultType
+	}
+	return false
+}
+
 type dataFrames struct {
 	decoded data.Frames
 	encoded [][]byte
@@ -70,6 +70,12 @@ const (
 	ActionOrgUsersRemove     = "org.users:remove"
 	ActionOrgUsersRoleUpdate = "org.users.role:update"
 
+	// Service accounts actions
+	ActionServiceAccountsRead   = "serviceaccounts:read"
+	ActionServiceAccountsCreate = "serviceaccounts:create"
+	ActionServiceAccountsWrite  = "serviceaccounts:write"
+	ActionServiceAccountsDelete = "serviceaccounts:delete"
+
 	// LDAP actions
 	ActionLDAPUsersRead  = "ldap.user:read"
 	ActionLDAPUsersSync  = "ldap.user:sync"
@@ -79,6 +85,8 @@ const (
 	ScopeUsersAll  = "users:*"
 	ScopeUsersSelf = "users:self"
 
+	ScopeServiceAccountsAll = "serviceaccounts:*"
+
 	ScopeOrgAllUsersAll     = "org:*/users:*"
 	ScopeOrgCurrentUsersAll = "org:current/users:*"
 )
@@ -154,6 +154,36 @@ var usersAdminEditRole = RoleDTO{
 	}),
 }
 
+var serviceAccountsReadRole = RoleDTO{
+	Name:    serviceAccountsRead,
+	Version: 1,
+	Permissions: []Permission{
+		{
+			Action: ActionServiceAccountsRead,
+			Scope:  ScopeServiceAccountsAll,
+		},
+	},
+}
+
+var serviceAccountsEditRole = RoleDTO{
+	Name:    serviceAccountsEdit,
+	Version: 1,
+	Permissions: ConcatPermissions(serviceAccountsReadRole.Permissions, []Permission{
+		{
+			Action: ActionServiceAccountsCreate,
+			Scope:  ScopeServiceAccountsAll,
+		},
+		{
+			Action: ActionServiceAccountsWrite,
+			Scope:  ScopeServiceAccountsAll,
+		},
+		{
+			Action: ActionServiceAccountsDelete,
+			Scope:  ScopeServiceAccountsAll,
+		},
+	}),
+}
+
 // PredefinedRoles provides a map of permission sets/roles which can be
 // assigned to a set of users. When adding a new resource protected by
 // Grafana access control the default permissions should be added to a
@@ -172,6 +202,9 @@ var PredefinedRoles = map[string]RoleDTO{
 
 	ldapAdminRead: ldapAdminReadRole,
 	ldapAdminEdit: ldapAdminEditRole,
+
+	serviceAccountsRead: serviceAccountsReadRole,
+	serviceAccountsEdit: serviceAccountsEditRole,
 }
 
 const (
@@ -186,6 +219,9 @@ const (
 
 	ldapAdminEdit = "grafana:roles:ldap:admin:edit"
 	ldapAdminRead = "grafana:roles:ldap:admin:read"
+
+	serviceAccountsEdit = "grafana:roles:serviceaccounts:edit"
+	serviceAccountsRead = "grafana:roles:serviceaccounts:read"
 )
 
 // PredefinedRoleGrants specifies which organization roles are assigned
@@ -202,6 +238,8 @@ var PredefinedRoleGrants = map[string][]string{
 	string(models.ROLE_ADMIN): {
 		orgsCurrentEdit,
 		orgsCurrentRead,
+		serviceAccountsEdit,
+		serviceAccountsRead,
 	},
 }
 
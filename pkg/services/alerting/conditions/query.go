@@ -184,6 +184,10 @@ func (c *QueryCondition) executeQuery(context *alerting.EvalContext, timeRange p
 			}
 
 			for _, frame := range frames {
+				// exemplars are returned for display only and are not evaluated
+				if plugins.IsExemplarFrame(frame) {
+					continue
+				}
 				ss, err := FrameToSeriesSlice(frame)
 				if err != nil {
 					return nil, errutil.Wrapf(err,
@@ -98,6 +98,19 @@ func TestQueryCondition(t *testing.T) {
 				So(cr.Firing, ShouldBeFalse)
 			})
 
+			Convey("Should not evaluate exemplars", func() {
+				ctx.frame = data.NewFrame("exemplar",
+					data.NewField("time", nil, []time.Time{time.Now()}),
+					data.NewField("value", nil, []float64{500}),
+					data.NewField("traceID", nil, []string{"abc"}),
+				).SetMeta(plugins.ExemplarFrameMeta())
+				cr, err := ctx.exec()
+
+				So(err, ShouldBeNil)
+				So(cr.Firing, ShouldBeFalse)
+				So(cr.NoDataFound, ShouldBeTrue)
+			})
+
 			Convey("Should fire if only first series matches", func() {
 				ctx.series = plugins.DataTimeSeriesSlice{
 					plugins.DataTimeSeries{Name: "test1", Points: newTimeSeriesPointsFromArgs(120, 0)},
@@ -15,12 +15,16 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 type CleanUpService struct {
 	log               log.Logger
 	Cfg               *setting.Cfg                  `inject:""`
+	SQLStore          *sqlstore.SQLStore            `inject:""`
 	ServerLockService *serverlock.ServerLockService `inject:""`
 	ShortURLService   *shorturls.ShortURLService    `inject:""`
 }
@@ -50,6 +54,7 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			srv.cleanUpOldAnnotations(ctxWithTimeout)
 			srv.expireOldUserInvites()
 			srv.deleteStaleShortURLs()
+			srv.deleteExpiredAlertStateHistory()
 			err := srv.ServerLockService.LockAndExecute(ctx, "delete old login attempts",
 				time.Minute*10, func() {
 					srv.deleteOldLoginAttempts()
@@ -168,3 +173,20 @@ func (srv *CleanUpService) deleteStaleShortURLs() {
 		srv.log.Debug("Deleted short urls", "rows affected", cmd.NumDeleted)
 	}
 }
+
+func (srv *CleanUpService) deleteExpiredAlertStateHistory() {
+	maxAge := srv.Cfg.UnifiedAlerting.StateHistoryMaxAge
+	if !srv.Cfg.IsNgAlertEnabled() || maxAge == 0 {
+		return
+	}
+
+	cmd := ngmodels.DeleteExpiredAlertStateHistoryCommand{
+		OlderThan: time.Now().Add(-maxAge),
+	}
+	store := ngstore.DBstore{SQLStore: srv.SQLStore}
+	if err := store.DeleteExpiredAlertStateHistory(&cmd); err != nil {
+		srv.log.Error("Problem deleting expired alert state history", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired alert state history", "rows affected", cmd.DeletedRows)
+	}
+}
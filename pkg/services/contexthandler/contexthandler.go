@@ -33,6 +33,9 @@ const (
 
 const ServiceName = "ContextHandler"
 
+// apiKeyLastUsedInterval is how often the last use of an API key is updated.
+const apiKeyLastUsedInterval = time.Minute
+
 func init() {
 	registry.Register(&registry.Descriptor{
 		Name:         ServiceName,
@@ -186,11 +189,50 @@ func (h *ContextHandler) initContextWithAPIKey(ctx *models.ReqContext) bool {
 	if getTime == nil {
 		getTime = time.Now
 	}
-	if apikey.Expires != nil && *apikey.Expires <= getTime().Unix() {
+	now := getTime()
+	if apikey.HasExpired(now) {
 		ctx.JsonApiErr(401, "Expired API key", err)
 		return true
 	}
 
+	if apikey.LastUsedAt == nil || now.Sub(*apikey.LastUsedAt) > apiKeyLastUsedInterval {
+		if err := bus.Dispatch(&models.UpdateApiKeyLastUsedCommand{Id: apikey.Id, LastUsedAt: now}); err != nil {
+			ctx.Logger.Warn("Failed to update the last use of the API key", "keyId", apikey.Id, "error", err)
+		}
+	}
+
+	// the tokens of service accounts authenticate as their service account
+	if apikey.ServiceAccountId != nil {
+		userQuery := models.GetUserByIdQuery{Id: *apikey.ServiceAccountId}
+		if err := bus.Dispatch(&userQuery); err != nil {
+			ctx.Logger.Error("Failed to get the service account of the API key", "keyId", apikey.Id, "error", err)
+			ctx.JsonApiErr(401, InvalidAPIKey, err)
+			return true
+		}
+		if userQuery.Result.IsDisabled {
+			ctx.JsonApiErr(401, InvalidAPIKey, errors.New("service account is disabled"))
+			return true
+		}
+
+		query := models.GetSignedInUserQuery{UserId: *apikey.ServiceAccountId, OrgId: apikey.OrgId}
+		if err := bus.Dispatch(&query); err != nil {
+			ctx.Logger.Error("Failed to get the service account of the API key", "keyId", apikey.Id, "error", err)
+			ctx.JsonApiErr(401, InvalidAPIKey, err)
+			return true
+		}
+		if query.Result.OrgId != apikey.OrgId {
+			ctx.JsonApiErr(401, InvalidAPIKey, errors.New("service account is not a member of the organization of the API key"))
+			return true
+		}
+
+		// the signed in user is cached, so it's copied before it's tied to the key
+		user := *query.Result
+		user.ApiKeyId = apikey.Id
+		ctx.IsSignedIn = true
+		ctx.SignedInUser = &user
+		return true
+	}
+
 	ctx.IsSignedIn = true
 	ctx.SignedInUser = &models.SignedInUser{}
 	ctx.OrgRole = apikey.Role
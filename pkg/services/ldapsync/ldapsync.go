@@ -0,0 +1,195 @@
+// Package ldapsync synchronizes the users who logged in with LDAP with the LDAP servers in the background.
+package ldapsync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/multildap"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	getLDAPConfig = multildap.GetConfig
+	newLDAP       = multildap.New
+	getTime       = time.Now
+)
+
+// usersPageSize is the number of Grafana users searched in LDAP at once.
+const usersPageSize = 500
+
+// cronParser parses the sync_cron setting, whose first field is the seconds.
+var cronParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+func init() {
+	registry.RegisterService(&LDAPSyncService{})
+}
+
+// LDAPSyncService updates the LDAP users on the schedule of the sync_cron setting.
+// The users found in LDAP get their information, organization roles and teams updated,
+// while the users that are gone are disabled and logged out.
+type LDAPSyncService struct {
+	Cfg               *setting.Cfg                  `inject:""`
+	Bus               bus.Bus                       `inject:""`
+	ServerLockService *serverlock.ServerLockService `inject:""`
+	AuthTokenService  models.UserTokenService       `inject:""`
+
+	log      log.Logger
+	schedule cron.Schedule
+}
+
+func (s *LDAPSyncService) Init() error {
+	s.log = log.New("ldapsync")
+	if s.IsDisabled() {
+		return nil
+	}
+
+	schedule, err := cronParser.Parse(s.Cfg.LDAPSyncCron)
+	if err != nil {
+		return fmt.Errorf("failed to parse LDAP sync_cron %q: %w", s.Cfg.LDAPSyncCron, err)
+	}
+	s.schedule = schedule
+	return nil
+}
+
+// IsDisabled returns true if LDAP or its active synchronization is disabled.
+func (s *LDAPSyncService) IsDisabled() bool {
+	return s.Cfg == nil || !s.Cfg.LDAPEnabled || !s.Cfg.LDAPActiveSyncEnabled
+}
+
+func (s *LDAPSyncService) Run(ctx context.Context) error {
+	for {
+		next := s.schedule.Next(getTime())
+		// every server runs the schedule, the server lock makes sure only one of them syncs the users
+		lockInterval := s.schedule.Next(next).Sub(next) / 2
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			err := s.ServerLockService.LockAndExecute(ctx, "ldap sync", lockInterval, func() {
+				if err := s.SyncUsers(ctx); err != nil {
+					s.log.Error("Failed to sync LDAP users", "error", err)
+				}
+			})
+			if err != nil {
+				s.log.Error("Failed to lock and execute LDAP sync", "error", err)
+			}
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// SyncUsers pages through the Grafana users that logged in with LDAP and syncs them with the LDAP servers.
+// Nothing is synced if any LDAP server is unavailable, since its users would otherwise be disabled.
+func (s *LDAPSyncService) SyncUsers(ctx context.Context) error {
+	start := getTime()
+
+	ldapConfig, err := getLDAPConfig(s.Cfg)
+	if err != nil {
+		return fmt.Errorf("failed to get LDAP config: %w", err)
+	}
+	if ldapConfig == nil {
+		return nil
+	}
+	ldapServer := newLDAP(ldapConfig.Servers)
+
+	statuses, err := ldapServer.Ping()
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if !status.Available {
+			return fmt.Errorf("LDAP server %s:%d is unavailable: %w", status.Host, status.Port, status.Error)
+		}
+	}
+
+	var synced, disabled int
+	for page := 1; ; page++ {
+		query := &models.SearchUsersQuery{AuthModule: models.AuthModuleLDAP, Page: page, Limit: usersPageSize}
+		if err := s.Bus.Dispatch(query); err != nil {
+			return err
+		}
+		if len(query.Result.Users) == 0 {
+			break
+		}
+
+		pageSynced, pageDisabled, err := s.syncUsersPage(ctx, ldapServer, query.Result.Users)
+		if err != nil {
+			return err
+		}
+		synced += pageSynced
+		disabled += pageDisabled
+
+		if len(query.Result.Users) < usersPageSize {
+			break
+		}
+	}
+
+	s.log.Info("Synced LDAP users", "synced", synced, "disabled", disabled, "duration", getTime().Sub(start))
+	return nil
+}
+
+// syncUsersPage syncs the users found in LDAP, and disables and logs out the other ones.
+func (s *LDAPSyncService) syncUsersPage(ctx context.Context, ldapServer multildap.IMultiLDAP, users []*models.UserSearchHitDTO) (int, int, error) {
+	logins := make([]string, 0, len(users))
+	for _, user := range users {
+		logins = append(logins, user.Login)
+	}
+
+	externalUsers, err := ldapServer.Users(logins)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to search LDAP users: %w", err)
+	}
+	found := make(map[string]*models.ExternalUserInfo, len(externalUsers))
+	for _, externalUser := range externalUsers {
+		found[strings.ToLower(externalUser.Login)] = externalUser
+	}
+
+	synced := 0
+	disableUserIds := make([]int64, 0)
+	for _, user := range users {
+		externalUser, ok := found[strings.ToLower(user.Login)]
+		if !ok {
+			if user.Login == s.Cfg.AdminUser {
+				s.log.Warn("Refusing to disable Grafana server admin not found in LDAP", "user", user.Login)
+				continue
+			}
+			if !user.IsDisabled {
+				disableUserIds = append(disableUserIds, user.Id)
+			}
+			continue
+		}
+
+		// the user is upserted like at login: a disabled user is enabled again, and the roles and teams are synced
+		cmd := &models.UpsertUserCommand{ExternalUser: externalUser, SignupAllowed: false}
+		if err := s.Bus.Dispatch(cmd); err != nil {
+			s.log.Error("Failed to sync LDAP user", "user", user.Login, "error", err)
+			continue
+		}
+		synced++
+	}
+
+	if len(disableUserIds) == 0 {
+		return synced, 0, nil
+	}
+
+	s.log.Debug("Disabling users not found in LDAP", "userIds", disableUserIds)
+	if err := s.Bus.Dispatch(&models.BatchDisableUsersCommand{UserIds: disableUserIds, IsDisabled: true}); err != nil {
+		return synced, 0, fmt.Errorf("failed to disable users not found in LDAP: %w", err)
+	}
+	if err := s.AuthTokenService.BatchRevokeAllUserTokens(ctx, disableUserIds); err != nil {
+		return synced, 0, fmt.Errorf("failed to revoke the tokens of users not found in LDAP: %w", err)
+	}
+	return synced, len(disableUserIds), nil
+}
@@ -0,0 +1,194 @@
+package ldapsync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/ldap"
+	"github.com/grafana/grafana/pkg/services/multildap"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type ldapMock struct {
+	statuses []*multildap.ServerStatus
+	users    map[string]*models.ExternalUserInfo
+	searched [][]string
+}
+
+func (m *ldapMock) Ping() ([]*multildap.ServerStatus, error) {
+	return m.statuses, nil
+}
+
+func (m *ldapMock) Login(query *models.LoginUserQuery) (*models.ExternalUserInfo, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *ldapMock) Users(logins []string) ([]*models.ExternalUserInfo, error) {
+	m.searched = append(m.searched, logins)
+	result := make([]*models.ExternalUserInfo, 0)
+	for _, login := range logins {
+		if user, ok := m.users[login]; ok {
+			result = append(result, user)
+		}
+	}
+	return result, nil
+}
+
+func (m *ldapMock) User(login string) (*models.ExternalUserInfo, ldap.ServerConfig, error) {
+	return nil, ldap.ServerConfig{}, errors.New("not implemented")
+}
+
+type syncResult struct {
+	upserted []string
+	disabled []int64
+	revoked  []int64
+}
+
+func setupSyncService(t *testing.T, mock *ldapMock, users []*models.UserSearchHitDTO) (*LDAPSyncService, *syncResult) {
+	t.Helper()
+
+	origGetLDAPConfig, origNewLDAP := getLDAPConfig, newLDAP
+	t.Cleanup(func() { getLDAPConfig, newLDAP = origGetLDAPConfig, origNewLDAP })
+	getLDAPConfig = func(*setting.Cfg) (*ldap.Config, error) {
+		return &ldap.Config{Servers: []*ldap.ServerConfig{{Host: "localhost", Port: 389}}}, nil
+	}
+	newLDAP = func([]*ldap.ServerConfig) multildap.IMultiLDAP {
+		return mock
+	}
+
+	result := &syncResult{}
+	b := bus.New()
+	b.AddHandler(func(query *models.SearchUsersQuery) error {
+		require.Equal(t, models.AuthModuleLDAP, query.AuthModule)
+		start := (query.Page - 1) * query.Limit
+		end := start + query.Limit
+		if start > len(users) {
+			start = len(users)
+		}
+		if end > len(users) {
+			end = len(users)
+		}
+		query.Result = models.SearchUserQueryResult{Users: users[start:end], TotalCount: int64(len(users))}
+		return nil
+	})
+	b.AddHandler(func(cmd *models.UpsertUserCommand) error {
+		require.False(t, cmd.SignupAllowed)
+		result.upserted = append(result.upserted, cmd.ExternalUser.Login)
+		return nil
+	})
+	b.AddHandler(func(cmd *models.BatchDisableUsersCommand) error {
+		require.True(t, cmd.IsDisabled)
+		result.disabled = append(result.disabled, cmd.UserIds...)
+		return nil
+	})
+
+	tokenService := auth.NewFakeUserAuthTokenService()
+	tokenService.BatchRevokedTokenProvider = func(ctx context.Context, userIds []int64) error {
+		result.revoked = append(result.revoked, userIds...)
+		return nil
+	}
+
+	cfg := setting.NewCfg()
+	cfg.AdminUser = "admin"
+	return &LDAPSyncService{
+		Cfg:              cfg,
+		Bus:              b,
+		AuthTokenService: tokenService,
+		log:              log.New("ldapsync.test"),
+	}, result
+}
+
+func TestLDAPSyncService_SyncUsers(t *testing.T) {
+	available := []*multildap.ServerStatus{{Host: "localhost", Port: 389, Available: true}}
+
+	t.Run("syncs the users found in LDAP and disables the other ones", func(t *testing.T) {
+		mock := &ldapMock{
+			statuses: available,
+			users: map[string]*models.ExternalUserInfo{
+				"alice": {Login: "alice", AuthModule: models.AuthModuleLDAP},
+				"carol": {Login: "carol", AuthModule: models.AuthModuleLDAP},
+			},
+		}
+		s, result := setupSyncService(t, mock, []*models.UserSearchHitDTO{
+			{Id: 1, Login: "admin"},
+			{Id: 2, Login: "alice"},
+			{Id: 3, Login: "bob"},
+			{Id: 4, Login: "carol", IsDisabled: true},
+			{Id: 5, Login: "dave", IsDisabled: true},
+		})
+
+		require.NoError(t, s.SyncUsers(context.Background()))
+		require.Equal(t, []string{"alice", "carol"}, result.upserted)
+		require.Equal(t, []int64{3}, result.disabled)
+		require.Equal(t, []int64{3}, result.revoked)
+	})
+
+	t.Run("pages through the users", func(t *testing.T) {
+		users := make([]*models.UserSearchHitDTO, 0, usersPageSize+1)
+		for i := 0; i <= usersPageSize; i++ {
+			users = append(users, &models.UserSearchHitDTO{Id: int64(i + 1), Login: "user"})
+		}
+		mock := &ldapMock{statuses: available}
+		s, result := setupSyncService(t, mock, users)
+
+		require.NoError(t, s.SyncUsers(context.Background()))
+		require.Len(t, mock.searched, 2)
+		require.Len(t, mock.searched[0], usersPageSize)
+		require.Len(t, mock.searched[1], 1)
+		require.Len(t, result.disabled, usersPageSize+1)
+	})
+
+	t.Run("does nothing when an LDAP server is unavailable", func(t *testing.T) {
+		mock := &ldapMock{statuses: []*multildap.ServerStatus{
+			{Host: "localhost", Port: 389, Available: true},
+			{Host: "remote", Port: 389, Available: false, Error: errors.New("connection refused")},
+		}}
+		s, result := setupSyncService(t, mock, []*models.UserSearchHitDTO{{Id: 2, Login: "alice"}})
+
+		err := s.SyncUsers(context.Background())
+		require.EqualError(t, err, "LDAP server remote:389 is unavailable: connection refused")
+		require.Empty(t, mock.searched)
+		require.Empty(t, result.disabled)
+		require.Empty(t, result.revoked)
+	})
+}
+
+func TestLDAPSyncService_Init(t *testing.T) {
+	t.Run("is disabled unless LDAP and active sync are enabled", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.LDAPEnabled = true
+		s := &LDAPSyncService{Cfg: cfg}
+		require.NoError(t, s.Init())
+		require.True(t, s.IsDisabled())
+	})
+
+	t.Run("parses the sync cron with seconds", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.LDAPEnabled = true
+		cfg.LDAPActiveSyncEnabled = true
+		cfg.LDAPSyncCron = "0 0 1 * * *"
+		s := &LDAPSyncService{Cfg: cfg}
+		require.NoError(t, s.Init())
+		require.False(t, s.IsDisabled())
+
+		now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+		require.Equal(t, time.Date(2021, 6, 2, 1, 0, 0, 0, time.UTC), s.schedule.Next(now))
+	})
+
+	t.Run("fails with an invalid sync cron", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.LDAPEnabled = true
+		cfg.LDAPActiveSyncEnabled = true
+		cfg.LDAPSyncCron = "every day"
+		s := &LDAPSyncService{Cfg: cfg}
+		require.Error(t, s.Init())
+	})
+}
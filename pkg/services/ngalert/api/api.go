@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
@@ -46,17 +47,19 @@ type Alertmanager interface {
 
 // API handlers.
 type API struct {
-	Cfg             *setting.Cfg
-	DatasourceCache datasources.CacheService
-	RouteRegister   routing.RouteRegister
-	DataService     *tsdb.Service
-	Schedule        schedule.ScheduleService
-	Store           store.Store
-	RuleStore       store.RuleStore
-	AlertingStore   store.AlertingStore
-	DataProxy       *datasourceproxy.DatasourceProxyService
-	Alertmanager    Alertmanager
-	StateManager    *state.Manager
+	Cfg               *setting.Cfg
+	DatasourceCache   datasources.CacheService
+	RouteRegister     routing.RouteRegister
+	DataService       *tsdb.Service
+	Schedule          schedule.ScheduleService
+	Store             store.Store
+	RuleStore         store.RuleStore
+	AlertingStore     store.AlertingStore
+	DataProxy         *datasourceproxy.DatasourceProxyService
+	Alertmanagers     *notifier.MultiOrgAlertmanager
+	StateManager      *state.Manager
+	StateHistoryStore store.StateHistoryStore
+	ProvisioningStore store.ProvisioningStore
 }
 
 // RegisterAPIEndpoints registers API handlers
@@ -70,7 +73,7 @@ func (api *API) RegisterAPIEndpoints() {
 	api.RegisterAlertmanagerApiEndpoints(NewForkedAM(
 		api.DatasourceCache,
 		NewLotexAM(proxy, logger),
-		AlertmanagerSrv{store: api.AlertingStore, am: api.Alertmanager, log: logger},
+		AlertmanagerSrv{store: api.AlertingStore, provisioningStore: api.ProvisioningStore, mam: api.Alertmanagers, log: logger},
 	), metrics)
 	// Register endpoints for proxing to Prometheus-compatible backends.
 	api.RegisterPrometheusApiEndpoints(NewForkedProm(
@@ -82,13 +85,14 @@ func (api *API) RegisterAPIEndpoints() {
 	api.RegisterRulerApiEndpoints(NewForkedRuler(
 		api.DatasourceCache,
 		NewLotexRuler(proxy, logger),
-		RulerSrv{DatasourceCache: api.DatasourceCache, store: api.RuleStore, log: logger},
+		RulerSrv{DatasourceCache: api.DatasourceCache, store: api.RuleStore, provisioningStore: api.ProvisioningStore, log: logger},
 	), metrics)
 	api.RegisterTestingApiEndpoints(TestingApiSrv{
 		AlertingProxy:   proxy,
 		Cfg:             api.Cfg,
 		DataService:     api.DataService,
 		DatasourceCache: api.DatasourceCache,
+		mam:             api.Alertmanagers,
 		log:             logger,
 	}, metrics)
 
@@ -128,4 +132,13 @@ func (api *API) RegisterAPIEndpoints() {
 	api.RouteRegister.Group("/api/alert-instances", func(alertInstances routing.RouteRegister) {
 		alertInstances.Get("", middleware.ReqSignedIn, routing.Wrap(api.listAlertInstancesEndpoint))
 	})
+
+	api.RouteRegister.Group("/api/alert-state-history", func(stateHistory routing.RouteRegister) {
+		stateHistory.Get("", middleware.ReqSignedIn, routing.Wrap(api.getAlertStateHistoryEndpoint))
+	})
+
+	api.RouteRegister.Group("/api/ruler/grafana/api/v1", func(ruleFiles routing.RouteRegister) {
+		ruleFiles.Get("/export", middleware.ReqSignedIn, routing.Wrap(api.exportRulesEndpoint))
+		ruleFiles.Post("/import", middleware.ReqEditorRole, routing.Wrap(api.importRulesEndpoint))
+	})
 }
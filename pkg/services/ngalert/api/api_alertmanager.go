@@ -15,13 +15,31 @@ import (
 )
 
 type AlertmanagerSrv struct {
-	am    Alertmanager
-	store store.AlertingStore
-	log   log.Logger
+	mam               *notifier.MultiOrgAlertmanager
+	store             store.AlertingStore
+	provisioningStore store.ProvisioningStore
+	log               log.Logger
+}
+
+// loadAlertmanager returns the Alertmanager of the organization of the signed in user.
+func (srv AlertmanagerSrv) loadAlertmanager(orgID int64) (Alertmanager, *response.NormalResponse) {
+	am, err := srv.mam.AlertmanagerFor(orgID)
+	if err != nil {
+		if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+			return nil, response.Error(http.StatusNotFound, err.Error(), nil)
+		}
+		return nil, response.Error(http.StatusInternalServerError, "failed to get Alertmanager of the organization", err)
+	}
+	return am, nil
 }
 
 func (srv AlertmanagerSrv) RouteCreateSilence(c *models.ReqContext, postableSilence apimodels.PostableSilence) response.Response {
-	silenceID, err := srv.am.CreateSilence(&postableSilence)
+	am, errResp := srv.loadAlertmanager(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	silenceID, err := am.CreateSilence(&postableSilence)
 	if err != nil {
 		if errors.Is(err, notifier.ErrSilenceNotFound) {
 			return response.Error(http.StatusNotFound, err.Error(), nil)
@@ -42,8 +60,13 @@ func (srv AlertmanagerSrv) RouteDeleteAlertingConfig(c *models.ReqContext) respo
 }
 
 func (srv AlertmanagerSrv) RouteDeleteSilence(c *models.ReqContext) response.Response {
+	am, errResp := srv.loadAlertmanager(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
 	silenceID := c.Params(":SilenceId")
-	if err := srv.am.DeleteSilence(silenceID); err != nil {
+	if err := am.DeleteSilence(silenceID); err != nil {
 		if errors.Is(err, notifier.ErrSilenceNotFound) {
 			return response.Error(http.StatusNotFound, err.Error(), nil)
 		}
@@ -53,7 +76,7 @@ func (srv AlertmanagerSrv) RouteDeleteSilence(c *models.ReqContext) response.Res
 }
 
 func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response.Response {
-	query := ngmodels.GetLatestAlertmanagerConfigurationQuery{}
+	query := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: c.OrgId}
 	if err := srv.store.GetLatestAlertmanagerConfiguration(&query); err != nil {
 		if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
 			return response.Error(http.StatusNotFound, err.Error(), nil)
@@ -66,11 +89,17 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 		return response.Error(http.StatusInternalServerError, "failed to unmarshal alertmanager configuration", err)
 	}
 
+	provisioned, err := srv.isConfigProvisioned(c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get provisioned configuration", err)
+	}
+
 	result := apimodels.GettableUserConfig{
 		TemplateFiles: cfg.TemplateFiles,
 		AlertmanagerConfig: apimodels.GettableApiAlertingConfig{
 			Config: cfg.AlertmanagerConfig.Config,
 		},
+		Provisioned: provisioned,
 	}
 	for _, recv := range cfg.AlertmanagerConfig.Receivers {
 		receivers := make([]*apimodels.GettableGrafanaReceiver, 0, len(recv.PostableGrafanaReceivers.GrafanaManagedReceivers))
@@ -105,7 +134,12 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 }
 
 func (srv AlertmanagerSrv) RouteGetAMAlertGroups(c *models.ReqContext) response.Response {
-	groups, err := srv.am.GetAlertGroups(
+	am, errResp := srv.loadAlertmanager(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	groups, err := am.GetAlertGroups(
 		c.QueryBoolWithDefault("active", true),
 		c.QueryBoolWithDefault("silenced", true),
 		c.QueryBoolWithDefault("inhibited", true),
@@ -124,7 +158,12 @@ func (srv AlertmanagerSrv) RouteGetAMAlertGroups(c *models.ReqContext) response.
 }
 
 func (srv AlertmanagerSrv) RouteGetAMAlerts(c *models.ReqContext) response.Response {
-	alerts, err := srv.am.GetAlerts(
+	am, errResp := srv.loadAlertmanager(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	alerts, err := am.GetAlerts(
 		c.QueryBoolWithDefault("active", true),
 		c.QueryBoolWithDefault("silenced", true),
 		c.QueryBoolWithDefault("inhibited", true),
@@ -143,8 +182,13 @@ func (srv AlertmanagerSrv) RouteGetAMAlerts(c *models.ReqContext) response.Respo
 }
 
 func (srv AlertmanagerSrv) RouteGetSilence(c *models.ReqContext) response.Response {
+	am, errResp := srv.loadAlertmanager(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
 	silenceID := c.Params(":SilenceId")
-	gettableSilence, err := srv.am.GetSilence(silenceID)
+	gettableSilence, err := am.GetSilence(silenceID)
 	if err != nil {
 		if errors.Is(err, notifier.ErrSilenceNotFound) {
 			return response.Error(http.StatusNotFound, err.Error(), nil)
@@ -156,7 +200,12 @@ func (srv AlertmanagerSrv) RouteGetSilence(c *models.ReqContext) response.Respon
 }
 
 func (srv AlertmanagerSrv) RouteGetSilences(c *models.ReqContext) response.Response {
-	gettableSilences, err := srv.am.ListSilences(c.QueryStrings("filter"))
+	am, errResp := srv.loadAlertmanager(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	gettableSilences, err := am.ListSilences(c.QueryStrings("filter"))
 	if err != nil {
 		if errors.Is(err, notifier.ErrListSilencesBadPayload) {
 			return response.Error(http.StatusBadRequest, err.Error(), nil)
@@ -168,13 +217,35 @@ func (srv AlertmanagerSrv) RouteGetSilences(c *models.ReqContext) response.Respo
 }
 
 func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body apimodels.PostableUserConfig) response.Response {
-	if err := srv.am.SaveAndApplyConfig(&body); err != nil {
+	am, errResp := srv.loadAlertmanager(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	provisioned, err := srv.isConfigProvisioned(c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get provisioned configuration", err)
+	}
+	if provisioned {
+		return response.Error(http.StatusBadRequest, ngmodels.ErrAlertmanagerConfigurationProvisioned.Error(), nil)
+	}
+
+	if err := am.SaveAndApplyConfig(&body); err != nil {
 		return response.Error(http.StatusInternalServerError, "failed to save and apply Alertmanager configuration", err)
 	}
 
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "configuration created"})
 }
 
+// isConfigProvisioned returns whether the Alertmanager configuration of the organization is provisioned from a file.
+func (srv AlertmanagerSrv) isConfigProvisioned(orgID int64) (bool, error) {
+	q := ngmodels.GetAlertProvisioningQuery{OrgID: orgID, Kind: ngmodels.ProvisionedAlertmanagerConfiguration}
+	if err := srv.provisioningStore.GetAlertProvisioning(&q); err != nil {
+		return false, err
+	}
+	return len(q.Result) > 0, nil
+}
+
 func (srv AlertmanagerSrv) RoutePostAMAlerts(c *models.ReqContext, body apimodels.PostableAlerts) response.Response {
 	// not implemented
 	return response.Error(http.StatusNotImplemented, "", nil)
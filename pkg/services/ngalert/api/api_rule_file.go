@@ -0,0 +1,194 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// exportRulesEndpoint handles GET /api/ruler/grafana/api/v1/export.
+// It returns the rule groups of the organization as a YAML rule file, or only those of the
+// namespace given by its title with the namespace parameter.
+func (api *API) exportRulesEndpoint(c *models.ReqContext) response.Response {
+	var rules []*ngmodels.AlertRule
+	if namespaceTitle := c.Query("namespace"); namespaceTitle != "" {
+		namespace, err := api.RuleStore.GetNamespaceByTitle(namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, false)
+		if err != nil {
+			return toNamespaceErrorResponse(err)
+		}
+		q := ngmodels.ListNamespaceAlertRulesQuery{OrgID: c.SignedInUser.OrgId, NamespaceUID: namespace.Uid}
+		if err := api.RuleStore.GetNamespaceAlertRules(&q); err != nil {
+			return response.Error(http.StatusInternalServerError, "failed to get alert rules", err)
+		}
+		rules = q.Result
+	} else {
+		q := ngmodels.ListAlertRulesQuery{OrgID: c.SignedInUser.OrgId}
+		if err := api.RuleStore.GetOrgAlertRules(&q); err != nil {
+			return response.Error(http.StatusInternalServerError, "failed to get alert rules", err)
+		}
+		rules = q.Result
+	}
+
+	ruleFile, err := toRuleFile(api.RuleStore, rules, c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	b, err := yaml.Marshal(ruleFile)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to marshal rule file", err)
+	}
+	return response.Respond(http.StatusOK, b).SetHeader("Content-Type", "application/yaml")
+}
+
+// toRuleFile groups the rules by namespace and rule group, sorted by name.
+func toRuleFile(ruleStore store.RuleStore, rules []*ngmodels.AlertRule, user *models.SignedInUser) (apimodels.RuleFile, error) {
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	namespaceTitles := make(map[string]string)
+	groups := make(map[string]map[string]*apimodels.PostableRuleGroupConfig)
+	for _, r := range rules {
+		title, ok := namespaceTitles[r.NamespaceUID]
+		if !ok {
+			folder, err := ruleStore.GetNamespaceByUID(r.NamespaceUID, r.OrgID, user)
+			if err != nil {
+				return apimodels.RuleFile{}, err
+			}
+			title = folder.Title
+			namespaceTitles[r.NamespaceUID] = title
+			groups[title] = make(map[string]*apimodels.PostableRuleGroupConfig)
+		}
+
+		group, ok := groups[title][r.RuleGroup]
+		if !ok {
+			group = &apimodels.PostableRuleGroupConfig{
+				Name:     r.RuleGroup,
+				Interval: model.Duration(time.Duration(r.IntervalSeconds) * time.Second),
+			}
+			groups[title][r.RuleGroup] = group
+		}
+		group.Rules = append(group.Rules, toPostableExtendedRuleNode(*r))
+	}
+
+	ruleFile := apimodels.RuleFile{Namespaces: make([]apimodels.RuleFileNamespace, 0, len(groups))}
+	for title, namespaceGroups := range groups {
+		namespace := apimodels.RuleFileNamespace{Name: title, Groups: make([]apimodels.PostableRuleGroupConfig, 0, len(namespaceGroups))}
+		for _, group := range namespaceGroups {
+			namespace.Groups = append(namespace.Groups, *group)
+		}
+		sort.Slice(namespace.Groups, func(i, j int) bool { return namespace.Groups[i].Name < namespace.Groups[j].Name })
+		ruleFile.Namespaces = append(ruleFile.Namespaces, namespace)
+	}
+	sort.Slice(ruleFile.Namespaces, func(i, j int) bool { return ruleFile.Namespaces[i].Name < ruleFile.Namespaces[j].Name })
+	return ruleFile, nil
+}
+
+// importRulesEndpoint handles POST /api/ruler/grafana/api/v1/import.
+// The body is a YAML rule file. Each rule group of the file replaces the rule group with the same name
+// in its namespace, the other rule groups are left as they are. The rules of the file are matched to the
+// existing rules of their group by UID, then by title, so that importing the same file again changes nothing.
+// With dry_run=true, the changes are returned without being applied.
+func (api *API) importRulesEndpoint(c *models.ReqContext) response.Response {
+	body, err := ioutil.ReadAll(c.Req.Request.Body)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "failed to read rule file", err)
+	}
+	var ruleFile apimodels.RuleFile
+	if err := yaml.Unmarshal(body, &ruleFile); err != nil {
+		return response.Error(http.StatusBadRequest, "failed to parse rule file", err)
+	}
+
+	type ruleGroupImport struct {
+		namespaceUID string
+		group        apimodels.PostableRuleGroupConfig
+		changed      bool
+	}
+
+	// the whole rule file is checked before any rule group is updated
+	result := apimodels.RuleImportResult{DryRun: c.QueryBool("dry_run"), Changes: []apimodels.RuleImportChange{}}
+	imports := make([]ruleGroupImport, 0)
+	provisioned, err := getProvisionedRuleGroups(api.ProvisioningStore, c.SignedInUser.OrgId, "")
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get provisioned rule groups", err)
+	}
+	for _, ns := range ruleFile.Namespaces {
+		namespace, err := api.RuleStore.GetNamespaceByTitle(ns.Name, c.SignedInUser.OrgId, c.SignedInUser, true)
+		if err != nil {
+			return toNamespaceErrorResponse(err)
+		}
+
+		groupNames := make(map[string]struct{}, len(ns.Groups))
+		for _, group := range ns.Groups {
+			if group.Name == "" {
+				return response.Error(http.StatusBadRequest, fmt.Sprintf("rule group name is not valid in namespace %s", ns.Name), nil)
+			}
+			if _, ok := groupNames[group.Name]; ok {
+				return response.Error(http.StatusBadRequest, fmt.Sprintf("rule group %s is defined more than once in namespace %s", group.Name, ns.Name), nil)
+			}
+			groupNames[group.Name] = struct{}{}
+
+			for _, r := range group.Rules {
+				if r.GrafanaManagedAlert == nil {
+					return response.Error(http.StatusBadRequest, fmt.Sprintf("rule group %s has rules that are not Grafana managed", group.Name), nil)
+				}
+				cond := ngmodels.Condition{
+					Condition: r.GrafanaManagedAlert.Condition,
+					OrgID:     c.SignedInUser.OrgId,
+					Data:      r.GrafanaManagedAlert.Data,
+				}
+				if err := validateCondition(cond, c.SignedInUser, c.SkipCache, api.DatasourceCache); err != nil {
+					return response.Error(http.StatusBadRequest, fmt.Sprintf("failed to validate alert rule %s", r.GrafanaManagedAlert.Title), err)
+				}
+			}
+
+			q := ngmodels.ListRuleGroupAlertRulesQuery{OrgID: c.SignedInUser.OrgId, NamespaceUID: namespace.Uid, RuleGroup: group.Name}
+			if err := api.RuleStore.GetRuleGroupAlertRules(&q); err != nil {
+				return response.Error(http.StatusInternalServerError, "failed to get group alert rules", err)
+			}
+
+			changes, err := store.DiffRuleGroup(ns.Name, group, q.Result)
+			if err != nil {
+				return response.Error(http.StatusBadRequest, fmt.Sprintf("failed to validate rule group %s", group.Name), err)
+			}
+
+			changed := false
+			for _, change := range changes {
+				changed = changed || change.Action != apimodels.RuleImportUnchanged
+			}
+			if changed && provisioned.has(namespace.Uid, group.Name) {
+				return response.Error(http.StatusBadRequest, fmt.Sprintf("failed to import rule group %s", group.Name), ngmodels.ErrRuleGroupProvisioned)
+			}
+			result.Changes = append(result.Changes, changes...)
+			imports = append(imports, ruleGroupImport{namespaceUID: namespace.Uid, group: group, changed: changed})
+		}
+	}
+
+	if result.DryRun {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	for _, i := range imports {
+		if !i.changed {
+			continue
+		}
+		if err := api.RuleStore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:           c.SignedInUser.OrgId,
+			NamespaceUID:    i.namespaceUID,
+			RuleGroupConfig: i.group,
+		}); err != nil {
+			return toRuleGroupUpdateErrorResponse(err)
+		}
+	}
+	return response.JSON(http.StatusOK, result)
+}
@@ -20,9 +20,10 @@ import (
 )
 
 type RulerSrv struct {
-	store           store.RuleStore
-	DatasourceCache datasources.CacheService
-	log             log.Logger
+	store             store.RuleStore
+	provisioningStore store.ProvisioningStore
+	DatasourceCache   datasources.CacheService
+	log               log.Logger
 }
 
 func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) response.Response {
@@ -32,6 +33,14 @@ func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) respon
 		return toNamespaceErrorResponse(err)
 	}
 
+	provisioned, err := getProvisionedRuleGroups(srv.provisioningStore, c.SignedInUser.OrgId, namespace.Uid)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get provisioned rule groups", err)
+	}
+	if len(provisioned) > 0 {
+		return response.Error(http.StatusBadRequest, ngmodels.ErrRuleGroupProvisioned.Error(), nil)
+	}
+
 	if err := srv.store.DeleteNamespaceAlertRules(c.SignedInUser.OrgId, namespace.Uid); err != nil {
 		return response.Error(http.StatusInternalServerError, "failed to delete namespace alert rules", err)
 	}
@@ -45,6 +54,9 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 		return toNamespaceErrorResponse(err)
 	}
 	ruleGroup := c.Params(":Groupname")
+	if errResp := srv.checkRuleGroupNotProvisioned(c.SignedInUser.OrgId, namespace.Uid, ruleGroup); errResp != nil {
+		return errResp
+	}
 	if err := srv.store.DeleteRuleGroupAlertRules(c.SignedInUser.OrgId, namespace.Uid, ruleGroup); err != nil {
 		if errors.Is(err, ngmodels.ErrRuleGroupNamespaceNotFound) {
 			return response.Error(http.StatusNotFound, "failed to delete rule group", err)
@@ -69,6 +81,10 @@ func (srv RulerSrv) RouteGetNamespaceRulesConfig(c *models.ReqContext) response.
 	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
 		return response.Error(http.StatusInternalServerError, "failed to update rule group", err)
 	}
+	provisioned, err := getProvisionedRuleGroups(srv.provisioningStore, c.SignedInUser.OrgId, namespace.Uid)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get provisioned rule groups", err)
+	}
 
 	result := apimodels.NamespaceConfigResponse{}
 	ruleGroupConfigs := make(map[string]apimodels.GettableRuleGroupConfig)
@@ -80,11 +96,11 @@ func (srv RulerSrv) RouteGetNamespaceRulesConfig(c *models.ReqContext) response.
 				Name:     r.RuleGroup,
 				Interval: ruleGroupInterval,
 				Rules: []apimodels.GettableExtendedRuleNode{
-					toGettableExtendedRuleNode(*r, namespace.Id),
+					toGettableExtendedRuleNode(*r, namespace.Id, provisioned.has(r.NamespaceUID, r.RuleGroup)),
 				},
 			}
 		} else {
-			ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, namespace.Id))
+			ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, namespace.Id, provisioned.has(r.NamespaceUID, r.RuleGroup)))
 			ruleGroupConfigs[r.RuleGroup] = ruleGroupConfig
 		}
 	}
@@ -112,12 +128,16 @@ func (srv RulerSrv) RouteGetRulegGroupConfig(c *models.ReqContext) response.Resp
 	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
 		return response.Error(http.StatusInternalServerError, "failed to get group alert rules", err)
 	}
+	provisioned, err := getProvisionedRuleGroups(srv.provisioningStore, c.SignedInUser.OrgId, namespace.Uid)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get provisioned rule groups", err)
+	}
 
 	var ruleGroupInterval model.Duration
 	ruleNodes := make([]apimodels.GettableExtendedRuleNode, 0, len(q.Result))
 	for _, r := range q.Result {
 		ruleGroupInterval = model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
-		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, namespace.Id))
+		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, namespace.Id, provisioned.has(r.NamespaceUID, r.RuleGroup)))
 	}
 
 	result := apimodels.RuleGroupConfigResponse{
@@ -137,6 +157,10 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 	if err := srv.store.GetOrgAlertRules(&q); err != nil {
 		return response.Error(http.StatusInternalServerError, "failed to get alert rules", err)
 	}
+	provisioned, err := getProvisionedRuleGroups(srv.provisioningStore, c.SignedInUser.OrgId, "")
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get provisioned rule groups", err)
+	}
 
 	configs := make(map[string]map[string]apimodels.GettableRuleGroupConfig)
 	for _, r := range q.Result {
@@ -153,7 +177,7 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 				Name:     r.RuleGroup,
 				Interval: ruleGroupInterval,
 				Rules: []apimodels.GettableExtendedRuleNode{
-					toGettableExtendedRuleNode(*r, folder.Id),
+					toGettableExtendedRuleNode(*r, folder.Id, provisioned.has(r.NamespaceUID, r.RuleGroup)),
 				},
 			}
 		} else {
@@ -164,11 +188,11 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 					Name:     r.RuleGroup,
 					Interval: ruleGroupInterval,
 					Rules: []apimodels.GettableExtendedRuleNode{
-						toGettableExtendedRuleNode(*r, folder.Id),
+						toGettableExtendedRuleNode(*r, folder.Id, provisioned.has(r.NamespaceUID, r.RuleGroup)),
 					},
 				}
 			} else {
-				ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, folder.Id))
+				ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, folder.Id, provisioned.has(r.NamespaceUID, r.RuleGroup)))
 				configs[namespace][r.RuleGroup] = ruleGroupConfig
 			}
 		}
@@ -199,6 +223,10 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		return response.Error(http.StatusBadRequest, "rule group name is not valid", nil)
 	}
 
+	if errResp := srv.checkRuleGroupNotProvisioned(c.SignedInUser.OrgId, namespace.Uid, ruleGroupConfig.Name); errResp != nil {
+		return errResp
+	}
+
 	for _, r := range ruleGroupConfig.Rules {
 		cond := ngmodels.Condition{
 			Condition: r.GrafanaManagedAlert.Condition,
@@ -215,18 +243,49 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		NamespaceUID:    namespace.Uid,
 		RuleGroupConfig: ruleGroupConfig,
 	}); err != nil {
-		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-			return response.Error(http.StatusNotFound, "failed to update rule group", err)
-		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
-			return response.Error(http.StatusBadRequest, "failed to update rule group", err)
-		}
-		return response.Error(http.StatusInternalServerError, "failed to update rule group", err)
+		return toRuleGroupUpdateErrorResponse(err)
 	}
 
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group updated successfully"})
 }
 
-func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimodels.GettableExtendedRuleNode {
+// checkRuleGroupNotProvisioned returns an error response if the rule group is provisioned from a file.
+func (srv RulerSrv) checkRuleGroupNotProvisioned(orgID int64, namespaceUID string, ruleGroup string) response.Response {
+	provisioned, err := getProvisionedRuleGroups(srv.provisioningStore, orgID, namespaceUID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get provisioned rule groups", err)
+	}
+	if provisioned.has(namespaceUID, ruleGroup) {
+		return response.Error(http.StatusBadRequest, ngmodels.ErrRuleGroupProvisioned.Error(), nil)
+	}
+	return nil
+}
+
+// provisionedRuleGroups holds the names of the rule groups provisioned from files by namespace UID.
+type provisionedRuleGroups map[string]map[string]bool
+
+func (p provisionedRuleGroups) has(namespaceUID string, ruleGroup string) bool {
+	return p[namespaceUID][ruleGroup]
+}
+
+// getProvisionedRuleGroups returns the rule groups of the organization provisioned from files,
+// restricted to a namespace unless namespaceUID is empty.
+func getProvisionedRuleGroups(st store.ProvisioningStore, orgID int64, namespaceUID string) (provisionedRuleGroups, error) {
+	q := ngmodels.GetAlertProvisioningQuery{OrgID: orgID, Kind: ngmodels.ProvisionedRuleGroup, NamespaceUID: namespaceUID}
+	if err := st.GetAlertProvisioning(&q); err != nil {
+		return nil, err
+	}
+	provisioned := make(provisionedRuleGroups)
+	for _, o := range q.Result {
+		if provisioned[o.NamespaceUID] == nil {
+			provisioned[o.NamespaceUID] = make(map[string]bool)
+		}
+		provisioned[o.NamespaceUID][o.RuleGroup] = true
+	}
+	return provisioned, nil
+}
+
+func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64, provisioned bool) apimodels.GettableExtendedRuleNode {
 	gettableExtendedRuleNode := apimodels.GettableExtendedRuleNode{
 		GrafanaManagedAlert: &apimodels.GettableGrafanaRule{
 			ID:              r.ID,
@@ -243,6 +302,8 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimode
 			RuleGroup:       r.RuleGroup,
 			NoDataState:     apimodels.NoDataState(r.NoDataState),
 			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
+			ResolveAfter:    model.Duration(r.ResolveAfter),
+			Provisioned:     provisioned,
 		},
 	}
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
@@ -262,6 +323,7 @@ func toPostableExtendedRuleNode(r ngmodels.AlertRule) apimodels.PostableExtended
 			UID:          r.UID,
 			NoDataState:  apimodels.NoDataState(r.NoDataState),
 			ExecErrState: apimodels.ExecutionErrorState(r.ExecErrState),
+			ResolveAfter: model.Duration(r.ResolveAfter),
 		},
 	}
 	postableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
@@ -272,6 +334,15 @@ func toPostableExtendedRuleNode(r ngmodels.AlertRule) apimodels.PostableExtended
 	return postableExtendedRuleNode
 }
 
+func toRuleGroupUpdateErrorResponse(err error) response.Response {
+	if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		return response.Error(http.StatusNotFound, "failed to update rule group", err)
+	} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
+		return response.Error(http.StatusBadRequest, "failed to update rule group", err)
+	}
+	return response.Error(http.StatusInternalServerError, "failed to update rule group", err)
+}
+
 func toNamespaceErrorResponse(err error) response.Response {
 	if errors.Is(err, ngmodels.ErrCannotEditNamespace) {
 		return response.Error(http.StatusForbidden, err.Error(), err)
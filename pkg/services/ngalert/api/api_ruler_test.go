@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestCheckRuleGroupNotProvisioned(t *testing.T) {
+	srv := RulerSrv{provisioningStore: fakeProvisioningStore{objects: []*ngmodels.AlertProvisioning{
+		{OrgID: 1, Kind: ngmodels.ProvisionedRuleGroup, NamespaceUID: "folder", RuleGroup: "provisioned"},
+	}}}
+
+	require.Nil(t, srv.checkRuleGroupNotProvisioned(1, "folder", "group"))
+	require.Nil(t, srv.checkRuleGroupNotProvisioned(2, "folder", "provisioned"))
+
+	resp := srv.checkRuleGroupNotProvisioned(1, "folder", "provisioned")
+	require.NotNil(t, resp)
+	require.Equal(t, http.StatusBadRequest, resp.(*response.NormalResponse).Status())
+}
+
+type fakeProvisioningStore struct {
+	objects []*ngmodels.AlertProvisioning
+}
+
+func (f fakeProvisioningStore) GetAlertProvisioning(query *ngmodels.GetAlertProvisioningQuery) error {
+	for _, o := range f.objects {
+		if o.OrgID != query.OrgID || (query.Kind != "" && o.Kind != query.Kind) ||
+			(query.NamespaceUID != "" && o.NamespaceUID != query.NamespaceUID) || (query.RuleGroup != "" && o.RuleGroup != query.RuleGroup) {
+			continue
+		}
+		query.Result = append(query.Result, o)
+	}
+	return nil
+}
+
+func (f fakeProvisioningStore) SetAlertProvisioning(cmd *ngmodels.SetAlertProvisioningCmd) error {
+	return nil
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// defaultStateHistoryLimit is the maximum number of state transitions returned when no limit is requested.
+const defaultStateHistoryLimit = 100
+
+// getAlertStateHistoryEndpoint handles GET /api/alert-state-history.
+// The transitions can be filtered by alert rule with ruleUID, by instance labels with label=name=value, which
+// can be repeated, and by time range with from and to, in epoch milliseconds. Filtering by labels requires from,
+// as the labels are matched after reading the transitions.
+func (api *API) getAlertStateHistoryEndpoint(c *models.ReqContext) response.Response {
+	query := ngmodels.GetAlertStateHistoryQuery{
+		OrgID:   c.SignedInUser.OrgId,
+		RuleUID: c.Query("ruleUID"),
+		Limit:   c.QueryInt("limit"),
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultStateHistoryLimit
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+
+	labels, err := parseLabelMatchers(c.QueryStrings("label"))
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
+	if len(labels) > 0 && query.From.IsZero() {
+		return response.Error(http.StatusBadRequest, "filtering by label requires a time range", nil)
+	}
+	query.Labels = labels
+
+	if err := api.StateHistoryStore.GetAlertStateHistory(&query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get alert state history", err)
+	}
+
+	return response.JSON(http.StatusOK, query.Result)
+}
+
+// parseLabelMatchers parses labels in the name=value format.
+func parseLabelMatchers(matchers []string) (map[string]string, error) {
+	if len(matchers) == 0 {
+		return nil, nil
+	}
+
+	labels := make(map[string]string, len(matchers))
+	for _, m := range matchers {
+		parts := strings.SplitN(m, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid label %q, expected name=value", m)
+		}
+		labels[parts[0]] = parts[1]
+	}
+	return labels, nil
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	macaron "gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestGetAlertStateHistoryRequiresTimeRangeForLabels(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/api/alert-state-history?label=instance=server1", nil)
+	require.NoError(t, err)
+	c := &models.ReqContext{
+		Context:      &macaron.Context{Req: macaron.Request{Request: req}},
+		SignedInUser: &models.SignedInUser{OrgId: 1},
+	}
+
+	api := &API{}
+	resp := api.getAlertStateHistoryEndpoint(c)
+	require.Equal(t, http.StatusBadRequest, resp.(*response.NormalResponse).Status())
+}
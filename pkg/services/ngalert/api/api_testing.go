@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -12,9 +13,9 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
-	"github.com/grafana/grafana/pkg/util"
 )
 
 type TestingApiSrv struct {
@@ -22,12 +23,46 @@ type TestingApiSrv struct {
 	Cfg             *setting.Cfg
 	DataService     *tsdb.Service
 	DatasourceCache datasources.CacheService
+	mam             *notifier.MultiOrgAlertmanager
 	log             log.Logger
 }
 
-func (srv TestingApiSrv) RouteTestReceiverConfig(c *models.ReqContext, body apimodels.ExtendedReceiver) response.Response {
-	srv.log.Info("RouteTestReceiverConfig: ", "body", body)
-	return response.JSON(http.StatusOK, util.DynMap{"message": "success"})
+func (srv TestingApiSrv) RouteTestReceiverConfig(c *models.ReqContext, body apimodels.TestReceiverPayload) response.Response {
+	// Testing a receiver sends requests to arbitrary URLs, so it requires the same role as editing receivers.
+	if !c.HasRole(models.ROLE_EDITOR) {
+		return response.Error(http.StatusForbidden, "permission denied", nil)
+	}
+
+	if c.Params("Recipient") != apimodels.GrafanaBackend.String() {
+		return response.Error(http.StatusBadRequest, "receivers can only be tested against the Grafana Alertmanager", nil)
+	}
+
+	am, err := srv.mam.AlertmanagerFor(c.OrgId)
+	if err != nil {
+		if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+			return response.Error(http.StatusNotFound, err.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "failed to get Alertmanager of the organization", err)
+	}
+
+	result, err := am.TestReceiver(c.Req.Context(), body.Receiver, body.Alert)
+	if err != nil {
+		var validationErr notifier.ReceiverValidationError
+		if errors.Is(err, notifier.ErrNoReceiverIntegrations) || errors.As(err, &validationErr) {
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "failed to test receiver", err)
+	}
+
+	// Multi-Status tells the client that some of the integrations failed to send the test alert.
+	status := http.StatusOK
+	for _, r := range result.Integrations {
+		if r.Error != "" {
+			status = http.StatusMultiStatus
+			break
+		}
+	}
+	return response.JSON(status, result)
 }
 
 func (srv TestingApiSrv) RouteTestRuleConfig(c *models.ReqContext, body apimodels.TestRulePayload) response.Response {
@@ -0,0 +1,22 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestRouteTestReceiverConfigRequiresEditor(t *testing.T) {
+	srv := TestingApiSrv{}
+	c := &models.ReqContext{
+		SignedInUser: &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_VIEWER},
+	}
+
+	resp := srv.RouteTestReceiverConfig(c, apimodels.TestReceiverPayload{})
+	require.Equal(t, http.StatusForbidden, resp.(*response.NormalResponse).Status())
+}
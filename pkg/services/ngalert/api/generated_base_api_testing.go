@@ -21,7 +21,7 @@ import (
 
 type TestingApiService interface {
 	RouteEvalQueries(*models.ReqContext, apimodels.EvalQueriesPayload) response.Response
-	RouteTestReceiverConfig(*models.ReqContext, apimodels.ExtendedReceiver) response.Response
+	RouteTestReceiverConfig(*models.ReqContext, apimodels.TestReceiverPayload) response.Response
 	RouteTestRuleConfig(*models.ReqContext, apimodels.TestRulePayload) response.Response
 }
 
@@ -39,7 +39,7 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApiService, metrics *Metr
 		)
 		group.Post(
 			toMacaronPath("/api/v1/receiver/test/{Recipient}"),
-			binding.Bind(apimodels.ExtendedReceiver{}),
+			binding.Bind(apimodels.TestReceiverPayload{}),
 			Instrument(
 				http.MethodPost,
 				"/api/v1/receiver/test/{Recipient}",
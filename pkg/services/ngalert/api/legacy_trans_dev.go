@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/prometheus/common/model"
@@ -171,6 +172,9 @@ func (api *API) ruleGroupByOldID(c *models.ReqContext) response.Response {
 		}
 		ruleTags[k] = sV
 	}
+	// Link the rule to the panel of the dashboard alert, for its state changes to be shown on the panel.
+	ruleTags[ngmodels.DashboardUIDAnnotation] = oldAlertsDash.Uid
+	ruleTags[ngmodels.PanelIDAnnotation] = strconv.FormatInt(oldAlert.PanelId, 10)
 
 	rule := ngmodels.AlertRule{
 		Title:        oldAlert.Name,
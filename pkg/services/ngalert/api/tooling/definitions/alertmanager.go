@@ -266,6 +266,8 @@ func (c *PostableUserConfig) UnmarshalYAML(value *yaml.Node) error {
 type GettableUserConfig struct {
 	TemplateFiles      map[string]string         `yaml:"template_files" json:"template_files"`
 	AlertmanagerConfig GettableApiAlertingConfig `yaml:"alertmanager_config" json:"alertmanager_config"`
+	// Provisioned is whether the configuration is provisioned from a file and cannot be changed in the API.
+	Provisioned bool `yaml:"provisioned,omitempty" json:"provisioned,omitempty"`
 }
 
 func (c *GettableUserConfig) UnmarshalYAML(value *yaml.Node) error {
@@ -417,6 +419,9 @@ func (c *PostableApiAlertingConfig) Type() (backend Backend) {
 // AllReceivers will recursively walk a routing tree and return a list of all the
 // referenced receiver names.
 func AllReceivers(route *config.Route) (res []string) {
+	if route == nil {
+		return res
+	}
 	res = append(res, route.Receiver)
 	for _, subRoute := range route.Routes {
 		res = append(res, AllReceivers(subRoute)...)
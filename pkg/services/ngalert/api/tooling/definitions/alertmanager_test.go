@@ -86,6 +86,7 @@ func Test_AllReceivers(t *testing.T) {
 	}
 
 	require.Equal(t, []string{"foo", "bar", "bazz", "buzz"}, AllReceivers(input))
+	require.Nil(t, AllReceivers(nil))
 }
 
 func Test_ApiAlertingConfig_Marshaling(t *testing.T) {
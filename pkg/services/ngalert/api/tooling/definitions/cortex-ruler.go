@@ -301,6 +301,7 @@ type PostableGrafanaRule struct {
 	UID          string              `json:"uid" yaml:"uid"`
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	ResolveAfter model.Duration      `json:"resolve_after,omitempty" yaml:"resolve_after,omitempty"`
 }
 
 // swagger:model
@@ -319,4 +320,7 @@ type GettableGrafanaRule struct {
 	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
 	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	ResolveAfter    model.Duration      `json:"resolve_after,omitempty" yaml:"resolve_after,omitempty"`
+	// Provisioned is whether the rule group of the rule is provisioned from a file and cannot be changed in the API.
+	Provisioned bool `json:"provisioned,omitempty" yaml:"provisioned,omitempty"`
 }
@@ -0,0 +1,40 @@
+package definitions
+
+// RuleFile is a Prometheus-style rule file holding the Grafana managed rule groups of namespaces.
+type RuleFile struct {
+	Namespaces []RuleFileNamespace `yaml:"namespaces" json:"namespaces"`
+}
+
+// RuleFileNamespace holds the rule groups of a namespace, the folder of the rules.
+type RuleFileNamespace struct {
+	Name   string                    `yaml:"name" json:"name"`
+	Groups []PostableRuleGroupConfig `yaml:"groups" json:"groups"`
+}
+
+// RuleImportAction is what importing a rule file does to a rule.
+type RuleImportAction string
+
+const (
+	RuleImportCreate    RuleImportAction = "create"
+	RuleImportUpdate    RuleImportAction = "update"
+	RuleImportDelete    RuleImportAction = "delete"
+	RuleImportUnchanged RuleImportAction = "unchanged"
+)
+
+// RuleImportChange is the change of a rule by the import of a rule file.
+type RuleImportChange struct {
+	Namespace string           `json:"namespace"`
+	Group     string           `json:"group"`
+	Title     string           `json:"title"`
+	UID       string           `json:"uid,omitempty"`
+	Action    RuleImportAction `json:"action"`
+	// Fields are the fields of an updated rule that changed.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// RuleImportResult is the result of the import of a rule file.
+type RuleImportResult struct {
+	// DryRun is whether the changes were only computed and not applied.
+	DryRun  bool               `json:"dryRun"`
+	Changes []RuleImportChange `json:"changes"`
+}
@@ -0,0 +1,63 @@
+package definitions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_RuleFile_Marshaling(t *testing.T) {
+	data := `namespaces:
+    - name: infrastructure
+      groups:
+        - name: instances
+          interval: 1m
+          rules:
+            - expr: ""
+              for: 5m
+              labels:
+                team: infra
+              grafana_alert:
+                title: instance down
+                condition: B
+                data:
+                    - datasourceUid: datasource-uid
+                      model:
+                        expr: up
+                        refId: A
+                      queryType: ""
+                      refId: A
+                      relativeTimeRange:
+                        from: 600
+                        to: 0
+                    - datasourceUid: "-100"
+                      model:
+                        expression: A
+                        refId: B
+                        type: math
+                      queryType: ""
+                      refId: B
+                      relativeTimeRange:
+                        from: 0
+                        to: 0
+                uid: instance-down
+                no_data_state: NoData
+                exec_err_state: Alerting
+                resolve_after: 10m
+`
+
+	var res RuleFile
+	require.NoError(t, yaml.Unmarshal([]byte(data), &res))
+	require.Len(t, res.Namespaces, 1)
+	require.Len(t, res.Namespaces[0].Groups, 1)
+	rules := res.Namespaces[0].Groups[0].Rules
+	require.Len(t, rules, 1)
+	require.Equal(t, GrafanaManagedRule, rules[0].Type())
+	require.Len(t, rules[0].GrafanaManagedAlert.Data, 2)
+	require.JSONEq(t, `{"expr":"up","refId":"A"}`, string(rules[0].GrafanaManagedAlert.Data[0].Model))
+
+	b, err := yaml.Marshal(res)
+	require.NoError(t, err)
+	require.Equal(t, data, string(b))
+}
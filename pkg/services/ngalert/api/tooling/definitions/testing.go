@@ -8,7 +8,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
-	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql"
 )
 
@@ -16,6 +16,8 @@ import (
 //
 // Test receiver
 //
+// Sends a test alert through each integration of the receiver.
+//
 //     Consumes:
 //     - application/json
 //
@@ -23,9 +25,9 @@ import (
 //     - application/json
 //
 //     Responses:
-//       200: Success
-//		 412: SmtpNotEnabled
-//		 500: Failure
+//       200: TestReceiverResult
+//       207: TestReceiverResult
+//       400: ValidationError
 
 // swagger:route Post /api/v1/rule/test/{Recipient} testing RouteTestRuleConfig
 //
@@ -56,7 +58,7 @@ import (
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
-	Body ExtendedReceiver
+	Body TestReceiverPayload
 }
 
 // swagger:parameters RouteTestRuleConfig
@@ -135,16 +137,35 @@ type AlertInstancesResponse struct {
 }
 
 // swagger:model
-type ExtendedReceiver struct {
-	EmailConfigs     config.EmailConfig      `yaml:"email_configs,omitempty" json:"email_configs,omitempty"`
-	PagerdutyConfigs config.PagerdutyConfig  `yaml:"pagerduty_configs,omitempty" json:"pagerduty_configs,omitempty"`
-	SlackConfigs     config.SlackConfig      `yaml:"slack_configs,omitempty" json:"slack_configs,omitempty"`
-	WebhookConfigs   config.WebhookConfig    `yaml:"webhook_configs,omitempty" json:"webhook_configs,omitempty"`
-	OpsGenieConfigs  config.OpsGenieConfig   `yaml:"opsgenie_configs,omitempty" json:"opsgenie_configs,omitempty"`
-	WechatConfigs    config.WechatConfig     `yaml:"wechat_configs,omitempty" json:"wechat_configs,omitempty"`
-	PushoverConfigs  config.PushoverConfig   `yaml:"pushover_configs,omitempty" json:"pushover_configs,omitempty"`
-	VictorOpsConfigs config.VictorOpsConfig  `yaml:"victorops_configs,omitempty" json:"victorops_configs,omitempty"`
-	GrafanaReceiver  PostableGrafanaReceiver `yaml:"grafana_managed_receiver,omitempty" json:"grafana_managed_receiver,omitempty"`
+type TestReceiverPayload struct {
+	Receiver *PostableApiReceiver `json:"receiver"`
+	// Alert overrides the labels and annotations of the test alert.
+	Alert *TestReceiverAlert `json:"alert,omitempty"`
+}
+
+// swagger:model
+type TestReceiverAlert struct {
+	Labels      model.LabelSet `json:"labels,omitempty"`
+	Annotations model.LabelSet `json:"annotations,omitempty"`
+}
+
+// swagger:model
+type TestReceiverResult struct {
+	Receiver     string                  `json:"receiver"`
+	Integrations []TestIntegrationResult `json:"integrations"`
+	NotifiedAt   time.Time               `json:"notified_at"`
+}
+
+// swagger:model
+type TestIntegrationResult struct {
+	Name string `json:"name"`
+	UID  string `json:"uid"`
+	Type string `json:"type"`
+	// Status is either "success" or "failed".
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// LatencyMs is the time it took the integration to send the test alert, in milliseconds.
+	LatencyMs int64 `json:"latency_ms"`
 }
 
 // swagger:model
@@ -59,8 +59,12 @@ type Results []Result
 // Result contains the evaluated State of an alert instance
 // identified by its labels.
 type Result struct {
-	Instance           data.Labels
-	State              State // Enum
+	Instance data.Labels
+	State    State // Enum
+	// Value is the value of the condition, nil if there was no data.
+	Value *float64
+	// Error is the error that occurred while evaluating the condition, if any.
+	Error              error
 	EvaluatedAt        time.Time
 	EvaluationDuration time.Duration
 }
@@ -97,8 +101,11 @@ func (s State) String() string {
 
 // AlertExecCtx is the context provided for executing an alert condition.
 type AlertExecCtx struct {
-	OrgID              int64
-	ExpressionsEnabled bool
+	OrgID                           int64
+	ExpressionsEnabled              bool
+	ExpressionsMaxConcurrentQueries int
+	ExpressionsQueryCacheTTL        time.Duration
+	LoadedDimensions                []data.Labels
 
 	Ctx context.Context
 }
@@ -115,6 +122,12 @@ func GetExprRequest(ctx AlertExecCtx, data []models.AlertQuery, now time.Time) (
 		if err != nil {
 			return nil, fmt.Errorf("failed to get query model: %w", err)
 		}
+		if len(ctx.LoadedDimensions) > 0 && q.DatasourceUID == expr.DatasourceUID {
+			model, err = expr.SetLoadedDimensions(model, ctx.LoadedDimensions)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set loaded dimensions on query model: %w", err)
+			}
+		}
 		interval, err := q.GetIntervalDuration()
 		if err != nil {
 			return nil, fmt.Errorf("failed to retrieve intervalMs from the model: %w", err)
@@ -173,7 +186,11 @@ func executeQueriesAndExpressions(ctx AlertExecCtx, data []models.AlertQuery, no
 	}
 
 	exprService := expr.Service{
-		Cfg:         &setting.Cfg{ExpressionsEnabled: ctx.ExpressionsEnabled},
+		Cfg: &setting.Cfg{
+			ExpressionsEnabled:              ctx.ExpressionsEnabled,
+			ExpressionsMaxConcurrentQueries: ctx.ExpressionsMaxConcurrentQueries,
+			ExpressionsQueryCacheTTL:        ctx.ExpressionsQueryCacheTTL,
+		},
 		DataService: dataService,
 	}
 	return exprService.TransformData(ctx.Ctx, queryDataReq)
@@ -215,6 +232,7 @@ func evaluateExecutionResult(results *ExecutionResults, ts time.Time) (Results,
 
 		r := Result{
 			Instance:           f.Fields[0].Labels,
+			Value:              val,
 			EvaluatedAt:        ts,
 			EvaluationDuration: time.Since(ts),
 		}
@@ -222,6 +240,7 @@ func evaluateExecutionResult(results *ExecutionResults, ts time.Time) (Results,
 		switch {
 		case err != nil:
 			r.State = Error
+			r.Error = err
 		case val == nil:
 			r.State = NoData
 		case *val == 0:
@@ -270,12 +289,23 @@ func (evalResults Results) AsDataFrame() data.Frame {
 	return *frame
 }
 
+func (e *Evaluator) alertExecCtx(ctx context.Context, orgID int64) AlertExecCtx {
+	return AlertExecCtx{
+		OrgID:                           orgID,
+		Ctx:                             ctx,
+		ExpressionsEnabled:              e.Cfg.ExpressionsEnabled,
+		ExpressionsMaxConcurrentQueries: e.Cfg.ExpressionsMaxConcurrentQueries,
+		ExpressionsQueryCacheTTL:        e.Cfg.ExpressionsQueryCacheTTL,
+	}
+}
+
 // ConditionEval executes conditions and evaluates the result.
 func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, dataService *tsdb.Service) (Results, error) {
 	alertCtx, cancelFn := context.WithTimeout(context.Background(), alertingEvaluationTimeout)
 	defer cancelFn()
 
-	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled}
+	alertExecCtx := e.alertExecCtx(alertCtx, condition.OrgID)
+	alertExecCtx.LoadedDimensions = condition.LoadedDimensions
 
 	execResult, err := executeCondition(alertExecCtx, condition, now, dataService)
 	if err != nil {
@@ -294,7 +324,7 @@ func (e *Evaluator) QueriesAndExpressionsEval(orgID int64, data []models.AlertQu
 	alertCtx, cancelFn := context.WithTimeout(context.Background(), alertingEvaluationTimeout)
 	defer cancelFn()
 
-	alertExecCtx := AlertExecCtx{OrgID: orgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled}
+	alertExecCtx := e.alertExecCtx(alertCtx, orgID)
 
 	execResult, err := executeQueriesAndExpressions(alertExecCtx, data, now, dataService)
 	if err != nil {
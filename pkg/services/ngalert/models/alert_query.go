@@ -7,6 +7,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/expr"
+	"gopkg.in/yaml.v3"
 )
 
 const defaultMaxDataPoints float64 = 100
@@ -77,6 +78,32 @@ type AlertQuery struct {
 	modelProps map[string]interface{}
 }
 
+// MarshalYAML marshals the query like its JSON representation, so that the model is readable in rule files.
+func (aq AlertQuery) MarshalYAML() (interface{}, error) {
+	b, err := json.Marshal(aq)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// UnmarshalYAML unmarshals the query from the YAML form of its JSON representation.
+func (aq *AlertQuery) UnmarshalYAML(value *yaml.Node) error {
+	var v interface{}
+	if err := value.Decode(&v); err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, aq)
+}
+
 func (aq *AlertQuery) setModelProps() error {
 	aq.modelProps = make(map[string]interface{})
 	err := json.Unmarshal(aq.Model, &aq.modelProps)
@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestAlertQuery(t *testing.T) {
@@ -245,3 +246,35 @@ func TestAlertQueryMarshalling(t *testing.T) {
 		}
 	}
 }
+
+func TestAlertQueryYAMLMarshalling(t *testing.T) {
+	aq := AlertQuery{
+		RefID:             "A",
+		QueryType:         "range",
+		DatasourceUID:     "datasource-uid",
+		RelativeTimeRange: RelativeTimeRange{From: Duration(10 * time.Minute), To: 0},
+		Model:             json.RawMessage(`{"expr":"up == 0","refId":"A"}`),
+	}
+
+	b, err := yaml.Marshal(aq)
+	require.NoError(t, err)
+	require.YAMLEq(t, `
+datasourceUid: datasource-uid
+model:
+  expr: up == 0
+  refId: A
+queryType: range
+refId: A
+relativeTimeRange:
+  from: 600
+  to: 0
+`, string(b))
+
+	var res AlertQuery
+	require.NoError(t, yaml.Unmarshal(b, &res))
+	require.Equal(t, aq.RefID, res.RefID)
+	require.Equal(t, aq.QueryType, res.QueryType)
+	require.Equal(t, aq.DatasourceUID, res.DatasourceUID)
+	require.Equal(t, aq.RelativeTimeRange, res.RelativeTimeRange)
+	require.JSONEq(t, string(aq.Model), string(res.Model))
+}
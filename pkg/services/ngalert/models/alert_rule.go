@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
 var (
@@ -46,6 +48,11 @@ const (
 const (
 	UIDLabel          = "__alert_rule_uid__"
 	NamespaceUIDLabel = "__alert_rule_namespace_uid__"
+
+	// DashboardUIDAnnotation is the annotation linking an alert rule to a dashboard.
+	DashboardUIDAnnotation = "__dashboardUid__"
+	// PanelIDAnnotation is the annotation linking an alert rule to a panel of the dashboard.
+	PanelIDAnnotation = "__panelId__"
 )
 
 // AlertRule is the model for alert rules in unified alerting.
@@ -65,9 +72,11 @@ type AlertRule struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
+	For time.Duration
+	// ResolveAfter is how long the condition must stop firing before a firing instance is resolved.
+	ResolveAfter time.Duration
+	Annotations  map[string]string
+	Labels       map[string]string
 }
 
 // AlertRuleKey is the alert definition identifier
@@ -118,9 +127,11 @@ type AlertRuleVersion struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
+	For time.Duration
+	// ResolveAfter is how long the condition must stop firing before a firing instance is resolved.
+	ResolveAfter time.Duration
+	Annotations  map[string]string
+	Labels       map[string]string
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -174,6 +185,10 @@ type Condition struct {
 
 	// Data is an array of data source queries and/or server side expressions.
 	Data []AlertQuery `json:"data"`
+
+	// LoadedDimensions holds the labels of the alert instances that are currently firing.
+	// It is used by threshold expressions with a recovery threshold.
+	LoadedDimensions []data.Labels `json:"-"`
 }
 
 // IsValid checks the condition's validity.
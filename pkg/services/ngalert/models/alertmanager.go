@@ -6,7 +6,8 @@ const AlertConfigurationVersion = 1
 
 // AlertConfiguration represents a single version of the Alerting Engine Configuration.
 type AlertConfiguration struct {
-	ID int64 `xorm:"pk autoincr 'id'"`
+	ID    int64 `xorm:"pk autoincr 'id'"`
+	OrgID int64 `xorm:"org_id"`
 
 	AlertmanagerConfiguration string
 	ConfigurationVersion      string
@@ -15,18 +16,22 @@ type AlertConfiguration struct {
 
 // GetLatestAlertmanagerConfigurationQuery is the query to get the latest alertmanager configuration.
 type GetLatestAlertmanagerConfigurationQuery struct {
+	OrgID int64
+
 	Result *AlertConfiguration
 }
 
 // GetAlertmanagerConfigurationQuery is the query to get the latest alertmanager configuration.
 type GetAlertmanagerConfigurationQuery struct {
-	ID int64
+	ID    int64
+	OrgID int64
 
 	Result *AlertConfiguration
 }
 
 // SaveAlertmanagerConfigurationCmd is the command to save an alertmanager configuration.
 type SaveAlertmanagerConfigurationCmd struct {
+	OrgID                     int64
 	AlertmanagerConfiguration string
 	ConfigurationVersion      string
 }
@@ -34,3 +39,51 @@ type SaveAlertmanagerConfigurationCmd struct {
 type DeleteAlertmanagerConfigurationCmd struct {
 	ID int64
 }
+
+// GetOrgIDsQuery is the query to get the IDs of all organizations.
+type GetOrgIDsQuery struct {
+	Result []int64
+}
+
+// AlertClusterState is the serialized state, such as the silences or the notification log,
+// of an Alertmanager replica shared with the other replicas through the database.
+type AlertClusterState struct {
+	ID       int64  `xorm:"pk autoincr 'id'"`
+	PeerID   string `xorm:"peer_id"`
+	StateKey string `xorm:"state_key"`
+	State    []byte
+	// UpdatedAt is when the state last changed, HeartbeatAt when the replica was last seen.
+	UpdatedAt   int64
+	HeartbeatAt int64
+}
+
+// SaveAlertmanagerClusterStateCmd is the command to save the state of a replica.
+type SaveAlertmanagerClusterStateCmd struct {
+	PeerID   string
+	StateKey string
+	State    []byte
+	Now      time.Time
+}
+
+// HeartbeatAlertmanagerClusterPeerCmd is the command to mark the states of a replica as alive
+// and remove the states of replicas that have not been seen since ExpireBefore.
+type HeartbeatAlertmanagerClusterPeerCmd struct {
+	PeerID       string
+	Now          time.Time
+	ExpireBefore time.Time
+}
+
+// GetAlertmanagerClusterStatesQuery is the query to get the states of all replicas.
+type GetAlertmanagerClusterStatesQuery struct {
+	// UpdatedSince limits the states to the ones that changed since.
+	UpdatedSince time.Time
+
+	Result []*AlertClusterState
+}
+
+// GetAlertmanagerClusterPeersQuery is the query to get the IDs of the replicas seen since AliveSince.
+type GetAlertmanagerClusterPeersQuery struct {
+	AliveSince time.Time
+
+	Result []string
+}
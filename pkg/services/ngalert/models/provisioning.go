@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrRuleGroupProvisioned is an error for when a rule group provisioned from a file is changed in the API.
+	ErrRuleGroupProvisioned = errors.New("cannot change a provisioned rule group")
+	// ErrAlertmanagerConfigurationProvisioned is an error for when an Alertmanager configuration provisioned from a file
+	// is changed in the API.
+	ErrAlertmanagerConfigurationProvisioned = errors.New("cannot change a provisioned Alertmanager configuration")
+)
+
+// ProvisionedObjectKind is the kind of an alerting object provisioned from a file.
+type ProvisionedObjectKind string
+
+const (
+	ProvisionedRuleGroup                 ProvisionedObjectKind = "rule_group"
+	ProvisionedAlertmanagerConfiguration ProvisionedObjectKind = "alertmanager_configuration"
+)
+
+// AlertProvisioning is an alerting object provisioned from a file, which is read-only in the API.
+// Rule groups are identified by their namespace and name, Alertmanager configurations by their organization.
+type AlertProvisioning struct {
+	ID           int64 `xorm:"pk autoincr 'id'"`
+	OrgID        int64 `xorm:"org_id"`
+	Kind         ProvisionedObjectKind
+	NamespaceUID string `xorm:"namespace_uid"`
+	RuleGroup    string
+	// File is the provisioning file the object is defined in.
+	File    string
+	Updated time.Time
+}
+
+// GetAlertProvisioningQuery is the query for the provisioned objects of an organization, optionally restricted
+// to a kind of object, a namespace and a rule group.
+type GetAlertProvisioningQuery struct {
+	OrgID        int64
+	Kind         ProvisionedObjectKind
+	NamespaceUID string
+	RuleGroup    string
+
+	Result []*AlertProvisioning
+}
+
+// SetAlertProvisioningCmd is the command for replacing all provisioned objects by the ones of the provisioning files.
+type SetAlertProvisioningCmd struct {
+	Objects []*AlertProvisioning
+}
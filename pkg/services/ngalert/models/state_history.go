@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// AlertStateHistory is a transition of an alert instance from one state to another.
+type AlertStateHistory struct {
+	ID            int64             `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID         int64             `xorm:"org_id" json:"orgId"`
+	RuleUID       string            `xorm:"rule_uid" json:"ruleUid"`
+	Labels        InstanceLabels    `json:"labels"`
+	LabelsHash    string            `json:"-"`
+	PreviousState InstanceStateType `json:"previousState"`
+	State         InstanceStateType `json:"state"`
+	// Value is the value of the condition that caused the transition, nil if there was no data.
+	Value          *float64  `json:"value"`
+	Error          string    `json:"error,omitempty"`
+	TransitionedAt time.Time `json:"transitionedAt"`
+}
+
+// SaveAlertStateHistoryCommand is the command for recording state transitions of alert instances.
+type SaveAlertStateHistoryCommand struct {
+	Transitions []*AlertStateHistory
+}
+
+// GetAlertStateHistoryQuery is the query for retrieving the state transitions of the alert instances of an organization.
+type GetAlertStateHistoryQuery struct {
+	OrgID   int64
+	RuleUID string
+	// Labels restricts the result to the instances having all these labels.
+	Labels map[string]string
+	From   time.Time
+	To     time.Time
+	// Limit is the maximum number of transitions returned, most recent first.
+	Limit int
+
+	Result []*AlertStateHistory
+}
+
+// DeleteExpiredAlertStateHistoryCommand is the command for deleting the state transitions older than a point in time.
+type DeleteExpiredAlertStateHistoryCommand struct {
+	OlderThan time.Time
+
+	DeletedRows int64
+}
+
+// ValidateAlertStateHistory validates that the state transition belongs to an alert rule and has valid states.
+func ValidateAlertStateHistory(h *AlertStateHistory) error {
+	if h == nil {
+		return fmt.Errorf("alert state history is invalid because it is nil")
+	}
+
+	if h.OrgID == 0 {
+		return fmt.Errorf("alert state history is invalid due to missing organisation")
+	}
+
+	if h.RuleUID == "" {
+		return fmt.Errorf("alert state history is invalid due to missing alert rule uid")
+	}
+
+	if !isValidHistoryState(h.PreviousState) {
+		return fmt.Errorf("alert state history is invalid because the previous state '%v' is invalid", h.PreviousState)
+	}
+
+	if !isValidHistoryState(h.State) {
+		return fmt.Errorf("alert state history is invalid because the state '%v' is invalid", h.State)
+	}
+
+	return nil
+}
+
+// isValidHistoryState checks the state of a transition. Unlike alert instances, transitions can be pending.
+func isValidHistoryState(s InstanceStateType) bool {
+	return s.IsValid() || s == InstanceStatePending
+}
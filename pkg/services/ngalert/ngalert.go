@@ -24,17 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb"
 )
 
-const (
-	maxAttempts int64 = 3
-	// scheduler interval
-	// changing this value is discouraged
-	// because this could cause existing alert definition
-	// with intervals that are not exactly divided by this number
-	// not to be evaluated
-	baseIntervalSeconds = 10
-	// default alert definiiton interval
-	defaultIntervalSeconds int64 = 6 * baseIntervalSeconds
-)
+const maxAttempts int64 = 3
 
 // AlertNG is the service for evaluating the condition of an alert definition.
 type AlertNG struct {
@@ -43,7 +33,7 @@ type AlertNG struct {
 	RouteRegister   routing.RouteRegister                   `inject:""`
 	SQLStore        *sqlstore.SQLStore                      `inject:""`
 	DataService     *tsdb.Service                           `inject:""`
-	Alertmanager    *notifier.Alertmanager                  `inject:""`
+	Alertmanagers   *notifier.MultiOrgAlertmanager          `inject:""`
 	DataProxy       *datasourceproxy.DatasourceProxyService `inject:""`
 	Log             log.Logger
 	schedule        schedule.ScheduleService
@@ -57,10 +47,13 @@ func init() {
 // Init initializes the AlertingService.
 func (ng *AlertNG) Init() error {
 	ng.Log = log.New("ngalert")
-	ng.stateManager = state.NewManager(ng.Log)
-	baseInterval := baseIntervalSeconds * time.Second
+	baseInterval := store.BaseIntervalSeconds * time.Second
 
-	store := store.DBstore{BaseInterval: baseInterval, DefaultIntervalSeconds: defaultIntervalSeconds, SQLStore: ng.SQLStore}
+	store := store.DBstore{BaseInterval: baseInterval, DefaultIntervalSeconds: store.DefaultIntervalSeconds, SQLStore: ng.SQLStore}
+	ng.stateManager = state.NewManager(ng.Log, store, state.FlapDetection{
+		Threshold: ng.Cfg.UnifiedAlerting.FlapDetectionThreshold,
+		Window:    ng.Cfg.UnifiedAlerting.FlapDetectionWindow,
+	})
 
 	schedCfg := schedule.SchedulerCfg{
 		C:            clock.New(),
@@ -70,22 +63,27 @@ func (ng *AlertNG) Init() error {
 		Evaluator:    eval.Evaluator{Cfg: ng.Cfg},
 		Store:        store,
 		RuleStore:    store,
-		Notifier:     ng.Alertmanager,
+		Notifier:     ng.Alertmanagers,
+
+		EvaluationJitter:         ng.Cfg.UnifiedAlerting.EvaluationJitter,
+		MaxConcurrentEvaluations: ng.Cfg.UnifiedAlerting.MaxConcurrentEvaluations,
 	}
 	ng.schedule = schedule.NewScheduler(schedCfg, ng.DataService)
 
 	api := api.API{
-		Cfg:             ng.Cfg,
-		DatasourceCache: ng.DatasourceCache,
-		RouteRegister:   ng.RouteRegister,
-		DataService:     ng.DataService,
-		Schedule:        ng.schedule,
-		DataProxy:       ng.DataProxy,
-		Store:           store,
-		RuleStore:       store,
-		AlertingStore:   store,
-		Alertmanager:    ng.Alertmanager,
-		StateManager:    ng.stateManager,
+		Cfg:               ng.Cfg,
+		DatasourceCache:   ng.DatasourceCache,
+		RouteRegister:     ng.RouteRegister,
+		DataService:       ng.DataService,
+		Schedule:          ng.schedule,
+		DataProxy:         ng.DataProxy,
+		Store:             store,
+		RuleStore:         store,
+		AlertingStore:     store,
+		Alertmanagers:     ng.Alertmanagers,
+		StateManager:      ng.stateManager,
+		StateHistoryStore: store,
+		ProvisioningStore: store,
 	}
 	api.RegisterAPIEndpoints()
 
@@ -113,12 +111,18 @@ func (ng *AlertNG) AddMigration(mg *migrator.Migrator) {
 	if ng.IsDisabled() {
 		return
 	}
-	store.AddAlertDefinitionMigrations(mg, defaultIntervalSeconds)
+	store.AddAlertDefinitionMigrations(mg, store.DefaultIntervalSeconds)
 	store.AddAlertDefinitionVersionMigrations(mg)
 	// Create alert_instance table
 	store.AlertInstanceMigration(mg)
 
 	// Create alert_rule
-	store.AddAlertRuleMigrations(mg, defaultIntervalSeconds)
+	store.AddAlertRuleMigrations(mg, store.DefaultIntervalSeconds)
 	store.AddAlertRuleVersionMigrations(mg)
+
+	// Create alert_state_history
+	store.AddAlertStateHistoryMigrations(mg)
+
+	// Create alert_provisioning
+	store.AddAlertProvisioningMigrations(mg)
 }
@@ -7,7 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"time"
 
@@ -18,6 +20,7 @@ import (
 	"github.com/prometheus/alertmanager/nflog"
 	"github.com/prometheus/alertmanager/nflog/nflogpb"
 	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/provider"
 	"github.com/prometheus/alertmanager/provider/mem"
 	"github.com/prometheus/alertmanager/silence"
 	"github.com/prometheus/alertmanager/template"
@@ -28,20 +31,19 @@ import (
 	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
-	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/alerting"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
-	"github.com/grafana/grafana/pkg/services/sqlstore"
-	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
-	pollInterval = 1 * time.Minute
-	workingDir   = "alerting"
+	workingDir = "alerting"
+	// legacyStateOrgID is the organization the notification log and silences of the single
+	// Alertmanager shared by all organizations are moved to.
+	legacyStateOrgID = 1
 	// How long should we keep silences and notification entries on-disk after they've served their purpose.
 	retentionNotificationsAndSilences = 5 * 24 * time.Hour
 	// maintenanceNotificationAndSilences how often should we flush and gargabe collect notifications and silences
@@ -78,9 +80,14 @@ const (
 
 type Alertmanager struct {
 	logger   log.Logger
-	Settings *setting.Cfg       `inject:""`
-	SQLStore *sqlstore.SQLStore `inject:""`
+	Settings *setting.Cfg
 	Store    store.AlertingStore
+	// orgID is the organization the Alertmanager routes the alerts of.
+	orgID int64
+
+	// peer shares the silences and the notification log with the other replicas.
+	peer        ClusterPeer
+	peerTimeout time.Duration
 
 	notificationLog *nflog.Log
 	marker          types.Marker
@@ -103,28 +110,37 @@ type Alertmanager struct {
 
 	reloadConfigMtx sync.RWMutex
 	config          []byte
+	// template is the template of the applied configuration, used to test receivers.
+	template *template.Template
 }
 
-func init() {
-	registry.RegisterService(&Alertmanager{})
-}
-
-func (am *Alertmanager) IsDisabled() bool {
-	if am.Settings == nil {
-		return true
+// newAlertmanager creates the Alertmanager of an organization, with its own notification log,
+// silences and alerts persisted in the working directory of the organization. The notification
+// log and silences are shared with the other replicas through peer.
+func newAlertmanager(orgID int64, cfg *setting.Cfg, store store.AlertingStore, peer ClusterPeer) (*Alertmanager, error) {
+	am := &Alertmanager{
+		Settings:    cfg,
+		Store:       store,
+		orgID:       orgID,
+		peer:        peer,
+		peerTimeout: cfg.UnifiedAlerting.HAPeerTimeout,
+		stopc:       make(chan struct{}),
+		logger:      log.New("alertmanager", "org", orgID),
 	}
-	return !am.Settings.IsNgAlertEnabled()
-}
 
-func (am *Alertmanager) Init() (err error) {
-	am.stopc = make(chan struct{})
-	am.logger = log.New("alertmanager")
 	r := prometheus.NewRegistry()
 	am.marker = types.NewMarker(r)
 	am.stageMetrics = notify.NewMetrics(r)
 	am.dispatcherMetrics = dispatch.NewDispatcherMetrics(r)
-	am.Store = store.DBstore{SQLStore: am.SQLStore}
 
+	if err := os.MkdirAll(am.WorkingDirPath(), 0750); err != nil {
+		return nil, fmt.Errorf("unable to create the working directory of alerting: %w", err)
+	}
+	if err := am.moveLegacyState(); err != nil {
+		return nil, fmt.Errorf("unable to move the state of alerting to the working directory of the organization: %w", err)
+	}
+
+	var err error
 	// Initialize the notification log
 	am.wg.Add(1)
 	am.notificationLog, err = nflog.New(
@@ -133,16 +149,21 @@ func (am *Alertmanager) Init() (err error) {
 		nflog.WithMaintenance(maintenanceNotificationAndSilences, am.stopc, am.wg.Done),
 	)
 	if err != nil {
-		return fmt.Errorf("unable to initialize the notification log component of alerting: %w", err)
+		return nil, fmt.Errorf("unable to initialize the notification log component of alerting: %w", err)
 	}
+	c := am.peer.AddState(fmt.Sprintf("notificationlog:%d", orgID), am.notificationLog, r)
+	am.notificationLog.SetBroadcast(c.Broadcast)
+
 	// Initialize silences
 	am.silences, err = silence.New(silence.Options{
 		SnapshotFile: filepath.Join(am.WorkingDirPath(), "silences"),
 		Retention:    retentionNotificationsAndSilences,
 	})
 	if err != nil {
-		return fmt.Errorf("unable to initialize the silencing component of alerting: %w", err)
+		return nil, fmt.Errorf("unable to initialize the silencing component of alerting: %w", err)
 	}
+	c = am.peer.AddState(fmt.Sprintf("silences:%d", orgID), am.silences, r)
+	am.silences.SetBroadcast(c.Broadcast)
 
 	am.wg.Add(1)
 	go func() {
@@ -153,36 +174,14 @@ func (am *Alertmanager) Init() (err error) {
 	// Initialize in-memory alerts
 	am.alerts, err = mem.NewAlerts(context.Background(), am.marker, memoryAlertsGCInterval, gokit_log.NewNopLogger())
 	if err != nil {
-		return fmt.Errorf("unable to initialize the alert provider component of alerting: %w", err)
-	}
-
-	return nil
-}
-
-func (am *Alertmanager) Run(ctx context.Context) error {
-	// Make sure dispatcher starts. We can tolerate future reload failures.
-	if err := am.SyncAndApplyConfigFromDatabase(); err != nil {
-		am.logger.Error("unable to sync configuration", "err", err)
-	}
-
-	for {
-		select {
-		case <-ctx.Done():
-			return am.StopAndWait()
-		case <-time.After(pollInterval):
-			if err := am.SyncAndApplyConfigFromDatabase(); err != nil {
-				am.logger.Error("unable to sync configuration", "err", err)
-			}
-		}
+		return nil, fmt.Errorf("unable to initialize the alert provider component of alerting: %w", err)
 	}
-}
 
-// AddMigration runs the database migrations as the service starts.
-func (am *Alertmanager) AddMigration(mg *migrator.Migrator) {
-	alertmanagerConfigurationMigration(mg)
+	return am, nil
 }
 
-func (am *Alertmanager) StopAndWait() error {
+// StopAndWait stops the Alertmanager and waits for its components to finish.
+func (am *Alertmanager) StopAndWait() {
 	if am.dispatcher != nil {
 		am.dispatcher.Stop()
 	}
@@ -196,7 +195,6 @@ func (am *Alertmanager) StopAndWait() error {
 	close(am.stopc)
 
 	am.wg.Wait()
-	return nil
 }
 
 func (am *Alertmanager) SaveAndApplyConfig(cfg *apimodels.PostableUserConfig) error {
@@ -209,6 +207,7 @@ func (am *Alertmanager) SaveAndApplyConfig(cfg *apimodels.PostableUserConfig) er
 	defer am.reloadConfigMtx.Unlock()
 
 	cmd := &ngmodels.SaveAlertmanagerConfigurationCmd{
+		OrgID:                     am.orgID,
 		AlertmanagerConfiguration: string(rawConfig),
 		ConfigurationVersion:      fmt.Sprintf("v%d", ngmodels.AlertConfigurationVersion),
 	}
@@ -230,7 +229,7 @@ func (am *Alertmanager) SyncAndApplyConfigFromDatabase() error {
 	defer am.reloadConfigMtx.Unlock()
 
 	// First, let's get the configuration we need from the database.
-	q := &ngmodels.GetLatestAlertmanagerConfigurationQuery{}
+	q := &ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: am.orgID}
 	if err := am.Store.GetLatestAlertmanagerConfiguration(q); err != nil {
 		// If there's no configuration in the database, let's use the default configuration.
 		if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
@@ -316,18 +315,21 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig) error {
 		am.dispatcher.Stop()
 	}
 
-	am.inhibitor = inhibit.NewInhibitor(am.alerts, cfg.AlertmanagerConfig.InhibitRules, am.marker, gokit_log.NewNopLogger())
+	inhibitorAlerts := newSubscriptionAlerts(am.alerts)
+	am.inhibitor = inhibit.NewInhibitor(inhibitorAlerts, cfg.AlertmanagerConfig.InhibitRules, am.marker, gokit_log.NewNopLogger())
 	am.silencer = silence.NewSilencer(am.silences, am.marker, gokit_log.NewNopLogger())
 
+	settleStage := notify.NewGossipSettleStage(am.peer)
 	inhibitionStage := notify.NewMuteStage(am.inhibitor)
 	silencingStage := notify.NewMuteStage(am.silencer)
 	for name := range integrationsMap {
-		stage := am.createReceiverStage(name, integrationsMap[name], waitFunc, am.notificationLog)
-		routingStage[name] = notify.MultiStage{silencingStage, inhibitionStage, stage}
+		stage := am.createReceiverStage(name, integrationsMap[name], am.waitFunc, am.notificationLog)
+		routingStage[name] = notify.MultiStage{settleStage, silencingStage, inhibitionStage, stage}
 	}
 
 	am.route = dispatch.NewRoute(cfg.AlertmanagerConfig.Route, nil)
-	am.dispatcher = dispatch.NewDispatcher(am.alerts, am.route, routingStage, am.marker, timeoutFunc, gokit_log.NewNopLogger(), am.dispatcherMetrics)
+	dispatcherAlerts := newSubscriptionAlerts(am.alerts)
+	am.dispatcher = dispatch.NewDispatcher(dispatcherAlerts, am.route, routingStage, am.marker, am.timeoutFunc, gokit_log.NewNopLogger(), am.dispatcherMetrics)
 
 	am.wg.Add(1)
 	go func() {
@@ -341,12 +343,68 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig) error {
 		am.inhibitor.Run()
 	}()
 
+	// Stopping the dispatcher or inhibitor before they run blocks them forever.
+	<-dispatcherAlerts.subscribed
+	<-inhibitorAlerts.subscribed
+
 	am.config = rawConfig
+	am.template = tmpl
 	return nil
 }
 
+// moveLegacyState moves the notification log and silences persisted by the single Alertmanager,
+// which ran before each organization had its own one, to the working directory of the main
+// organization, so that active silences are kept and notified alerts aren't sent again.
+func (am *Alertmanager) moveLegacyState() error {
+	if am.orgID != legacyStateOrgID {
+		return nil
+	}
+
+	for _, name := range []string{"notifications", "silences"} {
+		legacyPath := filepath.Join(am.Settings.DataPath, workingDir, name)
+		path := filepath.Join(am.WorkingDirPath(), name)
+
+		if _, err := os.Stat(legacyPath); errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		// the state of the organization wins if it already exists
+		if _, err := os.Stat(path); err == nil {
+			continue
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		if err := os.Rename(legacyPath, path); err != nil {
+			return err
+		}
+		am.logger.Info("moved the state of alerting to the working directory of the organization", "from", legacyPath, "to", path)
+	}
+	return nil
+}
+
+// WorkingDirPath returns the directory the Alertmanager of the organization persists its state to.
 func (am *Alertmanager) WorkingDirPath() string {
-	return filepath.Join(am.Settings.DataPath, workingDir)
+	return filepath.Join(am.Settings.DataPath, workingDir, strconv.FormatInt(am.orgID, 10))
+}
+
+// subscriptionAlerts is an alert provider that signals when it is first subscribed to. The dispatcher
+// and inhibitor subscribe to the alerts once they have started and are able to be stopped.
+type subscriptionAlerts struct {
+	provider.Alerts
+	once       sync.Once
+	subscribed chan struct{}
+}
+
+func newSubscriptionAlerts(alerts provider.Alerts) *subscriptionAlerts {
+	return &subscriptionAlerts{Alerts: alerts, subscribed: make(chan struct{})}
+}
+
+func (a *subscriptionAlerts) Subscribe() provider.AlertIterator {
+	it := a.Alerts.Subscribe()
+	a.once.Do(func() { close(a.subscribed) })
+	return it
 }
 
 // buildIntegrationsMap builds a map of name to the list of Grafana integration notifiers off of a list of receiver config.
@@ -402,6 +460,30 @@ func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableAp
 			n, err = channels.NewDingDingNotifier(cfg, tmpl)
 		case "webhook":
 			n, err = channels.NewWebHookNotifier(cfg, tmpl)
+		case "opsgenie":
+			n, err = channels.NewOpsgenieNotifier(cfg, tmpl)
+		case "victorops":
+			n, err = channels.NewVictoropsNotifier(cfg, tmpl)
+		case "pushover":
+			n, err = channels.NewPushoverNotifier(cfg, tmpl)
+		case "sensu":
+			n, err = channels.NewSensuNotifier(cfg, tmpl)
+		case "sensugo":
+			n, err = channels.NewSensuGoNotifier(cfg, tmpl)
+		case "discord":
+			n, err = channels.NewDiscordNotifier(cfg, tmpl)
+		case "googlechat":
+			n, err = channels.NewGoogleChatNotifier(cfg, tmpl)
+		case "kafka":
+			n, err = channels.NewKafkaNotifier(cfg, tmpl)
+		case "LINE":
+			n, err = channels.NewLINENotifier(cfg, tmpl)
+		case "threema":
+			n, err = channels.NewThreemaNotifier(cfg, tmpl)
+		case "prometheus-alertmanager":
+			n, err = channels.NewAlertmanagerNotifier(cfg)
+		default:
+			return nil, fmt.Errorf("notifier %s is not supported", r.Type)
 		}
 		if err != nil {
 			return nil, err
@@ -517,16 +599,18 @@ func (am *Alertmanager) createReceiverStage(name string, integrations []notify.I
 	return fs
 }
 
-func waitFunc() time.Duration {
-	return setting.AlertingNotificationTimeout
+// waitFunc returns how long the Alertmanager waits before it notifies, giving the replicas
+// before it in the cluster a chance to notify first.
+func (am *Alertmanager) waitFunc() time.Duration {
+	return time.Duration(am.peer.Position()) * am.peerTimeout
 }
 
-func timeoutFunc(d time.Duration) time.Duration {
+func (am *Alertmanager) timeoutFunc(d time.Duration) time.Duration {
 	//TODO: What does MinTimeout means here?
 	if d < notify.MinTimeout {
 		d = notify.MinTimeout
 	}
-	return d + waitFunc()
+	return d + am.waitFunc()
 }
 
 // GetAvailableNotifiers returns the metadata of all the notification channels that can be configured.
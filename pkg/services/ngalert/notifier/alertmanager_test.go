@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"sort"
 	"testing"
 	"time"
@@ -13,38 +14,86 @@ import (
 	"github.com/go-openapi/strfmt"
 	"github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/alertmanager/provider/mem"
+	"github.com/prometheus/alertmanager/silence"
+	"github.com/prometheus/alertmanager/silence/silencepb"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+func setupAMTest(t *testing.T) *Alertmanager {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+
+	cfg := &setting.Cfg{
+		DataPath: dir,
+	}
+
+	sqlStore := sqlstore.InitTestDB(t)
+	store := store.DBstore{SQLStore: sqlStore}
+
+	am, err := newAlertmanager(1, cfg, store, NilPeer{})
+	require.NoError(t, err)
+	return am
+}
+
 func TestAlertmanager_ShouldUseDefaultConfigurationWhenNoConfiguration(t *testing.T) {
-	am := &Alertmanager{}
-	am.Settings = &setting.Cfg{}
-	am.SQLStore = sqlstore.InitTestDB(t)
-	require.NoError(t, am.Init())
+	am := setupAMTest(t)
 	require.NoError(t, am.SyncAndApplyConfigFromDatabase())
 	require.NotNil(t, am.config)
 }
 
-func TestPutAlert(t *testing.T) {
-	am := &Alertmanager{}
+func TestAlertmanager_ShouldMoveLegacyState(t *testing.T) {
 	dir, err := ioutil.TempDir("", "")
 	require.NoError(t, err)
 	t.Cleanup(func() {
 		require.NoError(t, os.RemoveAll(dir))
 	})
-
-	am.Settings = &setting.Cfg{
+	cfg := &setting.Cfg{
 		DataPath: dir,
 	}
+	store := store.DBstore{SQLStore: sqlstore.InitTestDB(t)}
 
-	require.NoError(t, am.Init())
+	// the single Alertmanager persisted its state directly in the alerting directory
+	silences := newSilences(t)
+	silenceID, err := silences.Set(newSilence(t))
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, workingDir), 0750))
+	f, err := os.Create(filepath.Join(dir, workingDir, "silences"))
+	require.NoError(t, err)
+	_, err = silences.Snapshot(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// other organizations start without state
+	am, err := newAlertmanager(2, cfg, store, NilPeer{})
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(dir, workingDir, "silences"))
+	sils, _, err := am.silences.Query()
+	require.NoError(t, err)
+	require.Empty(t, sils)
+
+	am, err = newAlertmanager(legacyStateOrgID, cfg, store, NilPeer{})
+	require.NoError(t, err)
+	require.NoFileExists(t, filepath.Join(dir, workingDir, "silences"))
+	require.FileExists(t, filepath.Join(am.WorkingDirPath(), "silences"))
+	sils, _, err = am.silences.Query()
+	require.NoError(t, err)
+	require.Len(t, sils, 1)
+	require.Equal(t, silenceID, sils[0].Id)
+}
+
+func TestPutAlert(t *testing.T) {
+	am := setupAMTest(t)
 
 	startTime := time.Now()
 	endTime := startTime.Add(2 * time.Hour)
@@ -264,11 +313,12 @@ func TestPutAlert(t *testing.T) {
 		t.Run(c.title, func(t *testing.T) {
 			r := prometheus.NewRegistry()
 			am.marker = types.NewMarker(r)
+			var err error
 			am.alerts, err = mem.NewAlerts(context.Background(), am.marker, 15*time.Minute, gokit_log.NewNopLogger())
 			require.NoError(t, err)
 
 			alerts := []*types.Alert{}
-			err := am.PutAlerts(c.postableAlerts)
+			err = am.PutAlerts(c.postableAlerts)
 			if c.expError != nil {
 				require.Error(t, err)
 				require.Equal(t, c.expError, err)
@@ -294,3 +344,24 @@ func TestPutAlert(t *testing.T) {
 		})
 	}
 }
+
+func newSilences(t *testing.T) *silence.Silences {
+	t.Helper()
+
+	silences, err := silence.New(silence.Options{Retention: time.Hour})
+	require.NoError(t, err)
+	return silences
+}
+
+func newSilence(t *testing.T) *silencepb.Silence {
+	t.Helper()
+
+	now := time.Now()
+	return &silencepb.Silence{
+		Matchers:  []*silencepb.Matcher{{Type: silencepb.Matcher_EQUAL, Name: "alertname", Pattern: "test"}},
+		StartsAt:  now,
+		EndsAt:    now.Add(time.Hour),
+		Comment:   "maintenance",
+		CreatedBy: "admin",
+	}
+}
@@ -0,0 +1,105 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+// AlertmanagerNotifier sends alert notifications to external Alertmanagers.
+type AlertmanagerNotifier struct {
+	old_notifiers.NotifierBase
+	URLs              []string
+	BasicAuthUser     string
+	BasicAuthPassword string
+	log               log.Logger
+}
+
+// NewAlertmanagerNotifier is the constructor for the Alertmanager notifier.
+func NewAlertmanagerNotifier(model *models.AlertNotification) (*AlertmanagerNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	urlStr := model.Settings.Get("url").MustString()
+	if urlStr == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find url property in settings"}
+	}
+
+	var urls []string
+	for _, u := range strings.Split(urlStr, ",") {
+		u = strings.TrimSpace(u)
+		if u != "" {
+			urls = append(urls, u)
+		}
+	}
+
+	return &AlertmanagerNotifier{
+		NotifierBase:      old_notifiers.NewNotifierBase(model),
+		URLs:              urls,
+		BasicAuthUser:     model.Settings.Get("basicAuthUser").MustString(),
+		BasicAuthPassword: model.DecryptedValue("basicAuthPassword", model.Settings.Get("basicAuthPassword").MustString()),
+		log:               log.New("alerting.notifier.prometheus-alertmanager"),
+	}, nil
+}
+
+// Notify sends the alerts, with their labels and annotations, to every configured Alertmanager.
+func (n *AlertmanagerNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	n.log.Debug("Sending Alertmanager alert", "notification", n.Name)
+	if len(as) == 0 {
+		return true, nil
+	}
+
+	alerts := make([]*model.Alert, 0, len(as))
+	for _, a := range as {
+		alert := a.Alert
+		alerts = append(alerts, &alert)
+	}
+
+	body, err := json.Marshal(alerts)
+	if err != nil {
+		return false, err
+	}
+
+	var (
+		lastErr error
+		numErrs int
+	)
+	for _, u := range n.URLs {
+		cmd := &models.SendWebhookSync{
+			Url:        strings.TrimSuffix(u, "/") + "/api/v1/alerts",
+			User:       n.BasicAuthUser,
+			Password:   n.BasicAuthPassword,
+			HttpMethod: "POST",
+			Body:       string(body),
+		}
+
+		if err := bus.DispatchCtx(ctx, cmd); err != nil {
+			n.log.Warn("Failed to send to Alertmanager", "error", err, "alertmanager", n.Name, "url", u)
+			lastErr = err
+			numErrs++
+		}
+	}
+
+	if numErrs == len(n.URLs) {
+		// All attempts to send alerts have failed
+		n.log.Warn("All attempts to send to Alertmanager failed", "alertmanager", n.Name)
+		return false, fmt.Errorf("failed to send alert to Alertmanager: %w", lastErr)
+	}
+
+	return true, nil
+}
+
+func (n *AlertmanagerNotifier) SendResolved() bool {
+	return !n.GetDisableResolveMessage()
+}
@@ -0,0 +1,115 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestAlertmanagerNotifier(t *testing.T) {
+	startsAt := time.Date(2021, time.May, 1, 12, 0, 0, 0, time.UTC)
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:       model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations:  model.LabelSet{"ann1": "annv1"},
+				StartsAt:     startsAt,
+				EndsAt:       startsAt.Add(time.Hour),
+				GeneratorURL: "http://localhost/alerting/list",
+			},
+		},
+	}
+	expBody := `[{
+		"labels": {"alertname": "alert1", "lbl1": "val1"},
+		"annotations": {"ann1": "annv1"},
+		"startsAt": "2021-05-01T12:00:00Z",
+		"endsAt": "2021-05-01T13:00:00Z",
+		"generatorURL": "http://localhost/alerting/list"
+	}]`
+
+	cases := []struct {
+		name         string
+		settings     string
+		failingURLs  map[string]bool
+		expURLs      []string
+		expMsgError  error
+		expInitError error
+	}{
+		{
+			name:     "Alerts are sent to every Alertmanager",
+			settings: `{"url": "http://alertmanager1:9093/, http://alertmanager2:9093", "basicAuthUser": "user", "basicAuthPassword": "password"}`,
+			expURLs:  []string{"http://alertmanager1:9093/api/v1/alerts", "http://alertmanager2:9093/api/v1/alerts"},
+		}, {
+			name:        "One failing Alertmanager is tolerated",
+			settings:    `{"url": "http://alertmanager1:9093,http://alertmanager2:9093", "basicAuthUser": "user", "basicAuthPassword": "password"}`,
+			failingURLs: map[string]bool{"http://alertmanager1:9093/api/v1/alerts": true},
+			expURLs:     []string{"http://alertmanager1:9093/api/v1/alerts", "http://alertmanager2:9093/api/v1/alerts"},
+		}, {
+			name:        "Error when every Alertmanager fails",
+			settings:    `{"url": "http://alertmanager1:9093", "basicAuthUser": "user", "basicAuthPassword": "password"}`,
+			failingURLs: map[string]bool{"http://alertmanager1:9093/api/v1/alerts": true},
+			expURLs:     []string{"http://alertmanager1:9093/api/v1/alerts"},
+			expMsgError: errors.New("failed to send alert to Alertmanager: unavailable"),
+		}, {
+			name:         "Error in initing",
+			settings:     `{}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find url property in settings"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "alertmanager_testing",
+				Type:     "prometheus-alertmanager",
+				Settings: settingsJSON,
+			}
+
+			n, err := NewAlertmanagerNotifier(m)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var urls []string
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				urls = append(urls, webhook.Url)
+				require.Equal(t, "user", webhook.User)
+				require.Equal(t, "password", webhook.Password)
+				require.JSONEq(t, expBody, webhook.Body)
+				if c.failingURLs[webhook.Url] {
+					return errors.New("unavailable")
+				}
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ok, err := n.Notify(ctx, alerts...)
+			require.Equal(t, c.expURLs, urls)
+			if c.expMsgError != nil {
+				require.False(t, ok)
+				require.Error(t, err)
+				require.Equal(t, c.expMsgError.Error(), err.Error())
+				return
+			}
+			require.True(t, ok)
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// DiscordNotifier is responsible for sending alert
+// notifications to Discord.
+type DiscordNotifier struct {
+	old_notifiers.NotifierBase
+	WebhookURL string
+	Content    string
+	log        log.Logger
+	tmpl       *template.Template
+}
+
+// NewDiscordNotifier is the constructor for the Discord notifier
+func NewDiscordNotifier(model *models.AlertNotification, t *template.Template) (*DiscordNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	url := model.Settings.Get("url").MustString()
+	if url == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find webhook url property in settings"}
+	}
+
+	return &DiscordNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(model),
+		WebhookURL:   url,
+		Content:      model.Settings.Get("content").MustString(`{{ template "default.message" . }}`),
+		log:          log.New("alerting.notifier.discord"),
+		tmpl:         t,
+	}, nil
+}
+
+// Notify sends an alert notification to Discord.
+func (dn *DiscordNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	data := notify.GetTemplateData(ctx, dn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(dn.tmpl, data, &tmplErr)
+
+	bodyJSON := map[string]interface{}{
+		"username": "Grafana",
+	}
+	if content := tmpl(dn.Content); content != "" {
+		bodyJSON["content"] = content
+	}
+
+	// Discord takes integer for color
+	color, _ := strconv.ParseInt(strings.TrimLeft(getAlertStatusColor(types.Alerts(as...).Status()), "#"), 16, 0)
+	embed := map[string]interface{}{
+		"title": tmpl(`{{ template "default.title" . }}`),
+		"color": color,
+		"url":   ruleListURL(dn.tmpl.ExternalURL),
+		"type":  "rich",
+		"footer": map[string]interface{}{
+			"text":     "Grafana v" + setting.BuildVersion,
+			"icon_url": FooterIconURL,
+		},
+	}
+	bodyJSON["embeds"] = []interface{}{embed}
+
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template discord message: %w", tmplErr)
+	}
+
+	body, err := json.Marshal(bodyJSON)
+	if err != nil {
+		return false, err
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:         dn.WebhookURL,
+		HttpMethod:  "POST",
+		ContentType: "application/json",
+		Body:        string(body),
+	}
+
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		dn.log.Error("Failed to send notification to Discord", "error", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (dn *DiscordNotifier) SendResolved() bool {
+	return !dn.GetDisableResolveMessage()
+}
@@ -0,0 +1,130 @@
+package channels
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestDiscordNotifier(t *testing.T) {
+	tmpl, err := template.FromGlobs("templates/default.tmpl")
+	require.NoError(t, err)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expMsg       string
+		expInitError error
+	}{
+		{
+			name:     "Default config with one alert",
+			settings: `{"url": "http://localhost"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: `{
+				"username": "Grafana",
+				"content": "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n",
+				"embeds": [{
+					"title": "[FIRING:1]  (val1)",
+					"color": 14037554,
+					"url": "http://localhost/alerting/list",
+					"type": "rich",
+					"footer": {
+						"text": "Grafana v",
+						"icon_url": "https://grafana.com/assets/img/fav32.png"
+					}
+				}]
+			}`,
+		}, {
+			name: "Custom content with resolved alerts",
+			settings: `{
+				"url": "http://localhost",
+				"content": "{{ len .Alerts.Resolved }} alerts are resolved"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expMsg: `{
+				"username": "Grafana",
+				"content": "1 alerts are resolved",
+				"embeds": [{
+					"title": "[RESOLVED]  ",
+					"color": 3581519,
+					"url": "http://localhost/alerting/list",
+					"type": "rich",
+					"footer": {
+						"text": "Grafana v",
+						"icon_url": "https://grafana.com/assets/img/fav32.png"
+					}
+				}]
+			}`,
+		}, {
+			name:         "Error in initing",
+			settings:     `{}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find webhook url property in settings"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "discord_testing",
+				Type:     "discord",
+				Settings: settingsJSON,
+			}
+
+			dn, err := NewDiscordNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			body := ""
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				body = webhook.Body
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := dn.Notify(ctx, c.alerts...)
+			require.True(t, ok)
+			require.NoError(t, err)
+
+			require.JSONEq(t, c.expMsg, body)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package channels
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestGoogleChatNotifier(t *testing.T) {
+	tmpl, err := template.FromGlobs("templates/default.tmpl")
+	require.NoError(t, err)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	timeNow = func() time.Time { return time.Date(2021, time.May, 1, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { timeNow = time.Now })
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expMsg       string
+		expInitError error
+	}{
+		{
+			name:     "Default config with one alert",
+			settings: `{"url": "http://localhost"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: `{
+				"previewText": "[FIRING:1]  (val1)",
+				"fallbackText": "[FIRING:1]  (val1)",
+				"cards": [{
+					"header": {"title": "[FIRING:1]  (val1)"},
+					"sections": [{
+						"widgets": [
+							{"textParagraph": {"text": "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n"}},
+							{"buttons": [{"textButton": {"text": "OPEN IN GRAFANA", "onClick": {"openLink": {"url": "http://localhost/alerting/list"}}}}]},
+							{"textParagraph": {"text": "Grafana v | 01 May 21 12:00 UTC"}}
+						]
+					}]
+				}]
+			}`,
+		}, {
+			name:     "An empty message leaves out its widget",
+			settings: `{"url": "http://localhost", "message": ""}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+					},
+				},
+			},
+			expMsg: `{
+				"previewText": "[FIRING:1]  ",
+				"fallbackText": "[FIRING:1]  ",
+				"cards": [{
+					"header": {"title": "[FIRING:1]  "},
+					"sections": [{
+						"widgets": [
+							{"buttons": [{"textButton": {"text": "OPEN IN GRAFANA", "onClick": {"openLink": {"url": "http://localhost/alerting/list"}}}}]},
+							{"textParagraph": {"text": "Grafana v | 01 May 21 12:00 UTC"}}
+						]
+					}]
+				}]
+			}`,
+		}, {
+			name:         "Error in initing",
+			settings:     `{}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find url property in settings"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "googlechat_testing",
+				Type:     "googlechat",
+				Settings: settingsJSON,
+			}
+
+			gcn, err := NewGoogleChatNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			body := ""
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				body = webhook.Body
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := gcn.Notify(ctx, c.alerts...)
+			require.True(t, ok)
+			require.NoError(t, err)
+
+			require.JSONEq(t, c.expMsg, body)
+		})
+	}
+}
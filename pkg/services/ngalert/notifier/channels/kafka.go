@@ -0,0 +1,122 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+// KafkaNotifier is responsible for sending
+// alert notifications to Kafka.
+type KafkaNotifier struct {
+	old_notifiers.NotifierBase
+	Endpoint string
+	Topic    string
+	log      log.Logger
+	tmpl     *template.Template
+}
+
+// NewKafkaNotifier is the constructor function for the Kafka notifier.
+func NewKafkaNotifier(model *models.AlertNotification, t *template.Template) (*KafkaNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	endpoint := model.Settings.Get("kafkaRestProxy").MustString()
+	if endpoint == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find kafka rest proxy endpoint property in settings"}
+	}
+	topic := model.Settings.Get("kafkaTopic").MustString()
+	if topic == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find kafka topic property in settings"}
+	}
+
+	return &KafkaNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(model),
+		Endpoint:     endpoint,
+		Topic:        topic,
+		log:          log.New("alerting.notifier.kafka"),
+		tmpl:         t,
+	}, nil
+}
+
+// Notify sends the alert notification.
+func (kn *KafkaNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	// The legacy alert states are kept so that the consumers of the topic keep working.
+	alerts := types.Alerts(as...)
+	state := models.AlertStateAlerting
+	if alerts.Status() == model.AlertResolved {
+		state = models.AlertStateOK
+	}
+
+	kn.log.Debug("Notifying Kafka", "alert_state", state)
+
+	key, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	data := notify.GetTemplateData(ctx, kn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(kn.tmpl, data, &tmplErr)
+
+	bodyJSON := map[string]interface{}{
+		"alert_state":  state,
+		"description":  tmpl(`{{ template "default.title" . }}`),
+		"client":       "Grafana",
+		"details":      tmpl(`{{ template "default.message" . }}`),
+		"client_url":   ruleListURL(kn.tmpl.ExternalURL),
+		"incident_key": key.Hash(),
+	}
+	topicURL := strings.TrimRight(kn.Endpoint, "/") + "/topics/" + tmpl(kn.Topic)
+
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template Kafka message: %w", tmplErr)
+	}
+
+	recordJSON := map[string]interface{}{
+		"records": []interface{}{
+			map[string]interface{}{
+				"value": bodyJSON,
+			},
+		},
+	}
+	body, err := json.Marshal(recordJSON)
+	if err != nil {
+		return false, err
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:        topicURL,
+		Body:       string(body),
+		HttpMethod: "POST",
+		HttpHeader: map[string]string{
+			"Content-Type": "application/vnd.kafka.json.v2+json",
+			"Accept":       "application/vnd.kafka.v2+json",
+		},
+	}
+
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		kn.log.Error("Failed to send notification to Kafka", "error", err, "body", string(body))
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (kn *KafkaNotifier) SendResolved() bool {
+	return !kn.GetDisableResolveMessage()
+}
@@ -0,0 +1,129 @@
+package channels
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestKafkaNotifier(t *testing.T) {
+	tmpl, err := template.FromGlobs("templates/default.tmpl")
+	require.NoError(t, err)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expMsg       string
+		expInitError error
+	}{
+		{
+			name:     "A single alert",
+			settings: `{"kafkaRestProxy": "http://localhost:8082/", "kafkaTopic": "sometopic"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: `{
+				"records": [{
+					"value": {
+						"alert_state": "alerting",
+						"client": "Grafana",
+						"client_url": "http://localhost/alerting/list",
+						"description": "[FIRING:1]  (val1)",
+						"details": "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n",
+						"incident_key": "6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733"
+					}
+				}]
+			}`,
+		}, {
+			name:     "Resolved alerts",
+			settings: `{"kafkaRestProxy": "http://localhost:8082", "kafkaTopic": "sometopic"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expMsg: `{
+				"records": [{
+					"value": {
+						"alert_state": "ok",
+						"client": "Grafana",
+						"client_url": "http://localhost/alerting/list",
+						"description": "[RESOLVED]  ",
+						"details": "\n\n**Resolved**\nLabels:\n - alertname = alert1\nAnnotations:\nSource: \n\n\n",
+						"incident_key": "6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733"
+					}
+				}]
+			}`,
+		}, {
+			name:         "Endpoint missing",
+			settings:     `{"kafkaTopic": "sometopic"}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find kafka rest proxy endpoint property in settings"},
+		}, {
+			name:         "Topic missing",
+			settings:     `{"kafkaRestProxy": "http://localhost:8082"}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find kafka topic property in settings"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "kafka_testing",
+				Type:     "kafka",
+				Settings: settingsJSON,
+			}
+
+			kn, err := NewKafkaNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var webhook *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, w *models.SendWebhookSync) error {
+				webhook = w
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := kn.Notify(ctx, c.alerts...)
+			require.True(t, ok)
+			require.NoError(t, err)
+
+			require.NotNil(t, webhook)
+			require.Equal(t, "http://localhost:8082/topics/sometopic", webhook.Url)
+			require.JSONEq(t, c.expMsg, webhook.Body)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+var (
+	lineNotifyURL = "https://notify-api.line.me/api/notify"
+)
+
+// LineNotifier is responsible for sending
+// alert notifications to LINE.
+type LineNotifier struct {
+	old_notifiers.NotifierBase
+	Token string
+	log   log.Logger
+	tmpl  *template.Template
+}
+
+// NewLINENotifier is the constructor for the LINE notifier
+func NewLINENotifier(model *models.AlertNotification, t *template.Template) (*LineNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	token := model.DecryptedValue("token", model.Settings.Get("token").MustString())
+	if token == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find token in settings"}
+	}
+
+	return &LineNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(model),
+		Token:        token,
+		log:          log.New("alerting.notifier.line"),
+		tmpl:         t,
+	}, nil
+}
+
+// Notify sends an alert notification to LINE
+func (ln *LineNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	ln.log.Debug("Executing line notification", "notification", ln.Name)
+
+	data := notify.GetTemplateData(ctx, ln.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(ln.tmpl, data, &tmplErr)
+
+	body := fmt.Sprintf(
+		"%s\n%s\n\n%s",
+		tmpl(`{{ template "default.title" . }}`),
+		ruleListURL(ln.tmpl.ExternalURL),
+		tmpl(`{{ template "default.message" . }}`),
+	)
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template LINE message: %w", tmplErr)
+	}
+
+	form := url.Values{}
+	form.Add("message", body)
+
+	cmd := &models.SendWebhookSync{
+		Url:        lineNotifyURL,
+		HttpMethod: "POST",
+		HttpHeader: map[string]string{
+			"Authorization": fmt.Sprintf("Bearer %s", ln.Token),
+			"Content-Type":  "application/x-www-form-urlencoded;charset=UTF-8",
+		},
+		Body: form.Encode(),
+	}
+
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		ln.log.Error("Failed to send notification to LINE", "error", err, "body", body)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (ln *LineNotifier) SendResolved() bool {
+	return !ln.GetDisableResolveMessage()
+}
@@ -0,0 +1,211 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+const (
+	opsgenieSendTags    = "tags"
+	opsgenieSendDetails = "details"
+	opsgenieSendBoth    = "both"
+)
+
+var (
+	opsgenieAlertURL        = "https://api.opsgenie.com/v2/alerts"
+	opsgenieValidPriorities = map[string]bool{"P1": true, "P2": true, "P3": true, "P4": true, "P5": true}
+)
+
+// OpsgenieNotifier is responsible for sending alert notifications to Opsgenie.
+type OpsgenieNotifier struct {
+	old_notifiers.NotifierBase
+	APIKey           string
+	APIUrl           string
+	AutoClose        bool
+	OverridePriority bool
+	SendTagsAs       string
+	tmpl             *template.Template
+	log              log.Logger
+}
+
+// NewOpsgenieNotifier is the constructor for the Opsgenie notifier
+func NewOpsgenieNotifier(model *models.AlertNotification, t *template.Template) (*OpsgenieNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	autoClose := model.Settings.Get("autoClose").MustBool(true)
+	overridePriority := model.Settings.Get("overridePriority").MustBool(true)
+	apiKey := model.DecryptedValue("apiKey", model.Settings.Get("apiKey").MustString())
+	apiURL := model.Settings.Get("apiUrl").MustString()
+	if apiKey == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find api key property in settings"}
+	}
+	if apiURL == "" {
+		apiURL = opsgenieAlertURL
+	}
+
+	sendTagsAs := model.Settings.Get("sendTagsAs").MustString(opsgenieSendTags)
+	if sendTagsAs != opsgenieSendTags && sendTagsAs != opsgenieSendDetails && sendTagsAs != opsgenieSendBoth {
+		return nil, alerting.ValidationError{
+			Reason: fmt.Sprintf("Invalid value for sendTagsAs: %q", sendTagsAs),
+		}
+	}
+
+	return &OpsgenieNotifier{
+		NotifierBase:     old_notifiers.NewNotifierBase(model),
+		APIKey:           apiKey,
+		APIUrl:           apiURL,
+		AutoClose:        autoClose,
+		OverridePriority: overridePriority,
+		SendTagsAs:       sendTagsAs,
+		tmpl:             t,
+		log:              log.New("alerting.notifier." + model.Name),
+	}, nil
+}
+
+// Notify sends an alert notification to Opsgenie
+func (on *OpsgenieNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	on.log.Debug("Executing Opsgenie notification", "notification", on.Name)
+
+	alerts := types.Alerts(as...)
+	if alerts.Status() == model.AlertResolved && !on.SendResolved() {
+		on.log.Debug("Not sending a trigger to Opsgenie", "status", alerts.Status(), "auto resolve", on.SendResolved())
+		return true, nil
+	}
+
+	bodyJSON, url, err := on.buildOpsgenieMessage(ctx, alerts, as)
+	if err != nil {
+		return false, fmt.Errorf("build Opsgenie message: %w", err)
+	}
+
+	if url == "" {
+		// Resolved alert with no auto close.
+		// Hence skip sending anything.
+		return true, nil
+	}
+
+	body, err := json.Marshal(bodyJSON)
+	if err != nil {
+		return false, fmt.Errorf("marshal json: %w", err)
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:        url,
+		Body:       string(body),
+		HttpMethod: "POST",
+		HttpHeader: map[string]string{
+			"Content-Type":  "application/json",
+			"Authorization": fmt.Sprintf("GenieKey %s", on.APIKey),
+		},
+	}
+
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return false, fmt.Errorf("send notification to Opsgenie: %w", err)
+	}
+
+	return true, nil
+}
+
+func (on *OpsgenieNotifier) buildOpsgenieMessage(ctx context.Context, alerts model.Alerts, as []*types.Alert) (payload map[string]interface{}, apiURL string, err error) {
+	key, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if alerts.Status() == model.AlertResolved {
+		// For resolved notification, we only need the source.
+		// Don't need to run other templates.
+		if !on.AutoClose {
+			return nil, "", nil
+		}
+		return map[string]interface{}{
+			"source": "Grafana",
+		}, fmt.Sprintf("%s/%s/close?identifierType=alias", on.APIUrl, key.Hash()), nil
+	}
+
+	data := notify.GetTemplateData(ctx, on.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(on.tmpl, data, &tmplErr)
+
+	title := tmpl(`{{ template "default.title" . }}`)
+	description := fmt.Sprintf(
+		"%s\n%s\n\n%s",
+		title,
+		ruleListURL(on.tmpl.ExternalURL),
+		tmpl(`{{ template "default.message" . }}`),
+	)
+
+	// The labels common to all alerts replace the tags of the legacy alert rules.
+	var priority string
+	lbls := make(map[string]string, len(data.CommonLabels))
+	for k, v := range data.CommonLabels {
+		lbls[k] = v
+		if k == "og_priority" && opsgenieValidPriorities[v] {
+			priority = v
+		}
+	}
+
+	bodyJSON := map[string]interface{}{
+		"message":     truncate(title, 130),
+		"source":      "Grafana",
+		"alias":       key.Hash(),
+		"description": description,
+	}
+
+	details := map[string]interface{}{
+		"url": ruleListURL(on.tmpl.ExternalURL),
+	}
+	if on.sendDetails() {
+		for k, v := range lbls {
+			details[k] = v
+		}
+	}
+
+	tags := make([]string, 0, len(lbls))
+	if on.sendTags() {
+		for k, v := range lbls {
+			tags = append(tags, fmt.Sprintf("%s:%s", k, v))
+		}
+	}
+	sort.Strings(tags)
+
+	if priority != "" && on.OverridePriority {
+		bodyJSON["priority"] = priority
+	}
+
+	bodyJSON["tags"] = tags
+	bodyJSON["details"] = details
+
+	if tmplErr != nil {
+		return nil, "", fmt.Errorf("failed to template Opsgenie message: %w", tmplErr)
+	}
+
+	return bodyJSON, on.APIUrl, nil
+}
+
+func (on *OpsgenieNotifier) SendResolved() bool {
+	return !on.GetDisableResolveMessage()
+}
+
+func (on *OpsgenieNotifier) sendDetails() bool {
+	return on.SendTagsAs == opsgenieSendDetails || on.SendTagsAs == opsgenieSendBoth
+}
+
+func (on *OpsgenieNotifier) sendTags() bool {
+	return on.SendTagsAs == opsgenieSendTags || on.SendTagsAs == opsgenieSendBoth
+}
@@ -0,0 +1,163 @@
+package channels
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestOpsgenieNotifier(t *testing.T) {
+	tmpl, err := template.FromGlobs("templates/default.tmpl")
+	require.NoError(t, err)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expURL       string
+		expMsg       string
+		expInitError error
+	}{
+		{
+			name:     "Default config with one alert",
+			settings: `{"apiKey": "abcdefgh0123456789"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expURL: "https://api.opsgenie.com/v2/alerts",
+			expMsg: `{
+				"alias": "6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733",
+				"description": "[FIRING:1]  (val1)\nhttp://localhost/alerting/list\n\n\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n",
+				"details": {
+					"url": "http://localhost/alerting/list"
+				},
+				"message": "[FIRING:1]  (val1)",
+				"source": "Grafana",
+				"tags": ["alertname:alert1", "lbl1:val1"]
+			}`,
+		}, {
+			name: "Labels as details and priority from a label",
+			settings: `{
+				"apiKey": "abcdefgh0123456789",
+				"apiUrl": "http://opsgenie/v2/alerts",
+				"sendTagsAs": "details"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "og_priority": "P1"},
+					},
+				},
+			},
+			expURL: "http://opsgenie/v2/alerts",
+			expMsg: `{
+				"alias": "6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733",
+				"description": "[FIRING:1]  (P1)\nhttp://localhost/alerting/list\n\n\n**Firing**\nLabels:\n - alertname = alert1\n - og_priority = P1\nAnnotations:\nSource: \n\n\n\n\n",
+				"details": {
+					"alertname": "alert1",
+					"og_priority": "P1",
+					"url": "http://localhost/alerting/list"
+				},
+				"message": "[FIRING:1]  (P1)",
+				"priority": "P1",
+				"source": "Grafana",
+				"tags": []
+			}`,
+		}, {
+			name:     "Resolved alerts close the Opsgenie alert",
+			settings: `{"apiKey": "abcdefgh0123456789"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expURL: "https://api.opsgenie.com/v2/alerts/6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733/close?identifierType=alias",
+			expMsg: `{"source": "Grafana"}`,
+		}, {
+			name:     "Resolved alerts without auto close send nothing",
+			settings: `{"apiKey": "abcdefgh0123456789", "autoClose": false}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+		}, {
+			name:         "Error when incorrect settings",
+			settings:     `{}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find api key property in settings"},
+		}, {
+			name:         "Error when invalid sendTagsAs",
+			settings:     `{"apiKey": "abcdefgh0123456789", "sendTagsAs": "labels"}`,
+			expInitError: alerting.ValidationError{Reason: `Invalid value for sendTagsAs: "labels"`},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "opsgenie_testing",
+				Type:     "opsgenie",
+				Settings: settingsJSON,
+			}
+
+			pn, err := NewOpsgenieNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var webhook *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, w *models.SendWebhookSync) error {
+				webhook = w
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := pn.Notify(ctx, c.alerts...)
+			require.True(t, ok)
+			require.NoError(t, err)
+
+			if c.expURL == "" {
+				require.Nil(t, webhook)
+				return
+			}
+			require.NotNil(t, webhook)
+			require.Equal(t, c.expURL, webhook.Url)
+			require.Equal(t, "GenieKey abcdefgh0123456789", webhook.HttpHeader["Authorization"])
+			require.JSONEq(t, c.expMsg, webhook.Body)
+		})
+	}
+}
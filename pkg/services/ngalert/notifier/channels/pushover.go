@@ -0,0 +1,179 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"strconv"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+var (
+	pushoverEndpoint = "https://api.pushover.net/1/messages.json"
+)
+
+// PushoverNotifier is responsible for sending
+// alert notifications to Pushover
+type PushoverNotifier struct {
+	old_notifiers.NotifierBase
+	UserKey          string
+	APIToken         string
+	AlertingPriority int
+	OKPriority       int
+	Retry            int
+	Expire           int
+	Device           string
+	AlertingSound    string
+	OKSound          string
+	Message          string
+	tmpl             *template.Template
+	log              log.Logger
+}
+
+// NewPushoverNotifier is the constructor for the Pushover Notifier
+func NewPushoverNotifier(model *models.AlertNotification, t *template.Template) (*PushoverNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	userKey := model.DecryptedValue("userKey", model.Settings.Get("userKey").MustString())
+	APIToken := model.DecryptedValue("apiToken", model.Settings.Get("apiToken").MustString())
+	device := model.Settings.Get("device").MustString()
+	alertingPriority, err := strconv.Atoi(model.Settings.Get("priority").MustString("0")) // default Normal
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert alerting priority to integer: %w", err)
+	}
+	okPriority, err := strconv.Atoi(model.Settings.Get("okPriority").MustString("0")) // default Normal
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert OK priority to integer: %w", err)
+	}
+	retry, _ := strconv.Atoi(model.Settings.Get("retry").MustString())
+	expire, _ := strconv.Atoi(model.Settings.Get("expire").MustString())
+	alertingSound := model.Settings.Get("sound").MustString()
+	okSound := model.Settings.Get("okSound").MustString()
+
+	if userKey == "" {
+		return nil, alerting.ValidationError{Reason: "User key not given"}
+	}
+	if APIToken == "" {
+		return nil, alerting.ValidationError{Reason: "API token not given"}
+	}
+	return &PushoverNotifier{
+		NotifierBase:     old_notifiers.NewNotifierBase(model),
+		UserKey:          userKey,
+		APIToken:         APIToken,
+		AlertingPriority: alertingPriority,
+		OKPriority:       okPriority,
+		Retry:            retry,
+		Expire:           expire,
+		Device:           device,
+		AlertingSound:    alertingSound,
+		OKSound:          okSound,
+		Message:          model.Settings.Get("message").MustString(`{{ template "default.message" .}}`),
+		tmpl:             t,
+		log:              log.New("alerting.notifier.pushover"),
+	}, nil
+}
+
+// Notify sends an alert notification to Pushover
+func (pn *PushoverNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	headers, uploadBody, err := pn.genPushoverBody(ctx, as...)
+	if err != nil {
+		pn.log.Error("Failed to generate body for pushover", "error", err)
+		return false, err
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:        pushoverEndpoint,
+		HttpMethod: "POST",
+		HttpHeader: headers,
+		Body:       uploadBody.String(),
+	}
+
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		pn.log.Error("Failed to send pushover notification", "error", err, "webhook", pn.Name)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (pn *PushoverNotifier) genPushoverBody(ctx context.Context, as ...*types.Alert) (map[string]string, bytes.Buffer, error) {
+	var b bytes.Buffer
+
+	data := notify.GetTemplateData(ctx, pn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(pn.tmpl, data, &tmplErr)
+
+	w := multipart.NewWriter(&b)
+	fields := [][2]string{
+		{"user", pn.UserKey},
+		{"token", pn.APIToken},
+	}
+
+	// The priority and the sound depend on whether the alerts are firing.
+	priority, sound := pn.AlertingPriority, pn.AlertingSound
+	if types.Alerts(as...).Status() == model.AlertResolved {
+		priority, sound = pn.OKPriority, pn.OKSound
+	}
+	fields = append(fields, [2]string{"priority", strconv.Itoa(priority)})
+	if priority == 2 {
+		fields = append(fields,
+			[2]string{"retry", strconv.Itoa(pn.Retry)},
+			[2]string{"expire", strconv.Itoa(pn.Expire)},
+		)
+	}
+	if pn.Device != "" {
+		fields = append(fields, [2]string{"device", pn.Device})
+	}
+	if sound != "" && sound != "default" {
+		fields = append(fields, [2]string{"sound", sound})
+	}
+
+	message := tmpl(pn.Message)
+	if message == "" {
+		message = "Notification message missing (Set a notification message to replace this text.)"
+	}
+	fields = append(fields,
+		[2]string{"title", tmpl(`{{ template "default.title" . }}`)},
+		[2]string{"url", ruleListURL(pn.tmpl.ExternalURL)},
+		[2]string{"url_title", "Show alert rule"},
+		[2]string{"message", message},
+		// Mark as html message
+		[2]string{"html", "1"},
+	)
+
+	if tmplErr != nil {
+		return nil, b, fmt.Errorf("failed to template pushover message: %w", tmplErr)
+	}
+
+	for _, f := range fields {
+		if err := w.WriteField(f[0], f[1]); err != nil {
+			return nil, b, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, b, err
+	}
+
+	headers := map[string]string{
+		"Content-Type": w.FormDataContentType(),
+	}
+	return headers, b, nil
+}
+
+func (pn *PushoverNotifier) SendResolved() bool {
+	return !pn.GetDisableResolveMessage()
+}
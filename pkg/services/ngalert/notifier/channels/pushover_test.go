@@ -0,0 +1,159 @@
+package channels
+
+import (
+	"context"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/securejsondata"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestPushoverNotifier(t *testing.T) {
+	tmpl, err := template.FromGlobs("templates/default.tmpl")
+	require.NoError(t, err)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name           string
+		settings       string
+		secureSettings map[string]string
+		alerts         []*types.Alert
+		expMsg         map[string]string
+		expInitError   error
+	}{
+		{
+			name:     "Correct config with one alert",
+			settings: `{"userKey": "<userKey>", "apiToken": "<apiToken>"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: map[string]string{
+				"user":      "<userKey>",
+				"token":     "<apiToken>",
+				"priority":  "0",
+				"title":     "[FIRING:1]  (val1)",
+				"url":       "http://localhost/alerting/list",
+				"url_title": "Show alert rule",
+				"message":   "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n",
+				"html":      "1",
+			},
+		}, {
+			name: "Custom config with resolved alerts and secure settings",
+			settings: `{
+				"priority": "2",
+				"okPriority": "-1",
+				"retry": "30",
+				"expire": "86400",
+				"device": "device",
+				"sound": "echo",
+				"okSound": "magic",
+				"message": "{{ len .Alerts.Resolved }} alerts are resolved"
+			}`,
+			secureSettings: map[string]string{
+				"userKey":  "<userKey>",
+				"apiToken": "<apiToken>",
+			},
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expMsg: map[string]string{
+				"user":      "<userKey>",
+				"token":     "<apiToken>",
+				"priority":  "-1",
+				"device":    "device",
+				"sound":     "magic",
+				"title":     "[RESOLVED]  ",
+				"url":       "http://localhost/alerting/list",
+				"url_title": "Show alert rule",
+				"message":   "1 alerts are resolved",
+				"html":      "1",
+			},
+		}, {
+			name:         "Missing user key",
+			settings:     `{"apiToken": "<apiToken>"}`,
+			expInitError: alerting.ValidationError{Reason: "User key not given"},
+		}, {
+			name:         "Missing api token",
+			settings:     `{"userKey": "<userKey>"}`,
+			expInitError: alerting.ValidationError{Reason: "API token not given"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "pushover_testing",
+				Type:     "pushover",
+				Settings: settingsJSON,
+			}
+			if c.secureSettings != nil {
+				m.SecureSettings = securejsondata.GetEncryptedJsonData(c.secureSettings)
+			}
+
+			pn, err := NewPushoverNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var webhook *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, w *models.SendWebhookSync) error {
+				webhook = w
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := pn.Notify(ctx, c.alerts...)
+			require.True(t, ok)
+			require.NoError(t, err)
+
+			require.NotNil(t, webhook)
+			require.Equal(t, "https://api.pushover.net/1/messages.json", webhook.Url)
+
+			_, params, err := mime.ParseMediaType(webhook.HttpHeader["Content-Type"])
+			require.NoError(t, err)
+			r := multipart.NewReader(strings.NewReader(webhook.Body), params["boundary"])
+			form, err := r.ReadForm(1 << 20)
+			require.NoError(t, err)
+
+			fields := map[string]string{}
+			for k, v := range form.Value {
+				fields[k] = v[0]
+			}
+			require.Equal(t, c.expMsg, fields)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+// SensuNotifier is responsible for sending
+// alert notifications to Sensu.
+type SensuNotifier struct {
+	old_notifiers.NotifierBase
+	URL      string
+	Source   string
+	User     string
+	Password string
+	Handler  string
+	Message  string
+	log      log.Logger
+	tmpl     *template.Template
+}
+
+// NewSensuNotifier is the constructor for the Sensu notifier
+func NewSensuNotifier(model *models.AlertNotification, t *template.Template) (*SensuNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	url := model.Settings.Get("url").MustString()
+	if url == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find url property in settings"}
+	}
+
+	return &SensuNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(model),
+		URL:          url,
+		User:         model.Settings.Get("username").MustString(),
+		Source:       model.Settings.Get("source").MustString(),
+		Password:     model.DecryptedValue("password", model.Settings.Get("password").MustString()),
+		Handler:      model.Settings.Get("handler").MustString(),
+		Message:      model.Settings.Get("message").MustString(`{{ template "default.message" .}}`),
+		log:          log.New("alerting.notifier.sensu"),
+		tmpl:         t,
+	}, nil
+}
+
+// Notify sends an alert notification to Sensu
+func (sn *SensuNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	sn.log.Debug("Sending sensu result", "notification", sn.Name)
+
+	key, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	data := notify.GetTemplateData(ctx, sn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(sn.tmpl, data, &tmplErr)
+
+	// Sensu alerts cannot have spaces in them
+	name := strings.ReplaceAll(data.CommonLabels["alertname"], " ", "_")
+	if name == "" {
+		name = "grafana_alert"
+	}
+
+	// Sensu alerts require a source. We set it to the user-specified value (optional),
+	// else we fallback and use the group key of the alerts.
+	source := tmpl(sn.Source)
+	if source == "" {
+		source = "grafana_alert_" + key.Hash()
+	}
+
+	status := 0
+	if types.Alerts(as...).Status() == model.AlertFiring {
+		status = 2
+	}
+
+	bodyJSON := map[string]interface{}{
+		"name":    name,
+		"source":  source,
+		"output":  tmpl(sn.Message),
+		"status":  status,
+		"ruleUrl": ruleListURL(sn.tmpl.ExternalURL),
+	}
+	if sn.Handler != "" {
+		bodyJSON["handler"] = tmpl(sn.Handler)
+	}
+
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template sensu message: %w", tmplErr)
+	}
+
+	body, err := json.Marshal(bodyJSON)
+	if err != nil {
+		return false, err
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:        sn.URL,
+		User:       sn.User,
+		Password:   sn.Password,
+		Body:       string(body),
+		HttpMethod: "POST",
+	}
+
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		sn.log.Error("Failed to send sensu event", "error", err, "sensu", sn.Name)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (sn *SensuNotifier) SendResolved() bool {
+	return !sn.GetDisableResolveMessage()
+}
@@ -0,0 +1,121 @@
+package channels
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestSensuNotifier(t *testing.T) {
+	tmpl, err := template.FromGlobs("templates/default.tmpl")
+	require.NoError(t, err)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expMsg       string
+		expInitError error
+	}{
+		{
+			name:     "Default config with one alert",
+			settings: `{"url": "http://sensu-api.local:4567/results"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert 1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: `{
+				"name": "alert_1",
+				"source": "grafana_alert_6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733",
+				"output": "\n**Firing**\nLabels:\n - alertname = alert 1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n",
+				"status": 2,
+				"ruleUrl": "http://localhost/alerting/list"
+			}`,
+		}, {
+			name: "Custom config with multiple alerts",
+			settings: `{
+				"url": "http://sensu-api.local:4567/results",
+				"source": "grafana",
+				"handler": "{{ .CommonLabels.team }}",
+				"message": "{{ len .Alerts.Firing }} alerts are firing"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "team": "ops", "lbl1": "val1"},
+					},
+				}, {
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert2", "team": "ops", "lbl1": "val2"},
+					},
+				},
+			},
+			expMsg: `{
+				"name": "grafana_alert",
+				"source": "grafana",
+				"output": "2 alerts are firing",
+				"status": 2,
+				"handler": "ops",
+				"ruleUrl": "http://localhost/alerting/list"
+			}`,
+		}, {
+			name:         "Error in initing",
+			settings:     `{}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find url property in settings"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "sensu_testing",
+				Type:     "sensu",
+				Settings: settingsJSON,
+			}
+
+			sn, err := NewSensuNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			body := ""
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				body = webhook.Body
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := sn.Notify(ctx, c.alerts...)
+			require.True(t, ok)
+			require.NoError(t, err)
+
+			require.JSONEq(t, c.expMsg, body)
+		})
+	}
+}
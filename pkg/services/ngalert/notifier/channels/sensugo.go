@@ -0,0 +1,155 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+// SensuGoNotifier is responsible for sending
+// alert notifications to Sensu Go.
+type SensuGoNotifier struct {
+	old_notifiers.NotifierBase
+	URL       string
+	Entity    string
+	Check     string
+	Namespace string
+	Handler   string
+	APIKey    string
+	Message   string
+	log       log.Logger
+	tmpl      *template.Template
+}
+
+// NewSensuGoNotifier is the constructor for the Sensu Go notifier
+func NewSensuGoNotifier(model *models.AlertNotification, t *template.Template) (*SensuGoNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	url := model.Settings.Get("url").MustString()
+	apikey := model.DecryptedValue("apikey", model.Settings.Get("apikey").MustString())
+
+	if url == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find URL property in settings"}
+	}
+	if apikey == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find the API Key property in settings"}
+	}
+
+	return &SensuGoNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(model),
+		URL:          url,
+		Entity:       model.Settings.Get("entity").MustString(),
+		Check:        model.Settings.Get("check").MustString(),
+		Namespace:    model.Settings.Get("namespace").MustString(),
+		Handler:      model.Settings.Get("handler").MustString(),
+		APIKey:       apikey,
+		Message:      model.Settings.Get("message").MustString(`{{ template "default.message" .}}`),
+		log:          log.New("alerting.notifier.sensugo"),
+		tmpl:         t,
+	}, nil
+}
+
+// Notify sends an alert notification to Sensu Go
+func (sn *SensuGoNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	sn.log.Debug("Sending Sensu Go result", "notification", sn.Name)
+
+	data := notify.GetTemplateData(ctx, sn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(sn.tmpl, data, &tmplErr)
+
+	// Sensu Go alerts require an entity and a check. We set it to the user-specified
+	// value (optional), else we fallback to the default ones.
+	entity := tmpl(sn.Entity)
+	if entity == "" {
+		entity = "default"
+	}
+	check := tmpl(sn.Check)
+	if check == "" {
+		check = "default"
+	}
+	// Sensu Go requires the entity in an event specify its namespace. We set it to
+	// the user-specified value (optional), else we fallback and use default
+	namespace := tmpl(sn.Namespace)
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	status := 0
+	if types.Alerts(as...).Status() == model.AlertFiring {
+		status = 2
+	}
+
+	var handlers []string
+	if sn.Handler != "" {
+		handlers = []string{tmpl(sn.Handler)}
+	}
+
+	ruleURL := ruleListURL(sn.tmpl.ExternalURL)
+	bodyJSON := map[string]interface{}{
+		"entity": map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      entity,
+				"namespace": namespace,
+			},
+		},
+		"check": map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": check,
+				"labels": map[string]string{
+					"ruleURL": ruleURL,
+				},
+			},
+			"output": tmpl(sn.Message),
+			"issued": timeNow().Unix(),
+			// Sensu Go requires that the check portion of the event have an interval
+			"interval": 86400,
+			"status":   status,
+			"handlers": handlers,
+		},
+		"ruleUrl": ruleURL,
+	}
+
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template sensugo message: %w", tmplErr)
+	}
+
+	body, err := json.Marshal(bodyJSON)
+	if err != nil {
+		return false, err
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:        fmt.Sprintf("%s/api/core/v2/namespaces/%s/events", strings.TrimSuffix(sn.URL, "/"), namespace),
+		Body:       string(body),
+		HttpMethod: "POST",
+		HttpHeader: map[string]string{
+			"Content-Type":  "application/json",
+			"Authorization": fmt.Sprintf("Key %s", sn.APIKey),
+		},
+	}
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		sn.log.Error("Failed to send Sensu Go event", "error", err, "sensugo", sn.Name)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (sn *SensuGoNotifier) SendResolved() bool {
+	return !sn.GetDisableResolveMessage()
+}
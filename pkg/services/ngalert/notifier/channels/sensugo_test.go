@@ -0,0 +1,164 @@
+package channels
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestSensuGoNotifier(t *testing.T) {
+	tmpl, err := template.FromGlobs("templates/default.tmpl")
+	require.NoError(t, err)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	timeNow = func() time.Time { return time.Unix(1600000000, 0) }
+	t.Cleanup(func() { timeNow = time.Now })
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expURL       string
+		expMsg       string
+		expInitError error
+	}{
+		{
+			name:     "Default config with one alert",
+			settings: `{"url": "http://sensu-api.local:8080", "apikey": "<apikey>"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expURL: "http://sensu-api.local:8080/api/core/v2/namespaces/default/events",
+			expMsg: `{
+				"entity": {
+					"metadata": {
+						"name": "default",
+						"namespace": "default"
+					}
+				},
+				"check": {
+					"metadata": {
+						"name": "default",
+						"labels": {
+							"ruleURL": "http://localhost/alerting/list"
+						}
+					},
+					"output": "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n",
+					"issued": 1600000000,
+					"interval": 86400,
+					"status": 2,
+					"handlers": null
+				},
+				"ruleUrl": "http://localhost/alerting/list"
+			}`,
+		}, {
+			name: "Custom config with resolved alerts",
+			settings: `{
+				"url": "http://sensu-api.local:8080/",
+				"apikey": "<apikey>",
+				"entity": "grafana_instance_01",
+				"check": "grafana_rule_0",
+				"namespace": "namespace",
+				"handler": "myhandler",
+				"message": "{{ len .Alerts.Resolved }} alerts are resolved"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+						EndsAt: time.Unix(1500000000, 0),
+					},
+				},
+			},
+			expURL: "http://sensu-api.local:8080/api/core/v2/namespaces/namespace/events",
+			expMsg: `{
+				"entity": {
+					"metadata": {
+						"name": "grafana_instance_01",
+						"namespace": "namespace"
+					}
+				},
+				"check": {
+					"metadata": {
+						"name": "grafana_rule_0",
+						"labels": {
+							"ruleURL": "http://localhost/alerting/list"
+						}
+					},
+					"output": "1 alerts are resolved",
+					"issued": 1600000000,
+					"interval": 86400,
+					"status": 0,
+					"handlers": ["myhandler"]
+				},
+				"ruleUrl": "http://localhost/alerting/list"
+			}`,
+		}, {
+			name:         "Error on empty url",
+			settings:     `{"apikey": "<apikey>"}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find URL property in settings"},
+		}, {
+			name:         "Error on empty apikey",
+			settings:     `{"url": "http://sensu-api.local:8080"}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find the API Key property in settings"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "Sensu Go",
+				Type:     "sensugo",
+				Settings: settingsJSON,
+			}
+
+			sn, err := NewSensuGoNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var webhook *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, w *models.SendWebhookSync) error {
+				webhook = w
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := sn.Notify(ctx, c.alerts...)
+			require.True(t, ok)
+			require.NoError(t, err)
+
+			require.NotNil(t, webhook)
+			require.Equal(t, c.expURL, webhook.Url)
+			require.Equal(t, "Key <apikey>", webhook.HttpHeader["Authorization"])
+			require.JSONEq(t, c.expMsg, webhook.Body)
+		})
+	}
+}
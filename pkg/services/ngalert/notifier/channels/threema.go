@@ -0,0 +1,127 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+var (
+	threemaGwBaseURL = "https://msgapi.threema.ch/send_simple"
+)
+
+// ThreemaNotifier is responsible for sending
+// alert notifications to Threema.
+type ThreemaNotifier struct {
+	old_notifiers.NotifierBase
+	GatewayID   string
+	RecipientID string
+	APISecret   string
+	log         log.Logger
+	tmpl        *template.Template
+}
+
+// NewThreemaNotifier is the constructor for the Threema notifier
+func NewThreemaNotifier(model *models.AlertNotification, t *template.Template) (*ThreemaNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	gatewayID := model.Settings.Get("gateway_id").MustString()
+	recipientID := model.Settings.Get("recipient_id").MustString()
+	apiSecret := model.DecryptedValue("api_secret", model.Settings.Get("api_secret").MustString())
+
+	// Validation
+	if gatewayID == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find Threema Gateway ID in settings"}
+	}
+	if !strings.HasPrefix(gatewayID, "*") {
+		return nil, alerting.ValidationError{Reason: "Invalid Threema Gateway ID: Must start with a *"}
+	}
+	if len(gatewayID) != 8 {
+		return nil, alerting.ValidationError{Reason: "Invalid Threema Gateway ID: Must be 8 characters long"}
+	}
+	if recipientID == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find Threema Recipient ID in settings"}
+	}
+	if len(recipientID) != 8 {
+		return nil, alerting.ValidationError{Reason: "Invalid Threema Recipient ID: Must be 8 characters long"}
+	}
+	if apiSecret == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find Threema API secret in settings"}
+	}
+
+	return &ThreemaNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(model),
+		GatewayID:    gatewayID,
+		RecipientID:  recipientID,
+		APISecret:    apiSecret,
+		log:          log.New("alerting.notifier.threema"),
+		tmpl:         t,
+	}, nil
+}
+
+// Notify sends an alert notification to Threema
+func (tn *ThreemaNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	tn.log.Debug("Sending threema alert notification", "from", tn.GatewayID, "to", tn.RecipientID)
+
+	data := notify.GetTemplateData(ctx, tn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(tn.tmpl, data, &tmplErr)
+
+	// Set up basic API request data
+	form := url.Values{}
+	form.Set("from", tn.GatewayID)
+	form.Set("to", tn.RecipientID)
+	form.Set("secret", tn.APISecret)
+
+	// Determine emoji
+	stateEmoji := "⚠️ " // Warning sign
+	if types.Alerts(as...).Status() == model.AlertResolved {
+		stateEmoji = "✅ " // Check Mark Button
+	}
+
+	// Build message
+	message := fmt.Sprintf("%s%s\n\n*Message:*\n%s\n*URL:* %s\n",
+		stateEmoji,
+		tmpl(`{{ template "default.title" . }}`),
+		tmpl(`{{ template "default.message" . }}`),
+		ruleListURL(tn.tmpl.ExternalURL),
+	)
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template Threema message: %w", tmplErr)
+	}
+	form.Set("text", message)
+
+	cmd := &models.SendWebhookSync{
+		Url:        threemaGwBaseURL,
+		Body:       form.Encode(),
+		HttpMethod: "POST",
+		HttpHeader: map[string]string{
+			"Content-Type": "application/x-www-form-urlencoded",
+		},
+	}
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		tn.log.Error("Failed to send threema notification", "error", err, "webhook", tn.Name)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (tn *ThreemaNotifier) SendResolved() bool {
+	return !tn.GetDisableResolveMessage()
+}
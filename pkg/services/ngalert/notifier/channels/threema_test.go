@@ -0,0 +1,119 @@
+package channels
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestThreemaNotifier(t *testing.T) {
+	tmpl, err := template.FromGlobs("templates/default.tmpl")
+	require.NoError(t, err)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expMsg       url.Values
+		expInitError error
+	}{
+		{
+			name:     "A single alert",
+			settings: `{"gateway_id": "*1234567", "recipient_id": "87654321", "api_secret": "supersecret"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: url.Values{
+				"from":   {"*1234567"},
+				"to":     {"87654321"},
+				"secret": {"supersecret"},
+				"text":   {"⚠️ [FIRING:1]  (val1)\n\n*Message:*\n\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n\n*URL:* http://localhost/alerting/list\n"},
+			},
+		}, {
+			name:     "Resolved alerts",
+			settings: `{"gateway_id": "*1234567", "recipient_id": "87654321", "api_secret": "supersecret"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expMsg: url.Values{
+				"from":   {"*1234567"},
+				"to":     {"87654321"},
+				"secret": {"supersecret"},
+				"text":   {"✅ [RESOLVED]  \n\n*Message:*\n\n\n**Resolved**\nLabels:\n - alertname = alert1\nAnnotations:\nSource: \n\n\n\n*URL:* http://localhost/alerting/list\n"},
+			},
+		}, {
+			name:         "Invalid gateway id",
+			settings:     `{"gateway_id": "12345678", "recipient_id": "87654321", "api_secret": "supersecret"}`,
+			expInitError: alerting.ValidationError{Reason: "Invalid Threema Gateway ID: Must start with a *"},
+		}, {
+			name:         "Invalid receipent id",
+			settings:     `{"gateway_id": "*1234567", "recipient_id": "8765432", "api_secret": "supersecret"}`,
+			expInitError: alerting.ValidationError{Reason: "Invalid Threema Recipient ID: Must be 8 characters long"},
+		}, {
+			name:         "No API secret",
+			settings:     `{"gateway_id": "*1234567", "recipient_id": "87654321"}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find Threema API secret in settings"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "threema_testing",
+				Type:     "threema",
+				Settings: settingsJSON,
+			}
+
+			tn, err := NewThreemaNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			body := ""
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				body = webhook.Body
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := tn.Notify(ctx, c.alerts...)
+			require.True(t, ok)
+			require.NoError(t, err)
+
+			require.Equal(t, c.expMsg.Encode(), body)
+		})
+	}
+}
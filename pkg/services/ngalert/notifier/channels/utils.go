@@ -2,7 +2,10 @@ package channels
 
 import (
 	"fmt"
+	"net/url"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/common/model"
@@ -14,6 +17,9 @@ const (
 	ColorAlertResolved = "#36a64f"
 )
 
+// timeNow makes it possible to test usage of time
+var timeNow = time.Now
+
 func getAlertStatusColor(status model.AlertStatus) string {
 	if status == model.AlertFiring {
 		return ColorAlertFiring
@@ -32,3 +38,27 @@ func getTitleFromTemplateData(data *template.Data) string {
 	}
 	return title
 }
+
+// joinURLPath returns the URL of p relative to the base URL, such as a Grafana page under the external URL.
+func joinURLPath(base *url.URL, p string) string {
+	u := *base
+	u.Path = path.Join(u.Path, p)
+	return u.String()
+}
+
+// ruleListURL returns the URL of the alert rule list of Grafana.
+func ruleListURL(externalURL *url.URL) string {
+	return joinURLPath(externalURL, "/alerting/list")
+}
+
+// truncate cuts s to at most n runes, ending it with an ellipsis when it was cut.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(r[:n])
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,134 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// victoropsAlertStateCritical - VictorOps "CRITICAL" message type
+	victoropsAlertStateCritical = "CRITICAL"
+	// victoropsAlertStateRecovery - VictorOps "RECOVERY" message type
+	victoropsAlertStateRecovery = "RECOVERY"
+)
+
+// VictoropsNotifier defines URL property for Victorops REST API
+// and handles notification process by formatting POST body according to
+// Victorops specifications (http://victorops.force.com/knowledgebase/articles/Integration/Alert-Ingestion-API-Documentation/)
+type VictoropsNotifier struct {
+	old_notifiers.NotifierBase
+	URL         string
+	MessageType string
+	AutoResolve bool
+	log         log.Logger
+	tmpl        *template.Template
+}
+
+// NewVictoropsNotifier creates an instance of VictoropsNotifier that
+// handles posting notifications to Victorops REST API
+func NewVictoropsNotifier(model *models.AlertNotification, t *template.Template) (*VictoropsNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	url := model.Settings.Get("url").MustString()
+	if url == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find victorops url property in settings"}
+	}
+
+	return &VictoropsNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(model),
+		URL:          url,
+		MessageType:  model.Settings.Get("messageType").MustString(victoropsAlertStateCritical),
+		AutoResolve:  model.Settings.Get("autoResolve").MustBool(true),
+		log:          log.New("alerting.notifier.victorops"),
+		tmpl:         t,
+	}, nil
+}
+
+// Notify sends notification to Victorops via POST to URL endpoint
+func (vn *VictoropsNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	vn.log.Debug("Executing victorops notification", "notification", vn.Name)
+
+	alerts := types.Alerts(as...)
+	if alerts.Status() == model.AlertResolved && !vn.AutoResolve {
+		vn.log.Debug("Not alerting VictorOps", "status", alerts.Status(), "auto resolve", vn.AutoResolve)
+		return true, nil
+	}
+
+	msg, err := vn.buildVictoropsMessage(ctx, alerts, as)
+	if err != nil {
+		return false, fmt.Errorf("build victorops message: %w", err)
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("marshal json: %w", err)
+	}
+
+	cmd := &models.SendWebhookSync{Url: vn.URL, Body: string(body)}
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return false, fmt.Errorf("send notification to Victorops: %w", err)
+	}
+
+	return true, nil
+}
+
+func (vn *VictoropsNotifier) buildVictoropsMessage(ctx context.Context, alerts model.Alerts, as []*types.Alert) (map[string]interface{}, error) {
+	key, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data := notify.GetTemplateData(ctx, vn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(vn.tmpl, data, &tmplErr)
+
+	// Default to the configured message type, which a valid severity label overrides.
+	messageType := strings.ToUpper(tmpl(vn.MessageType))
+	switch sev := strings.ToUpper(data.CommonLabels["severity"]); sev {
+	case "":
+	case "INFO", "WARNING", "CRITICAL":
+		messageType = sev
+	default:
+		vn.log.Warn("Ignoring invalid severity label", "severity", sev)
+	}
+	if alerts.Status() == model.AlertResolved {
+		messageType = victoropsAlertStateRecovery
+	}
+
+	msg := map[string]interface{}{
+		"message_type":        messageType,
+		"entity_id":           key.Hash(),
+		"entity_display_name": tmpl(`{{ template "default.title" . }}`),
+		"timestamp":           timeNow().Unix(),
+		"state_message":       tmpl(`{{ template "default.message" . }}`),
+		"monitoring_tool":     "Grafana v" + setting.BuildVersion,
+		"alert_url":           ruleListURL(vn.tmpl.ExternalURL),
+	}
+
+	if tmplErr != nil {
+		return nil, fmt.Errorf("failed to template VictorOps message: %w", tmplErr)
+	}
+
+	return msg, nil
+}
+
+func (vn *VictoropsNotifier) SendResolved() bool {
+	return !vn.GetDisableResolveMessage()
+}
@@ -0,0 +1,154 @@
+package channels
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestVictoropsNotifier(t *testing.T) {
+	tmpl, err := template.FromGlobs("templates/default.tmpl")
+	require.NoError(t, err)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	timeNow = func() time.Time { return time.Unix(1600000000, 0) }
+	t.Cleanup(func() { timeNow = time.Now })
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expMsg       string
+		expInitError error
+	}{
+		{
+			name:     "Default config with one alert",
+			settings: `{"url": "http://localhost"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: `{
+				"alert_url": "http://localhost/alerting/list",
+				"entity_display_name": "[FIRING:1]  (val1)",
+				"entity_id": "6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733",
+				"message_type": "CRITICAL",
+				"monitoring_tool": "Grafana v",
+				"state_message": "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n",
+				"timestamp": 1600000000
+			}`,
+		}, {
+			name:     "Severity label overrides the message type",
+			settings: `{"url": "http://localhost", "messageType": "warning"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "severity": "info"},
+					},
+				},
+			},
+			expMsg: `{
+				"alert_url": "http://localhost/alerting/list",
+				"entity_display_name": "[FIRING:1]  (info)",
+				"entity_id": "6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733",
+				"message_type": "INFO",
+				"monitoring_tool": "Grafana v",
+				"state_message": "\n**Firing**\nLabels:\n - alertname = alert1\n - severity = info\nAnnotations:\nSource: \n\n\n\n\n",
+				"timestamp": 1600000000
+			}`,
+		}, {
+			name:     "Resolved alerts recover the incident",
+			settings: `{"url": "http://localhost"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+						EndsAt: time.Unix(1500000000, 0),
+					},
+				},
+			},
+			expMsg: `{
+				"alert_url": "http://localhost/alerting/list",
+				"entity_display_name": "[RESOLVED]  ",
+				"entity_id": "6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733",
+				"message_type": "RECOVERY",
+				"monitoring_tool": "Grafana v",
+				"state_message": "\n\n**Resolved**\nLabels:\n - alertname = alert1\nAnnotations:\nSource: \n\n\n",
+				"timestamp": 1600000000
+			}`,
+		}, {
+			name:     "Resolved alerts without auto resolve send nothing",
+			settings: `{"url": "http://localhost", "autoResolve": false}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+						EndsAt: time.Unix(1500000000, 0),
+					},
+				},
+			},
+		}, {
+			name:         "Error in initing",
+			settings:     `{}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find victorops url property in settings"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "victorops_testing",
+				Type:     "victorops",
+				Settings: settingsJSON,
+			}
+
+			pn, err := NewVictoropsNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			body := ""
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				body = webhook.Body
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := pn.Notify(ctx, c.alerts...)
+			require.True(t, ok)
+			require.NoError(t, err)
+
+			if c.expMsg == "" {
+				require.Empty(t, body)
+				return
+			}
+			require.JSONEq(t, c.expMsg, body)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/cluster"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ClusterPeer shares the silences and the notification log of the Alertmanagers with the
+// other Grafana replicas, so that they don't send duplicate notifications.
+type ClusterPeer interface {
+	// AddState registers a state to share with the other replicas. The returned channel
+	// broadcasts the changes of the state.
+	AddState(key string, state cluster.State, reg prometheus.Registerer) cluster.ClusterChannel
+	// Position returns the position of the replica in the cluster. The replica at position n
+	// waits for n peer timeouts before it notifies, giving the replicas before it a chance to.
+	Position() int
+	// WaitReady waits until the replica has received the state of the other replicas.
+	WaitReady(ctx context.Context) error
+}
+
+// runnablePeer is a ClusterPeer that syncs with the other replicas in the background.
+type runnablePeer interface {
+	ClusterPeer
+	Run(ctx context.Context)
+}
+
+// NilPeer is the ClusterPeer of a Grafana instance that does not run in a cluster.
+type NilPeer struct{}
+
+func (NilPeer) AddState(string, cluster.State, prometheus.Registerer) cluster.ClusterChannel {
+	return NilChannel{}
+}
+
+func (NilPeer) Position() int                   { return 0 }
+func (NilPeer) WaitReady(context.Context) error { return nil }
+
+// NilChannel is the ClusterChannel of a NilPeer, which broadcasts nothing.
+type NilChannel struct{}
+
+func (NilChannel) Broadcast([]byte) {}
+
+// gossipPeer is a member of a gossip cluster.
+type gossipPeer struct {
+	*cluster.Peer
+	gossipInterval time.Duration
+	logger         log.Logger
+}
+
+// newGossipPeer creates a peer that gossips with the replicas configured in cfg and joins the cluster.
+func newGossipPeer(cfg setting.UnifiedAlertingSettings, reg prometheus.Registerer, logger log.Logger) (*gossipPeer, error) {
+	peer, err := cluster.Create(
+		newGoKitLogger(logger),
+		reg,
+		cfg.HAListenAddr,
+		cfg.HAAdvertiseAddr,
+		cfg.HAPeers,
+		true,
+		cfg.HAPushPullInterval,
+		cfg.HAGossipInterval,
+		cluster.DefaultTcpTimeout,
+		cluster.DefaultProbeTimeout,
+		cluster.DefaultProbeInterval,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize gossip mesh: %w", err)
+	}
+
+	if err := peer.Join(cluster.DefaultReconnectInterval, cluster.DefaultReconnectTimeout); err != nil {
+		logger.Error("unable to join gossip mesh while initializing cluster for high availability mode", "err", err)
+	}
+
+	return &gossipPeer{Peer: peer, gossipInterval: cfg.HAGossipInterval, logger: logger}, nil
+}
+
+// Run waits for the gossip to settle, and leaves the cluster once ctx is done.
+func (p *gossipPeer) Run(ctx context.Context) {
+	p.Settle(ctx, p.gossipInterval*10)
+	<-ctx.Done()
+	if err := p.Leave(10 * time.Second); err != nil {
+		p.logger.Warn("unable to leave the gossip mesh", "err", err)
+	}
+}
+
+// goKitLogger adapts a Grafana logger to the go-kit logger of the Alertmanager components.
+type goKitLogger struct {
+	logger log.Logger
+}
+
+func newGoKitLogger(logger log.Logger) *goKitLogger {
+	return &goKitLogger{logger: logger}
+}
+
+func (l *goKitLogger) Log(keyvals ...interface{}) error {
+	var lvl, msg string
+	ctx := make([]interface{}, 0, len(keyvals))
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		switch fmt.Sprint(keyvals[i]) {
+		case "level":
+			lvl = fmt.Sprint(keyvals[i+1])
+		case "msg":
+			msg = fmt.Sprint(keyvals[i+1])
+		default:
+			ctx = append(ctx, keyvals[i], keyvals[i+1])
+		}
+	}
+
+	switch lvl {
+	case "debug":
+		l.logger.Debug(msg, ctx...)
+	case "warn":
+		l.logger.Warn(msg, ctx...)
+	case "error":
+		l.logger.Error(msg, ctx...)
+	default:
+		l.logger.Info(msg, ctx...)
+	}
+	return nil
+}
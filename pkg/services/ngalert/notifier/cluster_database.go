@@ -0,0 +1,207 @@
+package notifier
+
+import (
+	"context"
+	"crypto/md5"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/cluster"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// peerLivenessSyncs is the number of sync intervals after which a replica that has not
+// synced is no longer considered part of the cluster.
+const peerLivenessSyncs = 3
+
+// dbPeer shares the state of the Alertmanagers with the other replicas through the database,
+// for environments where the gossip ports can't be opened between replicas. Every replica saves
+// its full states every sync interval, or as soon as they change, and merges the states the
+// other replicas saved since the last sync.
+type dbPeer struct {
+	id           string
+	store        store.ClusterStateStore
+	syncInterval time.Duration
+	logger       log.Logger
+
+	mtx      sync.RWMutex
+	states   map[string]cluster.State
+	position int
+
+	// pushed holds the checksum of the last saved state of every key.
+	pushed map[string][16]byte
+	// pulled holds the keys whose states of the other replicas have been merged.
+	pulled   map[string]bool
+	lastPull time.Time
+
+	syncc     chan struct{}
+	readyc    chan struct{}
+	readyOnce sync.Once
+}
+
+func newDBPeer(store store.ClusterStateStore, syncInterval time.Duration, logger log.Logger) *dbPeer {
+	return &dbPeer{
+		id:           util.GenerateShortUID(),
+		store:        store,
+		syncInterval: syncInterval,
+		logger:       logger,
+		states:       map[string]cluster.State{},
+		pushed:       map[string][16]byte{},
+		pulled:       map[string]bool{},
+		syncc:        make(chan struct{}, 1),
+		readyc:       make(chan struct{}),
+	}
+}
+
+func (p *dbPeer) AddState(key string, state cluster.State, _ prometheus.Registerer) cluster.ClusterChannel {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.states[key] = state
+	return &dbChannel{peer: p}
+}
+
+func (p *dbPeer) Position() int {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	return p.position
+}
+
+func (p *dbPeer) WaitReady(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.readyc:
+		return nil
+	}
+}
+
+// Run syncs the states with the database every sync interval and whenever a state changes.
+func (p *dbPeer) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.sync(time.Now()); err != nil {
+			p.logger.Error("unable to sync the Alertmanager state through the database", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.syncc:
+		}
+	}
+}
+
+func (p *dbPeer) sync(now time.Time) error {
+	p.mtx.RLock()
+	states := make(map[string]cluster.State, len(p.states))
+	for key, state := range p.states {
+		states[key] = state
+	}
+	p.mtx.RUnlock()
+
+	for key, state := range states {
+		b, err := state.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		sum := md5.Sum(b)
+		if sum == p.pushed[key] {
+			continue
+		}
+		cmd := &ngmodels.SaveAlertmanagerClusterStateCmd{PeerID: p.id, StateKey: key, State: b, Now: now}
+		if err := p.store.SaveAlertmanagerClusterState(cmd); err != nil {
+			return err
+		}
+		p.pushed[key] = sum
+	}
+
+	heartbeat := &ngmodels.HeartbeatAlertmanagerClusterPeerCmd{
+		PeerID:       p.id,
+		Now:          now,
+		ExpireBefore: now.Add(-retentionNotificationsAndSilences),
+	}
+	if err := p.store.HeartbeatAlertmanagerClusterPeer(heartbeat); err != nil {
+		return err
+	}
+
+	// The states of keys added since the last sync, such as the ones of a new organization, are
+	// merged in full. Otherwise the window overlaps the previous one by a sync interval, to
+	// tolerate clock drift between replicas; merging a state twice has no effect.
+	updatedSince := time.Unix(0, 0)
+	if !p.lastPull.IsZero() && p.allPulled(states) {
+		updatedSince = p.lastPull.Add(-p.syncInterval)
+	}
+
+	q := &ngmodels.GetAlertmanagerClusterStatesQuery{UpdatedSince: updatedSince}
+	if err := p.store.GetAlertmanagerClusterStates(q); err != nil {
+		return err
+	}
+
+	for _, s := range q.Result {
+		if s.PeerID == p.id {
+			continue
+		}
+		state, ok := states[s.StateKey]
+		if !ok {
+			continue
+		}
+		if err := state.Merge(s.State); err != nil {
+			p.logger.Warn("unable to merge the Alertmanager state of a replica", "peer", s.PeerID, "key", s.StateKey, "err", err)
+		}
+	}
+
+	peersQuery := &ngmodels.GetAlertmanagerClusterPeersQuery{AliveSince: now.Add(-peerLivenessSyncs * p.syncInterval)}
+	if err := p.store.GetAlertmanagerClusterPeers(peersQuery); err != nil {
+		return err
+	}
+	peers := []string{p.id}
+	for _, id := range peersQuery.Result {
+		if id != p.id {
+			peers = append(peers, id)
+		}
+	}
+	sort.Strings(peers)
+
+	p.mtx.Lock()
+	p.position = sort.SearchStrings(peers, p.id)
+	p.mtx.Unlock()
+
+	for key := range states {
+		p.pulled[key] = true
+	}
+	p.lastPull = now
+	p.readyOnce.Do(func() { close(p.readyc) })
+
+	return nil
+}
+
+func (p *dbPeer) allPulled(states map[string]cluster.State) bool {
+	for key := range states {
+		if !p.pulled[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// dbChannel requests a sync of its dbPeer when the state changes.
+type dbChannel struct {
+	peer *dbPeer
+}
+
+// Broadcast requests a sync, which saves the full state rather than the change in b.
+func (c *dbChannel) Broadcast([]byte) {
+	select {
+	case c.peer.syncc <- struct{}{}:
+	default:
+	}
+}
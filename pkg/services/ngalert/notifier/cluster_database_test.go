@@ -0,0 +1,138 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/silence"
+	"github.com/prometheus/alertmanager/silence/silencepb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestDBPeer(t *testing.T) {
+	store := &fakeClusterStateStore{}
+	logger := log.New("testlogger")
+
+	newPeerWithSilences := func(id string) (*dbPeer, *silence.Silences) {
+		peer := newDBPeer(store, time.Second, logger)
+		peer.id = id
+		silences, err := silence.New(silence.Options{Retention: time.Hour})
+		require.NoError(t, err)
+		c := peer.AddState("silences:1", silences, nil)
+		silences.SetBroadcast(c.Broadcast)
+		return peer, silences
+	}
+
+	peerA, silencesA := newPeerWithSilences("a")
+	peerB, silencesB := newPeerWithSilences("b")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	require.Error(t, peerA.WaitReady(ctx), "the peer is not ready before it synced")
+
+	now := time.Now()
+	require.NoError(t, peerA.sync(now))
+	require.NoError(t, peerB.sync(now))
+	require.NoError(t, peerA.WaitReady(context.Background()))
+	require.Equal(t, 0, peerA.Position())
+	require.Equal(t, 1, peerB.Position())
+
+	t.Run("a silence created on one replica is merged by the others", func(t *testing.T) {
+		id, err := silencesA.Set(&silencepb.Silence{
+			Matchers: []*silencepb.Matcher{{Name: "alertname", Pattern: "TestAlert"}},
+			StartsAt: now,
+			EndsAt:   now.Add(time.Hour),
+		})
+		require.NoError(t, err)
+		select {
+		case <-peerA.syncc:
+		default:
+			require.Fail(t, "creating a silence should request a sync")
+		}
+
+		now = now.Add(time.Second)
+		require.NoError(t, peerA.sync(now))
+		require.NoError(t, peerB.sync(now))
+
+		sils, _, err := silencesB.Query(silence.QIDs(id))
+		require.NoError(t, err)
+		require.Len(t, sils, 1)
+	})
+
+	t.Run("a replica that stopped syncing leaves the cluster", func(t *testing.T) {
+		now = now.Add(peerLivenessSyncs * time.Second * 2)
+		require.NoError(t, peerB.sync(now))
+		require.Equal(t, 0, peerB.Position())
+	})
+}
+
+type fakeClusterStateStore struct {
+	mtx    sync.Mutex
+	states []*models.AlertClusterState
+}
+
+func (f *fakeClusterStateStore) SaveAlertmanagerClusterState(cmd *models.SaveAlertmanagerClusterStateCmd) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, s := range f.states {
+		if s.PeerID == cmd.PeerID && s.StateKey == cmd.StateKey {
+			s.State = cmd.State
+			s.UpdatedAt = cmd.Now.UnixNano()
+			s.HeartbeatAt = cmd.Now.UnixNano()
+			return nil
+		}
+	}
+	f.states = append(f.states, &models.AlertClusterState{
+		PeerID:      cmd.PeerID,
+		StateKey:    cmd.StateKey,
+		State:       cmd.State,
+		UpdatedAt:   cmd.Now.UnixNano(),
+		HeartbeatAt: cmd.Now.UnixNano(),
+	})
+	return nil
+}
+
+func (f *fakeClusterStateStore) HeartbeatAlertmanagerClusterPeer(cmd *models.HeartbeatAlertmanagerClusterPeerCmd) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	states := f.states[:0]
+	for _, s := range f.states {
+		if s.PeerID == cmd.PeerID {
+			s.HeartbeatAt = cmd.Now.UnixNano()
+		}
+		if s.HeartbeatAt >= cmd.ExpireBefore.UnixNano() {
+			states = append(states, s)
+		}
+	}
+	f.states = states
+	return nil
+}
+
+func (f *fakeClusterStateStore) GetAlertmanagerClusterStates(query *models.GetAlertmanagerClusterStatesQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, s := range f.states {
+		if s.UpdatedAt >= query.UpdatedSince.UnixNano() {
+			query.Result = append(query.Result, s)
+		}
+	}
+	return nil
+}
+
+func (f *fakeClusterStateStore) GetAlertmanagerClusterPeers(query *models.GetAlertmanagerClusterPeersQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	seen := map[string]bool{}
+	for _, s := range f.states {
+		if s.HeartbeatAt >= query.AliveSince.UnixNano() && !seen[s.PeerID] {
+			seen[s.PeerID] = true
+			query.Result = append(query.Result, s.PeerID)
+		}
+	}
+	return nil
+}
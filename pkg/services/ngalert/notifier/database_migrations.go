@@ -14,4 +14,34 @@ func alertmanagerConfigurationMigration(mg *migrator.Migrator) {
 	}
 
 	mg.AddMigration("create_alert_configuration_table", migrator.NewAddTableMigration(alertConfiguration))
+
+	// Every organization has its own Alertmanager. A configuration saved before that belongs to the main organization.
+	mg.AddMigration("add column org_id in alert_configuration", migrator.NewAddColumnMigration(alertConfiguration, &migrator.Column{
+		Name: "org_id", Type: migrator.DB_BigInt, Nullable: false, Default: "1",
+	}))
+	mg.AddMigration("add index in alert_configuration table on org_id column", migrator.NewAddIndexMigration(alertConfiguration, &migrator.Index{
+		Cols: []string{"org_id"},
+	}))
+}
+
+func alertmanagerClusterStateMigration(mg *migrator.Migrator) {
+	clusterState := migrator.Table{
+		Name: "alert_cluster_state",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "peer_id", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "state_key", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "state", Type: migrator.DB_LongBlob, Nullable: false},
+			{Name: "updated_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "heartbeat_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"peer_id", "state_key"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"updated_at"}},
+		},
+	}
+
+	mg.AddMigration("create alert_cluster_state table", migrator.NewAddTableMigration(clusterState))
+	mg.AddMigration("add unique index alert_cluster_state peer_id state_key", migrator.NewAddIndexMigration(clusterState, clusterState.Indices[0]))
+	mg.AddMigration("add index alert_cluster_state updated_at", migrator.NewAddIndexMigration(clusterState, clusterState.Indices[1]))
 }
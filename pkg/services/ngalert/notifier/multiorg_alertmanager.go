@@ -0,0 +1,202 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/registry"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// pollInterval is how often the organizations and their configurations are synced from the database.
+const pollInterval = 1 * time.Minute
+
+var (
+	// ErrNoAlertmanagerForOrg is returned when there is no Alertmanager for an organization.
+	ErrNoAlertmanagerForOrg = fmt.Errorf("Alertmanager does not exist for this organization")
+)
+
+// MultiOrgAlertmanager runs an Alertmanager for every organization, so that each organization
+// has its own configuration, silences and notification log.
+type MultiOrgAlertmanager struct {
+	Settings *setting.Cfg       `inject:""`
+	SQLStore *sqlstore.SQLStore `inject:""`
+	Store    store.AlertingStore
+
+	logger log.Logger
+	// peer shares the state of the Alertmanagers with the other replicas.
+	peer ClusterPeer
+
+	alertmanagersMtx sync.RWMutex
+	alertmanagers    map[int64]*Alertmanager
+}
+
+func init() {
+	registry.RegisterService(&MultiOrgAlertmanager{})
+}
+
+func (moa *MultiOrgAlertmanager) IsDisabled() bool {
+	if moa.Settings == nil {
+		return true
+	}
+	return !moa.Settings.IsNgAlertEnabled()
+}
+
+func (moa *MultiOrgAlertmanager) Init() error {
+	moa.logger = log.New("multiorg.alertmanager")
+	moa.alertmanagers = map[int64]*Alertmanager{}
+	dbStore := store.DBstore{SQLStore: moa.SQLStore}
+	moa.Store = dbStore
+
+	moa.peer = NilPeer{}
+	haCfg := moa.Settings.UnifiedAlerting
+	if !haCfg.HAEnabled() {
+		return nil
+	}
+
+	if haCfg.HAMode == setting.AlertmanagerHAModeDatabase {
+		moa.logger.Info("sharing the Alertmanager state with the other replicas through the database", "interval", haCfg.HADatabaseSyncInterval)
+		moa.peer = newDBPeer(dbStore, haCfg.HADatabaseSyncInterval, log.New("alertmanager.cluster"))
+		return nil
+	}
+
+	moa.logger.Info("sharing the Alertmanager state with the other replicas by gossip", "peers", haCfg.HAPeers)
+	peer, err := newGossipPeer(haCfg, prometheus.NewRegistry(), log.New("alertmanager.cluster"))
+	if err != nil {
+		return err
+	}
+	moa.peer = peer
+	return nil
+}
+
+func (moa *MultiOrgAlertmanager) Run(ctx context.Context) error {
+	if peer, ok := moa.peer.(runnablePeer); ok {
+		go peer.Run(ctx)
+	}
+
+	// Make sure the Alertmanagers start. We can tolerate future sync failures.
+	if err := moa.LoadAndSyncAlertmanagersForOrgs(); err != nil {
+		moa.logger.Error("unable to sync Alertmanagers", "err", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			moa.StopAndWait()
+			return nil
+		case <-time.After(pollInterval):
+			if err := moa.LoadAndSyncAlertmanagersForOrgs(); err != nil {
+				moa.logger.Error("unable to sync Alertmanagers", "err", err)
+			}
+		}
+	}
+}
+
+// AddMigration runs the database migrations as the service starts.
+func (moa *MultiOrgAlertmanager) AddMigration(mg *migrator.Migrator) {
+	alertmanagerConfigurationMigration(mg)
+	alertmanagerClusterStateMigration(mg)
+}
+
+// LoadAndSyncAlertmanagersForOrgs loads the organizations from the database and syncs their Alertmanagers.
+func (moa *MultiOrgAlertmanager) LoadAndSyncAlertmanagersForOrgs() error {
+	q := &ngmodels.GetOrgIDsQuery{}
+	if err := moa.Store.GetOrgIDs(q); err != nil {
+		return fmt.Errorf("unable to get organizations from the database: %w", err)
+	}
+
+	moa.SyncAlertmanagersForOrgs(q.Result)
+	return nil
+}
+
+// SyncAlertmanagersForOrgs creates an Alertmanager for every new organization, applies the latest
+// configuration of every organization and stops the Alertmanagers of the removed organizations.
+func (moa *MultiOrgAlertmanager) SyncAlertmanagersForOrgs(orgIDs []int64) {
+	orgsFound := make(map[int64]struct{}, len(orgIDs))
+	moa.alertmanagersMtx.Lock()
+	for _, orgID := range orgIDs {
+		orgsFound[orgID] = struct{}{}
+
+		am, existing := moa.alertmanagers[orgID]
+		if !existing {
+			var err error
+			am, err = newAlertmanager(orgID, moa.Settings, moa.Store, moa.peer)
+			if err != nil {
+				moa.logger.Error("unable to create Alertmanager for organization", "org", orgID, "err", err)
+				continue
+			}
+			moa.alertmanagers[orgID] = am
+		}
+
+		if err := am.SyncAndApplyConfigFromDatabase(); err != nil {
+			moa.logger.Error("unable to sync configuration", "org", orgID, "err", err)
+		}
+	}
+
+	amsToStop := map[int64]*Alertmanager{}
+	for orgID, am := range moa.alertmanagers {
+		if _, exists := orgsFound[orgID]; !exists {
+			amsToStop[orgID] = am
+			delete(moa.alertmanagers, orgID)
+		}
+	}
+	moa.alertmanagersMtx.Unlock()
+
+	// Stopping an Alertmanager waits for its components, so do it without holding the lock.
+	for orgID, am := range amsToStop {
+		moa.logger.Info("stopping Alertmanager of removed organization", "org", orgID)
+		am.StopAndWait()
+		if err := os.RemoveAll(am.WorkingDirPath()); err != nil {
+			moa.logger.Warn("unable to remove the working directory of the Alertmanager", "org", orgID, "err", err)
+		}
+	}
+}
+
+// StopAndWait stops the Alertmanagers of all organizations.
+func (moa *MultiOrgAlertmanager) StopAndWait() {
+	moa.alertmanagersMtx.Lock()
+	defer moa.alertmanagersMtx.Unlock()
+
+	var wg sync.WaitGroup
+	for _, am := range moa.alertmanagers {
+		wg.Add(1)
+		go func(am *Alertmanager) {
+			defer wg.Done()
+			am.StopAndWait()
+		}(am)
+	}
+	wg.Wait()
+}
+
+// AlertmanagerFor returns the Alertmanager of the organization.
+// It returns ErrNoAlertmanagerForOrg if the organization has no Alertmanager yet.
+func (moa *MultiOrgAlertmanager) AlertmanagerFor(orgID int64) (*Alertmanager, error) {
+	moa.alertmanagersMtx.RLock()
+	defer moa.alertmanagersMtx.RUnlock()
+
+	am, existing := moa.alertmanagers[orgID]
+	if !existing {
+		return nil, ErrNoAlertmanagerForOrg
+	}
+	return am, nil
+}
+
+// PutAlerts sends the alerts through the Alertmanager of the organization.
+func (moa *MultiOrgAlertmanager) PutAlerts(orgID int64, postableAlerts apimodels.PostableAlerts) error {
+	am, err := moa.AlertmanagerFor(orgID)
+	if err != nil {
+		return err
+	}
+	return am.PutAlerts(postableAlerts)
+}
@@ -0,0 +1,126 @@
+package notifier
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestMultiOrgAlertmanager_SyncAlertmanagersForOrgs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+
+	configStore := &fakeConfigStore{configs: map[int64][]*models.AlertConfiguration{}}
+	moa := &MultiOrgAlertmanager{
+		Settings:      &setting.Cfg{DataPath: dir},
+		Store:         configStore,
+		logger:        log.New("testlogger"),
+		peer:          NilPeer{},
+		alertmanagers: map[int64]*Alertmanager{},
+	}
+
+	moa.SyncAlertmanagersForOrgs([]int64{1, 2, 3})
+	require.Len(t, moa.alertmanagers, 3)
+	for _, orgID := range []int64{1, 2, 3} {
+		am, err := moa.AlertmanagerFor(orgID)
+		require.NoError(t, err)
+		require.NotNil(t, am.config)
+		require.DirExists(t, am.WorkingDirPath())
+	}
+
+	t.Run("configurations are saved per organization", func(t *testing.T) {
+		am, err := moa.AlertmanagerFor(2)
+		require.NoError(t, err)
+		cfg, err := Load([]byte(alertmanagerDefaultConfiguration))
+		require.NoError(t, err)
+		cfg.AlertmanagerConfig.Route.Receiver = "org-2-email"
+		cfg.AlertmanagerConfig.Receivers[0].Name = "org-2-email"
+		require.NoError(t, am.SaveAndApplyConfig(cfg))
+
+		require.Len(t, configStore.configs[2], 1)
+		require.Empty(t, configStore.configs[1])
+		require.Empty(t, configStore.configs[3])
+	})
+
+	t.Run("removed organizations have their Alertmanager stopped", func(t *testing.T) {
+		removed, err := moa.AlertmanagerFor(3)
+		require.NoError(t, err)
+
+		moa.SyncAlertmanagersForOrgs([]int64{1, 2})
+		require.Len(t, moa.alertmanagers, 2)
+		_, err = moa.AlertmanagerFor(3)
+		require.ErrorIs(t, err, ErrNoAlertmanagerForOrg)
+		require.NoDirExists(t, removed.WorkingDirPath())
+
+		err = moa.PutAlerts(3, apimodels.PostableAlerts{})
+		require.ErrorIs(t, err, ErrNoAlertmanagerForOrg)
+	})
+
+	t.Run("new organizations get an Alertmanager", func(t *testing.T) {
+		moa.SyncAlertmanagersForOrgs([]int64{1, 2, 4})
+		require.Len(t, moa.alertmanagers, 3)
+		_, err := moa.AlertmanagerFor(4)
+		require.NoError(t, err)
+	})
+}
+
+type fakeConfigStore struct {
+	mtx     sync.Mutex
+	configs map[int64][]*models.AlertConfiguration
+}
+
+func (f *fakeConfigStore) GetLatestAlertmanagerConfiguration(query *models.GetLatestAlertmanagerConfigurationQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	configs := f.configs[query.OrgID]
+	if len(configs) == 0 {
+		return store.ErrNoAlertmanagerConfiguration
+	}
+	query.Result = configs[len(configs)-1]
+	return nil
+}
+
+func (f *fakeConfigStore) GetAlertmanagerConfiguration(query *models.GetAlertmanagerConfigurationQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, c := range f.configs[query.OrgID] {
+		if c.ID == query.ID {
+			query.Result = c
+			return nil
+		}
+	}
+	return store.ErrNoAlertmanagerConfiguration
+}
+
+func (f *fakeConfigStore) SaveAlertmanagerConfiguration(cmd *models.SaveAlertmanagerConfigurationCmd) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.configs[cmd.OrgID] = append(f.configs[cmd.OrgID], &models.AlertConfiguration{
+		ID:                        int64(len(f.configs[cmd.OrgID]) + 1),
+		OrgID:                     cmd.OrgID,
+		AlertmanagerConfiguration: cmd.AlertmanagerConfiguration,
+		ConfigurationVersion:      cmd.ConfigurationVersion,
+	})
+	return nil
+}
+
+func (f *fakeConfigStore) GetOrgIDs(query *models.GetOrgIDsQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for orgID := range f.configs {
+		query.Result = append(query.Result, orgID)
+	}
+	return nil
+}
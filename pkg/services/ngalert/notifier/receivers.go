@@ -0,0 +1,150 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const (
+	testReceiverStatusSuccess = "success"
+	testReceiverStatusFailed  = "failed"
+
+	// testReceiverTimeout is how long an integration has to send the test alert.
+	testReceiverTimeout = 30 * time.Second
+)
+
+var (
+	// ErrNoReceiverIntegrations is returned when the receiver under test has no Grafana managed integrations.
+	ErrNoReceiverIntegrations = errors.New("the receiver has no integrations")
+)
+
+// ReceiverValidationError is returned when the integrations of the receiver under test cannot be built from their settings.
+type ReceiverValidationError struct {
+	Err error
+}
+
+func (e ReceiverValidationError) Error() string {
+	return fmt.Sprintf("invalid receiver: %s", e.Err.Error())
+}
+
+func (e ReceiverValidationError) Unwrap() error {
+	return e.Err
+}
+
+// TestReceiver sends a synthetic alert through each integration of the receiver and reports, for each one of them,
+// whether the notification was sent. The labels and annotations of the alert can be overridden.
+func (am *Alertmanager) TestReceiver(ctx context.Context, receiver *apimodels.PostableApiReceiver, alert *apimodels.TestReceiverAlert) (*apimodels.TestReceiverResult, error) {
+	if receiver == nil || len(receiver.GrafanaManagedReceivers) == 0 {
+		return nil, ErrNoReceiverIntegrations
+	}
+
+	tmpl, err := am.getTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	integrations, err := am.buildReceiverIntegrations(receiver, tmpl)
+	if err != nil {
+		return nil, ReceiverValidationError{Err: err}
+	}
+
+	now := time.Now()
+	testAlert := newTestAlert(alert, now)
+
+	// The group key is what integrations such as PagerDuty or Opsgenie use to deduplicate the notifications.
+	ctx = notify.WithGroupKey(ctx, fmt.Sprintf("%s-%s-%d", receiver.Name, testAlert.Labels.Fingerprint(), now.Unix()))
+	ctx = notify.WithGroupLabels(ctx, testAlert.Labels)
+	ctx = notify.WithReceiverName(ctx, receiver.Name)
+
+	result := &apimodels.TestReceiverResult{
+		Receiver:     receiver.Name,
+		Integrations: make([]apimodels.TestIntegrationResult, len(integrations)),
+		NotifiedAt:   now,
+	}
+
+	var wg sync.WaitGroup
+	for i := range integrations {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r := receiver.GrafanaManagedReceivers[i]
+			res := apimodels.TestIntegrationResult{
+				Name:   r.Name,
+				UID:    r.Uid,
+				Type:   r.Type,
+				Status: testReceiverStatusSuccess,
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, testReceiverTimeout)
+			defer cancel()
+
+			start := time.Now()
+			if _, err := integrations[i].Notify(ctx, testAlert); err != nil {
+				am.logger.Warn("failed to send test notification", "receiver", receiver.Name, "integration", r.Name, "type", r.Type, "err", err)
+				res.Status = testReceiverStatusFailed
+				res.Error = err.Error()
+			}
+			res.LatencyMs = time.Since(start).Milliseconds()
+			result.Integrations[i] = res
+		}(i)
+	}
+	wg.Wait()
+
+	return result, nil
+}
+
+// getTemplate returns the template of the applied configuration, or the default template if no configuration was applied yet.
+func (am *Alertmanager) getTemplate() (*template.Template, error) {
+	am.reloadConfigMtx.RLock()
+	tmpl := am.template
+	am.reloadConfigMtx.RUnlock()
+	if tmpl != nil {
+		return tmpl, nil
+	}
+
+	tmpl, err := template.FromGlobs(defaultTemplate)
+	if err != nil {
+		return nil, err
+	}
+	externalURL, err := url.Parse(am.Settings.AppURL)
+	if err != nil {
+		return nil, err
+	}
+	tmpl.ExternalURL = externalURL
+	return tmpl, nil
+}
+
+// newTestAlert returns a firing alert with the default test labels and annotations, merged with the ones provided.
+func newTestAlert(alert *apimodels.TestReceiverAlert, now time.Time) *types.Alert {
+	labels := model.LabelSet{
+		model.AlertNameLabel: "TestAlert",
+		"instance":           "Grafana",
+	}
+	annotations := model.LabelSet{
+		"summary": "Notification test",
+	}
+	if alert != nil {
+		labels = labels.Merge(alert.Labels)
+		annotations = annotations.Merge(alert.Annotations)
+	}
+
+	return &types.Alert{
+		Alert: model.Alert{
+			Labels:      labels,
+			Annotations: annotations,
+			StartsAt:    now,
+		},
+		UpdatedAt: now,
+	}
+}
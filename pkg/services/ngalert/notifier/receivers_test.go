@@ -0,0 +1,139 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestAlertmanager_TestReceiver(t *testing.T) {
+	am := setupAMTest(t)
+
+	tmpl, err := template.FromGlobs("channels/templates/default.tmpl")
+	require.NoError(t, err)
+	tmpl.ExternalURL, err = url.Parse("http://localhost")
+	require.NoError(t, err)
+	am.template = tmpl
+
+	var (
+		mtx      sync.Mutex
+		webhooks = map[string]*models.SendWebhookSync{}
+	)
+	bus.AddHandlerCtx("test", func(ctx context.Context, w *models.SendWebhookSync) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		webhooks[w.Url] = w
+		if w.Url == "http://failing" {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	webhookReceiver := func(uid, u string) *apimodels.PostableGrafanaReceiver {
+		return &apimodels.PostableGrafanaReceiver{
+			Uid:      uid,
+			Name:     "webhook " + uid,
+			Type:     "webhook",
+			Settings: simplejson.NewFromAny(map[string]interface{}{"url": u}),
+		}
+	}
+
+	cases := []struct {
+		name         string
+		receivers    []*apimodels.PostableGrafanaReceiver
+		alert        *apimodels.TestReceiverAlert
+		expStatuses  map[string]string
+		expErrors    map[string]string
+		expLabels    model.LabelSet
+		expErr       error
+		expErrString string
+	}{
+		{
+			name:        "The default test alert is sent through every integration",
+			receivers:   []*apimodels.PostableGrafanaReceiver{webhookReceiver("a", "http://a"), webhookReceiver("b", "http://b")},
+			expStatuses: map[string]string{"a": "success", "b": "success"},
+			expErrors:   map[string]string{"a": "", "b": ""},
+			expLabels:   model.LabelSet{"alertname": "TestAlert", "instance": "Grafana"},
+		}, {
+			name:        "The labels of the test alert can be overridden",
+			receivers:   []*apimodels.PostableGrafanaReceiver{webhookReceiver("a", "http://a")},
+			alert:       &apimodels.TestReceiverAlert{Labels: model.LabelSet{"instance": "server1", "team": "ops"}},
+			expStatuses: map[string]string{"a": "success"},
+			expErrors:   map[string]string{"a": ""},
+			expLabels:   model.LabelSet{"alertname": "TestAlert", "instance": "server1", "team": "ops"},
+		}, {
+			name:        "A failing integration does not prevent the others from being notified",
+			receivers:   []*apimodels.PostableGrafanaReceiver{webhookReceiver("a", "http://a"), webhookReceiver("b", "http://failing")},
+			expStatuses: map[string]string{"a": "success", "b": "failed"},
+			expErrors:   map[string]string{"a": "", "b": "connection refused"},
+			expLabels:   model.LabelSet{"alertname": "TestAlert", "instance": "Grafana"},
+		}, {
+			name:   "Receiver without integrations",
+			expErr: ErrNoReceiverIntegrations,
+		}, {
+			name:         "Receiver with invalid settings",
+			receivers:    []*apimodels.PostableGrafanaReceiver{webhookReceiver("a", "")},
+			expErrString: "invalid receiver: alert validation error: Could not find url property in settings",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			webhooks = map[string]*models.SendWebhookSync{}
+
+			receiver := &apimodels.PostableApiReceiver{}
+			receiver.Name = "test receiver"
+			receiver.GrafanaManagedReceivers = c.receivers
+
+			res, err := am.TestReceiver(context.Background(), receiver, c.alert)
+			if c.expErr != nil {
+				require.ErrorIs(t, err, c.expErr)
+				return
+			}
+			if c.expErrString != "" {
+				require.EqualError(t, err, c.expErrString)
+				var validationErr ReceiverValidationError
+				require.True(t, errors.As(err, &validationErr))
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, "test receiver", res.Receiver)
+			require.Len(t, res.Integrations, len(c.receivers))
+			for i, r := range res.Integrations {
+				require.Equal(t, c.receivers[i].Uid, r.UID)
+				require.Equal(t, c.receivers[i].Name, r.Name)
+				require.Equal(t, "webhook", r.Type)
+				require.Equal(t, c.expStatuses[r.UID], r.Status)
+				require.Equal(t, c.expErrors[r.UID], r.Error)
+				require.GreaterOrEqual(t, r.LatencyMs, int64(0))
+			}
+
+			require.Len(t, webhooks, len(c.receivers))
+			for _, w := range webhooks {
+				var msg struct {
+					Alerts []struct {
+						Status string         `json:"status"`
+						Labels model.LabelSet `json:"labels"`
+					} `json:"alerts"`
+				}
+				require.NoError(t, json.Unmarshal([]byte(w.Body), &msg))
+				require.Len(t, msg.Alerts, 1)
+				require.Equal(t, "firing", msg.Alerts[0].Status)
+				require.Equal(t, c.expLabels, msg.Alerts[0].Labels)
+			}
+		})
+	}
+}
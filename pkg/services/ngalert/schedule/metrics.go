@@ -0,0 +1,38 @@
+package schedule
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var metrics = newMetrics(prometheus.DefaultRegisterer)
+
+type schedulerMetrics struct {
+	evaluationsMissed prometheus.Counter
+	evaluationsQueued prometheus.Gauge
+	schedulingLag     prometheus.Histogram
+}
+
+func newMetrics(r prometheus.Registerer) *schedulerMetrics {
+	return &schedulerMetrics{
+		evaluationsMissed: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "alerting",
+			Name:      "rule_evaluations_missed_total",
+			Help:      "The total number of alert rule evaluations skipped because the next evaluation was already due.",
+		}),
+		evaluationsQueued: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "alerting",
+			Name:      "rule_evaluations_queued",
+			Help:      "The number of alert rule evaluations waiting for the concurrent evaluations limit.",
+		}),
+		schedulingLag: promauto.With(r).NewHistogram(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Subsystem: "alerting",
+			Name:      "rule_evaluation_scheduling_lag_seconds",
+			Help:      "Histogram of the delay between the scheduled time of alert rule evaluations and their start.",
+			Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60},
+		}),
+	}
+}
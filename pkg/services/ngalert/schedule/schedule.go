@@ -3,10 +3,12 @@ package schedule
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
 	"sync"
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"golang.org/x/sync/errgroup"
 
@@ -65,9 +67,10 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 				}
 
 				condition := models.Condition{
-					Condition: alertRule.Condition,
-					OrgID:     alertRule.OrgID,
-					Data:      alertRule.Data,
+					Condition:        alertRule.Condition,
+					OrgID:            alertRule.OrgID,
+					Data:             alertRule.Data,
+					LoadedDimensions: firingDimensions(stateManager.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID)),
 				}
 				results, err := sch.evaluator.ConditionEval(&condition, ctx.now, sch.dataService)
 				end = timeNow()
@@ -82,7 +85,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 				sch.saveAlertStates(processedStates)
 				alerts := FromAlertStateToPostableAlerts(processedStates)
 				sch.log.Debug("sending alerts to notifier", "count", len(alerts.PostableAlerts), "alerts", alerts.PostableAlerts)
-				err = sch.sendAlerts(alerts)
+				err = sch.sendAlerts(alertRule.OrgID, alerts)
 				if err != nil {
 					sch.log.Error("failed to put alerts in the notifier", "count", len(alerts.PostableAlerts), "err", err)
 				}
@@ -96,6 +99,21 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 					sch.evalApplied(key, ctx.now)
 				}()
 
+				if !sch.acquireEvaluationSlot(grafanaCtx) {
+					return
+				}
+				defer sch.releaseEvaluationSlot()
+
+				// the evaluation is missed if the next one is already due,
+				// because the previous evaluation or the wait for a slot took too long
+				lag := sch.clock.Now().Sub(ctx.scheduledAt)
+				if lag >= ctx.interval {
+					metrics.evaluationsMissed.Inc()
+					sch.log.Warn("alert rule evaluation missed", "key", key, "now", ctx.now, "lag", lag)
+					return
+				}
+				metrics.schedulingLag.Observe(lag.Seconds())
+
 				for attempt = 0; attempt < sch.maxAttempts; attempt++ {
 					err := evaluate(attempt)
 					if err == nil {
@@ -116,7 +134,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 
 // Notifier handles the delivery of alert notifications to the end user
 type Notifier interface {
-	PutAlerts(alerts apimodels.PostableAlerts) error
+	PutAlerts(orgID int64, alerts apimodels.PostableAlerts) error
 }
 
 type schedule struct {
@@ -153,6 +171,12 @@ type schedule struct {
 	dataService *tsdb.Service
 
 	notifier Notifier
+
+	// evaluationJitter spreads the evaluations of each alert rule within its interval
+	evaluationJitter bool
+
+	// evalSlots limits the number of concurrent evaluations, unlimited if nil
+	evalSlots chan struct{}
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -167,25 +191,33 @@ type SchedulerCfg struct {
 	Store           store.Store
 	RuleStore       store.RuleStore
 	Notifier        Notifier
+	// EvaluationJitter offsets the evaluations of each alert rule by a hash of its UID within its interval.
+	EvaluationJitter bool
+	// MaxConcurrentEvaluations is the maximum number of alert rules evaluated at once, 0 is unlimited.
+	MaxConcurrentEvaluations int
 }
 
 // NewScheduler returns a new schedule.
 func NewScheduler(cfg SchedulerCfg, dataService *tsdb.Service) *schedule {
 	ticker := alerting.NewTicker(cfg.C.Now(), time.Second*0, cfg.C, int64(cfg.BaseInterval.Seconds()))
 	sch := schedule{
-		registry:        alertRuleRegistry{alertRuleInfo: make(map[models.AlertRuleKey]alertRuleInfo)},
-		maxAttempts:     cfg.MaxAttempts,
-		clock:           cfg.C,
-		baseInterval:    cfg.BaseInterval,
-		log:             cfg.Logger,
-		heartbeat:       ticker,
-		evalAppliedFunc: cfg.EvalAppliedFunc,
-		stopAppliedFunc: cfg.StopAppliedFunc,
-		evaluator:       cfg.Evaluator,
-		store:           cfg.Store,
-		ruleStore:       cfg.RuleStore,
-		dataService:     dataService,
-		notifier:        cfg.Notifier,
+		registry:         alertRuleRegistry{alertRuleInfo: make(map[models.AlertRuleKey]alertRuleInfo)},
+		maxAttempts:      cfg.MaxAttempts,
+		clock:            cfg.C,
+		baseInterval:     cfg.BaseInterval,
+		log:              cfg.Logger,
+		heartbeat:        ticker,
+		evalAppliedFunc:  cfg.EvalAppliedFunc,
+		stopAppliedFunc:  cfg.StopAppliedFunc,
+		evaluator:        cfg.Evaluator,
+		store:            cfg.Store,
+		ruleStore:        cfg.RuleStore,
+		dataService:      dataService,
+		notifier:         cfg.Notifier,
+		evaluationJitter: cfg.EvaluationJitter,
+	}
+	if cfg.MaxConcurrentEvaluations > 0 {
+		sch.evalSlots = make(chan struct{}, cfg.MaxConcurrentEvaluations)
 	}
 	return &sch
 }
@@ -250,6 +282,8 @@ func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Mana
 			type readyToRunItem struct {
 				key      models.AlertRuleKey
 				ruleInfo alertRuleInfo
+				interval time.Duration
+				delay    time.Duration
 			}
 			readyToRun := make([]readyToRunItem, 0)
 			for _, item := range alertRules {
@@ -273,8 +307,17 @@ func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Mana
 				}
 
 				itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
-				if item.IntervalSeconds != 0 && tickNum%itemFrequency == 0 {
-					readyToRun = append(readyToRun, readyToRunItem{key: key, ruleInfo: ruleInfo})
+				itemInterval := time.Duration(item.IntervalSeconds) * time.Second
+				if item.IntervalSeconds != 0 {
+					// with jitter, the rule is due on the ticks shifted by its offset
+					// and is evaluated after the remainder of its offset within the tick
+					var offset time.Duration
+					if sch.evaluationJitter {
+						offset = jitterOffset(item.UID, itemInterval)
+					}
+					if (tickNum-int64(offset/sch.baseInterval))%itemFrequency == 0 {
+						readyToRun = append(readyToRun, readyToRunItem{key: key, ruleInfo: ruleInfo, interval: itemInterval, delay: offset % sch.baseInterval})
+					}
 				}
 
 				// remove the alert rule from the registered alert rules
@@ -289,8 +332,13 @@ func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Mana
 			for i := range readyToRun {
 				item := readyToRun[i]
 
-				time.AfterFunc(time.Duration(int64(i)*step), func() {
-					item.ruleInfo.evalCh <- &evalContext{now: tick, version: item.ruleInfo.version}
+				// without jitter, the rules due are spread evenly over the base interval
+				if !sch.evaluationJitter {
+					item.delay = time.Duration(int64(i) * step)
+				}
+
+				time.AfterFunc(item.delay, func() {
+					item.ruleInfo.evalCh <- &evalContext{now: tick, version: item.ruleInfo.version, scheduledAt: tick.Add(item.delay), interval: item.interval}
 				})
 			}
 
@@ -313,8 +361,49 @@ func (sch *schedule) Ticker(grafanaCtx context.Context, stateManager *state.Mana
 	}
 }
 
-func (sch *schedule) sendAlerts(alerts apimodels.PostableAlerts) error {
-	return sch.notifier.PutAlerts(alerts)
+// jitterOffset returns the offset of the evaluations of an alert rule within its interval.
+// The offset is derived from the UID of the rule, so it is the same on every tick and every restart.
+func jitterOffset(uid string, interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(uid))
+	return time.Duration(h.Sum64() % uint64(interval))
+}
+
+// acquireEvaluationSlot waits until fewer evaluations than the limit are running.
+// It returns false if the context is cancelled while waiting.
+func (sch *schedule) acquireEvaluationSlot(ctx context.Context) bool {
+	if sch.evalSlots == nil {
+		return true
+	}
+
+	select {
+	case sch.evalSlots <- struct{}{}:
+		return true
+	default:
+	}
+
+	metrics.evaluationsQueued.Inc()
+	defer metrics.evaluationsQueued.Dec()
+	select {
+	case sch.evalSlots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (sch *schedule) releaseEvaluationSlot() {
+	if sch.evalSlots == nil {
+		return
+	}
+	<-sch.evalSlots
+}
+
+func (sch *schedule) sendAlerts(orgID int64, alerts apimodels.PostableAlerts) error {
+	return sch.notifier.PutAlerts(orgID, alerts)
 }
 
 func (sch *schedule) saveAlertStates(states []*state.State) {
@@ -376,6 +465,17 @@ func (sch *schedule) WarmStateCache(st *state.Manager) {
 	st.Put(states)
 }
 
+// firingDimensions returns the labels of the states that are pending or alerting.
+func firingDimensions(states []*state.State) []data.Labels {
+	var dimensions []data.Labels
+	for _, s := range states {
+		if s.State == eval.Alerting || s.State == eval.Pending {
+			dimensions = append(dimensions, s.Labels)
+		}
+	}
+	return dimensions
+}
+
 func translateInstanceState(state models.InstanceStateType) eval.State {
 	switch {
 	case state == models.InstanceStateFiring:
@@ -470,4 +570,8 @@ type alertRuleInfo struct {
 type evalContext struct {
 	now     time.Time
 	version int64
+	// scheduledAt is when the evaluation was dispatched to the rule routine
+	scheduledAt time.Time
+	// interval is the evaluation interval of the rule
+	interval time.Duration
 }
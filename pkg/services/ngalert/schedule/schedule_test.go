@@ -0,0 +1,79 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJitterOffset(t *testing.T) {
+	interval := time.Minute
+
+	t.Run("offset is deterministic and within the interval", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			uid := fmt.Sprintf("rule-%d", i)
+			offset := jitterOffset(uid, interval)
+			require.GreaterOrEqual(t, offset, time.Duration(0))
+			require.Less(t, offset, interval)
+			require.Equal(t, offset, jitterOffset(uid, interval))
+		}
+	})
+
+	t.Run("offsets are spread over the interval", func(t *testing.T) {
+		seconds := make(map[int64]struct{})
+		for i := 0; i < 100; i++ {
+			offset := jitterOffset(fmt.Sprintf("rule-%d", i), interval)
+			seconds[int64(offset/time.Second)] = struct{}{}
+		}
+		require.Greater(t, len(seconds), 30)
+	})
+
+	t.Run("offset is zero without interval", func(t *testing.T) {
+		require.Equal(t, time.Duration(0), jitterOffset("rule", 0))
+	})
+}
+
+func TestAcquireEvaluationSlot(t *testing.T) {
+	t.Run("evaluations are unlimited by default", func(t *testing.T) {
+		sch := &schedule{}
+		for i := 0; i < 10; i++ {
+			require.True(t, sch.acquireEvaluationSlot(context.Background()))
+		}
+	})
+
+	t.Run("evaluations above the limit wait for a slot", func(t *testing.T) {
+		sch := &schedule{evalSlots: make(chan struct{}, 2)}
+		require.True(t, sch.acquireEvaluationSlot(context.Background()))
+		require.True(t, sch.acquireEvaluationSlot(context.Background()))
+
+		acquired := make(chan bool)
+		go func() {
+			acquired <- sch.acquireEvaluationSlot(context.Background())
+		}()
+		select {
+		case <-acquired:
+			t.Fatal("slot acquired above the limit")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		sch.releaseEvaluationSlot()
+		select {
+		case ok := <-acquired:
+			require.True(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("slot not acquired after release")
+		}
+	})
+
+	t.Run("waiting for a slot stops when the context is cancelled", func(t *testing.T) {
+		sch := &schedule{evalSlots: make(chan struct{}, 1)}
+		require.True(t, sch.acquireEvaluationSlot(context.Background()))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.False(t, sch.acquireEvaluationSlot(ctx))
+	})
+}
@@ -109,6 +109,18 @@ func (c *cache) getStatesByRuleUID() map[string][]*State {
 	return ruleMap
 }
 
+func (c *cache) getStatesForRuleUID(orgID int64, alertRuleUID string) []*State {
+	var ruleStates []*State
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
+	for _, state := range c.states {
+		if state.OrgID == orgID && state.AlertRuleUID == alertRuleUID {
+			ruleStates = append(ruleStates, state)
+		}
+	}
+	return ruleStates
+}
+
 func (c *cache) reset() {
 	c.mtxStates.Lock()
 	defer c.mtxStates.Unlock()
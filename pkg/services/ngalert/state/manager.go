@@ -1,25 +1,39 @@
 package state
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 )
 
 type Manager struct {
 	cache *cache
 	quit  chan struct{}
 	Log   log.Logger
+	// history records the state transitions of the alert instances, if set.
+	history       store.StateHistoryStore
+	flapDetection FlapDetection
 }
 
-func NewManager(logger log.Logger) *Manager {
+func NewManager(logger log.Logger, history store.StateHistoryStore, flapDetection FlapDetection) *Manager {
 	manager := &Manager{
-		cache: newCache(logger),
-		quit:  make(chan struct{}),
-		Log:   logger,
+		cache:         newCache(logger),
+		quit:          make(chan struct{}),
+		Log:           logger,
+		history:       history,
+		flapDetection: flapDetection,
 	}
 	go manager.cleanUp()
 	return manager
@@ -49,19 +63,31 @@ func (st *Manager) ResetCache() {
 func (st *Manager) ProcessEvalResults(alertRule *ngModels.AlertRule, results eval.Results) []*State {
 	st.Log.Debug("state manager processing evaluation results", "uid", alertRule.UID, "resultCount", len(results))
 	var states []*State
+	var transitions []*ngModels.AlertStateHistory
 	for _, result := range results {
-		s := st.setNextState(alertRule, result)
+		s, transition := st.setNextState(alertRule, result)
 		states = append(states, s)
+		if transition != nil {
+			transitions = append(transitions, transition)
+			st.annotateState(alertRule, transition)
+		}
 	}
+	st.saveStateHistory(transitions)
 	st.Log.Debug("returning changed states to scheduler", "count", len(states))
 	return states
 }
 
 //TODO: When calculating if an alert should not be firing anymore, we should take into account the re-send delay if any. We don't want to send every firing alert every time, we should have a fixed delay across all alerts to avoid saturating the notification system
-//Set the current state based on evaluation results
-func (st *Manager) setNextState(alertRule *ngModels.AlertRule, result eval.Result) *State {
+//Set the current state based on evaluation results, and return the state transition if the state changed
+func (st *Manager) setNextState(alertRule *ngModels.AlertRule, result eval.Result) (*State, *ngModels.AlertStateHistory) {
 	currentState := st.getOrCreate(alertRule, result)
 
+	// An instance that has never been evaluated was normal until now.
+	previousState := currentState.State
+	if currentState.LastEvaluationTime.IsZero() {
+		previousState = eval.Normal
+	}
+
 	currentState.LastEvaluationTime = result.EvaluatedAt
 	currentState.EvaluationDuration = result.EvaluationDuration
 	currentState.Results = append(currentState.Results, Evaluation{
@@ -72,7 +98,7 @@ func (st *Manager) setNextState(alertRule *ngModels.AlertRule, result eval.Resul
 	st.Log.Debug("setting alert state", "uid", alertRule.UID)
 	switch result.State {
 	case eval.Normal:
-		currentState = resultNormal(currentState, result)
+		currentState = currentState.resultNormal(alertRule, result)
 	case eval.Alerting:
 		currentState = currentState.resultAlerting(alertRule, result)
 	case eval.Error:
@@ -81,9 +107,97 @@ func (st *Manager) setNextState(alertRule *ngModels.AlertRule, result eval.Resul
 		currentState = currentState.resultNoData(alertRule, result)
 	case eval.Pending: // we do not emit results with this state
 	}
+	if result.State != eval.Normal {
+		currentState.ResolvingSince = time.Time{}
+	}
+
+	wasFlapping := currentState.Flapping
+	currentState.detectFlapping(st.flapDetection, previousState, result.EvaluatedAt)
+	if currentState.Flapping != wasFlapping {
+		st.Log.Info("alert instance flapping changed", "uid", alertRule.UID, "labels", currentState.Labels.String(), "flapping", currentState.Flapping)
+	}
 
 	st.set(currentState)
-	return currentState
+
+	if currentState.State == previousState {
+		return currentState, nil
+	}
+	transition := &ngModels.AlertStateHistory{
+		OrgID:          currentState.OrgID,
+		RuleUID:        currentState.AlertRuleUID,
+		Labels:         ngModels.InstanceLabels(currentState.Labels),
+		PreviousState:  ngModels.InstanceStateType(previousState.String()),
+		State:          ngModels.InstanceStateType(currentState.State.String()),
+		Value:          result.Value,
+		TransitionedAt: result.EvaluatedAt,
+	}
+	if result.Error != nil {
+		transition.Error = result.Error.Error()
+	}
+	return currentState, transition
+}
+
+// annotateState saves an annotation of the state transition. If the alert rule is linked to the panel
+// of a dashboard, the annotation is shown on the panel.
+func (st *Manager) annotateState(alertRule *ngModels.AlertRule, transition *ngModels.AlertStateHistory) {
+	annotationRepo := annotations.GetRepository()
+	if annotationRepo == nil {
+		return
+	}
+
+	labels := make(map[string]interface{}, len(transition.Labels))
+	for k, v := range transition.Labels {
+		labels[k] = v
+	}
+	annotationData := simplejson.New()
+	annotationData.Set("ruleUID", transition.RuleUID)
+	annotationData.Set("labels", labels)
+	if transition.Value != nil {
+		annotationData.Set("value", *transition.Value)
+	}
+	if transition.Error != "" {
+		annotationData.Set("error", transition.Error)
+	}
+
+	// The alert id refers to the legacy alerts, so the rule is only identified by the ruleUID in the data.
+	item := &annotations.Item{
+		OrgId:     alertRule.OrgID,
+		PrevState: string(transition.PreviousState),
+		NewState:  string(transition.State),
+		Text:      fmt.Sprintf("%s {%s} - %s", alertRule.Title, data.Labels(transition.Labels).String(), transition.State),
+		Epoch:     transition.TransitionedAt.UnixNano() / int64(time.Millisecond),
+		Data:      annotationData,
+	}
+
+	// The annotation is saved without a dashboard if the link of the rule to its panel is broken.
+	if dashboardUID := alertRule.Annotations[ngModels.DashboardUIDAnnotation]; dashboardUID != "" {
+		panelID, err := strconv.ParseInt(alertRule.Annotations[ngModels.PanelIDAnnotation], 10, 64)
+		if err != nil {
+			st.Log.Error("failed to parse the panel id of the alert rule", "uid", alertRule.UID, "panelId", alertRule.Annotations[ngModels.PanelIDAnnotation], "err", err)
+		} else {
+			query := &models.GetDashboardQuery{Uid: dashboardUID, OrgId: alertRule.OrgID}
+			if err := bus.Dispatch(query); err != nil {
+				st.Log.Error("failed to get the dashboard of the alert rule", "uid", alertRule.UID, "dashboardUid", dashboardUID, "err", err)
+			} else {
+				item.DashboardId = query.Result.Id
+				item.PanelId = panelID
+			}
+		}
+	}
+
+	if err := annotationRepo.Save(item); err != nil {
+		st.Log.Error("failed to save annotation for new alert state", "uid", alertRule.UID, "err", err)
+	}
+}
+
+// saveStateHistory records the state transitions, if the manager keeps the history of the states.
+func (st *Manager) saveStateHistory(transitions []*ngModels.AlertStateHistory) {
+	if st.history == nil || len(transitions) == 0 {
+		return
+	}
+	if err := st.history.SaveAlertStateHistory(&ngModels.SaveAlertStateHistoryCommand{Transitions: transitions}); err != nil {
+		st.Log.Error("failed to save alert state history", "count", len(transitions), "err", err)
+	}
 }
 
 func (st *Manager) GetAll() []*State {
@@ -94,6 +208,10 @@ func (st *Manager) GetStatesByRuleUID() map[string][]*State {
 	return st.cache.getStatesByRuleUID()
 }
 
+func (st *Manager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*State {
+	return st.cache.getStatesForRuleUID(orgID, alertRuleUID)
+}
+
 func (st *Manager) cleanUp() {
 	ticker := time.NewTicker(time.Duration(60) * time.Minute)
 	st.Log.Debug("starting cleanup process", "intervalMinutes", 60)
@@ -20,6 +20,21 @@ type State struct {
 	LastEvaluationTime time.Time
 	EvaluationDuration time.Duration
 	Annotations        map[string]string
+	// ResolvingSince is when a firing instance started to evaluate as normal, zero if it is not resolving.
+	ResolvingSince time.Time
+	// StateChanges are the times the instance changed state within the flap detection window.
+	StateChanges []time.Time
+	// Flapping is whether the instance changed state too many times within the flap detection window.
+	// A flapping instance keeps firing until it stops flapping.
+	Flapping bool
+}
+
+// FlapDetection configures how flapping alert instances are detected.
+type FlapDetection struct {
+	// Threshold is the number of state changes within Window after which an instance is flapping,
+	// 0 disables flap detection.
+	Threshold int
+	Window    time.Duration
 }
 
 type Evaluation struct {
@@ -27,13 +42,23 @@ type Evaluation struct {
 	EvaluationState eval.State
 }
 
-func resultNormal(alertState *State, result eval.Result) *State {
-	newState := alertState
-	if alertState.State != eval.Normal {
-		newState.EndsAt = result.EvaluatedAt
+func (a *State) resultNormal(alertRule *ngModels.AlertRule, result eval.Result) *State {
+	if a.State == eval.Alerting {
+		if a.ResolvingSince.IsZero() {
+			a.ResolvingSince = result.EvaluatedAt
+		}
+		// A firing instance is resolved once it stopped flapping and has been normal for the resolve after duration.
+		if a.Flapping || result.EvaluatedAt.Sub(a.ResolvingSince) < alertRule.ResolveAfter {
+			a.EndsAt = result.EvaluatedAt.Add(time.Duration(alertRule.IntervalSeconds*2) * time.Second)
+			return a
+		}
+	}
+	if a.State != eval.Normal {
+		a.EndsAt = result.EvaluatedAt
 	}
-	newState.State = eval.Normal
-	return newState
+	a.State = eval.Normal
+	a.ResolvingSince = time.Time{}
+	return a
 }
 
 func (a *State) resultAlerting(alertRule *ngModels.AlertRule, result eval.Result) *State {
@@ -118,6 +143,23 @@ func (a *State) resultNoData(alertRule *ngModels.AlertRule, result eval.Result)
 	return a
 }
 
+// detectFlapping records a change of state of the instance and updates whether it is flapping.
+func (a *State) detectFlapping(flapDetection FlapDetection, previousState eval.State, evaluatedAt time.Time) {
+	if flapDetection.Threshold <= 0 {
+		return
+	}
+	if a.State != previousState {
+		a.StateChanges = append(a.StateChanges, evaluatedAt)
+	}
+
+	expired := 0
+	for expired < len(a.StateChanges) && evaluatedAt.Sub(a.StateChanges[expired]) > flapDetection.Window {
+		expired++
+	}
+	a.StateChanges = a.StateChanges[expired:]
+	a.Flapping = len(a.StateChanges) >= flapDetection.Threshold
+}
+
 func (a *State) Equals(b *State) bool {
 	return a.AlertRuleUID == b.AlertRuleUID &&
 		a.OrgID == b.OrgID &&
@@ -274,6 +274,7 @@ func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 				NoDataState:      r.New.NoDataState,
 				ExecErrState:     r.New.ExecErrState,
 				For:              r.New.For,
+				ResolveAfter:     r.New.ResolveAfter,
 				Annotations:      r.New.Annotations,
 				Labels:           r.New.Labels,
 			})
@@ -428,6 +429,10 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 		return fmt.Errorf("%w: no organisation is found", ngmodels.ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.ResolveAfter < 0 {
+		return fmt.Errorf("%w: resolve after (%v) should not be negative", ngmodels.ErrAlertRuleFailedValidation, alertRule.ResolveAfter)
+	}
+
 	return nil
 }
 
@@ -467,6 +472,7 @@ func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
 				RuleGroup:       ruleGroup,
 				NoDataState:     ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
 				ExecErrState:    ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+				ResolveAfter:    time.Duration(r.GrafanaManagedAlert.ResolveAfter),
 			}
 
 			if r.ApiRuleNode != nil {
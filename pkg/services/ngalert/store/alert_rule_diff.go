@@ -0,0 +1,146 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// DiffRuleGroup matches the rules of the imported rule group to the existing rules of the group and returns the
+// changes of the import. The matched rules of the imported group are given the UID of their existing rule and
+// the unmatched ones are given no UID, so that they are created.
+func DiffRuleGroup(namespaceTitle string, group apimodels.PostableRuleGroupConfig, existingRules []*models.AlertRule) ([]apimodels.RuleImportChange, error) {
+	byUID := make(map[string]*models.AlertRule, len(existingRules))
+	byTitle := make(map[string]*models.AlertRule, len(existingRules))
+	for _, r := range existingRules {
+		byUID[r.UID] = r
+		byTitle[r.Title] = r
+	}
+
+	matched := make(map[string]struct{}, len(existingRules))
+	changes := make([]apimodels.RuleImportChange, 0, len(group.Rules))
+	for _, r := range group.Rules {
+		rule := r.GrafanaManagedAlert
+		if rule.NoDataState == "" {
+			rule.NoDataState = apimodels.NoDataState(models.NoData)
+		}
+		if rule.ExecErrState == "" {
+			rule.ExecErrState = apimodels.ExecutionErrorState(models.AlertingErrState)
+		}
+
+		existing, ok := byUID[rule.UID]
+		if !ok {
+			existing, ok = byTitle[rule.Title]
+		}
+		if ok {
+			if _, alreadyMatched := matched[existing.UID]; alreadyMatched {
+				ok = false
+			}
+		}
+		if !ok {
+			rule.UID = ""
+			changes = append(changes, apimodels.RuleImportChange{Namespace: namespaceTitle, Group: group.Name, Title: rule.Title, Action: apimodels.RuleImportCreate})
+			continue
+		}
+
+		matched[existing.UID] = struct{}{}
+		rule.UID = existing.UID
+		fields, err := changedRuleFields(existing, r, group.Interval)
+		if err != nil {
+			return nil, err
+		}
+		change := apimodels.RuleImportChange{Namespace: namespaceTitle, Group: group.Name, Title: rule.Title, UID: rule.UID, Action: apimodels.RuleImportUnchanged}
+		if len(fields) > 0 {
+			change.Action = apimodels.RuleImportUpdate
+			change.Fields = fields
+		}
+		changes = append(changes, change)
+	}
+
+	for _, r := range existingRules {
+		if _, ok := matched[r.UID]; !ok {
+			changes = append(changes, apimodels.RuleImportChange{Namespace: namespaceTitle, Group: group.Name, Title: r.Title, UID: r.UID, Action: apimodels.RuleImportDelete})
+		}
+	}
+	return changes, nil
+}
+
+// changedRuleFields returns the fields of the existing rule that the imported rule changes.
+// Like the update of a rule group, an imported rule without for, annotations or labels keeps those of the existing rule.
+func changedRuleFields(existing *models.AlertRule, r apimodels.PostableExtendedRuleNode, interval model.Duration) ([]string, error) {
+	rule := r.GrafanaManagedAlert
+	var fields []string
+	if rule.Title != existing.Title {
+		fields = append(fields, "title")
+	}
+	if rule.Condition != existing.Condition {
+		fields = append(fields, "condition")
+	}
+	equal, err := alertQueriesEqual(rule.Data, existing.Data)
+	if err != nil {
+		return nil, err
+	}
+	if !equal {
+		fields = append(fields, "data")
+	}
+	if interval != 0 && int64(time.Duration(interval).Seconds()) != existing.IntervalSeconds {
+		fields = append(fields, "interval")
+	}
+	if models.NoDataState(rule.NoDataState) != existing.NoDataState {
+		fields = append(fields, "no_data_state")
+	}
+	if models.ExecutionErrorState(rule.ExecErrState) != existing.ExecErrState {
+		fields = append(fields, "exec_err_state")
+	}
+	if time.Duration(rule.ResolveAfter) != existing.ResolveAfter {
+		fields = append(fields, "resolve_after")
+	}
+	if r.ApiRuleNode != nil {
+		if r.For != 0 && time.Duration(r.For) != existing.For {
+			fields = append(fields, "for")
+		}
+		if len(r.Annotations) > 0 && !reflect.DeepEqual(r.Annotations, existing.Annotations) {
+			fields = append(fields, "annotations")
+		}
+		if len(r.Labels) > 0 && !reflect.DeepEqual(r.Labels, existing.Labels) {
+			fields = append(fields, "labels")
+		}
+	}
+	return fields, nil
+}
+
+// alertQueriesEqual compares the imported queries, once prepared to be saved, with the saved queries.
+func alertQueriesEqual(imported, saved []models.AlertQuery) (bool, error) {
+	if len(imported) != len(saved) {
+		return false, nil
+	}
+	for i := range imported {
+		q := imported[i]
+		if err := q.PreSave(); err != nil {
+			return false, fmt.Errorf("invalid alert query %s: %w", q.RefID, err)
+		}
+
+		s := saved[i]
+		if q.RefID != s.RefID || q.QueryType != s.QueryType || q.DatasourceUID != s.DatasourceUID || q.RelativeTimeRange != s.RelativeTimeRange {
+			return false, nil
+		}
+
+		var importedModel, savedModel interface{}
+		if err := json.Unmarshal(q.Model, &importedModel); err != nil {
+			return false, err
+		}
+		if err := json.Unmarshal(s.Model, &savedModel); err != nil {
+			return false, nil
+		}
+		if !reflect.DeepEqual(importedModel, savedModel) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
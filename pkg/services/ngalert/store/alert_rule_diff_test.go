@@ -0,0 +1,118 @@
+package store
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestDiffRuleGroup(t *testing.T) {
+	query := func(expr string) []models.AlertQuery {
+		return []models.AlertQuery{{
+			RefID:             "A",
+			DatasourceUID:     "datasource-uid",
+			RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(10 * time.Minute)},
+			Model:             json.RawMessage(`{"expr":"` + expr + `"}`),
+		}}
+	}
+	// savedQuery is the query as saved by the store
+	savedQuery := func(expr string) []models.AlertQuery {
+		q := query(expr)
+		require.NoError(t, q[0].PreSave())
+		return q
+	}
+
+	existingRules := []*models.AlertRule{
+		{
+			UID:             "uid1",
+			Title:           "rule1",
+			Condition:       "A",
+			Data:            savedQuery("up == 0"),
+			IntervalSeconds: 60,
+			NoDataState:     models.NoData,
+			ExecErrState:    models.AlertingErrState,
+			For:             5 * time.Minute,
+			Labels:          map[string]string{"team": "a"},
+		},
+		{
+			UID:             "uid2",
+			Title:           "rule2",
+			Condition:       "A",
+			Data:            savedQuery("up == 0"),
+			IntervalSeconds: 60,
+			NoDataState:     models.NoData,
+			ExecErrState:    models.AlertingErrState,
+		},
+		{
+			UID:             "uid3",
+			Title:           "rule3",
+			Condition:       "A",
+			Data:            savedQuery("up == 0"),
+			IntervalSeconds: 60,
+			NoDataState:     models.NoData,
+			ExecErrState:    models.AlertingErrState,
+		},
+	}
+
+	group := apimodels.PostableRuleGroupConfig{
+		Name:     "group",
+		Interval: model.Duration(time.Minute),
+		Rules: []apimodels.PostableExtendedRuleNode{
+			{
+				// unchanged, matched by UID
+				ApiRuleNode:         &apimodels.ApiRuleNode{For: model.Duration(5 * time.Minute), Labels: map[string]string{"team": "a"}},
+				GrafanaManagedAlert: &apimodels.PostableGrafanaRule{UID: "uid1", Title: "rule1", Condition: "A", Data: query("up == 0")},
+			},
+			{
+				// updated, matched by title
+				ApiRuleNode:         &apimodels.ApiRuleNode{Labels: map[string]string{"team": "b"}},
+				GrafanaManagedAlert: &apimodels.PostableGrafanaRule{UID: "unknown", Title: "rule2", Condition: "A", Data: query("up == 1"), NoDataState: apimodels.OK},
+			},
+			{
+				// created
+				GrafanaManagedAlert: &apimodels.PostableGrafanaRule{UID: "uid-of-another-instance", Title: "rule4", Condition: "A", Data: query("up == 0")},
+			},
+		},
+	}
+
+	changes, err := DiffRuleGroup("folder", group, existingRules)
+	require.NoError(t, err)
+	require.Equal(t, []apimodels.RuleImportChange{
+		{Namespace: "folder", Group: "group", Title: "rule1", UID: "uid1", Action: apimodels.RuleImportUnchanged},
+		{Namespace: "folder", Group: "group", Title: "rule2", UID: "uid2", Action: apimodels.RuleImportUpdate, Fields: []string{"data", "no_data_state", "labels"}},
+		{Namespace: "folder", Group: "group", Title: "rule4", Action: apimodels.RuleImportCreate},
+		{Namespace: "folder", Group: "group", Title: "rule3", UID: "uid3", Action: apimodels.RuleImportDelete},
+	}, changes)
+
+	// the imported rules are given the UID of the rules they update and no UID if they are created
+	require.Equal(t, "uid1", group.Rules[0].GrafanaManagedAlert.UID)
+	require.Equal(t, "uid2", group.Rules[1].GrafanaManagedAlert.UID)
+	require.Equal(t, "", group.Rules[2].GrafanaManagedAlert.UID)
+	// the default states are set
+	require.Equal(t, apimodels.NoData, group.Rules[2].GrafanaManagedAlert.NoDataState)
+	require.Equal(t, apimodels.AlertingErrState, group.Rules[2].GrafanaManagedAlert.ExecErrState)
+}
+
+func TestDiffRuleGroupInvalidQuery(t *testing.T) {
+	group := apimodels.PostableRuleGroupConfig{
+		Name: "group",
+		Rules: []apimodels.PostableExtendedRuleNode{
+			{
+				GrafanaManagedAlert: &apimodels.PostableGrafanaRule{UID: "uid1", Title: "rule1", Condition: "A", Data: []models.AlertQuery{{
+					RefID: "A",
+					Model: json.RawMessage(`{}`),
+				}}},
+			},
+		},
+	}
+	existingRules := []*models.AlertRule{{UID: "uid1", Title: "rule1", Data: []models.AlertQuery{{RefID: "A"}}}}
+
+	_, err := DiffRuleGroup("folder", group, existingRules)
+	require.Error(t, err)
+}
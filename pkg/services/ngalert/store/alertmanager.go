@@ -13,10 +13,10 @@ var (
 	ErrNoAlertmanagerConfiguration = fmt.Errorf("could not find an Alertmanager configuration")
 )
 
-func getAlertmanagerConfigurationByID(sess *sqlstore.DBSession, id int64) (*models.AlertConfiguration, error) {
+func getAlertmanagerConfigurationByID(sess *sqlstore.DBSession, id, orgID int64) (*models.AlertConfiguration, error) {
 	c := &models.AlertConfiguration{}
 
-	has, err := sess.ID(id).Get(c)
+	has, err := sess.ID(id).Where("org_id = ?", orgID).Get(c)
 	if err != nil {
 		return nil, err
 	}
@@ -27,10 +27,10 @@ func getAlertmanagerConfigurationByID(sess *sqlstore.DBSession, id int64) (*mode
 	return c, nil
 }
 
-func getLatestAlertmanagerConfiguration(sess *sqlstore.DBSession) (*models.AlertConfiguration, error) {
+func getLatestAlertmanagerConfiguration(sess *sqlstore.DBSession, orgID int64) (*models.AlertConfiguration, error) {
 	c := &models.AlertConfiguration{}
 	// The ID is already an auto incremental column, using the ID as an order should guarantee the latest.
-	ok, err := sess.Desc("id").Limit(1).Get(c)
+	ok, err := sess.Where("org_id = ?", orgID).Desc("id").Limit(1).Get(c)
 	if err != nil {
 		return nil, err
 	}
@@ -42,11 +42,11 @@ func getLatestAlertmanagerConfiguration(sess *sqlstore.DBSession) (*models.Alert
 	return c, nil
 }
 
-// GetLatestAlertmanagerConfiguration returns the lastest version of the alertmanager configuration.
+// GetLatestAlertmanagerConfiguration returns the lastest version of the alertmanager configuration of the organization.
 // It returns ErrNoAlertmanagerConfiguration if no configuration is found.
 func (st DBstore) GetLatestAlertmanagerConfiguration(query *models.GetLatestAlertmanagerConfigurationQuery) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		c, err := getLatestAlertmanagerConfiguration(sess)
+		c, err := getLatestAlertmanagerConfiguration(sess, query.OrgID)
 		if err != nil {
 			return err
 		}
@@ -59,7 +59,7 @@ func (st DBstore) GetLatestAlertmanagerConfiguration(query *models.GetLatestAler
 // It returns ErrNoAlertmanagerConfiguration if no such configuration is found.
 func (st DBstore) GetAlertmanagerConfiguration(query *models.GetAlertmanagerConfigurationQuery) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		c, err := getAlertmanagerConfigurationByID(sess, query.ID)
+		c, err := getAlertmanagerConfigurationByID(sess, query.ID, query.OrgID)
 		if err != nil {
 			return err
 		}
@@ -72,6 +72,7 @@ func (st DBstore) GetAlertmanagerConfiguration(query *models.GetAlertmanagerConf
 func (st DBstore) SaveAlertmanagerConfiguration(cmd *models.SaveAlertmanagerConfigurationCmd) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		config := models.AlertConfiguration{
+			OrgID:                     cmd.OrgID,
 			AlertmanagerConfiguration: cmd.AlertmanagerConfiguration,
 			ConfigurationVersion:      cmd.ConfigurationVersion,
 		}
@@ -81,3 +82,77 @@ func (st DBstore) SaveAlertmanagerConfiguration(cmd *models.SaveAlertmanagerConf
 		return nil
 	})
 }
+
+// GetOrgIDs returns the IDs of all organizations.
+func (st DBstore) GetOrgIDs(query *models.GetOrgIDsQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		orgIDs := make([]int64, 0)
+		if err := sess.Table("org").Cols("id").Asc("id").Find(&orgIDs); err != nil {
+			return err
+		}
+		query.Result = orgIDs
+		return nil
+	})
+}
+
+// SaveAlertmanagerClusterState creates or updates the state of a replica.
+func (st DBstore) SaveAlertmanagerClusterState(cmd *models.SaveAlertmanagerClusterStateCmd) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		state := models.AlertClusterState{
+			PeerID:      cmd.PeerID,
+			StateKey:    cmd.StateKey,
+			State:       cmd.State,
+			UpdatedAt:   cmd.Now.UnixNano(),
+			HeartbeatAt: cmd.Now.UnixNano(),
+		}
+
+		existing := models.AlertClusterState{}
+		has, err := sess.Where("peer_id = ? AND state_key = ?", cmd.PeerID, cmd.StateKey).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !has {
+			_, err = sess.Insert(&state)
+			return err
+		}
+
+		_, err = sess.ID(existing.ID).Cols("state", "updated_at", "heartbeat_at").Update(&state)
+		return err
+	})
+}
+
+// HeartbeatAlertmanagerClusterPeer marks the states of a replica as alive and removes the states
+// of the replicas that have not been seen since cmd.ExpireBefore.
+func (st DBstore) HeartbeatAlertmanagerClusterPeer(cmd *models.HeartbeatAlertmanagerClusterPeerCmd) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Exec("UPDATE alert_cluster_state SET heartbeat_at = ? WHERE peer_id = ?", cmd.Now.UnixNano(), cmd.PeerID); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM alert_cluster_state WHERE heartbeat_at < ?", cmd.ExpireBefore.UnixNano())
+		return err
+	})
+}
+
+// GetAlertmanagerClusterStates returns the states of all replicas that changed since query.UpdatedSince.
+func (st DBstore) GetAlertmanagerClusterStates(query *models.GetAlertmanagerClusterStatesQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		states := make([]*models.AlertClusterState, 0)
+		if err := sess.Where("updated_at >= ?", query.UpdatedSince.UnixNano()).Asc("peer_id", "state_key").Find(&states); err != nil {
+			return err
+		}
+		query.Result = states
+		return nil
+	})
+}
+
+// GetAlertmanagerClusterPeers returns the IDs of the replicas seen since query.AliveSince.
+func (st DBstore) GetAlertmanagerClusterPeers(query *models.GetAlertmanagerClusterPeersQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		peers := make([]string, 0)
+		if err := sess.Table("alert_cluster_state").Where("heartbeat_at >= ?", query.AliveSince.UnixNano()).Distinct("peer_id").Find(&peers); err != nil {
+			return err
+		}
+		query.Result = peers
+		return nil
+	})
+}
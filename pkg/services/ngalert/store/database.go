@@ -40,8 +40,41 @@ type AlertingStore interface {
 	GetLatestAlertmanagerConfiguration(*models.GetLatestAlertmanagerConfigurationQuery) error
 	GetAlertmanagerConfiguration(*models.GetAlertmanagerConfigurationQuery) error
 	SaveAlertmanagerConfiguration(*models.SaveAlertmanagerConfigurationCmd) error
+	GetOrgIDs(*models.GetOrgIDsQuery) error
 }
 
+// ClusterStateStore is the database interface used by Alertmanager replicas to share their state.
+type ClusterStateStore interface {
+	SaveAlertmanagerClusterState(*models.SaveAlertmanagerClusterStateCmd) error
+	HeartbeatAlertmanagerClusterPeer(*models.HeartbeatAlertmanagerClusterPeerCmd) error
+	GetAlertmanagerClusterStates(*models.GetAlertmanagerClusterStatesQuery) error
+	GetAlertmanagerClusterPeers(*models.GetAlertmanagerClusterPeersQuery) error
+}
+
+// StateHistoryStore is the database interface used to record and query the state transitions of alert instances.
+type StateHistoryStore interface {
+	SaveAlertStateHistory(*models.SaveAlertStateHistoryCommand) error
+	GetAlertStateHistory(*models.GetAlertStateHistoryQuery) error
+	DeleteExpiredAlertStateHistory(*models.DeleteExpiredAlertStateHistoryCommand) error
+}
+
+// ProvisioningStore is the database interface used to record the alerting objects provisioned from files.
+type ProvisioningStore interface {
+	GetAlertProvisioning(*models.GetAlertProvisioningQuery) error
+	SetAlertProvisioning(*models.SetAlertProvisioningCmd) error
+}
+
+const (
+	// BaseIntervalSeconds is the scheduler interval.
+	// changing this value is discouraged
+	// because this could cause existing alert definition
+	// with intervals that are not exactly divided by this number
+	// not to be evaluated
+	BaseIntervalSeconds = 10
+	// DefaultIntervalSeconds is the default alert definition interval.
+	DefaultIntervalSeconds int64 = 6 * BaseIntervalSeconds
+)
+
 // DBstore stores the alert definitions and instances in the database.
 type DBstore struct {
 	// the base scheduler tick rate; it's used for validating definition interval
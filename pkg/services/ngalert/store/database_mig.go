@@ -156,6 +156,9 @@ func AddAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64)
 
 	// add labels column
 	mg.AddMigration("add column labels to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "labels", Type: migrator.DB_Text, Nullable: true}))
+
+	// add resolve after column
+	mg.AddMigration("add column resolve_after to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "resolve_after", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
 }
 
 func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
@@ -199,4 +202,56 @@ func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
 
 	// add labels column
 	mg.AddMigration("add column labels to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "labels", Type: migrator.DB_Text, Nullable: true}))
+
+	// add resolve after column
+	mg.AddMigration("add column resolve_after to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "resolve_after", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+}
+
+func AddAlertStateHistoryMigrations(mg *migrator.Migrator) {
+	stateHistory := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "state", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "value", Type: migrator.DB_Double, Nullable: true},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "transitioned_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "rule_uid", "transitioned_at"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "transitioned_at"}, Type: migrator.IndexType},
+			{Cols: []string{"transitioned_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(stateHistory))
+	mg.AddMigration("add index in alert_state_history table on org_id, rule_uid and transitioned_at columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[0]))
+	mg.AddMigration("add index in alert_state_history table on org_id and transitioned_at columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[1]))
+	mg.AddMigration("add index in alert_state_history table on transitioned_at column", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[2]))
+}
+
+func AddAlertProvisioningMigrations(mg *migrator.Migrator) {
+	provisioning := migrator.Table{
+		Name: "alert_provisioning",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "kind", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "namespace_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "rule_group", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "file", Type: migrator.DB_NVarchar, Length: 2048, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "kind", "namespace_uid", "rule_group"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_provisioning table", migrator.NewAddTableMigration(provisioning))
+	mg.AddMigration("add index in alert_provisioning table on org_id, kind, namespace_uid and rule_group columns", migrator.NewAddIndexMigration(provisioning, provisioning.Indices[0]))
 }
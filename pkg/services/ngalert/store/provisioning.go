@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// GetAlertProvisioning is a handler for retrieving the provisioned objects of an organization.
+func (st DBstore) GetAlertProvisioning(query *models.GetAlertProvisioningQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		q := sess.Where("org_id = ?", query.OrgID)
+		if query.Kind != "" {
+			q = q.And("kind = ?", query.Kind)
+		}
+		if query.NamespaceUID != "" {
+			q = q.And("namespace_uid = ?", query.NamespaceUID)
+		}
+		if query.RuleGroup != "" {
+			q = q.And("rule_group = ?", query.RuleGroup)
+		}
+
+		objects := make([]*models.AlertProvisioning, 0)
+		if err := q.Asc("id").Find(&objects); err != nil {
+			return err
+		}
+		query.Result = objects
+		return nil
+	})
+}
+
+// SetAlertProvisioning is a handler for replacing all provisioned objects. The objects that are no longer
+// provisioned become editable in the API again.
+func (st DBstore) SetAlertProvisioning(cmd *models.SetAlertProvisioningCmd) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Exec("DELETE FROM alert_provisioning"); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, o := range cmd.Objects {
+			o.ID = 0
+			o.Updated = now
+			if _, err := sess.Insert(o); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
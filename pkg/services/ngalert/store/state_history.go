@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// SaveAlertStateHistory is a handler for recording state transitions of alert instances.
+func (st DBstore) SaveAlertStateHistory(cmd *models.SaveAlertStateHistoryCommand) error {
+	if len(cmd.Transitions) == 0 {
+		return nil
+	}
+
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		for _, h := range cmd.Transitions {
+			if err := models.ValidateAlertStateHistory(h); err != nil {
+				return err
+			}
+
+			labelTupleJSON, labelsHash, err := h.Labels.StringAndHash()
+			if err != nil {
+				return err
+			}
+
+			// The labels are inserted with raw SQL as their database serialization is not implemented.
+			if _, err := sess.Exec(`INSERT INTO alert_state_history
+				(org_id, rule_uid, labels, labels_hash, previous_state, state, value, error, transitioned_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				h.OrgID, h.RuleUID, labelTupleJSON, labelsHash, h.PreviousState, h.State, h.Value, h.Error, h.TransitionedAt.Unix()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// stateHistoryPageSize is the number of transitions read at once when filtering them by labels.
+const stateHistoryPageSize = 1000
+
+// GetAlertStateHistory is a handler for retrieving the state transitions of the alert instances of an organization,
+// optionally restricted to an alert rule, to the instances with some labels and to a time range.
+// The transitions are returned most recent first.
+func (st DBstore) GetAlertStateHistory(query *models.GetAlertStateHistoryQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		s := strings.Builder{}
+		params := make([]interface{}, 0)
+
+		addToQuery := func(stmt string, p ...interface{}) {
+			s.WriteString(stmt)
+			params = append(params, p...)
+		}
+
+		addToQuery("SELECT * FROM alert_state_history WHERE org_id = ?", query.OrgID)
+
+		if query.RuleUID != "" {
+			addToQuery(" AND rule_uid = ?", query.RuleUID)
+		}
+
+		if !query.From.IsZero() {
+			addToQuery(" AND transitioned_at >= ?", query.From.Unix())
+		}
+
+		if !query.To.IsZero() {
+			addToQuery(" AND transitioned_at <= ?", query.To.Unix())
+		}
+
+		addToQuery(" ORDER BY transitioned_at DESC, id DESC")
+
+		// Without labels to match, the limit can be applied by the database.
+		if len(query.Labels) == 0 {
+			if query.Limit > 0 {
+				addToQuery(st.SQLStore.Dialect.Limit(int64(query.Limit)))
+			}
+			history := make([]*models.AlertStateHistory, 0)
+			if err := sess.SQL(s.String(), params...).Find(&history); err != nil {
+				return err
+			}
+			query.Result = history
+			return nil
+		}
+
+		// The labels are stored serialized, so the transitions are read page by page
+		// and filtered until enough of them match.
+		history := make([]*models.AlertStateHistory, 0)
+		for offset := 0; ; offset += stateHistoryPageSize {
+			page := make([]*models.AlertStateHistory, 0, stateHistoryPageSize)
+			stmt := s.String() + st.SQLStore.Dialect.LimitOffset(stateHistoryPageSize, int64(offset))
+			if err := sess.SQL(stmt, params...).Find(&page); err != nil {
+				return err
+			}
+
+			remaining := 0
+			if query.Limit > 0 {
+				remaining = query.Limit - len(history)
+			}
+			history = append(history, filterAlertStateHistory(page, query.Labels, remaining)...)
+			if len(page) < stateHistoryPageSize || (query.Limit > 0 && len(history) >= query.Limit) {
+				break
+			}
+		}
+
+		query.Result = history
+		return nil
+	})
+}
+
+// filterAlertStateHistory returns up to limit transitions of the instances having all the labels.
+func filterAlertStateHistory(history []*models.AlertStateHistory, labels map[string]string, limit int) []*models.AlertStateHistory {
+	filtered := make([]*models.AlertStateHistory, 0, len(history))
+	for _, h := range history {
+		if limit > 0 && len(filtered) == limit {
+			break
+		}
+		matches := true
+		for k, v := range labels {
+			if h.Labels[k] != v {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			filtered = append(filtered, h)
+		}
+	}
+	return filtered
+}
+
+// DeleteExpiredAlertStateHistory is a handler for deleting the state transitions older than a point in time.
+func (st DBstore) DeleteExpiredAlertStateHistory(cmd *models.DeleteExpiredAlertStateHistoryCommand) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_state_history WHERE transitioned_at < ?", cmd.OlderThan.Unix())
+		if err != nil {
+			return err
+		}
+
+		cmd.DeletedRows, err = res.RowsAffected()
+		return err
+	})
+}
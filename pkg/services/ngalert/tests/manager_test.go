@@ -8,7 +8,10 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
+	grafanaModels "github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
@@ -775,7 +778,7 @@ func TestProcessEvalResults(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		st := state.NewManager(log.New("test_state_manager"))
+		st := state.NewManager(log.New("test_state_manager"), nil, state.FlapDetection{})
 		t.Run(tc.desc, func(t *testing.T) {
 			for _, res := range tc.evalResults {
 				_ = st.ProcessEvalResults(tc.alertRule, res)
@@ -788,3 +791,233 @@ func TestProcessEvalResults(t *testing.T) {
 		})
 	}
 }
+
+type fakeStateHistoryStore struct {
+	transitions []*models.AlertStateHistory
+}
+
+func (f *fakeStateHistoryStore) SaveAlertStateHistory(cmd *models.SaveAlertStateHistoryCommand) error {
+	f.transitions = append(f.transitions, cmd.Transitions...)
+	return nil
+}
+
+func (f *fakeStateHistoryStore) GetAlertStateHistory(*models.GetAlertStateHistoryQuery) error {
+	return nil
+}
+
+func (f *fakeStateHistoryStore) DeleteExpiredAlertStateHistory(*models.DeleteExpiredAlertStateHistoryCommand) error {
+	return nil
+}
+
+func TestProcessEvalResultsRecordsStateHistory(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+
+	alertRule := &models.AlertRule{
+		OrgID:           1,
+		Title:           "test_title",
+		UID:             "test_alert_rule_uid",
+		NamespaceUID:    "test_namespace_uid",
+		IntervalSeconds: 10,
+		For:             15 * time.Second,
+		NoDataState:     models.NoData,
+	}
+	value := 1.0
+	results := []eval.Result{
+		{Instance: data.Labels{"instance_label": "test"}, State: eval.Normal, Value: new(float64), EvaluatedAt: evaluationTime},
+		{Instance: data.Labels{"instance_label": "test"}, State: eval.Alerting, Value: &value, EvaluatedAt: evaluationTime.Add(10 * time.Second)},
+		{Instance: data.Labels{"instance_label": "test"}, State: eval.Alerting, Value: &value, EvaluatedAt: evaluationTime.Add(20 * time.Second)},
+		{Instance: data.Labels{"instance_label": "test"}, State: eval.Alerting, Value: &value, EvaluatedAt: evaluationTime.Add(30 * time.Second)},
+		{Instance: data.Labels{"instance_label": "test"}, State: eval.NoData, EvaluatedAt: evaluationTime.Add(40 * time.Second)},
+	}
+
+	history := &fakeStateHistoryStore{}
+	st := state.NewManager(log.New("test_state_manager"), history, state.FlapDetection{})
+	for _, res := range results {
+		_ = st.ProcessEvalResults(alertRule, eval.Results{res})
+	}
+
+	expected := []struct {
+		from, to models.InstanceStateType
+		value    *float64
+		at       time.Time
+	}{
+		{models.InstanceStateNormal, models.InstanceStatePending, &value, evaluationTime.Add(10 * time.Second)},
+		{models.InstanceStatePending, models.InstanceStateFiring, &value, evaluationTime.Add(30 * time.Second)},
+		{models.InstanceStateFiring, models.InstanceStateNoData, nil, evaluationTime.Add(40 * time.Second)},
+	}
+	require.Len(t, history.transitions, len(expected))
+	for i, exp := range expected {
+		h := history.transitions[i]
+		require.Equal(t, int64(1), h.OrgID)
+		require.Equal(t, "test_alert_rule_uid", h.RuleUID)
+		require.Equal(t, "test", h.Labels["instance_label"])
+		require.Equal(t, exp.from, h.PreviousState)
+		require.Equal(t, exp.to, h.State)
+		require.Equal(t, exp.value, h.Value)
+		require.Equal(t, exp.at, h.TransitionedAt)
+	}
+}
+
+type fakeAnnotationsRepo struct {
+	annotations.Repository
+	items []*annotations.Item
+}
+
+func (f *fakeAnnotationsRepo) Save(item *annotations.Item) error {
+	f.items = append(f.items, item)
+	return nil
+}
+
+func TestProcessEvalResultsAnnotatesStateTransitions(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+
+	repo := &fakeAnnotationsRepo{}
+	origRepo := annotations.GetRepository()
+	annotations.SetRepository(repo)
+	t.Cleanup(func() { annotations.SetRepository(origRepo) })
+
+	bus.AddHandler("test", func(query *grafanaModels.GetDashboardQuery) error {
+		if query.Uid != "dashboard_uid" {
+			return grafanaModels.ErrDashboardNotFound
+		}
+		query.Result = &grafanaModels.Dashboard{Id: 7, Uid: query.Uid, OrgId: query.OrgId}
+		return nil
+	})
+
+	value := 1.0
+	results := []eval.Result{
+		{Instance: data.Labels{"instance_label": "test"}, State: eval.Normal, Value: new(float64), EvaluatedAt: evaluationTime},
+		{Instance: data.Labels{"instance_label": "test"}, State: eval.Alerting, Value: &value, EvaluatedAt: evaluationTime.Add(10 * time.Second)},
+	}
+
+	testCases := []struct {
+		desc           string
+		annotations    map[string]string
+		expDashboardID int64
+		expPanelID     int64
+	}{
+		{
+			desc: "an alert rule that is not linked to a panel",
+		},
+		{
+			desc:           "an alert rule linked to a panel",
+			annotations:    map[string]string{models.DashboardUIDAnnotation: "dashboard_uid", models.PanelIDAnnotation: "3"},
+			expDashboardID: 7,
+			expPanelID:     3,
+		},
+		{
+			desc:        "an alert rule linked to a missing dashboard",
+			annotations: map[string]string{models.DashboardUIDAnnotation: "missing_uid", models.PanelIDAnnotation: "3"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repo.items = nil
+			alertRule := &models.AlertRule{
+				ID:              42,
+				OrgID:           1,
+				Title:           "test_title",
+				UID:             "test_alert_rule_uid",
+				NamespaceUID:    "test_namespace_uid",
+				Annotations:     tc.annotations,
+				IntervalSeconds: 10,
+			}
+
+			st := state.NewManager(log.New("test_state_manager"), nil, state.FlapDetection{})
+			for _, res := range results {
+				_ = st.ProcessEvalResults(alertRule, eval.Results{res})
+			}
+
+			require.Len(t, repo.items, 1)
+			item := repo.items[0]
+			require.Equal(t, int64(1), item.OrgId)
+			require.Zero(t, item.AlertId)
+			require.Equal(t, tc.expDashboardID, item.DashboardId)
+			require.Equal(t, tc.expPanelID, item.PanelId)
+			require.Equal(t, "Normal", item.PrevState)
+			require.Equal(t, "Alerting", item.NewState)
+			require.Equal(t, evaluationTime.Add(10*time.Second).UnixNano()/int64(time.Millisecond), item.Epoch)
+			require.Equal(t, "test_alert_rule_uid", item.Data.Get("ruleUID").MustString())
+			require.Equal(t, 1.0, item.Data.Get("value").MustFloat64())
+			require.Equal(t, "test", item.Data.Get("labels").Get("instance_label").MustString())
+		})
+	}
+}
+
+func TestProcessEvalResultsResolveAfterAndFlapping(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+
+	// sequence returns results of the same instance evaluated every 10 seconds
+	sequence := func(states ...eval.State) []eval.Result {
+		results := make([]eval.Result, 0, len(states))
+		for i, s := range states {
+			results = append(results, eval.Result{
+				Instance:    data.Labels{"instance_label": "test"},
+				State:       s,
+				EvaluatedAt: evaluationTime.Add(time.Duration(i*10) * time.Second),
+			})
+		}
+		return results
+	}
+
+	testCases := []struct {
+		desc          string
+		resolveAfter  time.Duration
+		flapDetection state.FlapDetection
+		results       []eval.Result
+		expStates     []eval.State
+		expFlapping   []bool
+	}{
+		{
+			desc:      "without resolve after, a firing instance is resolved by the first normal result",
+			results:   sequence(eval.Alerting, eval.Normal, eval.Alerting, eval.Normal),
+			expStates: []eval.State{eval.Alerting, eval.Normal, eval.Alerting, eval.Normal},
+		},
+		{
+			desc:         "with resolve after, a firing instance is resolved once it has been normal long enough",
+			resolveAfter: 20 * time.Second,
+			results:      sequence(eval.Alerting, eval.Normal, eval.Normal, eval.Normal, eval.Normal),
+			expStates:    []eval.State{eval.Alerting, eval.Alerting, eval.Alerting, eval.Normal, eval.Normal},
+		},
+		{
+			desc:         "with resolve after, a firing result restarts the resolve after duration",
+			resolveAfter: 20 * time.Second,
+			results:      sequence(eval.Alerting, eval.Normal, eval.Normal, eval.Alerting, eval.Normal, eval.Normal, eval.Normal),
+			expStates:    []eval.State{eval.Alerting, eval.Alerting, eval.Alerting, eval.Alerting, eval.Alerting, eval.Alerting, eval.Normal},
+		},
+		{
+			desc:          "a flapping instance keeps firing until it stops flapping",
+			flapDetection: state.FlapDetection{Threshold: 3, Window: 40 * time.Second},
+			results:       sequence(eval.Alerting, eval.Normal, eval.Alerting, eval.Normal, eval.Alerting, eval.Normal, eval.Normal, eval.Normal),
+			expStates:     []eval.State{eval.Alerting, eval.Normal, eval.Alerting, eval.Alerting, eval.Alerting, eval.Alerting, eval.Normal, eval.Normal},
+			expFlapping:   []bool{false, false, true, true, true, false, false, false},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			alertRule := &models.AlertRule{
+				OrgID:           1,
+				Title:           "test_title",
+				UID:             "test_alert_rule_uid",
+				NamespaceUID:    "test_namespace_uid",
+				IntervalSeconds: 10,
+				ResolveAfter:    tc.resolveAfter,
+				Annotations:     map[string]string{},
+			}
+			st := state.NewManager(log.New("test_state_manager"), nil, tc.flapDetection)
+			for i, res := range tc.results {
+				states := st.ProcessEvalResults(alertRule, eval.Results{res})
+				require.Len(t, states, 1)
+				require.Equal(t, tc.expStates[i], states[0].State, "state after evaluation %d", i)
+				if tc.expFlapping != nil {
+					require.Equal(t, tc.expFlapping[i], states[0].Flapping, "flapping after evaluation %d", i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// +build integration
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestAlertProvisioningOperations(t *testing.T) {
+	dbstore := setupTestEnv(t, baseIntervalSeconds)
+
+	err := dbstore.SetAlertProvisioning(&models.SetAlertProvisioningCmd{Objects: []*models.AlertProvisioning{
+		{OrgID: 1, Kind: models.ProvisionedRuleGroup, NamespaceUID: "folder1", RuleGroup: "group1", File: "rules.yaml"},
+		{OrgID: 1, Kind: models.ProvisionedRuleGroup, NamespaceUID: "folder2", RuleGroup: "group1", File: "rules.yaml"},
+		{OrgID: 1, Kind: models.ProvisionedAlertmanagerConfiguration, File: "alertmanagers.yaml"},
+		{OrgID: 2, Kind: models.ProvisionedRuleGroup, NamespaceUID: "folder1", RuleGroup: "group1", File: "rules.yaml"},
+	}})
+	require.NoError(t, err)
+
+	t.Run("get the provisioned objects of an organization", func(t *testing.T) {
+		q := models.GetAlertProvisioningQuery{OrgID: 1}
+		require.NoError(t, dbstore.GetAlertProvisioning(&q))
+		require.Len(t, q.Result, 3)
+	})
+
+	t.Run("get the provisioned objects of a kind", func(t *testing.T) {
+		q := models.GetAlertProvisioningQuery{OrgID: 1, Kind: models.ProvisionedAlertmanagerConfiguration}
+		require.NoError(t, dbstore.GetAlertProvisioning(&q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, "alertmanagers.yaml", q.Result[0].File)
+	})
+
+	t.Run("get a provisioned rule group", func(t *testing.T) {
+		q := models.GetAlertProvisioningQuery{OrgID: 1, Kind: models.ProvisionedRuleGroup, NamespaceUID: "folder2", RuleGroup: "group1"}
+		require.NoError(t, dbstore.GetAlertProvisioning(&q))
+		require.Len(t, q.Result, 1)
+
+		q = models.GetAlertProvisioningQuery{OrgID: 1, Kind: models.ProvisionedRuleGroup, NamespaceUID: "folder2", RuleGroup: "group2"}
+		require.NoError(t, dbstore.GetAlertProvisioning(&q))
+		require.Len(t, q.Result, 0)
+	})
+
+	t.Run("the provisioned objects are replaced", func(t *testing.T) {
+		err := dbstore.SetAlertProvisioning(&models.SetAlertProvisioningCmd{Objects: []*models.AlertProvisioning{
+			{OrgID: 2, Kind: models.ProvisionedRuleGroup, NamespaceUID: "folder1", RuleGroup: "group2", File: "rules.yaml"},
+		}})
+		require.NoError(t, err)
+
+		q := models.GetAlertProvisioningQuery{OrgID: 1}
+		require.NoError(t, dbstore.GetAlertProvisioning(&q))
+		require.Len(t, q.Result, 0)
+
+		q = models.GetAlertProvisioningQuery{OrgID: 2}
+		require.NoError(t, dbstore.GetAlertProvisioning(&q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, "group2", q.Result[0].RuleGroup)
+	})
+}
@@ -0,0 +1,138 @@
+// +build integration
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestAlertStateHistoryOperations(t *testing.T) {
+	dbstore := setupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Now().Truncate(time.Second)
+	value := 42.5
+	transitions := []*models.AlertStateHistory{
+		{
+			OrgID:          1,
+			RuleUID:        "rule1",
+			Labels:         models.InstanceLabels{"instance": "server1"},
+			PreviousState:  models.InstanceStateNormal,
+			State:          models.InstanceStatePending,
+			Value:          &value,
+			TransitionedAt: now.Add(-3 * time.Hour),
+		},
+		{
+			OrgID:          1,
+			RuleUID:        "rule1",
+			Labels:         models.InstanceLabels{"instance": "server1"},
+			PreviousState:  models.InstanceStatePending,
+			State:          models.InstanceStateFiring,
+			Value:          &value,
+			TransitionedAt: now.Add(-2 * time.Hour),
+		},
+		{
+			OrgID:          1,
+			RuleUID:        "rule1",
+			Labels:         models.InstanceLabels{"instance": "server2"},
+			PreviousState:  models.InstanceStateNormal,
+			State:          models.InstanceStateNoData,
+			TransitionedAt: now.Add(-time.Hour),
+		},
+		{
+			OrgID:          1,
+			RuleUID:        "rule2",
+			Labels:         models.InstanceLabels{"instance": "server1"},
+			PreviousState:  models.InstanceStateFiring,
+			State:          models.InstanceStateError,
+			Error:          "failed to query data",
+			TransitionedAt: now,
+		},
+		{
+			OrgID:          2,
+			RuleUID:        "rule3",
+			Labels:         models.InstanceLabels{},
+			PreviousState:  models.InstanceStateNormal,
+			State:          models.InstanceStateFiring,
+			TransitionedAt: now,
+		},
+	}
+	require.NoError(t, dbstore.SaveAlertStateHistory(&models.SaveAlertStateHistoryCommand{Transitions: transitions}))
+
+	t.Run("invalid transitions are not saved", func(t *testing.T) {
+		err := dbstore.SaveAlertStateHistory(&models.SaveAlertStateHistoryCommand{Transitions: []*models.AlertStateHistory{
+			{OrgID: 1, RuleUID: "rule1", PreviousState: models.InstanceStateNormal, State: "Unknown"},
+		}})
+		require.EqualError(t, err, "alert state history is invalid because the state 'Unknown' is invalid")
+	})
+
+	cases := []struct {
+		name   string
+		query  models.GetAlertStateHistoryQuery
+		expIdx []int
+	}{
+		{
+			name:   "all transitions of an organization, most recent first",
+			query:  models.GetAlertStateHistoryQuery{OrgID: 1},
+			expIdx: []int{3, 2, 1, 0},
+		},
+		{
+			name:   "transitions of a rule",
+			query:  models.GetAlertStateHistoryQuery{OrgID: 1, RuleUID: "rule1"},
+			expIdx: []int{2, 1, 0},
+		},
+		{
+			name:   "transitions of the instances with some labels",
+			query:  models.GetAlertStateHistoryQuery{OrgID: 1, Labels: map[string]string{"instance": "server1"}},
+			expIdx: []int{3, 1, 0},
+		},
+		{
+			name:   "transitions in a time range",
+			query:  models.GetAlertStateHistoryQuery{OrgID: 1, From: now.Add(-150 * time.Minute), To: now.Add(-time.Hour)},
+			expIdx: []int{2, 1},
+		},
+		{
+			name:   "limited number of transitions",
+			query:  models.GetAlertStateHistoryQuery{OrgID: 1, Limit: 2},
+			expIdx: []int{3, 2},
+		},
+		{
+			name:   "limited number of transitions of the instances with some labels",
+			query:  models.GetAlertStateHistoryQuery{OrgID: 1, Labels: map[string]string{"instance": "server1"}, Limit: 2},
+			expIdx: []int{3, 1},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			query := c.query
+			require.NoError(t, dbstore.GetAlertStateHistory(&query))
+			require.Len(t, query.Result, len(c.expIdx))
+			for i, idx := range c.expIdx {
+				exp := transitions[idx]
+				res := query.Result[i]
+				require.Equal(t, exp.RuleUID, res.RuleUID)
+				require.Equal(t, exp.Labels, res.Labels)
+				require.Equal(t, exp.PreviousState, res.PreviousState)
+				require.Equal(t, exp.State, res.State)
+				require.Equal(t, exp.Value, res.Value)
+				require.Equal(t, exp.Error, res.Error)
+				require.True(t, exp.TransitionedAt.Equal(res.TransitionedAt))
+			}
+		})
+	}
+
+	t.Run("expired transitions are deleted", func(t *testing.T) {
+		cmd := models.DeleteExpiredAlertStateHistoryCommand{OlderThan: now.Add(-90 * time.Minute)}
+		require.NoError(t, dbstore.DeleteExpiredAlertStateHistory(&cmd))
+		require.Equal(t, int64(2), cmd.DeletedRows)
+
+		query := models.GetAlertStateHistoryQuery{OrgID: 1}
+		require.NoError(t, dbstore.GetAlertStateHistory(&query))
+		require.Len(t, query.Result, 2)
+	})
+}
@@ -0,0 +1,222 @@
+package alerting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// alertingStore is the part of the ngalert store used to provision alerting.
+type alertingStore interface {
+	GetRuleGroupAlertRules(query *ngmodels.ListRuleGroupAlertRulesQuery) error
+	UpdateRuleGroup(cmd store.UpdateRuleGroupCmd) error
+	GetLatestAlertmanagerConfiguration(query *ngmodels.GetLatestAlertmanagerConfigurationQuery) error
+	SaveAlertmanagerConfiguration(cmd *ngmodels.SaveAlertmanagerConfigurationCmd) error
+	store.ProvisioningStore
+}
+
+// alertmanagerProvider returns the running Alertmanager of an organization.
+type alertmanagerProvider interface {
+	AlertmanagerFor(orgID int64) (*notifier.Alertmanager, error)
+}
+
+// Provision scans a directory for provisioning config files and provisions the alert rule groups and
+// Alertmanager configurations in those files.
+func Provision(configDirectory string, sqlStore *sqlstore.SQLStore, alertmanagers *notifier.MultiOrgAlertmanager) error {
+	logger := log.New("provisioning.alerting")
+	ap := AlertingProvisioner{
+		log:         logger,
+		cfgProvider: &configReader{log: logger},
+		store: store.DBstore{
+			BaseInterval:           store.BaseIntervalSeconds * time.Second,
+			DefaultIntervalSeconds: store.DefaultIntervalSeconds,
+			SQLStore:               sqlStore,
+		},
+		alertmanagers: alertmanagers,
+		getOrCreateFolder: func(orgID int64, title string) (*models.Folder, error) {
+			return getOrCreateFolder(sqlStore, orgID, title)
+		},
+	}
+	return ap.applyChanges(configDirectory)
+}
+
+// AlertingProvisioner is responsible for provisioning alert rule groups and Alertmanager configurations
+// based on configuration read by the `configReader`. The provisioned objects are recorded so that they
+// are read-only in the API, until they are removed from the provisioning files.
+type AlertingProvisioner struct {
+	log               log.Logger
+	cfgProvider       *configReader
+	store             alertingStore
+	alertmanagers     alertmanagerProvider
+	getOrCreateFolder func(orgID int64, title string) (*models.Folder, error)
+}
+
+func (ap *AlertingProvisioner) apply(configs []*alertingAsConfig) error {
+	provisioned := make([]*ngmodels.AlertProvisioning, 0)
+	ruleGroups := make(map[string]string)
+	alertmanagers := make(map[int64]string)
+	for _, cfg := range configs {
+		for _, group := range cfg.RuleGroups {
+			orgID, err := resolveOrgID(group.OrgID, group.OrgName)
+			if err != nil {
+				return err
+			}
+			folder, err := ap.getOrCreateFolder(orgID, group.Folder)
+			if err != nil {
+				return fmt.Errorf("failed to get folder %s of rule group %s: %w", group.Folder, group.Group.Name, err)
+			}
+
+			key := fmt.Sprintf("%d/%s/%s", orgID, folder.Uid, group.Group.Name)
+			if file, ok := ruleGroups[key]; ok {
+				return fmt.Errorf("rule group %s of folder %s is provisioned in both %s and %s", group.Group.Name, group.Folder, file, cfg.File)
+			}
+			ruleGroups[key] = cfg.File
+
+			if err := ap.applyRuleGroup(orgID, folder, group.Group); err != nil {
+				return fmt.Errorf("failed to provision rule group %s of folder %s: %w", group.Group.Name, group.Folder, err)
+			}
+			provisioned = append(provisioned, &ngmodels.AlertProvisioning{
+				OrgID:        orgID,
+				Kind:         ngmodels.ProvisionedRuleGroup,
+				NamespaceUID: folder.Uid,
+				RuleGroup:    group.Group.Name,
+				File:         cfg.File,
+			})
+		}
+
+		for _, am := range cfg.Alertmanagers {
+			orgID, err := resolveOrgID(am.OrgID, am.OrgName)
+			if err != nil {
+				return err
+			}
+
+			if file, ok := alertmanagers[orgID]; ok {
+				return fmt.Errorf("the Alertmanager configuration of organization %d is provisioned in both %s and %s", orgID, file, cfg.File)
+			}
+			alertmanagers[orgID] = cfg.File
+
+			if err := ap.applyAlertmanagerConfig(orgID, am.Config); err != nil {
+				return fmt.Errorf("failed to provision the Alertmanager configuration of organization %d: %w", orgID, err)
+			}
+			provisioned = append(provisioned, &ngmodels.AlertProvisioning{
+				OrgID: orgID,
+				Kind:  ngmodels.ProvisionedAlertmanagerConfiguration,
+				File:  cfg.File,
+			})
+		}
+	}
+
+	// the objects that are no longer in the provisioning files become editable again
+	return ap.store.SetAlertProvisioning(&ngmodels.SetAlertProvisioningCmd{Objects: provisioned})
+}
+
+// applyRuleGroup updates the rule group like the import of a rule file does: the rules are matched to the existing
+// rules of the group, and the group is only updated if a rule changed.
+func (ap *AlertingProvisioner) applyRuleGroup(orgID int64, folder *models.Folder, group apimodels.PostableRuleGroupConfig) error {
+	q := ngmodels.ListRuleGroupAlertRulesQuery{OrgID: orgID, NamespaceUID: folder.Uid, RuleGroup: group.Name}
+	if err := ap.store.GetRuleGroupAlertRules(&q); err != nil {
+		return err
+	}
+
+	changes, err := store.DiffRuleGroup(folder.Title, group, q.Result)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, change := range changes {
+		changed = changed || change.Action != apimodels.RuleImportUnchanged
+	}
+	if !changed {
+		ap.log.Debug("Rule group from configuration is unchanged", "folder", folder.Title, "group", group.Name)
+		return nil
+	}
+
+	ap.log.Info("Updating rule group from configuration", "folder", folder.Title, "group", group.Name)
+	return ap.store.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+		OrgID:           orgID,
+		NamespaceUID:    folder.Uid,
+		RuleGroupConfig: group,
+	})
+}
+
+// applyAlertmanagerConfig saves the configuration if it changed, and applies it if the Alertmanager of
+// the organization is running. Otherwise, the Alertmanager loads the configuration when it starts.
+func (ap *AlertingProvisioner) applyAlertmanagerConfig(orgID int64, cfg *apimodels.PostableUserConfig) error {
+	rawConfig, err := json.Marshal(&cfg)
+	if err != nil {
+		return fmt.Errorf("failed to serialize to the Alertmanager configuration: %w", err)
+	}
+
+	q := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: orgID}
+	if err := ap.store.GetLatestAlertmanagerConfiguration(&q); err != nil && !errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return err
+	}
+	if q.Result != nil && q.Result.AlertmanagerConfiguration == string(rawConfig) {
+		ap.log.Debug("Alertmanager configuration from configuration is unchanged", "org", orgID)
+		return nil
+	}
+
+	ap.log.Info("Updating Alertmanager configuration from configuration", "org", orgID)
+	am, err := ap.alertmanagers.AlertmanagerFor(orgID)
+	if err != nil {
+		if !errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+			return err
+		}
+		return ap.store.SaveAlertmanagerConfiguration(&ngmodels.SaveAlertmanagerConfigurationCmd{
+			OrgID:                     orgID,
+			AlertmanagerConfiguration: string(rawConfig),
+			ConfigurationVersion:      fmt.Sprintf("v%d", ngmodels.AlertConfigurationVersion),
+		})
+	}
+	return am.SaveAndApplyConfig(cfg)
+}
+
+func (ap *AlertingProvisioner) applyChanges(configPath string) error {
+	configs, err := ap.cfgProvider.readConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	return ap.apply(configs)
+}
+
+func resolveOrgID(orgID int64, orgName string) (int64, error) {
+	if orgID == 0 && orgName != "" {
+		getOrgQuery := &models.GetOrgByNameQuery{Name: orgName}
+		if err := bus.Dispatch(getOrgQuery); err != nil {
+			return 0, fmt.Errorf("failed to get organization %s: %w", orgName, err)
+		}
+		return getOrgQuery.Result.Id, nil
+	}
+	if err := utils.CheckOrgExists(orgID); err != nil {
+		return 0, fmt.Errorf("failed to get organization %d: %w", orgID, err)
+	}
+	return orgID, nil
+}
+
+// getOrCreateFolder returns the folder of the organization with the title, which is created if it doesn't exist.
+func getOrCreateFolder(sqlStore *sqlstore.SQLStore, orgID int64, title string) (*models.Folder, error) {
+	user := &models.SignedInUser{
+		UserId:  0,
+		OrgRole: models.ROLE_ADMIN,
+		OrgId:   orgID,
+	}
+	s := dashboards.NewFolderService(orgID, user, sqlStore)
+	folder, err := s.GetFolderByTitle(title)
+	if errors.Is(err, models.ErrFolderNotFound) {
+		return s.CreateFolder(title, "")
+	}
+	return folder, err
+}
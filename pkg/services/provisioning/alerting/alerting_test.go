@@ -0,0 +1,152 @@
+package alerting
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestAlertingProvisioner(t *testing.T) {
+	bus.AddHandler("test", func(query *models.GetOrgByNameQuery) error {
+		if query.Name == "Org 2" {
+			query.Result = &models.Org{Id: 2}
+			return nil
+		}
+		return models.ErrOrgNotFound
+	})
+	bus.AddHandler("test", func(query *models.GetOrgByIdQuery) error {
+		if query.Id > 2 {
+			return models.ErrOrgNotFound
+		}
+		query.Result = &models.Org{Id: query.Id}
+		return nil
+	})
+
+	newProvisioner := func(st *fakeAlertingStore) *AlertingProvisioner {
+		return &AlertingProvisioner{
+			log:           log.New("test"),
+			cfgProvider:   &configReader{log: log.New("test")},
+			store:         st,
+			alertmanagers: fakeAlertmanagerProvider{},
+			getOrCreateFolder: func(orgID int64, title string) (*models.Folder, error) {
+				return &models.Folder{Uid: title + "-uid", Title: title}, nil
+			},
+		}
+	}
+
+	t.Run("Should return error when config reader returns error", func(t *testing.T) {
+		ap := newProvisioner(&fakeAlertingStore{})
+		err := ap.applyChanges(brokenYaml)
+		require.Error(t, err)
+	})
+
+	t.Run("Should apply configurations and record the provisioned objects", func(t *testing.T) {
+		err := os.Setenv("FOLDER_VAR", "infra")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = os.Unsetenv("FOLDER_VAR")
+		})
+
+		st := &fakeAlertingStore{}
+		ap := newProvisioner(st)
+		require.NoError(t, ap.applyChanges(correctProperties))
+
+		// the empty rule group of Org 2 is unchanged
+		require.Len(t, st.updatedRuleGroups, 1)
+		require.Equal(t, int64(1), st.updatedRuleGroups[0].OrgID)
+		require.Equal(t, "infra-uid", st.updatedRuleGroups[0].NamespaceUID)
+		require.Equal(t, "group", st.updatedRuleGroups[0].RuleGroupConfig.Name)
+
+		// the Alertmanager of the organization is not running, so the configuration is only saved
+		require.Len(t, st.savedConfigs, 1)
+		require.Equal(t, int64(2), st.savedConfigs[0].OrgID)
+
+		require.Len(t, st.provisioned, 3)
+		require.Equal(t, ngmodels.ProvisionedAlertmanagerConfiguration, st.provisioned[0].Kind)
+		require.Equal(t, int64(2), st.provisioned[0].OrgID)
+		require.Equal(t, ngmodels.ProvisionedRuleGroup, st.provisioned[1].Kind)
+		require.Equal(t, int64(1), st.provisioned[1].OrgID)
+		require.Equal(t, "infra-uid", st.provisioned[1].NamespaceUID)
+		require.Equal(t, "group", st.provisioned[1].RuleGroup)
+		require.Equal(t, ngmodels.ProvisionedRuleGroup, st.provisioned[2].Kind)
+		require.Equal(t, int64(2), st.provisioned[2].OrgID)
+		require.Equal(t, "folder-uid", st.provisioned[2].NamespaceUID)
+
+		// an unchanged Alertmanager configuration is not saved again
+		st.latestConfig = &ngmodels.AlertConfiguration{OrgID: 2, AlertmanagerConfiguration: st.savedConfigs[0].AlertmanagerConfiguration}
+		require.NoError(t, ap.applyChanges(correctProperties))
+		require.Len(t, st.savedConfigs, 1)
+	})
+
+	t.Run("Should return error when a rule group is provisioned twice", func(t *testing.T) {
+		ap := newProvisioner(&fakeAlertingStore{})
+		err := ap.apply([]*alertingAsConfig{
+			{File: "a.yaml", RuleGroups: []*ruleGroupFromConfig{{OrgID: 1, Folder: "folder", Group: apimodels.PostableRuleGroupConfig{Name: "group"}}}},
+			{File: "b.yaml", RuleGroups: []*ruleGroupFromConfig{{OrgID: 1, Folder: "folder", Group: apimodels.PostableRuleGroupConfig{Name: "group"}}}},
+		})
+		require.EqualError(t, err, "rule group group of folder folder is provisioned in both a.yaml and b.yaml")
+	})
+
+	t.Run("Should return error when the organization does not exist", func(t *testing.T) {
+		ap := newProvisioner(&fakeAlertingStore{})
+		err := ap.apply([]*alertingAsConfig{
+			{File: "a.yaml", RuleGroups: []*ruleGroupFromConfig{{OrgID: 3, Folder: "folder"}}},
+		})
+		require.True(t, errors.Is(err, models.ErrOrgNotFound))
+	})
+}
+
+type fakeAlertingStore struct {
+	latestConfig      *ngmodels.AlertConfiguration
+	updatedRuleGroups []store.UpdateRuleGroupCmd
+	savedConfigs      []*ngmodels.SaveAlertmanagerConfigurationCmd
+	provisioned       []*ngmodels.AlertProvisioning
+}
+
+func (f *fakeAlertingStore) GetRuleGroupAlertRules(query *ngmodels.ListRuleGroupAlertRulesQuery) error {
+	query.Result = nil
+	return nil
+}
+
+func (f *fakeAlertingStore) UpdateRuleGroup(cmd store.UpdateRuleGroupCmd) error {
+	f.updatedRuleGroups = append(f.updatedRuleGroups, cmd)
+	return nil
+}
+
+func (f *fakeAlertingStore) GetLatestAlertmanagerConfiguration(query *ngmodels.GetLatestAlertmanagerConfigurationQuery) error {
+	if f.latestConfig == nil {
+		return store.ErrNoAlertmanagerConfiguration
+	}
+	query.Result = f.latestConfig
+	return nil
+}
+
+func (f *fakeAlertingStore) SaveAlertmanagerConfiguration(cmd *ngmodels.SaveAlertmanagerConfigurationCmd) error {
+	f.savedConfigs = append(f.savedConfigs, cmd)
+	return nil
+}
+
+func (f *fakeAlertingStore) GetAlertProvisioning(query *ngmodels.GetAlertProvisioningQuery) error {
+	return nil
+}
+
+func (f *fakeAlertingStore) SetAlertProvisioning(cmd *ngmodels.SetAlertProvisioningCmd) error {
+	f.provisioned = cmd.Objects
+	return nil
+}
+
+type fakeAlertmanagerProvider struct{}
+
+func (fakeAlertmanagerProvider) AlertmanagerFor(orgID int64) (*notifier.Alertmanager, error) {
+	return nil, notifier.ErrNoAlertmanagerForOrg
+}
@@ -0,0 +1,137 @@
+package alerting
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+type configReader struct {
+	log log.Logger
+}
+
+func (cr *configReader) readConfig(path string) ([]*alertingAsConfig, error) {
+	var configs []*alertingAsConfig
+	cr.log.Debug("Looking for alerting provisioning files", "path", path)
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Can't read alerting provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing alerting provisioning file", "path", path, "file.Name", file.Name())
+			cfg, err := cr.parseAlertingConfig(path, file)
+			if err != nil {
+				return nil, err
+			}
+
+			if cfg != nil {
+				configs = append(configs, cfg)
+			}
+		}
+	}
+
+	cr.log.Debug("Validating alerting provisioning files")
+	if err := validateRequiredField(configs); err != nil {
+		return nil, err
+	}
+
+	checkOrgIDAndOrgName(configs)
+
+	return configs, nil
+}
+
+func (cr *configReader) parseAlertingConfig(path string, file os.FileInfo) (*alertingAsConfig, error) {
+	filename, err := filepath.Abs(filepath.Join(path, file.Name()))
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rules and the Alertmanager configuration are only decoded properly by yaml.v3.
+	var cfg *alertingAsConfigV0
+	if err := yaml.Unmarshal(yamlFile, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	r, err := cfg.mapToAlertingFromConfig(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	return r, nil
+}
+
+func validateRequiredField(configs []*alertingAsConfig) error {
+	for i := range configs {
+		var errStrings []string
+		for index, group := range configs[i].RuleGroups {
+			if group.Group.Name == "" {
+				errStrings = append(
+					errStrings,
+					fmt.Sprintf("rule group item %d in configuration doesn't contain required field name", index+1),
+				)
+			}
+
+			if group.Folder == "" {
+				errStrings = append(
+					errStrings,
+					fmt.Sprintf("rule group item %d in configuration doesn't contain required field folder", index+1),
+				)
+			}
+
+			for ruleIndex, rule := range group.Group.Rules {
+				if rule.GrafanaManagedAlert == nil || rule.GrafanaManagedAlert.Title == "" {
+					errStrings = append(
+						errStrings,
+						fmt.Sprintf("rule %d of rule group item %d in configuration isn't a Grafana managed alert with a title", ruleIndex+1, index+1),
+					)
+				}
+			}
+		}
+
+		if len(errStrings) != 0 {
+			return fmt.Errorf("%s: %s", configs[i].File, strings.Join(errStrings, "\n"))
+		}
+	}
+
+	return nil
+}
+
+func checkOrgIDAndOrgName(configs []*alertingAsConfig) {
+	for i := range configs {
+		for _, group := range configs[i].RuleGroups {
+			group.OrgID = checkOrgID(group.OrgID, group.OrgName)
+		}
+
+		for _, am := range configs[i].Alertmanagers {
+			am.OrgID = checkOrgID(am.OrgID, am.OrgName)
+		}
+	}
+}
+
+// checkOrgID returns the main organization if neither an organization ID nor name is set, and 0 if the
+// organization is only given by its name.
+func checkOrgID(orgID int64, orgName string) int64 {
+	if orgID < 1 {
+		if orgName == "" {
+			return 1
+		}
+		return 0
+	}
+	return orgID
+}
@@ -0,0 +1,105 @@
+package alerting
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const (
+	correctProperties   = "./testdata/test-configs/correct-properties"
+	brokenYaml          = "./testdata/test-configs/broken-yaml"
+	emptyFolder         = "./testdata/test-configs/empty_folder"
+	noRequiredFields    = "./testdata/test-configs/no-required-fields"
+	invalidAlertmanager = "./testdata/test-configs/invalid-alertmanager"
+)
+
+func TestConfigReader(t *testing.T) {
+	t.Run("Broken yaml should return error", func(t *testing.T) {
+		reader := &configReader{log: log.New("test logger")}
+		_, err := reader.readConfig(brokenYaml)
+		require.Error(t, err)
+	})
+
+	t.Run("Skip invalid directory", func(t *testing.T) {
+		reader := &configReader{log: log.New("test logger")}
+		cfg, err := reader.readConfig(emptyFolder)
+		require.NoError(t, err)
+		require.Len(t, cfg, 0)
+	})
+
+	t.Run("Missing required fields should return error", func(t *testing.T) {
+		reader := &configReader{log: log.New("test logger")}
+		_, err := reader.readConfig(noRequiredFields)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "rule group item 1 in configuration doesn't contain required field folder")
+		require.Contains(t, err.Error(), "rule 1 of rule group item 1 in configuration isn't a Grafana managed alert with a title")
+		require.Contains(t, err.Error(), "rule group item 2 in configuration doesn't contain required field name")
+	})
+
+	t.Run("Invalid Alertmanager configuration should return error", func(t *testing.T) {
+		reader := &configReader{log: log.New("test logger")}
+		_, err := reader.readConfig(invalidAlertmanager)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no route provided in config")
+	})
+
+	t.Run("Can read correct properties", func(t *testing.T) {
+		err := os.Setenv("FOLDER_VAR", "infra")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = os.Unsetenv("FOLDER_VAR")
+		})
+
+		reader := &configReader{log: log.New("test logger")}
+		cfgs, err := reader.readConfig(correctProperties)
+		require.NoError(t, err)
+		require.Len(t, cfgs, 2)
+
+		// files are read in alphabetical order
+		amCfg := cfgs[0]
+		require.Len(t, amCfg.RuleGroups, 0)
+		require.Len(t, amCfg.Alertmanagers, 1)
+		am := amCfg.Alertmanagers[0]
+		require.Equal(t, int64(2), am.OrgID)
+		// templates are not expanded like environment variables
+		require.Equal(t, `{{ define "instance" }}{{ $labels.instance }}{{ end }}`, am.Config.TemplateFiles["instance"])
+		require.Equal(t, "team", am.Config.AlertmanagerConfig.Route.Receiver)
+		require.Len(t, am.Config.AlertmanagerConfig.InhibitRules, 1)
+		require.Len(t, am.Config.AlertmanagerConfig.Receivers, 1)
+		receivers := am.Config.AlertmanagerConfig.Receivers[0].GrafanaManagedReceivers
+		require.Len(t, receivers, 1)
+		require.Equal(t, "email", receivers[0].Type)
+		require.True(t, receivers[0].DisableResolveMessage)
+		require.Equal(t, "team@example.com", receivers[0].Settings.Get("addresses").MustString())
+
+		rulesCfg := cfgs[1]
+		require.Len(t, rulesCfg.Alertmanagers, 0)
+		require.Len(t, rulesCfg.RuleGroups, 2)
+
+		group := rulesCfg.RuleGroups[0]
+		require.Equal(t, int64(1), group.OrgID)
+		require.Equal(t, "infra", group.Folder)
+		require.Equal(t, "group", group.Group.Name)
+		require.Equal(t, model.Duration(time.Minute), group.Group.Interval)
+		require.Len(t, group.Group.Rules, 1)
+		rule := group.Group.Rules[0]
+		require.Equal(t, model.Duration(5*time.Minute), rule.For)
+		require.Equal(t, "{{ $labels.instance }} is down", rule.Annotations["summary"])
+		require.Equal(t, "Instance down", rule.GrafanaManagedAlert.Title)
+		require.Equal(t, apimodels.OK, rule.GrafanaManagedAlert.NoDataState)
+		require.Len(t, rule.GrafanaManagedAlert.Data, 1)
+		require.Equal(t, "datasource-uid", rule.GrafanaManagedAlert.Data[0].DatasourceUID)
+		require.JSONEq(t, `{"expr":"up == 0"}`, string(rule.GrafanaManagedAlert.Data[0].Model))
+
+		group = rulesCfg.RuleGroups[1]
+		require.Equal(t, int64(0), group.OrgID)
+		require.Equal(t, "Org 2", group.OrgName)
+	})
+}
@@ -0,0 +1,126 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+// alertingAsConfig is a normalized data object for alerting config data. Any config version should be mappable
+// to this type.
+type alertingAsConfig struct {
+	// File is the path of the provisioning file.
+	File          string
+	RuleGroups    []*ruleGroupFromConfig
+	Alertmanagers []*alertmanagerFromConfig
+}
+
+type ruleGroupFromConfig struct {
+	OrgID   int64
+	OrgName string
+	Folder  string
+	Group   apimodels.PostableRuleGroupConfig
+}
+
+type alertmanagerFromConfig struct {
+	OrgID   int64
+	OrgName string
+	Config  *apimodels.PostableUserConfig
+}
+
+// alertingAsConfigV0 is a mapping for zero version configs. This is mapped to its normalised version.
+type alertingAsConfigV0 struct {
+	RuleGroups    []*ruleGroupFromConfigV0    `json:"groups" yaml:"groups"`
+	Alertmanagers []*alertmanagerFromConfigV0 `json:"alertmanagers" yaml:"alertmanagers"`
+}
+
+// ruleGroupFromConfigV0 is a rule group in the format of the rule files exported with the rule file API.
+// Environment variables are not expanded in the rules, as they contain templates.
+type ruleGroupFromConfigV0 struct {
+	OrgID    values.Int64Value                    `json:"org_id" yaml:"org_id"`
+	OrgName  values.StringValue                   `json:"org_name" yaml:"org_name"`
+	Folder   values.StringValue                   `json:"folder" yaml:"folder"`
+	Name     values.StringValue                   `json:"name" yaml:"name"`
+	Interval model.Duration                       `json:"interval" yaml:"interval"`
+	Rules    []apimodels.PostableExtendedRuleNode `json:"rules" yaml:"rules"`
+}
+
+// alertmanagerFromConfigV0 is the Alertmanager configuration of an organization, in the format of the Alertmanager
+// configuration API. Environment variables are not expanded in the configuration, as it contains templates.
+type alertmanagerFromConfigV0 struct {
+	OrgID              values.Int64Value      `json:"org_id" yaml:"org_id"`
+	OrgName            values.StringValue     `json:"org_name" yaml:"org_name"`
+	TemplateFiles      map[string]string      `json:"template_files" yaml:"template_files"`
+	AlertmanagerConfig map[string]interface{} `json:"alertmanager_config" yaml:"alertmanager_config"`
+}
+
+// mapToAlertingFromConfig maps config syntax to a normalized alertingAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *alertingAsConfigV0) mapToAlertingFromConfig(file string) (*alertingAsConfig, error) {
+	r := &alertingAsConfig{File: file}
+	if cfg == nil {
+		return r, nil
+	}
+
+	for _, group := range cfg.RuleGroups {
+		r.RuleGroups = append(r.RuleGroups, &ruleGroupFromConfig{
+			OrgID:   group.OrgID.Value(),
+			OrgName: group.OrgName.Value(),
+			Folder:  group.Folder.Value(),
+			Group: apimodels.PostableRuleGroupConfig{
+				Name:     group.Name.Value(),
+				Interval: group.Interval,
+				Rules:    group.Rules,
+			},
+		})
+	}
+
+	for _, am := range cfg.Alertmanagers {
+		amConfig, err := am.toPostableUserConfig()
+		if err != nil {
+			return nil, err
+		}
+		r.Alertmanagers = append(r.Alertmanagers, &alertmanagerFromConfig{
+			OrgID:   am.OrgID.Value(),
+			OrgName: am.OrgName.Value(),
+			Config:  amConfig,
+		})
+	}
+
+	return r, nil
+}
+
+// toPostableUserConfig converts the configuration like the Alertmanager configuration API does, so that
+// it is validated the same way.
+func (am *alertmanagerFromConfigV0) toPostableUserConfig() (*apimodels.PostableUserConfig, error) {
+	if am.AlertmanagerConfig == nil {
+		return nil, fmt.Errorf("the Alertmanager configuration of organization %s doesn't contain required field alertmanager_config", am.orgString())
+	}
+
+	raw, err := json.Marshal(map[string]interface{}{
+		"template_files":      am.TemplateFiles,
+		"alertmanager_config": am.AlertmanagerConfig,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := notifier.Load(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Alertmanager configuration of organization %s: %w", am.orgString(), err)
+	}
+	return cfg, nil
+}
+
+func (am *alertmanagerFromConfigV0) orgString() string {
+	if name := am.OrgName.Value(); name != "" {
+		return name
+	}
+	return strconv.FormatInt(am.OrgID.Value(), 10)
+}
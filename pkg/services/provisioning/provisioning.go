@@ -8,6 +8,8 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	plugifaces "github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
 	"github.com/grafana/grafana/pkg/services/provisioning/notifiers"
@@ -23,6 +25,7 @@ type ProvisioningService interface {
 	ProvisionDatasources() error
 	ProvisionPlugins() error
 	ProvisionNotifications() error
+	ProvisionAlerting() error
 	ProvisionDashboards() error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
@@ -44,6 +47,7 @@ func NewProvisioningServiceImpl() *provisioningServiceImpl {
 		provisionNotifiers:      notifiers.Provision,
 		provisionDatasources:    datasources.Provision,
 		provisionPlugins:        plugins.Provision,
+		provisionAlerting:       alerting.Provision,
 	}
 }
 
@@ -53,6 +57,7 @@ func newProvisioningServiceImpl(
 	provisionNotifiers func(string) error,
 	provisionDatasources func(string) error,
 	provisionPlugins func(string, plugifaces.Manager) error,
+	provisionAlerting func(string, *sqlstore.SQLStore, *notifier.MultiOrgAlertmanager) error,
 ) *provisioningServiceImpl {
 	return &provisioningServiceImpl{
 		log:                     log.New("provisioning"),
@@ -60,13 +65,15 @@ func newProvisioningServiceImpl(
 		provisionNotifiers:      provisionNotifiers,
 		provisionDatasources:    provisionDatasources,
 		provisionPlugins:        provisionPlugins,
+		provisionAlerting:       provisionAlerting,
 	}
 }
 
 type provisioningServiceImpl struct {
-	Cfg                     *setting.Cfg       `inject:""`
-	SQLStore                *sqlstore.SQLStore `inject:""`
-	PluginManager           plugifaces.Manager `inject:""`
+	Cfg                     *setting.Cfg                   `inject:""`
+	SQLStore                *sqlstore.SQLStore             `inject:""`
+	PluginManager           plugifaces.Manager             `inject:""`
+	Alertmanagers           *notifier.MultiOrgAlertmanager `inject:""`
 	log                     log.Logger
 	pollingCtxCancel        context.CancelFunc
 	newDashboardProvisioner dashboards.DashboardProvisionerFactory
@@ -74,6 +81,7 @@ type provisioningServiceImpl struct {
 	provisionNotifiers      func(string) error
 	provisionDatasources    func(string) error
 	provisionPlugins        func(string, plugifaces.Manager) error
+	provisionAlerting       func(string, *sqlstore.SQLStore, *notifier.MultiOrgAlertmanager) error
 	mutex                   sync.Mutex
 }
 
@@ -97,6 +105,11 @@ func (ps *provisioningServiceImpl) RunInitProvisioners() error {
 		return err
 	}
 
+	err = ps.ProvisionAlerting()
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -147,6 +160,17 @@ func (ps *provisioningServiceImpl) ProvisionNotifications() error {
 	return errutil.Wrap("Alert notification provisioning error", err)
 }
 
+// ProvisionAlerting provisions the rule groups and Alertmanager configurations of unified alerting,
+// if it is enabled.
+func (ps *provisioningServiceImpl) ProvisionAlerting() error {
+	if !ps.Cfg.IsNgAlertEnabled() {
+		return nil
+	}
+	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
+	err := ps.provisionAlerting(alertingPath, ps.SQLStore, ps.Alertmanagers)
+	return errutil.Wrap("Alerting provisioning error", err)
+}
+
 func (ps *provisioningServiceImpl) ProvisionDashboards() error {
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
 	dashProvisioner, err := ps.newDashboardProvisioner(dashboardPath, ps.SQLStore)
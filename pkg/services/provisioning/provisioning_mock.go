@@ -7,6 +7,7 @@ type Calls struct {
 	ProvisionDatasources                []interface{}
 	ProvisionPlugins                    []interface{}
 	ProvisionNotifications              []interface{}
+	ProvisionAlerting                   []interface{}
 	ProvisionDashboards                 []interface{}
 	GetDashboardProvisionerResolvedPath []interface{}
 	GetAllowUIUpdatesFromConfig         []interface{}
@@ -19,6 +20,7 @@ type ProvisioningServiceMock struct {
 	ProvisionDatasourcesFunc                func() error
 	ProvisionPluginsFunc                    func() error
 	ProvisionNotificationsFunc              func() error
+	ProvisionAlertingFunc                   func() error
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
@@ -63,6 +65,14 @@ func (mock *ProvisioningServiceMock) ProvisionNotifications() error {
 	return nil
 }
 
+func (mock *ProvisioningServiceMock) ProvisionAlerting() error {
+	mock.Calls.ProvisionAlerting = append(mock.Calls.ProvisionAlerting, nil)
+	if mock.ProvisionAlertingFunc != nil {
+		return mock.ProvisionAlertingFunc()
+	}
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) ProvisionDashboards() error {
 	mock.Calls.ProvisionDashboards = append(mock.Calls.ProvisionDashboards, nil)
 	if mock.ProvisionDashboardsFunc != nil {
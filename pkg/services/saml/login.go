@@ -0,0 +1,272 @@
+package saml
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	crewjam "github.com/crewjam/saml"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/login"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	ErrUnsolicitedResponse = errors.New("SAML response was not requested by Grafana")
+	ErrInvalidResponse     = errors.New("invalid SAML response")
+	ErrAssertionReplayed   = errors.New("SAML assertion was already used")
+)
+
+// authnRequestTTL is how long the user has to log in with the IdP.
+const authnRequestTTL = 10 * time.Minute
+
+// authnRequest is an authentication request sent to the IdP, stored under the relay state of the request.
+// It is kept in the remote cache rather than in a cookie since the IdP posts the response cross-site.
+type authnRequest struct {
+	RequestID  string
+	RedirectTo string
+}
+
+// Message is a SAML message for the IdP. The browser is either redirected to RedirectURL,
+// or posts the message by submitting the HTML Form.
+type Message struct {
+	RedirectURL string
+	Form        []byte
+}
+
+type samlMessage interface {
+	Redirect(relayState string) *url.URL
+	Post(relayState string) []byte
+}
+
+func newMessage(m samlMessage, binding, relayState string) *Message {
+	if binding == crewjam.HTTPRedirectBinding {
+		return &Message{RedirectURL: m.Redirect(relayState).String()}
+	}
+	return &Message{Form: m.Post(relayState)}
+}
+
+// idpLocation returns the binding and the location of an IdP service, preferring the redirect binding.
+func idpLocation(location func(binding string) string) (string, string, error) {
+	for _, binding := range []string{crewjam.HTTPRedirectBinding, crewjam.HTTPPostBinding} {
+		if l := location(binding); l != "" {
+			return binding, l, nil
+		}
+	}
+	return "", "", errors.New("no location with a supported binding found in IdP metadata")
+}
+
+func relayStateKey(relayState string) string {
+	return "saml-relay-state-" + relayState
+}
+
+func assertionKey(id string) string {
+	return "saml-assertion-" + id
+}
+
+// AuthnRequest returns the authentication request that starts a login with the IdP.
+// The user is redirected to redirectTo once logged in.
+func (s *SAMLService) AuthnRequest(ctx context.Context, redirectTo string) (*Message, error) {
+	sp, err := s.serviceProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	binding, location, err := idpLocation(sp.GetSSOBindingLocation)
+	if err != nil {
+		return nil, err
+	}
+	req, err := sp.MakeAuthenticationRequest(location)
+	if err != nil {
+		return nil, err
+	}
+
+	relayState, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.RemoteCache.Set(relayStateKey(relayState), &authnRequest{RequestID: req.ID, RedirectTo: redirectTo}, authnRequestTTL); err != nil {
+		return nil, err
+	}
+
+	return newMessage(req, binding, relayState), nil
+}
+
+// ParseResponse verifies the response the IdP posted to the assertion consumer service, and returns the
+// user it asserts with the redirect_to of the authentication request. The response must answer an
+// authentication request of Grafana, unless IdP-initiated login is allowed and it comes with the configured relay state.
+func (s *SAMLService) ParseResponse(ctx context.Context, req *http.Request) (*models.ExternalUserInfo, string, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, "", err
+	}
+
+	sp, err := s.serviceProvider(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	relayState := req.PostForm.Get("RelayState")
+	var possibleRequestIDs []string
+	var redirectTo string
+	authnReq, err := s.takeAuthnRequest(relayState)
+	switch {
+	case err == nil:
+		possibleRequestIDs = []string{authnReq.RequestID}
+		redirectTo = authnReq.RedirectTo
+	case errors.Is(err, remotecache.ErrCacheItemNotFound):
+		if !s.Cfg.SAML.AllowIdPInitiated || relayState != s.Cfg.SAML.RelayState {
+			return nil, "", ErrUnsolicitedResponse
+		}
+		idpInitiatedSP := *sp
+		idpInitiatedSP.AllowIDPInitiated = true
+		sp = &idpInitiatedSP
+	default:
+		return nil, "", err
+	}
+
+	assertion, err := sp.ParseResponse(req, possibleRequestIDs)
+	if err != nil {
+		var invalidErr *crewjam.InvalidResponseError
+		if errors.As(err, &invalidErr) {
+			err = invalidErr.PrivateErr
+		}
+		s.log.Warn("Invalid SAML response", "err", err)
+		return nil, "", ErrInvalidResponse
+	}
+
+	// the assertions are rejected once they expire, so they are only remembered until then
+	if _, err := s.RemoteCache.Get(assertionKey(assertion.ID)); err == nil {
+		return nil, "", ErrAssertionReplayed
+	} else if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return nil, "", err
+	}
+	if err := s.RemoteCache.Set(assertionKey(assertion.ID), true, crewjam.MaxIssueDelay+crewjam.MaxClockSkew); err != nil {
+		return nil, "", err
+	}
+
+	extUser, err := s.externalUserInfo(assertion)
+	if err != nil {
+		return nil, "", err
+	}
+	return extUser, redirectTo, nil
+}
+
+// takeAuthnRequest returns the authentication request sent with relayState, which can only be used once.
+func (s *SAMLService) takeAuthnRequest(relayState string) (*authnRequest, error) {
+	if relayState == "" {
+		return nil, remotecache.ErrCacheItemNotFound
+	}
+
+	key := relayStateKey(relayState)
+	val, err := s.RemoteCache.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.RemoteCache.Delete(key); err != nil {
+		return nil, err
+	}
+
+	authnReq, ok := val.(*authnRequest)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %T stored for SAML relay state", val)
+	}
+	return authnReq, nil
+}
+
+// externalUserInfo maps the attributes of the assertion to the user, identified by the NameID of the assertion.
+func (s *SAMLService) externalUserInfo(assertion *crewjam.Assertion) (*models.ExternalUserInfo, error) {
+	if assertion.Subject == nil || assertion.Subject.NameID == nil || assertion.Subject.NameID.Value == "" {
+		s.log.Warn("SAML assertion has no NameID")
+		return nil, ErrInvalidResponse
+	}
+
+	settings := s.Cfg.SAML
+	attributes := assertionAttributes(assertion)
+	extUser := &models.ExternalUserInfo{
+		AuthModule: models.AuthModuleSAML,
+		AuthId:     assertion.Subject.NameID.Value,
+		Name:       first(attributes[settings.AssertionAttributeName]),
+		Login:      first(attributes[settings.AssertionAttributeLogin]),
+		Email:      first(attributes[settings.AssertionAttributeEmail]),
+		OrgRoles:   map[int64]models.RoleType{},
+	}
+	if extUser.Email == "" {
+		return nil, login.ErrNoEmail
+	}
+	if extUser.Login == "" {
+		extUser.Login = extUser.Email
+	}
+	if settings.AssertionAttributeGroups != "" {
+		extUser.Groups = attributes[settings.AssertionAttributeGroups]
+	}
+
+	if settings.AssertionAttributeRole != "" {
+		role, isGrafanaAdmin := s.mapRoles(attributes[settings.AssertionAttributeRole])
+		// The user will be assigned a role in either the auto-assigned organization or in the default one
+		orgID := int64(1)
+		if s.Cfg.AutoAssignOrg && s.Cfg.AutoAssignOrgId > 0 {
+			orgID = int64(s.Cfg.AutoAssignOrgId)
+		}
+		extUser.OrgRoles[orgID] = role
+		extUser.IsGrafanaAdmin = &isGrafanaAdmin
+	}
+
+	return extUser, nil
+}
+
+// mapRoles returns the organization role of the role values asserted for the user, and whether they are a Grafana admin.
+func (s *SAMLService) mapRoles(values []string) (models.RoleType, bool) {
+	settings := s.Cfg.SAML
+	switch {
+	case containsAny(settings.RoleValuesGrafanaAdmin, values):
+		return models.ROLE_ADMIN, true
+	case containsAny(settings.RoleValuesAdmin, values):
+		return models.ROLE_ADMIN, false
+	case containsAny(settings.RoleValuesEditor, values):
+		return models.ROLE_EDITOR, false
+	default:
+		return models.ROLE_VIEWER, false
+	}
+}
+
+// assertionAttributes returns the values of the attributes of the assertion by both their name and friendly name.
+func assertionAttributes(assertion *crewjam.Assertion) map[string][]string {
+	attributes := make(map[string][]string)
+	for _, statement := range assertion.AttributeStatements {
+		for _, attribute := range statement.Attributes {
+			values := make([]string, 0, len(attribute.Values))
+			for _, value := range attribute.Values {
+				values = append(values, value.Value)
+			}
+			for _, name := range []string{attribute.Name, attribute.FriendlyName} {
+				if name != "" {
+					attributes[name] = append(attributes[name], values...)
+				}
+			}
+		}
+	}
+	return attributes
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func containsAny(list []string, values []string) bool {
+	for _, value := range values {
+		for _, item := range list {
+			if item == value {
+				return true
+			}
+		}
+	}
+	return false
+}
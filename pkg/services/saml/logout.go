@@ -0,0 +1,314 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	crewjam "github.com/crewjam/saml"
+	xrv "github.com/mattermost/xml-roundtrip-validator"
+	dsig "github.com/russellhaering/goxmldsig"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var ErrInvalidLogoutMessage = errors.New("invalid SAML logout message")
+
+var signatureHashes = map[string]crypto.Hash{
+	dsig.RSASHA1SignatureMethod:   crypto.SHA1,
+	dsig.RSASHA256SignatureMethod: crypto.SHA256,
+	dsig.RSASHA512SignatureMethod: crypto.SHA512,
+}
+
+// LogoutRequest returns the logout request that ends the IdP session of the user, who must have logged in with SAML.
+func (s *SAMLService) LogoutRequest(ctx context.Context, userID int64) (*Message, error) {
+	query := &models.GetAuthInfoQuery{UserId: userID, AuthModule: models.AuthModuleSAML}
+	if err := s.Bus.Dispatch(query); err != nil {
+		return nil, err
+	}
+
+	sp, err := s.serviceProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	binding, location, err := idpLocation(sp.GetSLOBindingLocation)
+	if err != nil {
+		return nil, err
+	}
+	req, err := sp.MakeLogoutRequest(location, query.Result.AuthId)
+	if err != nil {
+		return nil, err
+	}
+	return newMessage(req, binding, ""), nil
+}
+
+// ValidateLogoutResponse verifies the response of the IdP to a logout request of Grafana.
+func (s *SAMLService) ValidateLogoutResponse(ctx context.Context, req *http.Request) error {
+	sp, err := s.serviceProvider(ctx)
+	if err != nil {
+		return err
+	}
+
+	data, err := readSignedMessage(sp, req, "SAMLResponse")
+	if err != nil {
+		s.log.Warn("Invalid SAML logout response", "err", err)
+		return ErrInvalidLogoutMessage
+	}
+	var resp crewjam.LogoutResponse
+	if err := xml.Unmarshal(data, &resp); err != nil {
+		return err
+	}
+
+	if err := validateLogoutMessage(sp, resp.Issuer, resp.Destination, resp.IssueInstant); err != nil {
+		s.log.Warn("Invalid SAML logout response", "err", err)
+		return ErrInvalidLogoutMessage
+	}
+	if resp.Status.StatusCode.Value != crewjam.StatusSuccess {
+		s.log.Warn("SAML logout failed in IdP", "status", resp.Status.StatusCode.Value)
+		return ErrInvalidLogoutMessage
+	}
+	return nil
+}
+
+// HandleLogoutRequest logs out the user of a logout request sent by the IdP, and returns the response for the IdP.
+// The user is found by the NameID they logged in with, and all of their sessions are revoked.
+func (s *SAMLService) HandleLogoutRequest(ctx context.Context, req *http.Request) (*Message, error) {
+	sp, err := s.serviceProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := readSignedMessage(sp, req, "SAMLRequest")
+	if err != nil {
+		s.log.Warn("Invalid SAML logout request", "err", err)
+		return nil, ErrInvalidLogoutMessage
+	}
+	var logoutReq crewjam.LogoutRequest
+	if err := xml.Unmarshal(data, &logoutReq); err != nil {
+		return nil, err
+	}
+
+	if err := validateLogoutMessage(sp, logoutReq.Issuer, logoutReq.Destination, logoutReq.IssueInstant); err != nil {
+		s.log.Warn("Invalid SAML logout request", "err", err)
+		return nil, ErrInvalidLogoutMessage
+	}
+	if logoutReq.NameID == nil || logoutReq.NameID.Value == "" {
+		s.log.Warn("SAML logout request has no NameID")
+		return nil, ErrInvalidLogoutMessage
+	}
+
+	query := &models.GetAuthInfoQuery{AuthModule: models.AuthModuleSAML, AuthId: logoutReq.NameID.Value}
+	err = s.Bus.Dispatch(query)
+	switch {
+	case err == nil:
+		if err := s.AuthTokenService.RevokeAllUserTokens(ctx, query.Result.UserId); err != nil {
+			return nil, err
+		}
+		s.log.Info("Logged out user by SAML logout request", "userId", query.Result.UserId)
+	case errors.Is(err, models.ErrUserNotFound):
+		s.log.Debug("No user found for SAML logout request", "nameId", logoutReq.NameID.Value)
+	default:
+		return nil, err
+	}
+
+	binding, location, err := idpLocation(sp.GetSLOBindingLocation)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := sp.MakeLogoutResponse(location, logoutReq.ID)
+	if err != nil {
+		return nil, err
+	}
+	return newMessage(resp, binding, req.Form.Get("RelayState")), nil
+}
+
+func validateLogoutMessage(sp *crewjam.ServiceProvider, issuer *crewjam.Issuer, destination string, issueInstant time.Time) error {
+	if issuer == nil || issuer.Value != sp.IDPMetadata.EntityID {
+		return fmt.Errorf("issuer is not %q", sp.IDPMetadata.EntityID)
+	}
+	if destination != "" && destination != sp.SloURL.String() {
+		return fmt.Errorf("destination is not %q", sp.SloURL.String())
+	}
+	if issueInstant.Add(crewjam.MaxIssueDelay).Before(crewjam.TimeNow()) {
+		return fmt.Errorf("expired on %s", issueInstant.Add(crewjam.MaxIssueDelay))
+	}
+	return nil
+}
+
+// readSignedMessage returns the SAML message in the param of the request, once its signature is verified.
+// Messages sent with the redirect binding are deflated and are either signed in the XML or in the query,
+// while messages sent with the post binding are always signed in the XML.
+func readSignedMessage(sp *crewjam.ServiceProvider, req *http.Request, param string) ([]byte, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	redirectBinding := req.Method == http.MethodGet
+	var encoded string
+	if redirectBinding {
+		encoded = req.URL.Query().Get(param)
+	} else {
+		encoded = req.PostForm.Get(param)
+	}
+	if encoded == "" {
+		return nil, fmt.Errorf("no %s found", param)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if redirectBinding {
+		if data, err = ioutil.ReadAll(flate.NewReader(bytes.NewReader(data))); err != nil {
+			return nil, err
+		}
+	}
+	if err := xrv.Validate(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	certs, err := idpSigningCerts(sp.IDPMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, err
+	}
+	if doc.Root() == nil {
+		return nil, errors.New("empty message")
+	}
+	if doc.Root().FindElement("./Signature") != nil {
+		return validateXMLSignature(doc.Root(), certs)
+	}
+	if redirectBinding && req.URL.Query().Get("Signature") != "" {
+		if err := validateQuerySignature(req.URL.RawQuery, param, certs); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+	return nil, errors.New("message is not signed")
+}
+
+// validateXMLSignature validates the signature embedded in the message, and returns the signed message.
+func validateXMLSignature(el *etree.Element, certs []*x509.Certificate) ([]byte, error) {
+	validationContext := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: certs})
+	validationContext.IdAttribute = "ID"
+	if crewjam.Clock != nil {
+		validationContext.Clock = crewjam.Clock
+	}
+
+	// a signature without certificate is verified with the certificates of the metadata
+	if el.FindElement("./Signature/KeyInfo/X509Data/X509Certificate") == nil {
+		if keyInfo := el.FindElement("./Signature/KeyInfo"); keyInfo != nil {
+			el.FindElement("./Signature").RemoveChild(keyInfo)
+		}
+	}
+
+	signed, err := validationContext.Validate(el)
+	if err != nil {
+		return nil, err
+	}
+	doc := etree.NewDocument()
+	doc.SetRoot(signed)
+	return doc.WriteToBytes()
+}
+
+// validateQuerySignature validates the signature of a message sent with the redirect binding,
+// computed over the URL encoded parameters in the order of the SAML bindings specification.
+func validateQuerySignature(rawQuery, param string, certs []*x509.Certificate) error {
+	values := make(map[string]string)
+	for _, part := range strings.Split(rawQuery, "&") {
+		if kv := strings.SplitN(part, "=", 2); len(kv) == 2 {
+			values[kv[0]] = kv[1]
+		}
+	}
+
+	signed := param + "=" + values[param]
+	if relayState, ok := values["RelayState"]; ok {
+		signed += "&RelayState=" + relayState
+	}
+	signed += "&SigAlg=" + values["SigAlg"]
+
+	sigAlg, err := url.QueryUnescape(values["SigAlg"])
+	if err != nil {
+		return err
+	}
+	hash, ok := signatureHashes[sigAlg]
+	if !ok {
+		return fmt.Errorf("unsupported signature algorithm %q", sigAlg)
+	}
+	encodedSignature, err := url.QueryUnescape(values["Signature"])
+	if err != nil {
+		return err
+	}
+	signature, err := base64.StdEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return err
+	}
+
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+	for _, cert := range certs {
+		if key, ok := cert.PublicKey.(*rsa.PublicKey); ok && rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil {
+			return nil
+		}
+	}
+	return errors.New("invalid signature")
+}
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+// idpSigningCerts returns the certificates the IdP signs with, or its first certificate if none is only for signing.
+func idpSigningCerts(metadata *crewjam.EntityDescriptor) ([]*x509.Certificate, error) {
+	var encodedCerts []string
+	for _, descriptor := range metadata.IDPSSODescriptors {
+		for _, keyDescriptor := range descriptor.KeyDescriptors {
+			if keyDescriptor.Use == "signing" {
+				encodedCerts = append(encodedCerts, keyDescriptor.KeyInfo.Certificate)
+			}
+		}
+	}
+	if len(encodedCerts) == 0 {
+		for _, descriptor := range metadata.IDPSSODescriptors {
+			for _, keyDescriptor := range descriptor.KeyDescriptors {
+				if keyDescriptor.Use == "" && keyDescriptor.KeyInfo.Certificate != "" {
+					encodedCerts = append(encodedCerts, keyDescriptor.KeyInfo.Certificate)
+				}
+			}
+		}
+	}
+	if len(encodedCerts) == 0 {
+		return nil, errors.New("no signing certificate found in IdP metadata")
+	}
+
+	certs := make([]*x509.Certificate, 0, len(encodedCerts))
+	for _, encoded := range encodedCerts {
+		data, err := base64.StdEncoding.DecodeString(whitespace.ReplaceAllString(encoded, ""))
+		if err != nil {
+			return nil, err
+		}
+		cert, err := x509.ParseCertificate(data)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
@@ -0,0 +1,283 @@
+// Package saml implements the SAML 2.0 service provider used to log in with a SAML identity provider.
+package saml
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	crewjam "github.com/crewjam/saml"
+	xrv "github.com/mattermost/xml-roundtrip-validator"
+	dsig "github.com/russellhaering/goxmldsig"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var signatureAlgorithms = map[string]string{
+	"rsa-sha1":   dsig.RSASHA1SignatureMethod,
+	"rsa-sha256": dsig.RSASHA256SignatureMethod,
+	"rsa-sha512": dsig.RSASHA512SignatureMethod,
+}
+
+var netClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+func init() {
+	registry.RegisterService(&SAMLService{})
+	remotecache.Register(&authnRequest{})
+}
+
+// SAMLService is the SAML service provider. Users are sent to the identity provider (IdP)
+// to log in, and the assertions it posts back are turned into external users that are
+// synced like the users of the OAuth connectors.
+type SAMLService struct {
+	Cfg              *setting.Cfg             `inject:""`
+	Bus              bus.Bus                  `inject:""`
+	RemoteCache      *remotecache.RemoteCache `inject:""`
+	AuthTokenService models.UserTokenService  `inject:""`
+
+	log log.Logger
+
+	mu sync.Mutex
+	// sp is nil until the IdP metadata is loaded.
+	sp *crewjam.ServiceProvider
+	// baseSP holds everything but the IdP metadata.
+	baseSP crewjam.ServiceProvider
+}
+
+func (s *SAMLService) Init() error {
+	s.log = log.New("saml.auth")
+	if !s.IsEnabled() {
+		return nil
+	}
+
+	settings := s.Cfg.SAML
+	key, err := loadPrivateKey(settings.PrivateKey, settings.PrivateKeyPath)
+	if err != nil {
+		return fmt.Errorf("failed to load SAML private key: %w", err)
+	}
+	cert, err := loadCertificate(settings.Certificate, settings.CertificatePath)
+	if err != nil {
+		return fmt.Errorf("failed to load SAML certificate: %w", err)
+	}
+
+	var signatureMethod string
+	if settings.SignatureAlgorithm != "" {
+		var ok bool
+		if signatureMethod, ok = signatureAlgorithms[settings.SignatureAlgorithm]; !ok {
+			return fmt.Errorf("unsupported SAML signature_algorithm %q", settings.SignatureAlgorithm)
+		}
+	}
+
+	appURL, err := url.Parse(s.Cfg.AppURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse root_url: %w", err)
+	}
+
+	// the crewjam/saml package only reads the issue delay from a package variable
+	crewjam.MaxIssueDelay = settings.MaxIssueDelay
+
+	s.baseSP = crewjam.ServiceProvider{
+		Key:                   key,
+		Certificate:           cert,
+		MetadataURL:           *appURL.ResolveReference(&url.URL{Path: "saml/metadata"}),
+		AcsURL:                *appURL.ResolveReference(&url.URL{Path: "saml/acs"}),
+		SloURL:                *appURL.ResolveReference(&url.URL{Path: "saml/slo"}),
+		MetadataValidDuration: settings.MetadataValidDuration,
+		SignatureMethod:       signatureMethod,
+	}
+
+	sources := 0
+	for _, source := range []string{settings.IdPMetadata, settings.IdPMetadataPath, settings.IdPMetadataURL} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return errors.New("exactly one of SAML idp_metadata, idp_metadata_path and idp_metadata_url must be set")
+	}
+
+	// the metadata from a URL is loaded on first use, so that Grafana starts while the IdP is unavailable
+	if settings.IdPMetadataURL == "" {
+		if _, err := s.serviceProvider(context.Background()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsEnabled returns true if SAML authentication is enabled.
+func (s *SAMLService) IsEnabled() bool {
+	return s != nil && s.Cfg != nil && s.Cfg.SAML.Enabled
+}
+
+// IsSingleLogoutEnabled returns true if logging out of Grafana logs out of the IdP too.
+func (s *SAMLService) IsSingleLogoutEnabled() bool {
+	return s.IsEnabled() && s.Cfg.SAML.SingleLogout
+}
+
+// Metadata returns the XML metadata of the service provider, to register Grafana in the IdP.
+func (s *SAMLService) Metadata() ([]byte, error) {
+	sp := s.baseSP
+	return xml.MarshalIndent(sp.Metadata(), "", "  ")
+}
+
+// serviceProvider returns the service provider, loading the IdP metadata if it isn't yet.
+func (s *SAMLService) serviceProvider(ctx context.Context) (*crewjam.ServiceProvider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sp != nil {
+		return s.sp, nil
+	}
+
+	metadata, err := s.loadIdPMetadata(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SAML IdP metadata: %w", err)
+	}
+	sp := s.baseSP
+	sp.IDPMetadata = metadata
+	s.sp = &sp
+	return s.sp, nil
+}
+
+func (s *SAMLService) loadIdPMetadata(ctx context.Context) (*crewjam.EntityDescriptor, error) {
+	settings := s.Cfg.SAML
+	switch {
+	case settings.IdPMetadata != "":
+		data, err := base64.StdEncoding.DecodeString(settings.IdPMetadata)
+		if err != nil {
+			return nil, err
+		}
+		return parseIdPMetadata(data)
+	case settings.IdPMetadataPath != "":
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because `IdPMetadataPath` comes from Grafana configuration file.
+		data, err := ioutil.ReadFile(settings.IdPMetadataPath)
+		if err != nil {
+			return nil, err
+		}
+		return parseIdPMetadata(data)
+	default:
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, settings.IdPMetadataURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := netClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err := resp.Body.Close(); err != nil {
+				s.log.Warn("Failed to close response body", "err", err)
+			}
+		}()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %q from %s", resp.Status, settings.IdPMetadataURL)
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return parseIdPMetadata(data)
+	}
+}
+
+// parseIdPMetadata parses the IdP metadata, which is either an EntityDescriptor
+// or an EntitiesDescriptor wrapping the EntityDescriptor of the IdP.
+func parseIdPMetadata(data []byte) (*crewjam.EntityDescriptor, error) {
+	if err := xrv.Validate(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	entity := &crewjam.EntityDescriptor{}
+	err := xml.Unmarshal(data, entity)
+	if err == nil {
+		return entity, nil
+	}
+
+	entities := &crewjam.EntitiesDescriptor{}
+	if xml.Unmarshal(data, entities) != nil {
+		return nil, err
+	}
+	for i, e := range entities.EntityDescriptors {
+		if len(e.IDPSSODescriptors) > 0 {
+			return &entities.EntityDescriptors[i], nil
+		}
+	}
+	return nil, errors.New("no entity with an IDPSSODescriptor found")
+}
+
+// loadPEM returns the PEM block of the base64 encoded value, or of the file at path.
+func loadPEM(value, path string) (*pem.Block, error) {
+	if value != "" && path != "" {
+		return nil, errors.New("only one of the value and its path can be set")
+	}
+
+	var data []byte
+	var err error
+	switch {
+	case value != "":
+		data, err = base64.StdEncoding.DecodeString(value)
+	case path != "":
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because `path` comes from Grafana configuration file.
+		data, err = ioutil.ReadFile(path)
+	default:
+		return nil, errors.New("not set")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	return block, nil
+}
+
+func loadCertificate(value, path string) (*x509.Certificate, error) {
+	block, err := loadPEM(value, path)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func loadPrivateKey(value, path string) (*rsa.PrivateKey, error) {
+	block, err := loadPEM(value, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("only RSA private keys are supported")
+	}
+	return rsaKey, nil
+}
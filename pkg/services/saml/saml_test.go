@@ -0,0 +1,570 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	crewjam "github.com/crewjam/saml"
+	"github.com/crewjam/saml/logger"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/login"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type keyPair struct {
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newKeyPair(t *testing.T) keyPair {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "grafana.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return keyPair{key: key, cert: cert}
+}
+
+func (kp keyPair) encodedKey() string {
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(kp.key)}
+	return base64.StdEncoding.EncodeToString(pem.EncodeToMemory(block))
+}
+
+func (kp keyPair) encodedCert() string {
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: kp.cert.Raw}
+	return base64.StdEncoding.EncodeToString(pem.EncodeToMemory(block))
+}
+
+type serviceProviderProvider struct {
+	s *SAMLService
+}
+
+func (p serviceProviderProvider) GetServiceProvider(r *http.Request, serviceProviderID string) (*crewjam.EntityDescriptor, error) {
+	sp := p.s.baseSP
+	if serviceProviderID != sp.MetadataURL.String() {
+		return nil, os.ErrNotExist
+	}
+	return sp.Metadata(), nil
+}
+
+type sessionProvider struct {
+	session *crewjam.Session
+}
+
+func (p sessionProvider) GetSession(w http.ResponseWriter, r *http.Request, req *crewjam.IdpAuthnRequest) *crewjam.Session {
+	return p.session
+}
+
+type testEnv struct {
+	s        *SAMLService
+	idp      *crewjam.IdentityProvider
+	idpKeys  keyPair
+	session  *crewjam.Session
+	authInfo map[string]int64
+	revoked  []int64
+}
+
+func setupTestEnv(t *testing.T, configure func(*setting.SAMLSettings)) *testEnv {
+	t.Helper()
+
+	env := &testEnv{
+		idpKeys:  newKeyPair(t),
+		authInfo: map[string]int64{},
+		session: &crewjam.Session{
+			ID:             "session",
+			NameID:         "alice-name-id",
+			UserName:       "alice",
+			UserEmail:      "alice@example.com",
+			UserCommonName: "Alice",
+			Groups:         []string{"engineering", "ops"},
+			CustomAttributes: []crewjam.Attribute{
+				{Name: "role", Values: []crewjam.AttributeValue{{Type: "xs:string", Value: "developer"}}},
+			},
+		},
+	}
+	env.idp = &crewjam.IdentityProvider{
+		Key:             env.idpKeys.key,
+		Certificate:     env.idpKeys.cert,
+		Logger:          logger.DefaultLogger,
+		MetadataURL:     url.URL{Scheme: "https", Host: "idp.example.com", Path: "/metadata"},
+		SSOURL:          url.URL{Scheme: "https", Host: "idp.example.com", Path: "/sso"},
+		LogoutURL:       url.URL{Scheme: "https", Host: "idp.example.com", Path: "/slo"},
+		SessionProvider: sessionProvider{session: env.session},
+	}
+	idpMetadata, err := xml.Marshal(env.idp.Metadata())
+	require.NoError(t, err)
+
+	spKeys := newKeyPair(t)
+	cfg := setting.NewCfg()
+	cfg.AppURL = "https://grafana.example.com/"
+	cfg.AutoAssignOrg = true
+	cfg.AutoAssignOrgId = 1
+	cfg.SAML = setting.SAMLSettings{
+		Enabled:                  true,
+		SingleLogout:             true,
+		AllowSignUp:              true,
+		Certificate:              spKeys.encodedCert(),
+		PrivateKey:               spKeys.encodedKey(),
+		IdPMetadata:              base64.StdEncoding.EncodeToString(idpMetadata),
+		MaxIssueDelay:            90 * time.Second,
+		MetadataValidDuration:    48 * time.Hour,
+		AssertionAttributeName:   "cn",
+		AssertionAttributeLogin:  "uid",
+		AssertionAttributeEmail:  "eduPersonPrincipalName",
+		AssertionAttributeGroups: "eduPersonAffiliation",
+		AssertionAttributeRole:   "role",
+		RoleValuesEditor:         []string{"developer"},
+		RoleValuesAdmin:          []string{"operator"},
+		RoleValuesGrafanaAdmin:   []string{"superadmin"},
+	}
+	if configure != nil {
+		configure(&cfg.SAML)
+	}
+
+	b := bus.New()
+	b.AddHandler(func(query *models.GetAuthInfoQuery) error {
+		for authID, userID := range env.authInfo {
+			if (query.AuthId == "" || query.AuthId == authID) && (query.UserId == 0 || query.UserId == userID) {
+				query.Result = &models.UserAuth{UserId: userID, AuthModule: query.AuthModule, AuthId: authID}
+				return nil
+			}
+		}
+		return models.ErrUserNotFound
+	})
+	tokenService := auth.NewFakeUserAuthTokenService()
+	tokenService.RevokeAllUserTokensProvider = func(ctx context.Context, userId int64) error {
+		env.revoked = append(env.revoked, userId)
+		return nil
+	}
+
+	env.s = &SAMLService{
+		Cfg:              cfg,
+		Bus:              b,
+		RemoteCache:      remotecache.NewFakeStore(t),
+		AuthTokenService: tokenService,
+	}
+	require.NoError(t, env.s.Init())
+	env.idp.ServiceProviderProvider = serviceProviderProvider{s: env.s}
+	return env
+}
+
+var formValue = regexp.MustCompile(`name="(SAMLResponse|RelayState)" value="([^"]*)"`)
+
+// acsRequest returns the request posting the response of the IdP form to the assertion consumer service.
+func acsRequest(t *testing.T, body string) *http.Request {
+	t.Helper()
+
+	form := url.Values{}
+	for _, match := range formValue.FindAllStringSubmatch(body, -1) {
+		form.Set(match[1], html.UnescapeString(match[2]))
+	}
+	require.NotEmpty(t, form.Get("SAMLResponse"), body)
+
+	req := httptest.NewRequest(http.MethodPost, "https://grafana.example.com/saml/acs", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+// spInitiatedResponse starts a login in Grafana and returns the response of the IdP.
+func (env *testEnv) spInitiatedResponse(t *testing.T, redirectTo string) *http.Request {
+	t.Helper()
+
+	msg, err := env.s.AuthnRequest(context.Background(), redirectTo)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(msg.RedirectURL, "https://idp.example.com/sso?"), msg.RedirectURL)
+
+	w := httptest.NewRecorder()
+	env.idp.ServeSSO(w, httptest.NewRequest(http.MethodGet, msg.RedirectURL, nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	return acsRequest(t, w.Body.String())
+}
+
+// idpInitiatedResponse returns the response of a login started in the IdP.
+func (env *testEnv) idpInitiatedResponse(t *testing.T, relayState string) *http.Request {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	env.idp.ServeIDPInitiated(w, httptest.NewRequest(http.MethodGet, "https://idp.example.com/login", nil),
+		"https://grafana.example.com/saml/metadata", relayState)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	return acsRequest(t, w.Body.String())
+}
+
+func TestSAMLService_Metadata(t *testing.T) {
+	env := setupTestEnv(t, nil)
+
+	metadata, err := env.s.Metadata()
+	require.NoError(t, err)
+
+	var descriptor crewjam.EntityDescriptor
+	require.NoError(t, xml.Unmarshal(metadata, &descriptor))
+	require.Equal(t, "https://grafana.example.com/saml/metadata", descriptor.EntityID)
+	require.Len(t, descriptor.SPSSODescriptors, 1)
+	require.Equal(t, "https://grafana.example.com/saml/acs", descriptor.SPSSODescriptors[0].AssertionConsumerServices[0].Location)
+	require.Equal(t, "https://grafana.example.com/saml/slo", descriptor.SPSSODescriptors[0].SingleLogoutServices[0].Location)
+	require.True(t, *descriptor.SPSSODescriptors[0].WantAssertionsSigned)
+}
+
+func TestSAMLService_Init(t *testing.T) {
+	t.Run("is a no-op when disabled", func(t *testing.T) {
+		s := &SAMLService{Cfg: setting.NewCfg()}
+		require.NoError(t, s.Init())
+		require.False(t, s.IsEnabled())
+		require.False(t, s.IsSingleLogoutEnabled())
+	})
+
+	t.Run("fails when a certificate and its path are both set", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		kp := newKeyPair(t)
+		cfg.SAML = setting.SAMLSettings{
+			Enabled:         true,
+			PrivateKey:      kp.encodedKey(),
+			Certificate:     kp.encodedCert(),
+			CertificatePath: "/etc/grafana/saml.crt",
+			IdPMetadataURL:  "https://idp.example.com/metadata",
+		}
+		s := &SAMLService{Cfg: cfg}
+		require.EqualError(t, s.Init(), "failed to load SAML certificate: only one of the value and its path can be set")
+	})
+
+	t.Run("fails with an unsupported signature algorithm", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		kp := newKeyPair(t)
+		cfg.SAML = setting.SAMLSettings{
+			Enabled:            true,
+			PrivateKey:         kp.encodedKey(),
+			Certificate:        kp.encodedCert(),
+			SignatureAlgorithm: "rsa-md5",
+			IdPMetadataURL:     "https://idp.example.com/metadata",
+		}
+		s := &SAMLService{Cfg: cfg}
+		require.EqualError(t, s.Init(), `unsupported SAML signature_algorithm "rsa-md5"`)
+	})
+
+	t.Run("loads the IdP metadata from a URL on first use", func(t *testing.T) {
+		env := setupTestEnv(t, nil)
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			env.idp.ServeMetadata(w, r)
+		}))
+		t.Cleanup(server.Close)
+
+		cfg := *env.s.Cfg
+		cfg.SAML.IdPMetadata = ""
+		cfg.SAML.IdPMetadataURL = server.URL
+		s := &SAMLService{Cfg: &cfg, RemoteCache: env.s.RemoteCache}
+		require.NoError(t, s.Init())
+		require.Equal(t, 0, requests)
+
+		_, err := s.AuthnRequest(context.Background(), "")
+		require.NoError(t, err)
+		_, err = s.AuthnRequest(context.Background(), "")
+		require.NoError(t, err)
+		require.Equal(t, 1, requests)
+	})
+}
+
+func TestSAMLService_ParseResponse(t *testing.T) {
+	t.Run("maps the assertion of an SP-initiated login", func(t *testing.T) {
+		env := setupTestEnv(t, nil)
+
+		extUser, redirectTo, err := env.s.ParseResponse(context.Background(), env.spInitiatedResponse(t, "/d/abc"))
+		require.NoError(t, err)
+		require.Equal(t, "/d/abc", redirectTo)
+
+		isGrafanaAdmin := false
+		require.Equal(t, &models.ExternalUserInfo{
+			AuthModule:     models.AuthModuleSAML,
+			AuthId:         "alice-name-id",
+			Login:          "alice",
+			Email:          "alice@example.com",
+			Name:           "Alice",
+			Groups:         []string{"engineering", "ops"},
+			OrgRoles:       map[int64]models.RoleType{1: models.ROLE_EDITOR},
+			IsGrafanaAdmin: &isGrafanaAdmin,
+		}, extUser)
+	})
+
+	t.Run("rejects a response posted twice", func(t *testing.T) {
+		env := setupTestEnv(t, nil)
+		req := env.spInitiatedResponse(t, "")
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		_, _, err = env.s.ParseResponse(context.Background(), newPost(body))
+		require.NoError(t, err)
+		_, _, err = env.s.ParseResponse(context.Background(), newPost(body))
+		require.ErrorIs(t, err, ErrUnsolicitedResponse)
+	})
+
+	t.Run("rejects a response signed by another key", func(t *testing.T) {
+		env := setupTestEnv(t, nil)
+		env.idp.Key = newKeyPair(t).key
+
+		_, _, err := env.s.ParseResponse(context.Background(), env.spInitiatedResponse(t, ""))
+		require.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("requires an email", func(t *testing.T) {
+		env := setupTestEnv(t, nil)
+		env.session.UserEmail = ""
+
+		_, _, err := env.s.ParseResponse(context.Background(), env.spInitiatedResponse(t, ""))
+		require.ErrorIs(t, err, login.ErrNoEmail)
+	})
+
+	t.Run("rejects IdP-initiated logins unless allowed", func(t *testing.T) {
+		env := setupTestEnv(t, nil)
+
+		_, _, err := env.s.ParseResponse(context.Background(), env.idpInitiatedResponse(t, ""))
+		require.ErrorIs(t, err, ErrUnsolicitedResponse)
+	})
+
+	t.Run("accepts IdP-initiated logins with the relay state once", func(t *testing.T) {
+		env := setupTestEnv(t, func(settings *setting.SAMLSettings) {
+			settings.AllowIdPInitiated = true
+			settings.RelayState = "grafana"
+		})
+
+		_, _, err := env.s.ParseResponse(context.Background(), env.idpInitiatedResponse(t, "other"))
+		require.ErrorIs(t, err, ErrUnsolicitedResponse)
+
+		req := env.idpInitiatedResponse(t, "grafana")
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		extUser, redirectTo, err := env.s.ParseResponse(context.Background(), newPost(body))
+		require.NoError(t, err)
+		require.Equal(t, "", redirectTo)
+		require.Equal(t, "alice@example.com", extUser.Email)
+
+		_, _, err = env.s.ParseResponse(context.Background(), newPost(body))
+		require.ErrorIs(t, err, ErrAssertionReplayed)
+	})
+}
+
+func TestSAMLService_mapRoles(t *testing.T) {
+	env := setupTestEnv(t, nil)
+
+	tests := []struct {
+		values         []string
+		role           models.RoleType
+		isGrafanaAdmin bool
+	}{
+		{values: []string{"superadmin"}, role: models.ROLE_ADMIN, isGrafanaAdmin: true},
+		{values: []string{"developer", "operator"}, role: models.ROLE_ADMIN},
+		{values: []string{"developer"}, role: models.ROLE_EDITOR},
+		{values: []string{"sales"}, role: models.ROLE_VIEWER},
+		{values: nil, role: models.ROLE_VIEWER},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%v", tc.values), func(t *testing.T) {
+			role, isGrafanaAdmin := env.s.mapRoles(tc.values)
+			require.Equal(t, tc.role, role)
+			require.Equal(t, tc.isGrafanaAdmin, isGrafanaAdmin)
+		})
+	}
+}
+
+func TestSAMLService_Logout(t *testing.T) {
+	t.Run("creates a logout request for the NameID of the user", func(t *testing.T) {
+		env := setupTestEnv(t, nil)
+		env.authInfo["alice-name-id"] = 2
+
+		msg, err := env.s.LogoutRequest(context.Background(), 2)
+		require.NoError(t, err)
+		logoutReq := inflateRedirect(t, msg.RedirectURL, "SAMLRequest")
+		require.Contains(t, logoutReq, "alice-name-id")
+		require.Contains(t, logoutReq, `Destination="https://idp.example.com/slo"`)
+
+		_, err = env.s.LogoutRequest(context.Background(), 3)
+		require.ErrorIs(t, err, models.ErrUserNotFound)
+	})
+
+	t.Run("logs out the user of a logout request signed in the XML", func(t *testing.T) {
+		env := setupTestEnv(t, nil)
+		env.authInfo["alice-name-id"] = 2
+
+		data := env.signedLogoutRequest(t, env.idpKeys.key)
+		form := url.Values{"SAMLRequest": {base64.StdEncoding.EncodeToString(data)}, "RelayState": {"idp-state"}}
+		msg, err := env.s.HandleLogoutRequest(context.Background(), newPost([]byte(form.Encode())))
+		require.NoError(t, err)
+		require.Equal(t, []int64{2}, env.revoked)
+
+		require.Contains(t, msg.RedirectURL, "RelayState=idp-state")
+		logoutResp := inflateRedirect(t, msg.RedirectURL, "SAMLResponse")
+		require.Contains(t, logoutResp, `InResponseTo="id-logout"`)
+		require.Contains(t, logoutResp, crewjam.StatusSuccess)
+	})
+
+	t.Run("logs out the user of a logout request signed in the query", func(t *testing.T) {
+		env := setupTestEnv(t, nil)
+		env.authInfo["alice-name-id"] = 2
+
+		doc := etree.NewDocument()
+		doc.SetRoot(env.logoutRequest().Element())
+		data, err := doc.WriteToBytes()
+		require.NoError(t, err)
+
+		query := "SAMLRequest=" + url.QueryEscape(deflate(t, data)) +
+			"&RelayState=" + url.QueryEscape("idp-state") +
+			"&SigAlg=" + url.QueryEscape(dsig.RSASHA256SignatureMethod)
+		digest := sha256.Sum256([]byte(query))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, env.idpKeys.key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		query += "&Signature=" + url.QueryEscape(base64.StdEncoding.EncodeToString(signature))
+
+		_, err = env.s.HandleLogoutRequest(context.Background(),
+			httptest.NewRequest(http.MethodGet, "https://grafana.example.com/saml/slo?"+query, nil))
+		require.NoError(t, err)
+		require.Equal(t, []int64{2}, env.revoked)
+
+		tampered := strings.Replace(query, "idp-state", "other-state", 1)
+		_, err = env.s.HandleLogoutRequest(context.Background(),
+			httptest.NewRequest(http.MethodGet, "https://grafana.example.com/saml/slo?"+tampered, nil))
+		require.ErrorIs(t, err, ErrInvalidLogoutMessage)
+	})
+
+	t.Run("rejects logout requests that are not signed by the IdP", func(t *testing.T) {
+		env := setupTestEnv(t, nil)
+		env.authInfo["alice-name-id"] = 2
+
+		doc := etree.NewDocument()
+		doc.SetRoot(env.logoutRequest().Element())
+		unsigned, err := doc.WriteToBytes()
+		require.NoError(t, err)
+
+		for _, data := range [][]byte{unsigned, env.signedLogoutRequest(t, newKeyPair(t).key)} {
+			form := url.Values{"SAMLRequest": {base64.StdEncoding.EncodeToString(data)}}
+			_, err := env.s.HandleLogoutRequest(context.Background(), newPost([]byte(form.Encode())))
+			require.ErrorIs(t, err, ErrInvalidLogoutMessage)
+		}
+		require.Empty(t, env.revoked)
+	})
+
+	t.Run("validates the logout response of the IdP", func(t *testing.T) {
+		env := setupTestEnv(t, nil)
+
+		resp := &crewjam.LogoutResponse{
+			ID:           "id-response",
+			InResponseTo: "id-request",
+			Version:      "2.0",
+			IssueInstant: crewjam.TimeNow(),
+			Destination:  "https://grafana.example.com/saml/slo",
+			Issuer:       &crewjam.Issuer{Value: "https://idp.example.com/metadata"},
+			Status:       crewjam.Status{StatusCode: crewjam.StatusCode{Value: crewjam.StatusSuccess}},
+		}
+		data := signEnveloped(t, env.idpKeys, resp.Element())
+		form := url.Values{"SAMLResponse": {base64.StdEncoding.EncodeToString(data)}}
+		require.NoError(t, env.s.ValidateLogoutResponse(context.Background(), newPost([]byte(form.Encode()))))
+
+		resp.Issuer.Value = "https://other.example.com/metadata"
+		data = signEnveloped(t, env.idpKeys, resp.Element())
+		form = url.Values{"SAMLResponse": {base64.StdEncoding.EncodeToString(data)}}
+		require.ErrorIs(t, env.s.ValidateLogoutResponse(context.Background(), newPost([]byte(form.Encode()))), ErrInvalidLogoutMessage)
+	})
+}
+
+func (env *testEnv) logoutRequest() *crewjam.LogoutRequest {
+	return &crewjam.LogoutRequest{
+		ID:           "id-logout",
+		Version:      "2.0",
+		IssueInstant: crewjam.TimeNow(),
+		Destination:  "https://grafana.example.com/saml/slo",
+		Issuer:       &crewjam.Issuer{Value: "https://idp.example.com/metadata"},
+		NameID:       &crewjam.NameID{Value: "alice-name-id"},
+	}
+}
+
+func (env *testEnv) signedLogoutRequest(t *testing.T, key *rsa.PrivateKey) []byte {
+	return signEnveloped(t, keyPair{key: key, cert: env.idpKeys.cert}, env.logoutRequest().Element())
+}
+
+func signEnveloped(t *testing.T, kp keyPair, el *etree.Element) []byte {
+	t.Helper()
+
+	signingContext := dsig.NewDefaultSigningContext(dsig.TLSCertKeyStore(tls.Certificate{
+		Certificate: [][]byte{kp.cert.Raw},
+		PrivateKey:  kp.key,
+	}))
+	require.NoError(t, signingContext.SetSignatureMethod(dsig.RSASHA256SignatureMethod))
+	signed, err := signingContext.SignEnveloped(el)
+	require.NoError(t, err)
+
+	doc := etree.NewDocument()
+	doc.SetRoot(signed)
+	data, err := doc.WriteToBytes()
+	require.NoError(t, err)
+	return data
+}
+
+func deflate(t *testing.T, data []byte) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// inflateRedirect returns the SAML message in the param of a redirect binding URL.
+func inflateRedirect(t *testing.T, redirectURL, param string) string {
+	t.Helper()
+
+	u, err := url.Parse(redirectURL)
+	require.NoError(t, err)
+	data, err := base64.StdEncoding.DecodeString(u.Query().Get(param))
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(flate.NewReader(bytes.NewReader(data)))
+	require.NoError(t, err)
+	return buf.String()
+}
+
+func newPost(body []byte) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "https://grafana.example.com/saml/acs", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
@@ -14,13 +14,15 @@ func init() {
 	bus.AddHandler("sql", GetApiKeyByName)
 	bus.AddHandlerCtx("sql", DeleteApiKeyCtx)
 	bus.AddHandler("sql", AddApiKey)
+	bus.AddHandler("sql", UpdateApiKeyLastUsed)
 }
 
 func GetApiKeys(query *models.GetApiKeysQuery) error {
-	sess := x.Limit(100, 0).Where("org_id=? and ( expires IS NULL or expires >= ?)",
+	// the tokens of service accounts are listed with their service account
+	sess := x.Limit(100, 0).Where("org_id=? and service_account_id IS NULL and ( expires IS NULL or expires >= ?)",
 		query.OrgId, timeNow().Unix()).Asc("name")
 	if query.IncludeExpired {
-		sess = x.Limit(100, 0).Where("org_id=?", query.OrgId).Asc("name")
+		sess = x.Limit(100, 0).Where("org_id=? and service_account_id IS NULL", query.OrgId).Asc("name")
 	}
 
 	query.Result = make([]*models.ApiKey, 0)
@@ -34,7 +36,8 @@ func DeleteApiKeyCtx(ctx context.Context, cmd *models.DeleteApiKeyCommand) error
 }
 
 func deleteAPIKey(sess *DBSession, id, orgID int64) error {
-	rawSQL := "DELETE FROM api_key WHERE id=? and org_id=?"
+	// the tokens of service accounts are deleted with their service account
+	rawSQL := "DELETE FROM api_key WHERE id=? and org_id=? and service_account_id IS NULL"
 	result, err := sess.Exec(rawSQL, id, orgID)
 	if err != nil {
 		return err
@@ -57,12 +60,9 @@ func AddApiKey(cmd *models.AddApiKeyCommand) error {
 		}
 
 		updated := timeNow()
-		var expires *int64 = nil
-		if cmd.SecondsToLive > 0 {
-			v := updated.Add(time.Second * time.Duration(cmd.SecondsToLive)).Unix()
-			expires = &v
-		} else if cmd.SecondsToLive < 0 {
-			return models.ErrInvalidApiKeyExpiration
+		expires, err := apiKeyExpiration(updated, cmd.SecondsToLive)
+		if err != nil {
+			return err
 		}
 		t := models.ApiKey{
 			OrgId:   cmd.OrgId,
@@ -82,6 +82,23 @@ func AddApiKey(cmd *models.AddApiKeyCommand) error {
 	})
 }
 
+// apiKeyExpiration returns the expiration of a key created at now, which never expires if secondsToLive is 0.
+func apiKeyExpiration(now time.Time, secondsToLive int64) (*int64, error) {
+	if secondsToLive < 0 {
+		return nil, models.ErrInvalidApiKeyExpiration
+	}
+	if secondsToLive == 0 {
+		return nil, nil
+	}
+	v := now.Add(time.Second * time.Duration(secondsToLive)).Unix()
+	return &v, nil
+}
+
+func UpdateApiKeyLastUsed(cmd *models.UpdateApiKeyLastUsedCommand) error {
+	_, err := x.Exec("UPDATE api_key SET last_used_at=? WHERE id=?", cmd.LastUsedAt, cmd.Id)
+	return err
+}
+
 func GetApiKeyById(query *models.GetApiKeyByIdQuery) error {
 	var apikey models.ApiKey
 	has, err := x.Id(query.ApiKeyId).Get(&apikey)
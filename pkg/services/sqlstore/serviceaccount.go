@@ -0,0 +1,282 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func (ss *SQLStore) addServiceAccountQueryAndCommandHandlers() {
+	ss.Bus.AddHandlerCtx(ss.CreateServiceAccount)
+	ss.Bus.AddHandlerCtx(ss.UpdateServiceAccount)
+	ss.Bus.AddHandlerCtx(ss.DeleteServiceAccount)
+	ss.Bus.AddHandlerCtx(ss.GetServiceAccounts)
+	ss.Bus.AddHandlerCtx(ss.GetServiceAccountById)
+	ss.Bus.AddHandlerCtx(ss.AddServiceAccountToken)
+	ss.Bus.AddHandlerCtx(ss.RotateServiceAccountToken)
+	ss.Bus.AddHandlerCtx(ss.DeleteServiceAccountToken)
+	ss.Bus.AddHandlerCtx(ss.GetServiceAccountTokens)
+	ss.Bus.AddHandlerCtx(ss.ConvertApiKeyToServiceAccount)
+}
+
+const serviceAccountColumns = `u.id, org_user.org_id, u.name, u.login, org_user.role,
+	(SELECT COUNT(*) FROM api_key WHERE api_key.service_account_id = u.id) AS tokens`
+
+// serviceAccountLogin returns the login of a service account, which is unique per organization and name.
+func serviceAccountLogin(orgID int64, name string) string {
+	return fmt.Sprintf("sa-%d-%s", orgID, models.SlugifyTitle(name))
+}
+
+// CreateServiceAccount creates a service account, which is a user of a single organization that can't log in.
+func (ss *SQLStore) CreateServiceAccount(ctx context.Context, cmd *models.CreateServiceAccountCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		if err := verifyExistingOrg(sess, cmd.OrgId); err != nil {
+			return err
+		}
+
+		sa, err := ss.createServiceAccount(ctx, sess, cmd.OrgId, cmd.Name, cmd.Role)
+		if err != nil {
+			return err
+		}
+		cmd.Result = sa
+		return nil
+	})
+}
+
+func (ss *SQLStore) createServiceAccount(ctx context.Context, sess *DBSession, orgID int64, name string, role models.RoleType) (*models.ServiceAccountDTO, error) {
+	user, err := ss.createUser(ctx, sess, userCreationArgs{
+		Login:            serviceAccountLogin(orgID, name),
+		Name:             name,
+		IsServiceAccount: true,
+	}, true)
+	if errors.Is(err, models.ErrUserAlreadyExists) {
+		return nil, models.ErrServiceAccountAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	orgUser := models.OrgUser{
+		OrgId:   orgID,
+		UserId:  user.Id,
+		Role:    role,
+		Created: timeNow(),
+		Updated: timeNow(),
+	}
+	if _, err := sess.Insert(&orgUser); err != nil {
+		return nil, err
+	}
+	if err := setUsingOrgInTransaction(sess, user.Id, orgID); err != nil {
+		return nil, err
+	}
+
+	return &models.ServiceAccountDTO{
+		Id:    user.Id,
+		OrgId: orgID,
+		Name:  user.Name,
+		Login: user.Login,
+		Role:  role,
+	}, nil
+}
+
+// UpdateServiceAccount updates the name and the role of a service account, leaving empty values unchanged.
+func (ss *SQLStore) UpdateServiceAccount(ctx context.Context, cmd *models.UpdateServiceAccountCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		sa, err := getServiceAccount(sess, cmd.OrgId, cmd.Id)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Name != "" && cmd.Name != sa.Name {
+			user := models.User{Name: cmd.Name, Updated: timeNow()}
+			if _, err := sess.ID(sa.Id).Cols("name", "updated").Update(&user); err != nil {
+				return err
+			}
+			sa.Name = cmd.Name
+		}
+
+		if cmd.Role != "" && cmd.Role != sa.Role {
+			if _, err := sess.Exec("UPDATE org_user SET role=?, updated=? WHERE org_id=? AND user_id=?",
+				cmd.Role, timeNow(), cmd.OrgId, sa.Id); err != nil {
+				return err
+			}
+			sa.Role = cmd.Role
+		}
+
+		cmd.Result = sa
+		return nil
+	})
+}
+
+// DeleteServiceAccount deletes a service account with its tokens.
+func (ss *SQLStore) DeleteServiceAccount(ctx context.Context, cmd *models.DeleteServiceAccountCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		if _, err := getServiceAccount(sess, cmd.OrgId, cmd.Id); err != nil {
+			return err
+		}
+		return deleteUserInTransaction(sess, &models.DeleteUserCommand{UserId: cmd.Id})
+	})
+}
+
+func (ss *SQLStore) GetServiceAccounts(ctx context.Context, query *models.GetServiceAccountsQuery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		query.Result = make([]*models.ServiceAccountDTO, 0)
+		return serviceAccountsSession(sess, query.OrgId).Asc("u.name").Find(&query.Result)
+	})
+}
+
+func (ss *SQLStore) GetServiceAccountById(ctx context.Context, query *models.GetServiceAccountByIdQuery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		sa, err := getServiceAccount(sess, query.OrgId, query.Id)
+		if err != nil {
+			return err
+		}
+		query.Result = sa
+		return nil
+	})
+}
+
+func serviceAccountsSession(sess *DBSession, orgID int64) *DBSession {
+	sess.Table("user").Alias("u").
+		Join("INNER", "org_user", "org_user.user_id = u.id").
+		Where("u.is_service_account = ? AND org_user.org_id = ?", true, orgID).
+		Select(serviceAccountColumns)
+	return sess
+}
+
+func getServiceAccount(sess *DBSession, orgID, id int64) (*models.ServiceAccountDTO, error) {
+	var sa models.ServiceAccountDTO
+	has, err := serviceAccountsSession(sess, orgID).And("u.id = ?", id).Get(&sa)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, models.ErrServiceAccountNotFound
+	}
+	return &sa, nil
+}
+
+// AddServiceAccountToken adds a token to a service account. Tokens are API keys, so their name is unique per organization.
+func (ss *SQLStore) AddServiceAccountToken(ctx context.Context, cmd *models.AddServiceAccountTokenCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		sa, err := getServiceAccount(sess, cmd.OrgId, cmd.ServiceAccountId)
+		if err != nil {
+			return err
+		}
+
+		if exists, err := sess.Get(&models.ApiKey{OrgId: cmd.OrgId, Name: cmd.Name}); err != nil {
+			return err
+		} else if exists {
+			return models.ErrDuplicateApiKey
+		}
+
+		updated := timeNow()
+		expires, err := apiKeyExpiration(updated, cmd.SecondsToLive)
+		if err != nil {
+			return err
+		}
+		key := models.ApiKey{
+			OrgId:            cmd.OrgId,
+			Name:             cmd.Name,
+			Role:             sa.Role,
+			Key:              cmd.Key,
+			Created:          updated,
+			Updated:          updated,
+			Expires:          expires,
+			ServiceAccountId: &sa.Id,
+		}
+		if _, err := sess.Insert(&key); err != nil {
+			return err
+		}
+		cmd.Result = &key
+		return nil
+	})
+}
+
+// RotateServiceAccountToken replaces the secret and the expiration of a token, so that the previous secret stops working.
+func (ss *SQLStore) RotateServiceAccountToken(ctx context.Context, cmd *models.RotateServiceAccountTokenCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		var key models.ApiKey
+		has, err := sess.Where("id=? AND org_id=? AND service_account_id=?", cmd.Id, cmd.OrgId, cmd.ServiceAccountId).Get(&key)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrServiceAccountTokenNotFound
+		}
+
+		key.Updated = timeNow()
+		if key.Expires, err = apiKeyExpiration(key.Updated, cmd.SecondsToLive); err != nil {
+			return err
+		}
+		key.Key = cmd.Key
+		key.LastUsedAt = nil
+		if _, err := sess.ID(key.Id).Cols("key", "updated", "expires", "last_used_at").Update(&key); err != nil {
+			return err
+		}
+		cmd.Result = &key
+		return nil
+	})
+}
+
+func (ss *SQLStore) DeleteServiceAccountToken(ctx context.Context, cmd *models.DeleteServiceAccountTokenCommand) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		result, err := sess.Exec("DELETE FROM api_key WHERE id=? AND org_id=? AND service_account_id=?",
+			cmd.Id, cmd.OrgId, cmd.ServiceAccountId)
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		} else if n == 0 {
+			return models.ErrServiceAccountTokenNotFound
+		}
+		return nil
+	})
+}
+
+func (ss *SQLStore) GetServiceAccountTokens(ctx context.Context, query *models.GetServiceAccountTokensQuery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		if _, err := getServiceAccount(sess, query.OrgId, query.ServiceAccountId); err != nil {
+			return err
+		}
+
+		query.Result = make([]*models.ApiKey, 0)
+		return sess.Table("api_key").
+			Where("org_id=? AND service_account_id=?", query.OrgId, query.ServiceAccountId).
+			Asc("name").
+			Find(&query.Result)
+	})
+}
+
+// ConvertApiKeyToServiceAccount migrates an API key to a new service account with the name and the role of the key.
+// The key becomes the token of the service account, so the clients using it keep working.
+func (ss *SQLStore) ConvertApiKeyToServiceAccount(ctx context.Context, cmd *models.ConvertApiKeyToServiceAccountCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		var key models.ApiKey
+		has, err := sess.Where("id=? AND org_id=?", cmd.KeyId, cmd.OrgId).Get(&key)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrApiKeyNotFound
+		}
+		if key.ServiceAccountId != nil {
+			return models.ErrApiKeyAlreadyMigrated
+		}
+
+		sa, err := ss.createServiceAccount(ctx, sess, key.OrgId, key.Name, key.Role)
+		if err != nil {
+			return err
+		}
+		if _, err := sess.Exec("UPDATE api_key SET service_account_id=? WHERE id=?", sa.Id, key.Id); err != nil {
+			return err
+		}
+		sa.Tokens = 1
+		cmd.Result = sa
+		return nil
+	})
+}
@@ -0,0 +1,139 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestServiceAccountDataAccess(t *testing.T) {
+	mockTimeNow()
+	defer resetTimeNow()
+
+	sqlStore := InitTestDB(t)
+	ctx := context.Background()
+	org, err := sqlStore.CreateOrgWithMember("service accounts", 0)
+	require.NoError(t, err)
+
+	createCmd := models.CreateServiceAccountCommand{OrgId: org.Id, Name: "CI pipeline", Role: models.ROLE_EDITOR}
+	require.NoError(t, sqlStore.CreateServiceAccount(ctx, &createCmd))
+	sa := createCmd.Result
+
+	t.Run("Should create a service account that is a member of the organization", func(t *testing.T) {
+		assert.Equal(t, fmt.Sprintf("sa-%d-ci-pipeline", org.Id), sa.Login)
+
+		query := models.GetSignedInUserQuery{UserId: sa.Id, OrgId: org.Id}
+		require.NoError(t, GetSignedInUser(&query))
+		assert.True(t, query.Result.IsServiceAccount)
+		assert.Equal(t, org.Id, query.Result.OrgId)
+		assert.Equal(t, models.ROLE_EDITOR, query.Result.OrgRole)
+	})
+
+	t.Run("Should not create a service account with the same name", func(t *testing.T) {
+		err := sqlStore.CreateServiceAccount(ctx, &models.CreateServiceAccountCommand{OrgId: org.Id, Name: "CI pipeline", Role: models.ROLE_VIEWER})
+		require.ErrorIs(t, err, models.ErrServiceAccountAlreadyExists)
+	})
+
+	t.Run("Should not list service accounts as users", func(t *testing.T) {
+		query := models.SearchUsersQuery{Query: "ci-pipeline"}
+		require.NoError(t, SearchUsers(&query))
+		assert.Empty(t, query.Result.Users)
+	})
+
+	t.Run("Should update the name and the role of the service account", func(t *testing.T) {
+		cmd := models.UpdateServiceAccountCommand{OrgId: org.Id, Id: sa.Id, Name: "CI", Role: models.ROLE_ADMIN}
+		require.NoError(t, sqlStore.UpdateServiceAccount(ctx, &cmd))
+
+		query := models.GetServiceAccountByIdQuery{OrgId: org.Id, Id: sa.Id}
+		require.NoError(t, sqlStore.GetServiceAccountById(ctx, &query))
+		assert.Equal(t, "CI", query.Result.Name)
+		assert.Equal(t, models.ROLE_ADMIN, query.Result.Role)
+	})
+
+	t.Run("Should not find the service account in another organization", func(t *testing.T) {
+		query := models.GetServiceAccountByIdQuery{OrgId: org.Id + 1, Id: sa.Id}
+		require.ErrorIs(t, sqlStore.GetServiceAccountById(ctx, &query), models.ErrServiceAccountNotFound)
+	})
+
+	t.Run("Should add, rotate and delete tokens", func(t *testing.T) {
+		addCmd := models.AddServiceAccountTokenCommand{OrgId: org.Id, ServiceAccountId: sa.Id, Name: "deploy", Key: "key1", SecondsToLive: 3600}
+		require.NoError(t, sqlStore.AddServiceAccountToken(ctx, &addCmd))
+		token := addCmd.Result
+		require.NotNil(t, token.ServiceAccountId)
+		assert.Equal(t, sa.Id, *token.ServiceAccountId)
+
+		err := sqlStore.AddServiceAccountToken(ctx, &models.AddServiceAccountTokenCommand{OrgId: org.Id, ServiceAccountId: sa.Id, Name: "deploy", Key: "key2"})
+		require.ErrorIs(t, err, models.ErrDuplicateApiKey)
+
+		keysQuery := models.GetApiKeysQuery{OrgId: org.Id, IncludeExpired: true}
+		require.NoError(t, GetApiKeys(&keysQuery))
+		assert.Empty(t, keysQuery.Result, "tokens of service accounts are not API keys of the organization")
+
+		err = DeleteApiKeyCtx(ctx, &models.DeleteApiKeyCommand{Id: token.Id, OrgId: org.Id})
+		require.ErrorIs(t, err, models.ErrApiKeyNotFound, "tokens of service accounts are not deleted as API keys")
+
+		require.NoError(t, UpdateApiKeyLastUsed(&models.UpdateApiKeyLastUsedCommand{Id: token.Id, LastUsedAt: timeNow()}))
+
+		rotateCmd := models.RotateServiceAccountTokenCommand{OrgId: org.Id, ServiceAccountId: sa.Id, Id: token.Id, Key: "key3"}
+		require.NoError(t, sqlStore.RotateServiceAccountToken(ctx, &rotateCmd))
+
+		tokensQuery := models.GetServiceAccountTokensQuery{OrgId: org.Id, ServiceAccountId: sa.Id}
+		require.NoError(t, sqlStore.GetServiceAccountTokens(ctx, &tokensQuery))
+		require.Len(t, tokensQuery.Result, 1)
+		assert.Equal(t, "key3", tokensQuery.Result[0].Key)
+		assert.Nil(t, tokensQuery.Result[0].Expires)
+		assert.Nil(t, tokensQuery.Result[0].LastUsedAt)
+
+		err = sqlStore.RotateServiceAccountToken(ctx, &models.RotateServiceAccountTokenCommand{OrgId: org.Id, ServiceAccountId: sa.Id + 1, Id: token.Id, Key: "key4"})
+		require.ErrorIs(t, err, models.ErrServiceAccountTokenNotFound)
+
+		deleteCmd := models.DeleteServiceAccountTokenCommand{OrgId: org.Id, ServiceAccountId: sa.Id, Id: token.Id}
+		require.NoError(t, sqlStore.DeleteServiceAccountToken(ctx, &deleteCmd))
+		require.ErrorIs(t, sqlStore.DeleteServiceAccountToken(ctx, &deleteCmd), models.ErrServiceAccountTokenNotFound)
+	})
+
+	t.Run("Should convert an API key to a service account", func(t *testing.T) {
+		keyCmd := models.AddApiKeyCommand{OrgId: org.Id, Name: "shared admin key", Role: models.ROLE_ADMIN, Key: "key5"}
+		require.NoError(t, AddApiKey(&keyCmd))
+
+		convertCmd := models.ConvertApiKeyToServiceAccountCommand{OrgId: org.Id, KeyId: keyCmd.Result.Id}
+		require.NoError(t, sqlStore.ConvertApiKeyToServiceAccount(ctx, &convertCmd))
+		assert.Equal(t, "shared admin key", convertCmd.Result.Name)
+		assert.Equal(t, models.ROLE_ADMIN, convertCmd.Result.Role)
+
+		keyQuery := models.GetApiKeyByNameQuery{OrgId: org.Id, KeyName: "shared admin key"}
+		require.NoError(t, GetApiKeyByName(&keyQuery))
+		require.NotNil(t, keyQuery.Result.ServiceAccountId)
+		assert.Equal(t, convertCmd.Result.Id, *keyQuery.Result.ServiceAccountId)
+		assert.Equal(t, "key5", keyQuery.Result.Key)
+
+		err := sqlStore.ConvertApiKeyToServiceAccount(ctx, &models.ConvertApiKeyToServiceAccountCommand{OrgId: org.Id, KeyId: keyCmd.Result.Id})
+		require.ErrorIs(t, err, models.ErrApiKeyAlreadyMigrated)
+
+		query := models.GetServiceAccountsQuery{OrgId: org.Id}
+		require.NoError(t, sqlStore.GetServiceAccounts(ctx, &query))
+		require.Len(t, query.Result, 2)
+		assert.Equal(t, "CI", query.Result[0].Name)
+		assert.Equal(t, int64(0), query.Result[0].Tokens)
+		assert.Equal(t, "shared admin key", query.Result[1].Name)
+		assert.Equal(t, int64(1), query.Result[1].Tokens)
+	})
+
+	t.Run("Should delete the service account with its tokens", func(t *testing.T) {
+		addCmd := models.AddServiceAccountTokenCommand{OrgId: org.Id, ServiceAccountId: sa.Id, Name: "build", Key: "key6"}
+		require.NoError(t, sqlStore.AddServiceAccountToken(ctx, &addCmd))
+
+		require.NoError(t, sqlStore.DeleteServiceAccount(ctx, &models.DeleteServiceAccountCommand{OrgId: org.Id, Id: sa.Id}))
+
+		require.ErrorIs(t, GetApiKeyById(&models.GetApiKeyByIdQuery{ApiKeyId: addCmd.Result.Id}), models.ErrInvalidApiKey)
+		require.ErrorIs(t, GetUserById(&models.GetUserByIdQuery{Id: sa.Id}), models.ErrUserNotFound)
+		require.ErrorIs(t, sqlStore.DeleteServiceAccount(ctx, &models.DeleteServiceAccountCommand{OrgId: org.Id, Id: sa.Id}), models.ErrServiceAccountNotFound)
+	})
+}
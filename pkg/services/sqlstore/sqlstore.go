@@ -111,6 +111,7 @@ func (ss *SQLStore) Init() error {
 	ss.addUserQueryAndCommandHandlers()
 	ss.addAlertNotificationUidByIdHandler()
 	ss.addPreferencesQueryAndCommandHandlers()
+	ss.addServiceAccountQueryAndCommandHandlers()
 
 	if err := ss.Reset(); err != nil {
 		return err
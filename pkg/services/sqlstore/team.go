@@ -125,7 +125,7 @@ func UpdateTeam(cmd *models.UpdateTeamCommand) error {
 	})
 }
 
-// DeleteTeam will delete a team, its member and any permissions connected to the team
+// DeleteTeam will delete a team, its member, its external groups and any permissions connected to the team
 func DeleteTeam(cmd *models.DeleteTeamCommand) error {
 	return inTransaction(func(sess *DBSession) error {
 		if _, err := teamExists(cmd.OrgId, cmd.Id, sess); err != nil {
@@ -134,6 +134,7 @@ func DeleteTeam(cmd *models.DeleteTeamCommand) error {
 
 		deletes := []string{
 			"DELETE FROM team_member WHERE org_id=? and team_id = ?",
+			"DELETE FROM team_group WHERE org_id=? and team_id = ?",
 			"DELETE FROM team WHERE org_id=? and id = ?",
 			"DELETE FROM dashboard_acl WHERE org_id=? and team_id = ?",
 		}
@@ -0,0 +1,87 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", AddTeamGroup)
+	bus.AddHandler("sql", RemoveTeamGroup)
+	bus.AddHandler("sql", GetTeamGroups)
+	bus.AddHandler("sql", GetTeamGroupsByGroupIds)
+}
+
+// AddTeamGroup maps an external group to a team
+func AddTeamGroup(cmd *models.AddTeamGroupCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := teamExists(cmd.OrgId, cmd.TeamId, sess); err != nil {
+			return err
+		}
+
+		if res, err := sess.Query("SELECT 1 from team_group WHERE org_id=? and team_id=? and group_id=?",
+			cmd.OrgId, cmd.TeamId, cmd.GroupId); err != nil {
+			return err
+		} else if len(res) == 1 {
+			return models.ErrTeamGroupAlreadyAdded
+		}
+
+		entity := models.TeamGroup{
+			OrgId:   cmd.OrgId,
+			TeamId:  cmd.TeamId,
+			GroupId: cmd.GroupId,
+			Created: time.Now(),
+			Updated: time.Now(),
+		}
+
+		_, err := sess.Insert(&entity)
+		return err
+	})
+}
+
+// RemoveTeamGroup removes the mapping of an external group to a team
+func RemoveTeamGroup(cmd *models.RemoveTeamGroupCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := teamExists(cmd.OrgId, cmd.TeamId, sess); err != nil {
+			return err
+		}
+
+		var rawSQL = "DELETE FROM team_group WHERE org_id=? and team_id=? and group_id=?"
+		res, err := sess.Exec(rawSQL, cmd.OrgId, cmd.TeamId, cmd.GroupId)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if rows == 0 {
+			return models.ErrTeamGroupNotFound
+		}
+
+		return err
+	})
+}
+
+// GetTeamGroups returns the external groups mapped to a team
+func GetTeamGroups(query *models.GetTeamGroupsQuery) error {
+	query.Result = make([]*models.TeamGroupDTO, 0)
+	return x.Table("team_group").
+		Where("org_id=? and team_id=?", query.OrgId, query.TeamId).
+		Cols("org_id", "team_id", "group_id").
+		Asc("group_id").
+		Find(&query.Result)
+}
+
+// GetTeamGroupsByGroupIds returns the team groups of all organizations mapping any of the given external groups
+func GetTeamGroupsByGroupIds(query *models.GetTeamGroupsByGroupIdsQuery) error {
+	query.Result = make([]*models.TeamGroupDTO, 0)
+	if len(query.GroupIds) == 0 {
+		return nil
+	}
+
+	return x.Table("team_group").
+		In("group_id", query.GroupIds).
+		Cols("org_id", "team_id", "group_id").
+		Asc("org_id", "team_id").
+		Find(&query.Result)
+}
@@ -0,0 +1,83 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestTeamGroupDataAccess(t *testing.T) {
+	sqlStore := InitTestDB(t)
+
+	const testOrgID int64 = 1
+	team1, err := sqlStore.CreateTeam("team1", "", testOrgID)
+	require.NoError(t, err)
+	team2, err := sqlStore.CreateTeam("team2", "", testOrgID)
+	require.NoError(t, err)
+
+	const ldapGroup = "cn=admins,ou=groups,dc=grafana,dc=org"
+	const oauthGroup = "grafana/admins"
+	for _, cmd := range []models.AddTeamGroupCommand{
+		{OrgId: testOrgID, TeamId: team1.Id, GroupId: ldapGroup},
+		{OrgId: testOrgID, TeamId: team1.Id, GroupId: oauthGroup},
+		{OrgId: testOrgID, TeamId: team2.Id, GroupId: ldapGroup},
+	} {
+		cmd := cmd
+		require.NoError(t, AddTeamGroup(&cmd))
+	}
+
+	t.Run("Should not add the same group twice", func(t *testing.T) {
+		err := AddTeamGroup(&models.AddTeamGroupCommand{OrgId: testOrgID, TeamId: team1.Id, GroupId: ldapGroup})
+		require.ErrorIs(t, err, models.ErrTeamGroupAlreadyAdded)
+	})
+
+	t.Run("Should not add a group to a missing team", func(t *testing.T) {
+		err := AddTeamGroup(&models.AddTeamGroupCommand{OrgId: testOrgID, TeamId: 1000, GroupId: ldapGroup})
+		require.ErrorIs(t, err, models.ErrTeamNotFound)
+	})
+
+	t.Run("Should get the groups of a team", func(t *testing.T) {
+		query := models.GetTeamGroupsQuery{OrgId: testOrgID, TeamId: team1.Id}
+		require.NoError(t, GetTeamGroups(&query))
+		require.Equal(t, []*models.TeamGroupDTO{
+			{OrgId: testOrgID, TeamId: team1.Id, GroupId: ldapGroup},
+			{OrgId: testOrgID, TeamId: team1.Id, GroupId: oauthGroup},
+		}, query.Result)
+	})
+
+	t.Run("Should get the teams of groups", func(t *testing.T) {
+		query := models.GetTeamGroupsByGroupIdsQuery{GroupIds: []string{ldapGroup, "unknown"}}
+		require.NoError(t, GetTeamGroupsByGroupIds(&query))
+		require.Equal(t, []*models.TeamGroupDTO{
+			{OrgId: testOrgID, TeamId: team1.Id, GroupId: ldapGroup},
+			{OrgId: testOrgID, TeamId: team2.Id, GroupId: ldapGroup},
+		}, query.Result)
+
+		query = models.GetTeamGroupsByGroupIdsQuery{}
+		require.NoError(t, GetTeamGroupsByGroupIds(&query))
+		require.Empty(t, query.Result)
+	})
+
+	t.Run("Should remove a group of a team", func(t *testing.T) {
+		require.NoError(t, RemoveTeamGroup(&models.RemoveTeamGroupCommand{OrgId: testOrgID, TeamId: team1.Id, GroupId: oauthGroup}))
+
+		err := RemoveTeamGroup(&models.RemoveTeamGroupCommand{OrgId: testOrgID, TeamId: team1.Id, GroupId: oauthGroup})
+		require.ErrorIs(t, err, models.ErrTeamGroupNotFound)
+
+		query := models.GetTeamGroupsQuery{OrgId: testOrgID, TeamId: team1.Id}
+		require.NoError(t, GetTeamGroups(&query))
+		require.Len(t, query.Result, 1)
+	})
+
+	t.Run("Should remove the groups of a deleted team", func(t *testing.T) {
+		require.NoError(t, DeleteTeam(&models.DeleteTeamCommand{OrgId: testOrgID, Id: team2.Id}))
+
+		query := models.GetTeamGroupsQuery{OrgId: testOrgID, TeamId: team2.Id}
+		require.NoError(t, GetTeamGroups(&query))
+		require.Empty(t, query.Result)
+	})
+}
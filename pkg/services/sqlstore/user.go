@@ -56,17 +56,18 @@ func getOrgIdForNewUser(sess *DBSession, cmd models.CreateUserCommand) (int64, e
 }
 
 type userCreationArgs struct {
-	Login          string
-	Email          string
-	Name           string
-	Company        string
-	Password       string
-	IsAdmin        bool
-	IsDisabled     bool
-	EmailVerified  bool
-	OrgID          int64
-	OrgName        string
-	DefaultOrgRole string
+	Login            string
+	Email            string
+	Name             string
+	Company          string
+	Password         string
+	IsAdmin          bool
+	IsDisabled       bool
+	IsServiceAccount bool
+	EmailVerified    bool
+	OrgID            int64
+	OrgName          string
+	DefaultOrgRole   string
 }
 
 func (ss *SQLStore) getOrgIDForNewUser(sess *DBSession, args userCreationArgs) (int64, error) {
@@ -111,17 +112,18 @@ func (ss *SQLStore) createUser(ctx context.Context, sess *DBSession, args userCr
 
 	// create user
 	user = models.User{
-		Email:         args.Email,
-		Name:          args.Name,
-		Login:         args.Login,
-		Company:       args.Company,
-		IsAdmin:       args.IsAdmin,
-		IsDisabled:    args.IsDisabled,
-		OrgId:         orgID,
-		EmailVerified: args.EmailVerified,
-		Created:       time.Now(),
-		Updated:       time.Now(),
-		LastSeenAt:    time.Now().AddDate(-10, 0, 0),
+		Email:            args.Email,
+		Name:             args.Name,
+		Login:            args.Login,
+		Company:          args.Company,
+		IsAdmin:          args.IsAdmin,
+		IsDisabled:       args.IsDisabled,
+		IsServiceAccount: args.IsServiceAccount,
+		OrgId:            orgID,
+		EmailVerified:    args.EmailVerified,
+		Created:          time.Now(),
+		Updated:          time.Now(),
+		LastSeenAt:       time.Now().AddDate(-10, 0, 0),
 	}
 
 	salt, err := util.GetRandomString(10)
@@ -516,6 +518,7 @@ func GetSignedInUser(query *models.GetSignedInUserQuery) error {
 	var rawSQL = `SELECT
 		u.id             as user_id,
 		u.is_admin       as is_grafana_admin,
+		u.is_service_account as is_service_account,
 		u.email          as email,
 		u.login          as login,
 		u.name           as name,
@@ -586,6 +589,10 @@ func SearchUsers(query *models.SearchUsersQuery) error {
 	joinCondition = "user_auth.id=" + joinCondition + dialect.Limit(1) + ")"
 	sess.Join("LEFT", "user_auth", joinCondition)
 
+	// service accounts are managed with the service accounts API
+	whereConditions = append(whereConditions, "is_service_account = ?")
+	whereParams = append(whereParams, false)
+
 	if query.OrgId > 0 {
 		whereConditions = append(whereConditions, "org_id = ?")
 		whereParams = append(whereParams, query.OrgId)
@@ -713,6 +720,7 @@ func deleteUserInTransaction(sess *DBSession, cmd *models.DeleteUserCommand) err
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM api_key WHERE service_account_id = ?",
 	}
 
 	for _, sql := range deletes {
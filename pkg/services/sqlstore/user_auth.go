@@ -103,6 +103,11 @@ func GetUserByAuthInfo(query *models.GetUserByAuthInfoQuery) error {
 		return models.ErrUserNotFound
 	}
 
+	// Service accounts can't log in, so they are never the user of an external login
+	if user.IsServiceAccount {
+		return models.ErrUserNotFound
+	}
+
 	// Special case for generic oauth duplicates
 	if query.AuthModule == genericOAuthModule && user.Id != 0 {
 		authQuery.UserId = user.Id
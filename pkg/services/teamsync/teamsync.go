@@ -0,0 +1,94 @@
+// Package teamsync keeps the team memberships of users in sync with the groups
+// of their external authentication provider, such as LDAP or OAuth.
+package teamsync
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func init() {
+	registry.RegisterService(&TeamSyncService{})
+}
+
+var logger = log.New("teamsync")
+
+// TeamSyncService registers the team sync function of the login service.
+type TeamSyncService struct {
+	SQLStore     *sqlstore.SQLStore `inject:""`
+	Bus          bus.Bus            `inject:""`
+	LoginService login.Service      `inject:""`
+}
+
+func (s *TeamSyncService) Init() error {
+	s.LoginService.SetTeamSyncFunc(s.SyncTeams)
+	return nil
+}
+
+// SyncTeams reconciles the team memberships of a user with the groups of the external user.
+// The user is added to the teams that any of their groups is mapped to, in the organizations they are
+// a member of. The external memberships of the user in other teams are removed, while the memberships
+// added by hand are left as they are.
+func (s *TeamSyncService) SyncTeams(user *models.User, externalUser *models.ExternalUserInfo) error {
+	groupsQuery := &models.GetTeamGroupsByGroupIdsQuery{GroupIds: externalUser.Groups}
+	if err := s.Bus.Dispatch(groupsQuery); err != nil {
+		return err
+	}
+
+	orgsQuery := &models.GetUserOrgListQuery{UserId: user.Id}
+	if err := s.Bus.Dispatch(orgsQuery); err != nil {
+		return err
+	}
+	userOrgIds := make(map[int64]bool, len(orgsQuery.Result))
+	for _, org := range orgsQuery.Result {
+		userOrgIds[org.OrgId] = true
+	}
+
+	// team ids are unique across organizations
+	syncedTeams := make(map[int64]int64)
+	for _, teamGroup := range groupsQuery.Result {
+		if userOrgIds[teamGroup.OrgId] {
+			syncedTeams[teamGroup.TeamId] = teamGroup.OrgId
+		}
+	}
+
+	membersQuery := &models.GetTeamMembersQuery{UserId: user.Id}
+	if err := s.Bus.Dispatch(membersQuery); err != nil {
+		return err
+	}
+
+	handledTeamIds := make(map[int64]bool, len(membersQuery.Result))
+	for _, member := range membersQuery.Result {
+		handledTeamIds[member.TeamId] = true
+		if _, ok := syncedTeams[member.TeamId]; ok || !member.External {
+			continue
+		}
+
+		logger.Debug("Removing user from team as part of team sync", "userId", user.Id, "orgId", member.OrgId, "teamId", member.TeamId)
+		cmd := &models.RemoveTeamMemberCommand{OrgId: member.OrgId, TeamId: member.TeamId, UserId: user.Id}
+		if err := s.Bus.Dispatch(cmd); err != nil &&
+			!errors.Is(err, models.ErrTeamNotFound) && !errors.Is(err, models.ErrTeamMemberNotFound) {
+			return err
+		}
+	}
+
+	for teamId, orgId := range syncedTeams {
+		if handledTeamIds[teamId] {
+			continue
+		}
+
+		logger.Debug("Adding user to team as part of team sync", "userId", user.Id, "orgId", orgId, "teamId", teamId)
+		err := s.SQLStore.AddTeamMember(user.Id, orgId, teamId, true, 0)
+		if err != nil && !errors.Is(err, models.ErrTeamNotFound) && !errors.Is(err, models.ErrTeamMemberAlreadyAdded) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package teamsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestTeamSyncService_SyncTeams(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	s := &TeamSyncService{SQLStore: sqlStore, Bus: bus.GetBus()}
+
+	user, err := sqlStore.CreateUser(context.Background(), models.CreateUserCommand{Login: "user", Email: "user@test.com"})
+	require.NoError(t, err)
+	otherOrg, err := sqlStore.CreateOrgWithMember("other org", 0)
+	require.NoError(t, err)
+
+	const adminsGroup = "cn=admins,ou=groups,dc=grafana,dc=org"
+	const editorsGroup = "grafana/editors"
+	createTeam := func(name string, orgID int64, groups ...string) models.Team {
+		team, err := sqlStore.CreateTeam(name, "", orgID)
+		require.NoError(t, err)
+		for _, group := range groups {
+			require.NoError(t, sqlstore.AddTeamGroup(&models.AddTeamGroupCommand{OrgId: orgID, TeamId: team.Id, GroupId: group}))
+		}
+		return team
+	}
+	adminsTeam := createTeam("admins", user.OrgId, adminsGroup)
+	editorsTeam := createTeam("editors", user.OrgId, editorsGroup)
+	manualTeam := createTeam("manual", user.OrgId)
+	otherOrgTeam := createTeam("other org admins", otherOrg.Id, adminsGroup)
+
+	// a membership added by hand is never removed by the team sync
+	require.NoError(t, sqlStore.AddTeamMember(user.Id, user.OrgId, manualTeam.Id, false, 0))
+
+	getTeamMemberships := func(t *testing.T) map[int64]bool {
+		t.Helper()
+		query := models.GetTeamMembersQuery{UserId: user.Id}
+		require.NoError(t, sqlstore.GetTeamMembers(&query))
+		memberships := make(map[int64]bool, len(query.Result))
+		for _, member := range query.Result {
+			memberships[member.TeamId] = member.External
+		}
+		return memberships
+	}
+
+	t.Run("adds the user to the teams of their groups in their organizations", func(t *testing.T) {
+		err := s.SyncTeams(user, &models.ExternalUserInfo{Groups: []string{adminsGroup, editorsGroup}})
+		require.NoError(t, err)
+		require.Equal(t, map[int64]bool{
+			adminsTeam.Id:  true,
+			editorsTeam.Id: true,
+			manualTeam.Id:  false,
+		}, getTeamMemberships(t))
+	})
+
+	t.Run("does nothing when the groups did not change", func(t *testing.T) {
+		err := s.SyncTeams(user, &models.ExternalUserInfo{Groups: []string{adminsGroup, editorsGroup}})
+		require.NoError(t, err)
+		require.Len(t, getTeamMemberships(t), 3)
+	})
+
+	t.Run("removes the user from the teams of groups they left", func(t *testing.T) {
+		err := s.SyncTeams(user, &models.ExternalUserInfo{Groups: []string{editorsGroup}})
+		require.NoError(t, err)
+		require.Equal(t, map[int64]bool{
+			editorsTeam.Id: true,
+			manualTeam.Id:  false,
+		}, getTeamMemberships(t))
+	})
+
+	t.Run("adds the user to the teams of an organization they joined", func(t *testing.T) {
+		require.NoError(t, sqlstore.AddOrgUser(&models.AddOrgUserCommand{OrgId: otherOrg.Id, UserId: user.Id, Role: models.ROLE_VIEWER}))
+
+		err := s.SyncTeams(user, &models.ExternalUserInfo{Groups: []string{adminsGroup}})
+		require.NoError(t, err)
+		require.Equal(t, map[int64]bool{
+			adminsTeam.Id:   true,
+			otherOrgTeam.Id: true,
+			manualTeam.Id:   false,
+		}, getTeamMemberships(t))
+	})
+
+	t.Run("removes the user from all synced teams without groups", func(t *testing.T) {
+		err := s.SyncTeams(user, &models.ExternalUserInfo{})
+		require.NoError(t, err)
+		require.Equal(t, map[int64]bool{
+			manualTeam.Id: false,
+		}, getTeamMemberships(t))
+	})
+}
@@ -226,6 +226,12 @@ type Cfg struct {
 	// SMTP email settings
 	Smtp SmtpSettings
 
+	// Unified Alerting
+	UnifiedAlerting UnifiedAlertingSettings
+
+	// SAML authentication
+	SAML SAMLSettings
+
 	// Rendering
 	ImagesDir                      string
 	RendererUrl                    string
@@ -340,6 +346,9 @@ type Cfg struct {
 	// Sentry config
 	Sentry Sentry
 
+	// Rate limiting of route groups
+	RateLimiting RateLimitSettings
+
 	// Data sources
 	DataSourceLimit int
 
@@ -356,8 +365,10 @@ type Cfg struct {
 	ReportingEnabled     bool
 
 	// LDAP
-	LDAPEnabled     bool
-	LDAPAllowSignup bool
+	LDAPEnabled           bool
+	LDAPAllowSignup       bool
+	LDAPSyncCron          string
+	LDAPActiveSyncEnabled bool
 
 	Quota QuotaSettings
 
@@ -370,6 +381,12 @@ type Cfg struct {
 
 	// ExpressionsEnabled specifies whether expressions are enabled.
 	ExpressionsEnabled bool
+	// ExpressionsMaxConcurrentQueries limits how many datasource queries of a
+	// single expression request are executed at the same time.
+	ExpressionsMaxConcurrentQueries int
+	// ExpressionsQueryCacheTTL is how long datasource query results used by
+	// expressions are cached. Zero disables the cache.
+	ExpressionsQueryCacheTTL time.Duration
 
 	ImageUploadProvider string
 }
@@ -526,6 +543,8 @@ func (cfg *Cfg) readAnnotationSettings() {
 func (cfg *Cfg) readExpressionsSettings() {
 	expressions := cfg.Raw.Section("expressions")
 	cfg.ExpressionsEnabled = expressions.Key("enabled").MustBool(true)
+	cfg.ExpressionsMaxConcurrentQueries = expressions.Key("max_concurrent_queries").MustInt(10)
+	cfg.ExpressionsQueryCacheTTL = expressions.Key("query_cache_ttl").MustDuration(0)
 }
 
 type AnnotationCleanupSettings struct {
@@ -907,9 +926,16 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	cfg.handleAWSConfig()
 	cfg.readSessionConfig()
 	cfg.readSmtpSettings()
+	cfg.readSAMLSettings()
 	cfg.readQuotaSettings()
+	if err := cfg.readRateLimitSettings(); err != nil {
+		return err
+	}
 	cfg.readAnnotationSettings()
 	cfg.readExpressionsSettings()
+	if err := cfg.readUnifiedAlertingSettings(); err != nil {
+		return err
+	}
 	if err := cfg.readGrafanaEnvironmentMetrics(); err != nil {
 		return err
 	}
@@ -961,9 +987,11 @@ func (cfg *Cfg) readLDAPConfig() {
 	ldapSec := cfg.Raw.Section("auth.ldap")
 	LDAPConfigFile = ldapSec.Key("config_file").String()
 	LDAPSyncCron = ldapSec.Key("sync_cron").String()
+	cfg.LDAPSyncCron = LDAPSyncCron
 	LDAPEnabled = ldapSec.Key("enabled").MustBool(false)
 	cfg.LDAPEnabled = LDAPEnabled
 	LDAPActiveSyncEnabled = ldapSec.Key("active_sync_enabled").MustBool(false)
+	cfg.LDAPActiveSyncEnabled = LDAPActiveSyncEnabled
 	LDAPAllowSignup = ldapSec.Key("allow_sign_up").MustBool(true)
 	cfg.LDAPAllowSignup = LDAPAllowSignup
 }
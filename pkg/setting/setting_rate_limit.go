@@ -0,0 +1,69 @@
+package setting
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// RateLimitGroups are the route groups that can be rate limited.
+var RateLimitGroups = []string{"login", "query", "render", "search", "admin"}
+
+type RateLimitSettings struct {
+	Enabled bool
+	// Window is the period in which the requests of a user, an API key or a client IP are counted.
+	Window time.Duration
+	// Limits is the number of requests allowed per window for each route group, 0 means unlimited.
+	Limits map[string]int64
+	// TrustedProxies are the networks of the proxies whose X-Forwarded-For header gives the client IP.
+	TrustedProxies []*net.IPNet
+}
+
+func (cfg *Cfg) readRateLimitSettings() error {
+	section := cfg.Raw.Section("rate_limiting")
+	cfg.RateLimiting = RateLimitSettings{
+		Enabled: section.Key("enabled").MustBool(false),
+		Window:  section.Key("window").MustDuration(time.Minute),
+		Limits:  make(map[string]int64, len(RateLimitGroups)),
+	}
+	// the remote cache expires items in whole seconds
+	if cfg.RateLimiting.Window < time.Second {
+		cfg.RateLimiting.Window = time.Second
+	}
+
+	for _, group := range RateLimitGroups {
+		cfg.RateLimiting.Limits[group] = section.Key(group).MustInt64(0)
+	}
+
+	for _, proxy := range util.SplitString(section.Key("trusted_proxies").String()) {
+		network, err := parseNetwork(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q in [rate_limiting]: %w", proxy, err)
+		}
+		cfg.RateLimiting.TrustedProxies = append(cfg.RateLimiting.TrustedProxies, network)
+	}
+	return nil
+}
+
+// parseNetwork parses a network in CIDR notation or a single IP address.
+func parseNetwork(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, errors.New("invalid IP address")
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 8 * net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, network, err := net.ParseCIDR(s)
+	return network, err
+}
@@ -0,0 +1,40 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitSettings(t *testing.T) {
+	cfg := NewCfg()
+	sec, err := cfg.Raw.NewSection("rate_limiting")
+	require.NoError(t, err)
+	_, err = sec.NewKey("enabled", "true")
+	require.NoError(t, err)
+	_, err = sec.NewKey("window", "100ms")
+	require.NoError(t, err)
+	_, err = sec.NewKey("query", "600")
+	require.NoError(t, err)
+
+	_, err = sec.NewKey("trusted_proxies", "10.0.0.0/8, 192.168.1.1")
+	require.NoError(t, err)
+
+	require.NoError(t, cfg.readRateLimitSettings())
+
+	assert.True(t, cfg.RateLimiting.Enabled)
+	assert.Equal(t, time.Second, cfg.RateLimiting.Window)
+	assert.Equal(t, map[string]int64{"login": 0, "query": 600, "render": 0, "search": 0, "admin": 0}, cfg.RateLimiting.Limits)
+	require.Len(t, cfg.RateLimiting.TrustedProxies, 2)
+	assert.Equal(t, "10.0.0.0/8", cfg.RateLimiting.TrustedProxies[0].String())
+	assert.Equal(t, "192.168.1.1/32", cfg.RateLimiting.TrustedProxies[1].String())
+
+	t.Run("invalid trusted proxy", func(t *testing.T) {
+		_, err := sec.NewKey("trusted_proxies", "10.0.0.0/33")
+		require.NoError(t, err)
+
+		require.Error(t, cfg.readRateLimitSettings())
+	})
+}
@@ -0,0 +1,75 @@
+package setting
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// SAMLSettings are the settings of the SAML authentication, read from the [auth.saml] section.
+type SAMLSettings struct {
+	Enabled           bool
+	SingleLogout      bool
+	AllowIdPInitiated bool
+	AllowSignUp       bool
+
+	// Certificate and PrivateKey are base64 encoded, only one of them or of their path can be set.
+	Certificate     string
+	CertificatePath string
+	PrivateKey      string
+	PrivateKeyPath  string
+	// SignatureAlgorithm signs the requests sent to the IdP when set.
+	SignatureAlgorithm string
+
+	// IdPMetadata is base64 encoded, only one of it, its path or its URL can be set.
+	IdPMetadata     string
+	IdPMetadataPath string
+	IdPMetadataURL  string
+
+	MaxIssueDelay         time.Duration
+	MetadataValidDuration time.Duration
+	// RelayState is the relay state sent by the IdP with the responses of IdP-initiated logins.
+	RelayState string
+
+	AssertionAttributeName   string
+	AssertionAttributeLogin  string
+	AssertionAttributeEmail  string
+	AssertionAttributeGroups string
+	AssertionAttributeRole   string
+
+	RoleValuesEditor       []string
+	RoleValuesAdmin        []string
+	RoleValuesGrafanaAdmin []string
+}
+
+func (cfg *Cfg) readSAMLSettings() {
+	sec := cfg.Raw.Section("auth.saml")
+	cfg.SAML.Enabled = sec.Key("enabled").MustBool(false)
+	cfg.SAML.SingleLogout = sec.Key("single_logout").MustBool(false)
+	cfg.SAML.AllowIdPInitiated = sec.Key("allow_idp_initiated").MustBool(false)
+	cfg.SAML.AllowSignUp = sec.Key("allow_sign_up").MustBool(true)
+
+	cfg.SAML.Certificate = valueAsString(sec, "certificate", "")
+	cfg.SAML.CertificatePath = valueAsString(sec, "certificate_path", "")
+	cfg.SAML.PrivateKey = valueAsString(sec, "private_key", "")
+	cfg.SAML.PrivateKeyPath = valueAsString(sec, "private_key_path", "")
+	cfg.SAML.SignatureAlgorithm = valueAsString(sec, "signature_algorithm", "")
+
+	cfg.SAML.IdPMetadata = valueAsString(sec, "idp_metadata", "")
+	cfg.SAML.IdPMetadataPath = valueAsString(sec, "idp_metadata_path", "")
+	cfg.SAML.IdPMetadataURL = valueAsString(sec, "idp_metadata_url", "")
+
+	cfg.SAML.MaxIssueDelay = sec.Key("max_issue_delay").MustDuration(90 * time.Second)
+	cfg.SAML.MetadataValidDuration = sec.Key("metadata_valid_duration").MustDuration(48 * time.Hour)
+	cfg.SAML.RelayState = valueAsString(sec, "relay_state", "")
+
+	cfg.SAML.AssertionAttributeName = valueAsString(sec, "assertion_attribute_name", "displayName")
+	cfg.SAML.AssertionAttributeLogin = valueAsString(sec, "assertion_attribute_login", "mail")
+	cfg.SAML.AssertionAttributeEmail = valueAsString(sec, "assertion_attribute_email", "mail")
+	cfg.SAML.AssertionAttributeGroups = valueAsString(sec, "assertion_attribute_groups", "")
+	cfg.SAML.AssertionAttributeRole = valueAsString(sec, "assertion_attribute_role", "")
+
+	cfg.SAML.RoleValuesEditor = util.SplitString(valueAsString(sec, "role_values_editor", ""))
+	cfg.SAML.RoleValuesAdmin = util.SplitString(valueAsString(sec, "role_values_admin", ""))
+	cfg.SAML.RoleValuesGrafanaAdmin = util.SplitString(valueAsString(sec, "role_values_grafana_admin", ""))
+}
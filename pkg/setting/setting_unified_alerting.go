@@ -0,0 +1,84 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// AlertmanagerHAModeGossip shares the Alertmanager state between replicas by gossip.
+	AlertmanagerHAModeGossip = "gossip"
+	// AlertmanagerHAModeDatabase shares the Alertmanager state between replicas through the database.
+	AlertmanagerHAModeDatabase = "database"
+)
+
+// UnifiedAlertingSettings are the settings of unified alerting and of its embedded Alertmanager.
+type UnifiedAlertingSettings struct {
+	// HAMode is how replicas share silences and the notification log, either gossip or database.
+	HAMode string
+	// HAListenAddr is the address gossip messages are received on.
+	HAListenAddr string
+	// HAAdvertiseAddr is the address advertised to the other replicas.
+	HAAdvertiseAddr string
+	// HAPeers are the initial replicas to gossip with.
+	HAPeers []string
+	// HAPeerTimeout is how long a replica waits for the replicas before it in the cluster
+	// to send a notification, for every replica before it.
+	HAPeerTimeout time.Duration
+	// HAGossipInterval is the interval between gossip messages.
+	HAGossipInterval time.Duration
+	// HAPushPullInterval is the interval between full state syncs between replicas.
+	HAPushPullInterval time.Duration
+	// HADatabaseSyncInterval is the interval between state syncs through the database.
+	HADatabaseSyncInterval time.Duration
+	// StateHistoryMaxAge is how long the state transitions of alert instances are kept, 0 keeps them forever.
+	StateHistoryMaxAge time.Duration
+	// FlapDetectionThreshold is the number of state changes within FlapDetectionWindow after which
+	// an alert instance is flapping, 0 disables flap detection.
+	FlapDetectionThreshold int
+	// FlapDetectionWindow is the period over which the state changes of alert instances are counted.
+	FlapDetectionWindow time.Duration
+	// EvaluationJitter spreads the evaluations of each alert rule within its interval.
+	EvaluationJitter bool
+	// MaxConcurrentEvaluations is the maximum number of alert rules evaluated at once, 0 is unlimited.
+	MaxConcurrentEvaluations int
+}
+
+// HAEnabled returns whether the embedded Alertmanager runs as part of a cluster.
+func (s UnifiedAlertingSettings) HAEnabled() bool {
+	return s.HAMode == AlertmanagerHAModeDatabase || len(s.HAPeers) > 0
+}
+
+func (cfg *Cfg) readUnifiedAlertingSettings() error {
+	ua := cfg.Raw.Section("unified_alerting")
+	cfg.UnifiedAlerting.HAMode = ua.Key("ha_mode").In(AlertmanagerHAModeGossip, []string{AlertmanagerHAModeGossip, AlertmanagerHAModeDatabase})
+	cfg.UnifiedAlerting.HAListenAddr = ua.Key("ha_listen_address").MustString("0.0.0.0:9094")
+	cfg.UnifiedAlerting.HAAdvertiseAddr = ua.Key("ha_advertise_address").MustString("")
+	cfg.UnifiedAlerting.HAPeers = util.SplitString(ua.Key("ha_peers").MustString(""))
+	cfg.UnifiedAlerting.HAPeerTimeout = ua.Key("ha_peer_timeout").MustDuration(15 * time.Second)
+	cfg.UnifiedAlerting.HAGossipInterval = ua.Key("ha_gossip_interval").MustDuration(200 * time.Millisecond)
+	cfg.UnifiedAlerting.HAPushPullInterval = ua.Key("ha_push_pull_interval").MustDuration(60 * time.Second)
+	cfg.UnifiedAlerting.HADatabaseSyncInterval = ua.Key("ha_database_sync_interval").MustDuration(5 * time.Second)
+	cfg.UnifiedAlerting.StateHistoryMaxAge = ua.Key("state_history_max_age").MustDuration(30 * 24 * time.Hour)
+	cfg.UnifiedAlerting.FlapDetectionThreshold = ua.Key("flap_detection_threshold").MustInt(0)
+	cfg.UnifiedAlerting.FlapDetectionWindow = ua.Key("flap_detection_window").MustDuration(30 * time.Minute)
+	cfg.UnifiedAlerting.EvaluationJitter = ua.Key("evaluation_jitter").MustBool(true)
+	cfg.UnifiedAlerting.MaxConcurrentEvaluations = ua.Key("max_concurrent_evaluations").MustInt(0)
+
+	if cfg.UnifiedAlerting.HAMode == AlertmanagerHAModeDatabase && cfg.UnifiedAlerting.HADatabaseSyncInterval >= cfg.UnifiedAlerting.HAPeerTimeout {
+		return fmt.Errorf("unified_alerting: ha_database_sync_interval (%s) must be lower than ha_peer_timeout (%s)",
+			cfg.UnifiedAlerting.HADatabaseSyncInterval, cfg.UnifiedAlerting.HAPeerTimeout)
+	}
+
+	if cfg.UnifiedAlerting.FlapDetectionThreshold < 0 {
+		return fmt.Errorf("unified_alerting: flap_detection_threshold (%d) must not be negative", cfg.UnifiedAlerting.FlapDetectionThreshold)
+	}
+
+	if cfg.UnifiedAlerting.MaxConcurrentEvaluations < 0 {
+		return fmt.Errorf("unified_alerting: max_concurrent_evaluations (%d) must not be negative", cfg.UnifiedAlerting.MaxConcurrentEvaluations)
+	}
+
+	return nil
+}
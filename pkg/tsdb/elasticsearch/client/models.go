@@ -35,6 +35,7 @@ type SearchRequest struct {
 	Interval    interval.Interval
 	Size        int
 	Sort        map[string]interface{}
+	SortFields  []string
 	Query       *Query
 	Aggs        AggArray
 	CustomProps map[string]interface{}
@@ -47,6 +48,14 @@ func (r *SearchRequest) MarshalJSON() ([]byte, error) {
 	root["size"] = r.Size
 	if len(r.Sort) > 0 {
 		root["sort"] = r.Sort
+		// an object does not keep the order of several sorts
+		if len(r.SortFields) > 1 {
+			sorts := make([]map[string]interface{}, 0, len(r.SortFields))
+			for _, field := range r.SortFields {
+				sorts = append(sorts, map[string]interface{}{field: r.Sort[field]})
+			}
+			root["sort"] = sorts
+		}
 	}
 
 	for key, value := range r.CustomProps {
@@ -62,6 +71,14 @@ func (r *SearchRequest) MarshalJSON() ([]byte, error) {
 	return json.Marshal(root)
 }
 
+// SortOrder is the order of a search request sort
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
+)
+
 // SearchResponseHits represents search response hits
 type SearchResponseHits struct {
 	Hits []map[string]interface{}
@@ -13,6 +13,7 @@ type SearchRequestBuilder struct {
 	index        string
 	size         int
 	sort         map[string]interface{}
+	sortFields   []string
 	queryBuilder *QueryBuilder
 	aggBuilders  []AggBuilder
 	customProps  map[string]interface{}
@@ -37,6 +38,7 @@ func (b *SearchRequestBuilder) Build() (*SearchRequest, error) {
 		Interval:    b.interval,
 		Size:        b.size,
 		Sort:        b.sort,
+		SortFields:  b.sortFields,
 		CustomProps: b.customProps,
 	}
 
@@ -71,19 +73,35 @@ func (b *SearchRequestBuilder) Size(size int) *SearchRequestBuilder {
 
 // SortDesc adds a sort to the search request
 func (b *SearchRequestBuilder) SortDesc(field, unmappedType string) *SearchRequestBuilder {
+	return b.Sort(SortOrderDesc, field, unmappedType)
+}
+
+// Sort adds a sort with the given order to the search request. Documents are sorted by the
+// fields in the order in which the sorts are added.
+func (b *SearchRequestBuilder) Sort(order SortOrder, field, unmappedType string) *SearchRequestBuilder {
 	props := map[string]string{
-		"order": "desc",
+		"order": string(order),
 	}
 
 	if unmappedType != "" {
 		props["unmapped_type"] = unmappedType
 	}
 
+	if _, exists := b.sort[field]; !exists {
+		b.sortFields = append(b.sortFields, field)
+	}
 	b.sort[field] = props
 
 	return b
 }
 
+// SearchAfter sets the sort values of the last document of the previous page,
+// so that the search request returns the documents following it
+func (b *SearchRequestBuilder) SearchAfter(values []interface{}) *SearchRequestBuilder {
+	b.customProps["search_after"] = values
+	return b
+}
+
 // AddDocValueField adds a doc value field to the search request
 func (b *SearchRequestBuilder) AddDocValueField(field string) *SearchRequestBuilder {
 	// fields field not supported on version >= 5
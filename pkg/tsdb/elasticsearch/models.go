@@ -50,7 +50,11 @@ var metricAggType = map[string]string{
 	"derivative":     "Derivative",
 	"serial_diff":    "Serial Difference",
 	"bucket_script":  "Bucket Script",
+	"top_metrics":    "Top Metrics",
+	"rate":           "Rate",
 	"raw_document":   "Raw Document",
+	"raw_data":       "Raw Data",
+	"logs":           "Logs",
 }
 
 var extendedStats = map[string]string{
@@ -77,6 +81,20 @@ var pipelineAggWithMultipleBucketPathsType = map[string]string{
 	"bucket_script": "bucket_script",
 }
 
+// documentQueryType are the metric types which return documents instead of aggregations
+var documentQueryType = map[string]string{
+	"raw_document": "raw_document",
+	"raw_data":     "raw_data",
+	"logs":         "logs",
+}
+
+func isDocumentQuery(metricType string) bool {
+	if _, ok := documentQueryType[metricType]; ok {
+		return true
+	}
+	return false
+}
+
 func isPipelineAgg(metricType string) bool {
 	if _, ok := pipelineAggType[metricType]; ok {
 		return true
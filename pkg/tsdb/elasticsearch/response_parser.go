@@ -1,12 +1,16 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/plugins"
@@ -18,6 +22,10 @@ const (
 	countType         = "count"
 	percentilesType   = "percentiles"
 	extendedStatsType = "extended_stats"
+	topMetricsType    = "top_metrics"
+	rawDocumentType   = "raw_document"
+	rawDataType       = "raw_data"
+	logsType          = "logs"
 	// Bucket types
 	dateHistType    = "date_histogram"
 	histogramType   = "histogram"
@@ -67,6 +75,17 @@ func (rp *responseParser) getTimeSeries() (plugins.DataResponse, error) {
 		queryRes := plugins.DataQueryResult{
 			Meta: debugInfo,
 		}
+
+		if len(target.BucketAggs) == 0 && len(target.Metrics) > 0 && isDocumentQuery(target.Metrics[0].Type) {
+			frame, err := processDocuments(res.Hits, target)
+			if err != nil {
+				return plugins.DataResponse{}, err
+			}
+			queryRes.Dataframes = plugins.NewDecodedDataFrames(data.Frames{frame})
+			result.Results[target.RefID] = queryRes
+			continue
+		}
+
 		props := make(map[string]string)
 		table := plugins.DataTable{
 			Columns: make([]plugins.DataTableColumn, 0),
@@ -265,6 +284,26 @@ func (rp *responseParser) processMetrics(esAgg *simplejson.Json, target *Query,
 				}
 				*series = append(*series, newSeries)
 			}
+		case topMetricsType:
+			buckets := esAgg.Get("buckets").MustArray()
+			for _, field := range metric.Settings.Get("metrics").MustStringArray() {
+				newSeries := plugins.DataTimeSeries{
+					Tags: make(map[string]string),
+				}
+				for k, v := range props {
+					newSeries.Tags[k] = v
+				}
+				newSeries.Tags["metric"] = metric.Type
+				newSeries.Tags["field"] = field
+
+				for _, v := range buckets {
+					bucket := simplejson.NewFromAny(v)
+					key := castToNullFloat(bucket.Get("key"))
+					value := castToNullFloat(topMetricValue(bucket, metric.ID, field))
+					newSeries.Points = append(newSeries.Points, plugins.DataTimePoint{value, key})
+				}
+				*series = append(*series, newSeries)
+			}
 		default:
 			newSeries := plugins.DataTimeSeries{
 				Tags: make(map[string]string),
@@ -371,6 +410,10 @@ func (rp *responseParser) processAggregationDocs(esAgg *simplejson.Json, aggDef
 					addMetricValue(&values, rp.getMetricName(metric.Type), value)
 					break
 				}
+			case topMetricsType:
+				for _, field := range metric.Settings.Get("metrics").MustStringArray() {
+					addMetricValue(&values, rp.getMetricName(metric.Type)+" "+field, castToNullFloat(topMetricValue(bucket, metric.ID, field)))
+				}
 			default:
 				metricName := rp.getMetricName(metric.Type)
 				otherMetrics := make([]*MetricAgg, 0)
@@ -504,7 +547,7 @@ func (rp *responseParser) getSeriesName(series plugins.DataTimeSeries, target *Q
 			found := false
 			for _, metric := range target.Metrics {
 				if metric.ID == field {
-					metricName += " " + describeMetric(metric.Type, field)
+					metricName += " " + describeMetric(metric.Type, metric.Field)
 					found = true
 				}
 			}
@@ -546,6 +589,11 @@ func (rp *responseParser) getMetricName(metric string) string {
 	return metric
 }
 
+// topMetricValue returns the value of a metric field of the top document of a top_metrics aggregation.
+func topMetricValue(bucket *simplejson.Json, metricID, field string) *simplejson.Json {
+	return bucket.GetPath(metricID, "top").GetIndex(0).GetPath("metrics", field)
+}
+
 func castToNullFloat(j *simplejson.Json) null.Float {
 	f, err := j.Float64()
 	if err == nil {
@@ -592,3 +640,181 @@ func getErrorFromElasticResponse(response *es.SearchResponse) plugins.DataQueryR
 
 	return result
 }
+
+// maxFlattenDepth is the depth up to which nested document objects are flattened into fields.
+const maxFlattenDepth = 10
+
+// processDocuments returns a frame with a row per document hit. Nested objects of the
+// document source are flattened into fields with dotted names, e.g. "host.name". The time
+// field comes first, followed by the other fields sorted by name. The sort values of the
+// last hit are returned in the frame meta as searchAfter, to request the next page.
+func processDocuments(hits *es.SearchResponseHits, target *Query) (*data.Frame, error) {
+	metricType := target.Metrics[0].Type
+
+	docs := make([]map[string]interface{}, 0)
+	var searchAfter interface{}
+	if hits != nil {
+		for _, hit := range hits.Hits {
+			doc := map[string]interface{}{}
+			if source, ok := hit["_source"].(map[string]interface{}); ok {
+				flatten(doc, "", source, 0)
+				if metricType == logsType {
+					encoded, err := json.Marshal(source)
+					if err != nil {
+						return nil, err
+					}
+					doc["_source"] = string(encoded)
+				}
+			}
+			for _, key := range []string{"_id", "_index", "_type"} {
+				if value, ok := hit[key]; ok {
+					doc[key] = value
+				}
+			}
+			if _, ok := doc[target.TimeField]; !ok {
+				if fields, ok := hit["fields"].(map[string]interface{}); ok {
+					if values, ok := fields[target.TimeField].([]interface{}); ok && len(values) > 0 {
+						doc[target.TimeField] = values[0]
+					}
+				}
+			}
+			searchAfter = hit["sort"]
+			docs = append(docs, doc)
+		}
+	}
+
+	fieldNames := make([]string, 0)
+	seen := map[string]bool{}
+	for _, doc := range docs {
+		for name := range doc {
+			if !seen[name] && name != target.TimeField {
+				seen[name] = true
+				fieldNames = append(fieldNames, name)
+			}
+		}
+	}
+	sort.Strings(fieldNames)
+
+	timeValues := make([]*time.Time, len(docs))
+	for i, doc := range docs {
+		t, err := parseDocumentTime(doc[target.TimeField])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse time field %q: %w", target.TimeField, err)
+		}
+		timeValues[i] = t
+	}
+
+	fields := []*data.Field{data.NewField(target.TimeField, nil, timeValues)}
+	for _, name := range fieldNames {
+		field, err := documentField(name, docs)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+
+	frame := data.NewFrame("", fields...)
+	frame.RefID = target.RefID
+	frame.Meta = &data.FrameMeta{}
+	if metricType == logsType {
+		frame.Meta.PreferredVisualization = data.VisTypeLogs
+	}
+	if searchAfter != nil {
+		frame.Meta.Custom = map[string]interface{}{"searchAfter": searchAfter}
+	}
+	return frame, nil
+}
+
+func flatten(target map[string]interface{}, prefix string, source map[string]interface{}, depth int) {
+	for key, value := range source {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && depth < maxFlattenDepth {
+			flatten(target, name, nested, depth+1)
+			continue
+		}
+		target[name] = value
+	}
+}
+
+// parseDocumentTime parses a document time value, which is either a date string or
+// a number of milliseconds since epoch.
+func parseDocumentTime(value interface{}) (*time.Time, error) {
+	var t time.Time
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		t = time.Unix(0, int64(v*float64(time.Millisecond)))
+	case string:
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+			t = time.Unix(0, ms*int64(time.Millisecond))
+			break
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, err
+		}
+		t = parsed
+	default:
+		return nil, fmt.Errorf("unsupported time value of type %T", value)
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+// documentField returns a field with the values of the named document property. Numbers
+// and booleans are kept as such if all documents agree on the type, other values are
+// returned as strings, with arrays and objects JSON encoded.
+func documentField(name string, docs []map[string]interface{}) (*data.Field, error) {
+	allNumbers, allBools := true, true
+	for _, doc := range docs {
+		switch doc[name].(type) {
+		case nil:
+		case float64:
+			allBools = false
+		case bool:
+			allNumbers = false
+		default:
+			allNumbers, allBools = false, false
+		}
+	}
+
+	switch {
+	case allNumbers:
+		values := make([]*float64, len(docs))
+		for i, doc := range docs {
+			if v, ok := doc[name].(float64); ok {
+				values[i] = &v
+			}
+		}
+		return data.NewField(name, nil, values), nil
+	case allBools:
+		values := make([]*bool, len(docs))
+		for i, doc := range docs {
+			if v, ok := doc[name].(bool); ok {
+				values[i] = &v
+			}
+		}
+		return data.NewField(name, nil, values), nil
+	}
+
+	values := make([]*string, len(docs))
+	for i, doc := range docs {
+		switch v := doc[name].(type) {
+		case nil:
+		case string:
+			values[i] = &v
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			s := string(encoded)
+			values[i] = &s
+		}
+	}
+	return data.NewField(name, nil, values), nil
+}
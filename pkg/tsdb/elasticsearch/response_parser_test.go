@@ -6,10 +6,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/plugins"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+	"github.com/stretchr/testify/require"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -994,6 +996,229 @@ func TestResponseParser(t *testing.T) {
 	})
 }
 
+func TestResponseParserPipelineAndTopMetrics(t *testing.T) {
+	t.Run("top_metrics returns a series per metric field", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{
+					"type": "top_metrics",
+					"id": "1",
+					"settings": { "order": "desc", "orderBy": "@timestamp", "metrics": ["@value", "@anotherValue"] }
+				}],
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }]
+			}`,
+		}
+		response := `{
+			"responses": [{
+				"aggregations": {
+					"2": {
+						"buckets": [
+							{
+								"key": 1000,
+								"1": { "top": [{ "sort": ["2021-01-01T00:00:00.000Z"], "metrics": { "@value": 1, "@anotherValue": 2 } }] }
+							},
+							{
+								"key": 2000,
+								"1": { "top": [{ "sort": ["2021-01-01T00:00:10.000Z"], "metrics": { "@value": 3, "@anotherValue": 4 } }] }
+							}
+						]
+					}
+				}
+			}]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		series := result.Results["A"].Series
+		require.Len(t, series, 2)
+		require.Equal(t, "Top Metrics @value", series[0].Name)
+		require.Len(t, series[0].Points, 2)
+		require.Equal(t, 1., series[0].Points[0][0].Float64)
+		require.Equal(t, 3., series[0].Points[1][0].Float64)
+		require.Equal(t, 2000., series[0].Points[1][1].Float64)
+		require.Equal(t, "Top Metrics @anotherValue", series[1].Name)
+		require.Equal(t, 4., series[1].Points[1][0].Float64)
+	})
+
+	t.Run("top_metrics in a table", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{
+					"type": "top_metrics",
+					"id": "1",
+					"settings": { "order": "desc", "orderBy": "@timestamp", "metrics": ["@value"] }
+				}],
+				"bucketAggs": [{ "type": "terms", "field": "host", "id": "2" }]
+			}`,
+		}
+		response := `{
+			"responses": [{
+				"aggregations": {
+					"2": {
+						"buckets": [
+							{ "key": "server-1", "1": { "top": [{ "sort": [1], "metrics": { "@value": 10 } }] } }
+						]
+					}
+				}
+			}]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		tables := result.Results["A"].Tables
+		require.Len(t, tables, 1)
+		require.Equal(t, "Top Metrics @value", tables[0].Columns[1].Text)
+		require.Equal(t, 10., tables[0].Rows[0][1].(null.Float).Float64)
+	})
+
+	t.Run("derivative and rate", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [
+					{ "type": "rate", "id": "1", "field": "bytes" },
+					{ "type": "derivative", "id": "3", "field": "1", "pipelineAgg": "1", "settings": { "unit": "1s" } }
+				],
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }]
+			}`,
+		}
+		response := `{
+			"responses": [{
+				"aggregations": {
+					"2": {
+						"buckets": [
+							{ "key": 1000, "1": { "value": 10 } },
+							{ "key": 2000, "1": { "value": 30 }, "3": { "value": 200, "normalized_value": 20 } }
+						]
+					}
+				}
+			}]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		series := result.Results["A"].Series
+		require.Len(t, series, 2)
+		require.Equal(t, "Rate bytes", series[0].Name)
+		require.Len(t, series[0].Points, 2)
+		require.Equal(t, 30., series[0].Points[1][0].Float64)
+		require.Equal(t, "Derivative Rate bytes", series[1].Name)
+		require.Len(t, series[1].Points, 1)
+		require.Equal(t, 20., series[1].Points[0][0].Float64)
+	})
+}
+
+func TestProcessDocuments(t *testing.T) {
+	response := `{
+		"responses": [
+			{
+				"hits": {
+					"hits": [
+						{
+							"_id": "1",
+							"_index": "logs",
+							"_source": {
+								"@timestamp": "2021-04-01T10:00:01.000Z",
+								"message": "first",
+								"host": { "name": "server-1", "cpu": 0.5 },
+								"tags": ["a", "b"],
+								"ok": true
+							},
+							"sort": [1617271201000]
+						},
+						{
+							"_id": "2",
+							"_index": "logs",
+							"_source": {
+								"@timestamp": "2021-04-01T10:00:00.000Z",
+								"message": "second",
+								"host": { "name": "server-2" },
+								"ok": false
+							},
+							"sort": [1617271200000]
+						}
+					]
+				}
+			}
+		]
+	}`
+
+	t.Run("raw_data query returns a flattened frame", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "raw_data", "id": "1" }]
+			}`,
+		}
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames, err := result.Results["A"].Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		frame := frames[0]
+
+		names := make([]string, 0, len(frame.Fields))
+		for _, f := range frame.Fields {
+			names = append(names, f.Name)
+		}
+		require.Equal(t, []string{"@timestamp", "_id", "_index", "host.cpu", "host.name", "message", "ok", "tags"}, names)
+		require.Equal(t, 2, frame.Rows())
+
+		ts := time.Date(2021, 4, 1, 10, 0, 1, 0, time.UTC)
+		require.Equal(t, &ts, frame.Fields[0].At(0))
+		cpu := 0.5
+		require.Equal(t, &cpu, frame.Fields[3].At(0))
+		require.Nil(t, frame.Fields[3].At(1))
+		host := "server-2"
+		require.Equal(t, &host, frame.Fields[4].At(1))
+		ok := true
+		require.Equal(t, &ok, frame.Fields[6].At(0))
+		tags := `["a","b"]`
+		require.Equal(t, &tags, frame.Fields[7].At(0))
+
+		require.Equal(t, []interface{}{float64(1617271200000)}, frame.Meta.Custom.(map[string]interface{})["searchAfter"])
+		require.Empty(t, frame.Meta.PreferredVisualization)
+	})
+
+	t.Run("logs query returns a log frame with the source", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "logs", "id": "1" }]
+			}`,
+		}
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames, err := result.Results["A"].Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, data.VisTypeLogs, string(frames[0].Meta.PreferredVisualization))
+
+		var source *data.Field
+		for _, f := range frames[0].Fields {
+			if f.Name == "_source" {
+				source = f
+			}
+		}
+		require.NotNil(t, source)
+		require.Contains(t, *source.At(1).(*string), `"message":"second"`)
+	})
+}
+
 func newResponseParserForTest(tsdbQueries map[string]string, responseBody string) (*responseParser, error) {
 	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
 	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
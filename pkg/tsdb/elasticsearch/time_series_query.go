@@ -11,6 +11,9 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/interval"
 )
 
+// defaultDocumentsSize is the number of documents returned by queries without a size.
+const defaultDocumentsSize = 500
+
 type timeSeriesQuery struct {
 	client             es.Client
 	tsdbQuery          plugins.DataQuery
@@ -80,7 +83,7 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 	}
 
 	if len(q.BucketAggs) == 0 {
-		if len(q.Metrics) == 0 || q.Metrics[0].Type != "raw_document" {
+		if len(q.Metrics) == 0 || !isDocumentQuery(q.Metrics[0].Type) {
 			result.Results[q.RefID] = plugins.DataQueryResult{
 				RefID:       q.RefID,
 				Error:       fmt.Errorf("invalid query, missing metrics and aggregations"),
@@ -88,10 +91,7 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 			}
 			return nil
 		}
-		metric := q.Metrics[0]
-		b.Size(metric.Settings.Get("size").MustInt(500))
-		b.SortDesc("@timestamp", "boolean")
-		b.AddDocValueField("@timestamp")
+		processDocumentQuery(q, b, e.client.GetTimeField())
 		return nil
 	}
 
@@ -171,6 +171,10 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 					continue
 				}
 			}
+		} else if m.Type == topMetricsType {
+			aggBuilder.Metric(m.ID, m.Type, "", func(a *es.MetricAggregation) {
+				a.Settings = m.generateSettingsForDSL()
+			})
 		} else {
 			aggBuilder.Metric(m.ID, m.Type, m.Field, func(a *es.MetricAggregation) {
 				a.Settings = m.Settings.MustMap()
@@ -181,6 +185,40 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 	return nil
 }
 
+// processDocumentQuery builds a search request returning the documents of a raw_document,
+// raw_data or logs query, sorted by time. The documents following a previous page are
+// requested by setting its last sort values in the searchAfter setting. Documents with the
+// same time are sorted by their index order, so that none is skipped between two pages.
+func processDocumentQuery(q *Query, b *es.SearchRequestBuilder, timeField string) {
+	metric := q.Metrics[0]
+	b.Size(intSetting(metric.Settings, "size", defaultDocumentsSize))
+
+	order := es.SortOrderDesc
+	if metric.Settings.Get("sortDirection").MustString("") == string(es.SortOrderAsc) {
+		order = es.SortOrderAsc
+	}
+	b.Sort(order, timeField, "boolean")
+	b.Sort(order, "_doc", "")
+	b.AddDocValueField(timeField)
+
+	if searchAfter := metric.Settings.Get("searchAfter").MustArray(); len(searchAfter) > 0 {
+		b.SearchAfter(searchAfter)
+	}
+}
+
+// intSetting returns a setting that can be either a number or a numeric string.
+func intSetting(settings *simplejson.Json, name string, defaultValue int) int {
+	if value, err := settings.Get(name).Int(); err == nil {
+		return value
+	}
+	if value, err := settings.Get(name).String(); err == nil {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
 // Casts values to int when required by Elastic's query DSL
 func (metricAggregation MetricAgg) generateSettingsForDSL() map[string]interface{} {
 	setFloatPath := func(path ...string) {
@@ -199,13 +237,41 @@ func (metricAggregation MetricAgg) generateSettingsForDSL() map[string]interface
 		setFloatPath("settings", "beta")
 		setFloatPath("settings", "gamma")
 		setFloatPath("settings", "period")
+	case "moving_fn":
+		setFloatPath("window")
+		setFloatPath("shift")
 	case "serial_diff":
 		setFloatPath("lag")
+	case topMetricsType:
+		return topMetricsSettingsForDSL(metricAggregation.Settings)
 	}
 
 	return metricAggregation.Settings.MustMap()
 }
 
+// topMetricsSettingsForDSL converts the top_metrics settings of the query model, the list of
+// metric fields and the field and order to sort by, to the top_metrics DSL returning the
+// metrics of the top document of each bucket.
+func topMetricsSettingsForDSL(settings *simplejson.Json) map[string]interface{} {
+	metrics := make([]interface{}, 0)
+	for _, field := range settings.Get("metrics").MustStringArray() {
+		metrics = append(metrics, map[string]interface{}{"field": field})
+	}
+
+	dsl := map[string]interface{}{
+		"metrics": metrics,
+		"size":    1,
+	}
+
+	if orderBy := settings.Get("orderBy").MustString(""); orderBy != "" {
+		dsl["sort"] = []interface{}{
+			map[string]interface{}{orderBy: settings.Get("order").MustString("desc")},
+		}
+	}
+
+	return dsl
+}
+
 func addDateHistogramAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, timeFrom, timeTo string) es.AggBuilder {
 	aggBuilder.DateHistogram(bucketAgg.ID, bucketAgg.Field, func(a *es.DateHistogramAgg, b es.AggBuilder) {
 		a.Interval = bucketAgg.Settings.Get("interval").MustString("auto")
@@ -1,6 +1,7 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -932,6 +933,148 @@ func TestSettingsCasting(t *testing.T) {
 
 		assert.Equal(t, 1., serialDiffSettings["lag"])
 	})
+
+	t.Run("Correctly transforms moving_fn settings", func(t *testing.T) {
+		c := newFakeClient(7)
+		_, err := executeTsdbQuery(c, `{
+			"timeField": "@timestamp",
+			"bucketAggs": [
+				{ "type": "date_histogram", "field": "@timestamp", "id": "2" }
+			],
+			"metrics": [
+				{ "id": "1", "type": "avg", "field": "@value" },
+				{
+					"id": "3",
+					"type": "moving_fn",
+					"field": "1",
+					"pipelineAgg": "1",
+					"settings": {
+						"window": "5",
+						"shift": "1",
+						"script": "MovingFunctions.unweightedAvg(values)"
+					}
+				}
+			]
+		}`, from, to, 15*time.Second)
+		assert.Nil(t, err)
+		sr := c.multisearchRequests[0].Requests[0]
+
+		movingFnAgg := sr.Aggs[0].Aggregation.Aggs[1].Aggregation.Aggregation.(*es.PipelineAggregation)
+
+		assert.Equal(t, "1", movingFnAgg.BucketPath)
+		assert.Equal(t, 5., movingFnAgg.Settings["window"])
+		assert.Equal(t, 1., movingFnAgg.Settings["shift"])
+		assert.Equal(t, "MovingFunctions.unweightedAvg(values)", movingFnAgg.Settings["script"])
+	})
+
+	t.Run("Correctly transforms top_metrics settings", func(t *testing.T) {
+		c := newFakeClient(7)
+		_, err := executeTsdbQuery(c, `{
+			"timeField": "@timestamp",
+			"bucketAggs": [
+				{ "type": "date_histogram", "field": "@timestamp", "id": "2" }
+			],
+			"metrics": [
+				{
+					"id": "1",
+					"type": "top_metrics",
+					"settings": {
+						"order": "asc",
+						"orderBy": "@timestamp",
+						"metrics": ["@value", "@anotherValue"]
+					}
+				}
+			]
+		}`, from, to, 15*time.Second)
+		assert.Nil(t, err)
+		sr := c.multisearchRequests[0].Requests[0]
+
+		topMetricsAgg := sr.Aggs[0].Aggregation.Aggs[0]
+		assert.Equal(t, "top_metrics", topMetricsAgg.Aggregation.Type)
+		topMetrics := topMetricsAgg.Aggregation.Aggregation.(*es.MetricAggregation)
+		assert.Equal(t, "", topMetrics.Field)
+		assert.Equal(t, map[string]interface{}{
+			"metrics": []interface{}{
+				map[string]interface{}{"field": "@value"},
+				map[string]interface{}{"field": "@anotherValue"},
+			},
+			"size": 1,
+			"sort": []interface{}{
+				map[string]interface{}{"@timestamp": "asc"},
+			},
+		}, topMetrics.Settings)
+	})
+
+	t.Run("Passes rate settings through", func(t *testing.T) {
+		c := newFakeClient(7)
+		_, err := executeTsdbQuery(c, `{
+			"timeField": "@timestamp",
+			"bucketAggs": [
+				{ "type": "date_histogram", "field": "@timestamp", "id": "2", "settings": { "interval": "1m" } }
+			],
+			"metrics": [
+				{ "id": "1", "type": "rate", "field": "bytes", "settings": { "unit": "second", "mode": "sum" } }
+			]
+		}`, from, to, 15*time.Second)
+		assert.Nil(t, err)
+		sr := c.multisearchRequests[0].Requests[0]
+
+		rateAgg := sr.Aggs[0].Aggregation.Aggs[0]
+		assert.Equal(t, "rate", rateAgg.Aggregation.Type)
+		rate := rateAgg.Aggregation.Aggregation.(*es.MetricAggregation)
+		assert.Equal(t, "bytes", rate.Field)
+		assert.Equal(t, "second", rate.Settings["unit"])
+		assert.Equal(t, "sum", rate.Settings["mode"])
+	})
+}
+
+func TestDocumentQueries(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+
+	t.Run("With raw data query sorted ascending after a previous page", func(t *testing.T) {
+		c := newFakeClient(7)
+		c.timeField = "time"
+		_, err := executeTsdbQuery(c, `{
+			"timeField": "time",
+			"bucketAggs": [],
+			"metrics": [
+				{
+					"id": "1",
+					"type": "raw_data",
+					"settings": { "size": "100", "sortDirection": "asc", "searchAfter": [1617271200000] }
+				}
+			]
+		}`, from, to, 15*time.Second)
+		assert.Nil(t, err)
+		sr := c.multisearchRequests[0].Requests[0]
+
+		assert.Equal(t, 100, sr.Size)
+		assert.Equal(t, map[string]string{"order": "asc", "unmapped_type": "boolean"}, sr.Sort["time"])
+		assert.Equal(t, map[string]string{"order": "asc"}, sr.Sort["_doc"])
+		assert.Equal(t, []string{"time", "_doc"}, sr.SortFields)
+		assert.Equal(t, []interface{}{json.Number("1617271200000")}, sr.CustomProps["search_after"])
+		assert.Equal(t, []string{"time"}, sr.CustomProps["docvalue_fields"])
+
+		body, err := json.Marshal(sr)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), `"sort":[{"time":{"order":"asc","unmapped_type":"boolean"}},{"_doc":{"order":"asc"}}]`)
+	})
+
+	t.Run("With logs query", func(t *testing.T) {
+		c := newFakeClient(7)
+		_, err := executeTsdbQuery(c, `{
+			"timeField": "@timestamp",
+			"bucketAggs": [],
+			"metrics": [{ "id": "1", "type": "logs" }]
+		}`, from, to, 15*time.Second)
+		assert.Nil(t, err)
+		sr := c.multisearchRequests[0].Requests[0]
+
+		assert.Equal(t, 500, sr.Size)
+		assert.Equal(t, map[string]string{"order": "desc", "unmapped_type": "boolean"}, sr.Sort["@timestamp"])
+		assert.NotContains(t, sr.CustomProps, "search_after")
+	})
 }
 
 type fakeClient struct {
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
@@ -35,6 +37,9 @@ func newExecutor() *LokiExecutor {
 	}
 }
 
+// defaultMaxLines is the line limit of log queries that do not specify one.
+const defaultMaxLines = 1000
+
 var (
 	plog         = log.New("tsdb.loki")
 	legendFormat = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
@@ -75,12 +80,15 @@ func (e *LokiExecutor) DataQuery(ctx context.Context, dsInfo *models.DataSource,
 		span.SetTag("stop_unixnano", query.End.UnixNano())
 		defer span.Finish()
 
-		//Currently hard coded as not used - applies to log queries
-		limit := 1000
-		//Currently hard coded as not used - applies to queries which produce a stream response
-		interval := time.Second * 1
+		var value *loghttp.QueryResponse
+		if query.Instant {
+			value, err = client.Query(query.Expr, query.MaxLines, query.End, query.Direction, false)
+		} else {
+			//Currently hard coded as not used - applies to queries which produce a stream response
+			interval := time.Second * 1
 
-		value, err := client.QueryRange(query.Expr, limit, query.Start, query.End, logproto.BACKWARD, query.Step, interval, false)
+			value, err = client.QueryRange(query.Expr, query.MaxLines, query.Start, query.End, query.Direction, query.Step, interval, false)
+		}
 		if err != nil {
 			return plugins.DataResponse{}, err
 		}
@@ -142,6 +150,16 @@ func (e *LokiExecutor) parseQuery(dsInfo *models.DataSource, queryContext plugin
 		interval := e.intervalCalculator.Calculate(*queryContext.TimeRange, dsInterval)
 		step := time.Duration(int64(interval.Value))
 
+		maxLines, err := getMaxLines(dsInfo, queryModel.Model)
+		if err != nil {
+			return nil, err
+		}
+
+		direction, err := getDirection(queryModel.Model)
+		if err != nil {
+			return nil, err
+		}
+
 		qs = append(qs, &lokiQuery{
 			Expr:         expr,
 			Step:         step,
@@ -149,33 +167,81 @@ func (e *LokiExecutor) parseQuery(dsInfo *models.DataSource, queryContext plugin
 			Start:        start,
 			End:          end,
 			RefID:        queryModel.RefID,
+			MaxLines:     maxLines,
+			Direction:    direction,
+			Instant:      queryModel.Model.Get("instant").MustBool(false),
 		})
 	}
 
 	return qs, nil
 }
 
+// getMaxLines returns the line limit of log queries. The limit of the query takes
+// precedence over the one configured for the data source.
+func getMaxLines(dsInfo *models.DataSource, model *simplejson.Json) (int, error) {
+	maxLines := defaultMaxLines
+	if dsInfo.JsonData != nil {
+		if dsMaxLines := dsInfo.JsonData.Get("maxLines").MustString(""); dsMaxLines != "" {
+			parsed, err := strconv.Atoi(dsMaxLines)
+			if err != nil {
+				return 0, fmt.Errorf("failed to parse data source maxLines: %v", err)
+			}
+			maxLines = parsed
+		}
+	}
+
+	maxLines = model.Get("maxLines").MustInt(maxLines)
+	if maxLines <= 0 {
+		return 0, fmt.Errorf("maxLines must be a positive number, got %d", maxLines)
+	}
+	return maxLines, nil
+}
+
+// getDirection returns the direction in which log lines are returned, newest first by default.
+func getDirection(model *simplejson.Json) (logproto.Direction, error) {
+	direction := model.Get("direction").MustString("")
+	if direction == "" {
+		return logproto.BACKWARD, nil
+	}
+
+	value, ok := logproto.Direction_value[strings.ToUpper(direction)]
+	if !ok {
+		return 0, fmt.Errorf("unsupported direction %q, must be one of FORWARD or BACKWARD", direction)
+	}
+	return logproto.Direction(value), nil
+}
+
 //nolint: staticcheck // plugins.DataPlugin deprecated
 func parseResponse(value *loghttp.QueryResponse, query *lokiQuery) (plugins.DataQueryResult, error) {
 	var queryRes plugins.DataQueryResult
-	frames := data.Frames{}
-
-	//We are currently processing only matrix results (for alerting)
-	matrix, ok := value.Data.Result.(loghttp.Matrix)
-	if !ok {
+	var frames data.Frames
+
+	switch result := value.Data.Result.(type) {
+	case loghttp.Matrix:
+		frames = parseMatrix(result, query)
+	case loghttp.Vector:
+		frames = parseVector(result, query)
+	case loghttp.Scalar:
+		frames = data.Frames{data.NewFrame(query.Expr,
+			data.NewField("time", nil, []time.Time{result.Timestamp.Time().UTC()}),
+			data.NewField("value", nil, []float64{float64(result.Value)}))}
+	case loghttp.Streams:
+		frames = parseStreams(result, query)
+	default:
 		return queryRes, fmt.Errorf("unsupported result format: %q", value.Data.ResultType)
 	}
+	queryRes.Dataframes = plugins.NewDecodedDataFrames(frames)
+
+	return queryRes, nil
+}
 
+func parseMatrix(matrix loghttp.Matrix, query *lokiQuery) data.Frames {
+	frames := data.Frames{}
 	for _, v := range matrix {
 		name := formatLegend(v.Metric, query)
-		tags := make(map[string]string, len(v.Metric))
 		timeVector := make([]time.Time, 0, len(v.Values))
 		values := make([]float64, 0, len(v.Values))
 
-		for k, v := range v.Metric {
-			tags[string(k)] = string(v)
-		}
-
 		for _, k := range v.Values {
 			timeVector = append(timeVector, time.Unix(k.Timestamp.Unix(), 0).UTC())
 			values = append(values, float64(k.Value))
@@ -183,9 +249,50 @@ func parseResponse(value *loghttp.QueryResponse, query *lokiQuery) (plugins.Data
 
 		frames = append(frames, data.NewFrame(name,
 			data.NewField("time", nil, timeVector),
-			data.NewField("value", tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
+			data.NewField("value", metricLabels(v.Metric), values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
 	}
-	queryRes.Dataframes = plugins.NewDecodedDataFrames(frames)
+	return frames
+}
 
-	return queryRes, nil
+// parseVector returns a single row frame per sample of an instant query.
+func parseVector(vector loghttp.Vector, query *lokiQuery) data.Frames {
+	frames := data.Frames{}
+	for _, v := range vector {
+		name := formatLegend(v.Metric, query)
+		frames = append(frames, data.NewFrame(name,
+			data.NewField("time", nil, []time.Time{v.Timestamp.Time().UTC()}),
+			data.NewField("value", metricLabels(v.Metric), []float64{float64(v.Value)}).SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
+	}
+	return frames
+}
+
+// parseStreams returns a log frame per stream, with the timestamp and content of each
+// line. The stream labels are set on the line field.
+func parseStreams(streams loghttp.Streams, query *lokiQuery) data.Frames {
+	frames := data.Frames{}
+	for _, stream := range streams {
+		labels := data.Labels(stream.Labels.Map())
+		timeVector := make([]time.Time, 0, len(stream.Entries))
+		lines := make([]string, 0, len(stream.Entries))
+
+		for _, entry := range stream.Entries {
+			timeVector = append(timeVector, entry.Timestamp.UTC())
+			lines = append(lines, entry.Line)
+		}
+
+		frame := data.NewFrame(stream.Labels.String(),
+			data.NewField("ts", nil, timeVector),
+			data.NewField("line", labels, lines))
+		frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeLogs}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func metricLabels(metric model.Metric) data.Labels {
+	tags := make(data.Labels, len(metric))
+	for k, v := range metric {
+		tags[string(k)] = string(v)
+	}
+	return tags
 }
@@ -4,10 +4,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/logproto"
 	p "github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 )
@@ -94,16 +96,60 @@ func TestLoki(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, time.Second*2, models[0].Step)
 	})
+
+	t.Run("parsing query model with line limit, direction and instant", func(t *testing.T) {
+		parse := func(dsInfo *models.DataSource, json string) (*lokiQuery, error) {
+			jsonModel, err := simplejson.NewJson([]byte(json))
+			require.NoError(t, err)
+			timeRange := plugins.NewDataTimeRange("1h", "now")
+			queryContext := plugins.DataQuery{
+				TimeRange: &timeRange,
+				Queries: []plugins.DataSubQuery{
+					{Model: jsonModel},
+				},
+			}
+			models, err := newExecutor().parseQuery(dsInfo, queryContext)
+			if err != nil {
+				return nil, err
+			}
+			return models[0], nil
+		}
+
+		query, err := parse(dsInfo, `{"expr": "{app=\"a\"}"}`)
+		require.NoError(t, err)
+		require.Equal(t, 1000, query.MaxLines)
+		require.Equal(t, logproto.BACKWARD, query.Direction)
+		require.False(t, query.Instant)
+
+		query, err = parse(dsInfo, `{"expr": "{app=\"a\"}", "maxLines": 20, "direction": "FORWARD", "instant": true}`)
+		require.NoError(t, err)
+		require.Equal(t, 20, query.MaxLines)
+		require.Equal(t, logproto.FORWARD, query.Direction)
+		require.True(t, query.Instant)
+
+		dsMaxLines := &models.DataSource{
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"maxLines": "50"}),
+		}
+		query, err = parse(dsMaxLines, `{"expr": "{app=\"a\"}"}`)
+		require.NoError(t, err)
+		require.Equal(t, 50, query.MaxLines)
+
+		_, err = parse(dsInfo, `{"expr": "{app=\"a\"}", "direction": "SIDEWAYS"}`)
+		require.Error(t, err)
+
+		_, err = parse(dsInfo, `{"expr": "{app=\"a\"}", "maxLines": 0}`)
+		require.Error(t, err)
+	})
 }
 
 func TestParseResponse(t *testing.T) {
-	t.Run("value is not of type matrix", func(t *testing.T) {
+	t.Run("value is of unsupported type", func(t *testing.T) {
 		//nolint: staticcheck // plugins.DataPlugin deprecated
 		queryRes := plugins.DataQueryResult{}
 
 		value := loghttp.QueryResponse{
 			Data: loghttp.QueryResponseData{
-				Result: loghttp.Vector{},
+				ResultType: "unknown",
 			},
 		}
 		res, err := parseResponse(&value, nil)
@@ -152,4 +198,55 @@ func TestParseResponse(t *testing.T) {
 		testValue := decoded[0].Fields[0].At(0)
 		require.Equal(t, "UTC", testValue.(time.Time).Location().String())
 	})
+
+	t.Run("vector should be parsed to a frame per sample", func(t *testing.T) {
+		value := loghttp.QueryResponse{
+			Data: loghttp.QueryResponseData{
+				Result: loghttp.Vector{
+					{Metric: p.Metric{"app": "Application"}, Value: 1, Timestamp: 1000},
+					{Metric: p.Metric{"app": "Other"}, Value: 2, Timestamp: 1000},
+				},
+			},
+		}
+
+		res, err := parseResponse(&value, &lokiQuery{LegendFormat: "{{app}}"})
+		require.NoError(t, err)
+
+		decoded, _ := res.Dataframes.Decoded()
+		require.Len(t, decoded, 2)
+		require.Equal(t, "Other", decoded[1].Name)
+		require.Equal(t, "app=Other", decoded[1].Fields[1].Labels.String())
+		require.Equal(t, time.Unix(1, 0).UTC(), decoded[1].Fields[0].At(0))
+		require.Equal(t, 2.0, decoded[1].Fields[1].At(0))
+	})
+
+	t.Run("streams should be parsed to log frames", func(t *testing.T) {
+		value := loghttp.QueryResponse{
+			Data: loghttp.QueryResponseData{
+				Result: loghttp.Streams{
+					{
+						Labels: loghttp.LabelSet{"app": "Application", "level": "error"},
+						Entries: []loghttp.Entry{
+							{Timestamp: time.Unix(2, 0), Line: "second"},
+							{Timestamp: time.Unix(1, 0), Line: "first"},
+						},
+					},
+				},
+			},
+		}
+
+		res, err := parseResponse(&value, &lokiQuery{})
+		require.NoError(t, err)
+
+		decoded, _ := res.Dataframes.Decoded()
+		require.Len(t, decoded, 1)
+		require.Equal(t, data.VisTypeLogs, string(decoded[0].Meta.PreferredVisualization))
+		require.Len(t, decoded[0].Fields, 2)
+		require.Equal(t, "ts", decoded[0].Fields[0].Name)
+		require.Equal(t, time.Unix(2, 0).UTC(), decoded[0].Fields[0].At(0))
+		require.Equal(t, "line", decoded[0].Fields[1].Name)
+		require.Equal(t, "app=Application, level=error", decoded[0].Fields[1].Labels.String())
+		require.Equal(t, "second", decoded[0].Fields[1].At(0))
+		require.Equal(t, "first", decoded[0].Fields[1].At(1))
+	})
 }
@@ -1,6 +1,10 @@
 package loki
 
-import "time"
+import (
+	"time"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
 
 type lokiQuery struct {
 	Expr         string
@@ -9,4 +13,7 @@ type lokiQuery struct {
 	Start        time.Time
 	End          time.Time
 	RefID        string
+	MaxLines     int
+	Direction    logproto.Direction
+	Instant      bool
 }
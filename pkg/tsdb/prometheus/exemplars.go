@@ -0,0 +1,120 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/prometheus/client_golang/api"
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+const exemplarsEndpoint = "/api/v1/query_exemplars"
+
+// exemplarQueryResult is a series returned by the Prometheus exemplars API.
+type exemplarQueryResult struct {
+	SeriesLabels model.LabelSet `json:"seriesLabels"`
+	Exemplars    []exemplar     `json:"exemplars"`
+}
+
+type exemplar struct {
+	Labels    model.LabelSet    `json:"labels"`
+	Value     model.SampleValue `json:"value"`
+	Timestamp model.Time        `json:"timestamp"`
+}
+
+type apiResponse struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"`
+	ErrorType apiv1.ErrorType `json:"errorType"`
+	Error     string          `json:"error"`
+}
+
+// queryExemplars calls the exemplars API, which the Prometheus client does not support yet.
+func queryExemplars(ctx context.Context, client api.Client, query string, start, end time.Time) ([]exemplarQueryResult, error) {
+	u := client.URL(exemplarsEndpoint, nil)
+	q := u.Query()
+	q.Set("query", query)
+	q.Set("start", formatTime(start))
+	q.Set("end", formatTime(end))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, body, err := client.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result apiResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode exemplars response with status %d: %w", resp.StatusCode, err)
+	}
+	if result.Status != "success" {
+		return nil, &apiv1.Error{Type: result.ErrorType, Msg: result.Error}
+	}
+
+	var exemplars []exemplarQueryResult
+	if err := json.Unmarshal(result.Data, &exemplars); err != nil {
+		return nil, err
+	}
+	return exemplars, nil
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.Unix())+float64(t.Nanosecond())/1e9, 'f', -1, 64)
+}
+
+// exemplarFrames returns one frame per series with the time and value of each exemplar
+// and a string field for every exemplar label, e.g. the trace ID.
+func exemplarFrames(results []exemplarQueryResult, query *PrometheusQuery) data.Frames {
+	frames := make(data.Frames, 0, len(results))
+	for _, res := range results {
+		labelNames := make([]string, 0)
+		seen := make(map[model.LabelName]bool)
+		for _, e := range res.Exemplars {
+			for name := range e.Labels {
+				if !seen[name] {
+					seen[name] = true
+					labelNames = append(labelNames, string(name))
+				}
+			}
+		}
+		sort.Strings(labelNames)
+
+		timeVector := make([]time.Time, 0, len(res.Exemplars))
+		values := make([]float64, 0, len(res.Exemplars))
+		labelValues := make([][]string, len(labelNames))
+		for _, e := range res.Exemplars {
+			timeVector = append(timeVector, e.Timestamp.Time().UTC())
+			values = append(values, float64(e.Value))
+			for i, name := range labelNames {
+				labelValues[i] = append(labelValues[i], string(e.Labels[model.LabelName(name)]))
+			}
+		}
+
+		name := formatLegend(model.Metric(res.SeriesLabels), query)
+		fields := []*data.Field{
+			data.NewField("time", nil, timeVector),
+			data.NewField("value", metricLabels(model.Metric(res.SeriesLabels)), values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name}),
+		}
+		for i, name := range labelNames {
+			fields = append(fields, data.NewField(name, nil, labelValues[i]))
+		}
+
+		frame := data.NewFrame("exemplar", fields...)
+		frame.Meta = plugins.ExemplarFrameMeta()
+		frames = append(frames, frame)
+	}
+	return frames
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
@@ -20,8 +21,12 @@ import (
 	"github.com/prometheus/client_golang/api"
 	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
+	"golang.org/x/sync/errgroup"
 )
 
+// maxConcurrentQueries limits how many queries of a single request are sent to Prometheus at the same time.
+const maxConcurrentQueries = 10
+
 type PrometheusExecutor struct {
 	Transport http.RoundTripper
 
@@ -62,7 +67,7 @@ func init() {
 	plog = log.New("tsdb.prometheus")
 }
 
-func (e *PrometheusExecutor) getClient(dsInfo *models.DataSource) (apiv1.API, error) {
+func (e *PrometheusExecutor) getClient(dsInfo *models.DataSource) (api.Client, error) {
 	cfg := api.Config{
 		Address:      dsInfo.Url,
 		RoundTripper: e.Transport,
@@ -76,12 +81,7 @@ func (e *PrometheusExecutor) getClient(dsInfo *models.DataSource) (apiv1.API, er
 		}
 	}
 
-	client, err := api.NewClient(cfg)
-	if err != nil {
-		return nil, err
-	}
-
-	return apiv1.NewAPI(client), nil
+	return api.NewClient(cfg)
 }
 
 //nolint: staticcheck // plugins.DataResponse deprecated
@@ -101,7 +101,55 @@ func (e *PrometheusExecutor) DataQuery(ctx context.Context, dsInfo *models.DataS
 		return result, err
 	}
 
+	var mu sync.Mutex
+	g, ctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, maxConcurrentQueries)
 	for _, query := range queries {
+		query := query
+		g.Go(func() error {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			defer func() { <-sem }()
+
+			queryResult, err := runQuery(ctx, client, query)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Results[query.RefId] = queryResult
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// runQuery executes the range, instant and exemplar requests of a single query
+// and returns all resulting frames in one result. Frames of exemplars are marked
+// with plugins.ExemplarFrameMeta, so that expressions and alerting skip them.
+//nolint: staticcheck // plugins.DataQueryResult deprecated
+func runQuery(ctx context.Context, client api.Client, query *PrometheusQuery) (plugins.DataQueryResult, error) {
+	var queryResult plugins.DataQueryResult
+	frames := data.Frames{}
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "alerting.prometheus")
+	span.SetTag("expr", query.Expr)
+	span.SetTag("start_unixnano", query.Start.UnixNano())
+	span.SetTag("stop_unixnano", query.End.UnixNano())
+	defer span.Finish()
+
+	promAPI := apiv1.NewAPI(client)
+
+	if query.RangeQuery {
 		timeRange := apiv1.Range{
 			Start: query.Start,
 			End:   query.End,
@@ -110,26 +158,47 @@ func (e *PrometheusExecutor) DataQuery(ctx context.Context, dsInfo *models.DataS
 
 		plog.Debug("Sending query", "start", timeRange.Start, "end", timeRange.End, "step", timeRange.Step, "query", query.Expr)
 
-		span, ctx := opentracing.StartSpanFromContext(ctx, "alerting.prometheus")
-		span.SetTag("expr", query.Expr)
-		span.SetTag("start_unixnano", query.Start.UnixNano())
-		span.SetTag("stop_unixnano", query.End.UnixNano())
-		defer span.Finish()
+		value, _, err := promAPI.QueryRange(ctx, query.Expr, timeRange)
+		if err != nil {
+			return queryResult, err
+		}
 
-		value, _, err := client.QueryRange(ctx, query.Expr, timeRange)
+		rangeFrames, err := parseMatrix(value, query)
+		if err != nil {
+			return queryResult, err
+		}
+		frames = append(frames, rangeFrames...)
+	}
+
+	if query.InstantQuery {
+		plog.Debug("Sending instant query", "time", query.End, "query", query.Expr)
 
+		value, _, err := promAPI.Query(ctx, query.Expr, query.End)
 		if err != nil {
-			return result, err
+			return queryResult, err
 		}
 
-		queryResult, err := parseResponse(value, query)
+		instantFrames, err := parseInstantResponse(value, query)
 		if err != nil {
-			return result, err
+			return queryResult, err
 		}
-		result.Results[query.RefId] = queryResult
+		frames = append(frames, instantFrames...)
 	}
 
-	return result, nil
+	if query.ExemplarQuery {
+		plog.Debug("Sending exemplar query", "start", query.Start, "end", query.End, "query", query.Expr)
+
+		// exemplars are optional, e.g. older versions of Prometheus do not support them
+		results, err := queryExemplars(ctx, client, query.Expr, query.Start, query.End)
+		if err != nil {
+			plog.Warn("Failed to query exemplars", "query", query.Expr, "error", err)
+		} else {
+			frames = append(frames, exemplarFrames(results, query)...)
+		}
+	}
+
+	queryResult.Dataframes = plugins.NewDecodedDataFrames(frames)
+	return queryResult, nil
 }
 
 func formatLegend(metric model.Metric, query *PrometheusQuery) string {
@@ -176,54 +245,88 @@ func (e *PrometheusExecutor) parseQuery(dsInfo *models.DataSource, query plugins
 			return nil, err
 		}
 
+		instant := queryModel.Model.Get("instant").MustBool(false)
+		// Range queries are the default, unless only an instant query is requested.
+		rangeQuery := queryModel.Model.Get("range").MustBool(!instant)
+		exemplar := queryModel.Model.Get("exemplar").MustBool(false)
+
 		intervalFactor := queryModel.Model.Get("intervalFactor").MustInt64(1)
 		interval := e.intervalCalculator.Calculate(*query.TimeRange, dsInterval)
 		step := time.Duration(int64(interval.Value) * intervalFactor)
 
 		qs = append(qs, &PrometheusQuery{
-			Expr:         expr,
-			Step:         step,
-			LegendFormat: format,
-			Start:        start,
-			End:          end,
-			RefId:        queryModel.RefID,
+			Expr:          expr,
+			Step:          step,
+			LegendFormat:  format,
+			Start:         start,
+			End:           end,
+			RefId:         queryModel.RefID,
+			RangeQuery:    rangeQuery,
+			InstantQuery:  instant,
+			ExemplarQuery: exemplar,
 		})
 	}
 
 	return qs, nil
 }
 
-//nolint: staticcheck // plugins.DataQueryResult deprecated
-func parseResponse(value model.Value, query *PrometheusQuery) (plugins.DataQueryResult, error) {
-	var queryRes plugins.DataQueryResult
+// parseMatrix converts the result of a range query to one frame per series.
+func parseMatrix(value model.Value, query *PrometheusQuery) (data.Frames, error) {
 	frames := data.Frames{}
 
 	matrix, ok := value.(model.Matrix)
 	if !ok {
-		return queryRes, fmt.Errorf("unsupported result format: %q", value.Type().String())
+		return nil, fmt.Errorf("unsupported result format: %q", value.Type().String())
 	}
 
 	for _, v := range matrix {
 		name := formatLegend(v.Metric, query)
-		tags := make(map[string]string, len(v.Metric))
 		timeVector := make([]time.Time, 0, len(v.Values))
 		values := make([]float64, 0, len(v.Values))
 
-		for k, v := range v.Metric {
-			tags[string(k)] = string(v)
-		}
-
 		for _, k := range v.Values {
 			timeVector = append(timeVector, time.Unix(k.Timestamp.Unix(), 0).UTC())
 			values = append(values, float64(k.Value))
 		}
 		frames = append(frames, data.NewFrame(name,
 			data.NewField("time", nil, timeVector),
-			data.NewField("value", tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
+			data.NewField("value", metricLabels(v.Metric), values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
 	}
-	queryRes.Dataframes = plugins.NewDecodedDataFrames(frames)
 
-	return queryRes, nil
+	return frames, nil
+}
+
+// parseInstantResponse converts the result of an instant query to frames. A vector
+// results in one single row frame per sample, a scalar in a single frame without labels.
+func parseInstantResponse(value model.Value, query *PrometheusQuery) (data.Frames, error) {
+	frames := data.Frames{}
+
+	switch v := value.(type) {
+	case model.Vector:
+		for _, sample := range v {
+			name := formatLegend(sample.Metric, query)
+			frames = append(frames, data.NewFrame(name,
+				data.NewField("time", nil, []time.Time{sample.Timestamp.Time().UTC()}),
+				data.NewField("value", metricLabels(sample.Metric), []float64{float64(sample.Value)}).
+					SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
+		}
+	case *model.Scalar:
+		frames = append(frames, data.NewFrame(query.Expr,
+			data.NewField("time", nil, []time.Time{v.Timestamp.Time().UTC()}),
+			data.NewField("value", nil, []float64{float64(v.Value)})))
+	default:
+		return nil, fmt.Errorf("unsupported result format: %q", value.Type().String())
+	}
+
+	return frames, nil
+}
+
+func metricLabels(metric model.Metric) data.Labels {
+	tags := make(data.Labels, len(metric))
+	for k, v := range metric {
+		tags[string(k)] = string(v)
+	}
+	return tags
 }
 
 // IsAPIError returns whether err is or wraps a Prometheus error.
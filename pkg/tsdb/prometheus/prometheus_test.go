@@ -1,6 +1,10 @@
 package prometheus
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -141,16 +145,156 @@ func TestPrometheus(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, time.Minute*2, models[0].Step)
 	})
+
+	t.Run("parsing query model query types", func(t *testing.T) {
+		tests := []struct {
+			json     string
+			rangeQ   bool
+			instant  bool
+			exemplar bool
+		}{
+			{json: `{"expr": "go_goroutines"}`, rangeQ: true},
+			{json: `{"expr": "go_goroutines", "instant": true}`, instant: true},
+			{json: `{"expr": "go_goroutines", "instant": true, "range": true}`, rangeQ: true, instant: true},
+			{json: `{"expr": "go_goroutines", "exemplar": true}`, rangeQ: true, exemplar: true},
+		}
+		for _, tt := range tests {
+			jsonModel, _ := simplejson.NewJson([]byte(tt.json))
+			timeRange := plugins.NewDataTimeRange("1h", "now")
+			queryContext := plugins.DataQuery{
+				TimeRange: &timeRange,
+				Queries:   []plugins.DataSubQuery{{Model: jsonModel}},
+			}
+
+			models, err := executor.parseQuery(dsInfo, queryContext)
+			require.NoError(t, err)
+			require.Equal(t, tt.rangeQ, models[0].RangeQuery, tt.json)
+			require.Equal(t, tt.instant, models[0].InstantQuery, tt.json)
+			require.Equal(t, tt.exemplar, models[0].ExemplarQuery, tt.json)
+		}
+	})
+}
+
+func TestDataQuery(t *testing.T) {
+	var mu sync.Mutex
+	paths := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths[r.URL.Path]++
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/query_range":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{"app":"a"},"values":[[1,"1"],[2,"2"]]}]}}`))
+		case "/api/v1/query":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+				{"metric":{"app":"a"},"value":[2,"2"]},{"metric":{"app":"b"},"value":[2,"3"]}]}}`))
+		case "/api/v1/query_exemplars":
+			_, _ = w.Write([]byte(`{"status":"success","data":[{"seriesLabels":{"app":"a"},"exemplars":[
+				{"labels":{"traceID":"abc"},"value":"6","timestamp":1.5}]}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	dsInfo := &models.DataSource{
+		Url:      srv.URL,
+		JsonData: simplejson.New(),
+	}
+	plug, err := NewExecutor(dsInfo)
+	require.NoError(t, err)
+
+	queryModels := []plugins.DataSubQuery{}
+	for refID, model := range map[string]string{
+		"A": `{"expr": "up"}`,
+		"B": `{"expr": "up", "instant": true}`,
+		"C": `{"expr": "up", "instant": true, "range": false, "exemplar": true}`,
+	} {
+		jsonModel, err := simplejson.NewJson([]byte(model))
+		require.NoError(t, err)
+		queryModels = append(queryModels, plugins.DataSubQuery{RefID: refID, Model: jsonModel})
+	}
+
+	timeRange := plugins.NewDataTimeRange("1h", "now")
+	res, err := plug.DataQuery(context.Background(), dsInfo, plugins.DataQuery{
+		TimeRange: &timeRange,
+		Queries:   queryModels,
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{
+		"/api/v1/query_range":     1,
+		"/api/v1/query":           2,
+		"/api/v1/query_exemplars": 1,
+	}, paths)
+
+	frames, err := res.Results["A"].Dataframes.Decoded()
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	require.Equal(t, 2, frames[0].Rows())
+
+	frames, err = res.Results["B"].Dataframes.Decoded()
+	require.NoError(t, err)
+	require.Len(t, frames, 2)
+	require.Equal(t, "app=b", frames[1].Fields[1].Labels.String())
+	require.Equal(t, 3.0, frames[1].Fields[1].At(0))
+
+	frames, err = res.Results["C"].Dataframes.Decoded()
+	require.NoError(t, err)
+	require.Len(t, frames, 3)
+	require.False(t, plugins.IsExemplarFrame(frames[0]))
+	exemplars := frames[2]
+	require.True(t, plugins.IsExemplarFrame(exemplars))
+	require.Equal(t, "exemplar", exemplars.Name)
+	require.Len(t, exemplars.Fields, 3)
+	require.Equal(t, "traceID", exemplars.Fields[2].Name)
+	require.Equal(t, "abc", exemplars.Fields[2].At(0))
+	require.Equal(t, 6.0, exemplars.Fields[1].At(0))
+	require.Equal(t, time.Unix(1, 500000000).UTC(), exemplars.Fields[0].At(0))
 }
 
-func TestParseResponse(t *testing.T) {
+func TestDataQueryWithoutExemplarsAPI(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/api/v1/query_range" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"app":"a"},"values":[[1,"1"],[2,"2"]]}]}}`))
+	}))
+	defer srv.Close()
+
+	dsInfo := &models.DataSource{
+		Url:      srv.URL,
+		JsonData: simplejson.New(),
+	}
+	plug, err := NewExecutor(dsInfo)
+	require.NoError(t, err)
+
+	timeRange := plugins.NewDataTimeRange("1h", "now")
+	res, err := plug.DataQuery(context.Background(), dsInfo, plugins.DataQuery{
+		TimeRange: &timeRange,
+		Queries: []plugins.DataSubQuery{
+			{RefID: "A", Model: simplejson.NewFromAny(map[string]interface{}{"expr": "up", "exemplar": true})},
+		},
+	})
+	require.NoError(t, err)
+
+	frames, err := res.Results["A"].Dataframes.Decoded()
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	require.Equal(t, 2, frames[0].Rows())
+}
+
+func TestParseMatrix(t *testing.T) {
 	t.Run("value is not of type matrix", func(t *testing.T) {
-		//nolint: staticcheck // plugins.DataQueryResult deprecated
-		queryRes := plugins.DataQueryResult{}
 		value := p.Vector{}
-		res, err := parseResponse(value, nil)
+		frames, err := parseMatrix(value, nil)
 
-		require.Equal(t, queryRes, res)
+		require.Nil(t, frames)
 		require.Error(t, err)
 	})
 
@@ -171,10 +315,9 @@ func TestParseResponse(t *testing.T) {
 		query := &PrometheusQuery{
 			LegendFormat: "legend {{app}}",
 		}
-		res, err := parseResponse(value, query)
+		decoded, err := parseMatrix(value, query)
 		require.NoError(t, err)
 
-		decoded, _ := res.Dataframes.Decoded()
 		require.Len(t, decoded, 1)
 		require.Equal(t, decoded[0].Name, "legend Application")
 		require.Len(t, decoded[0].Fields, 2)
@@ -190,3 +333,36 @@ func TestParseResponse(t *testing.T) {
 		require.Equal(t, "UTC", testValue.(time.Time).Location().String())
 	})
 }
+
+func TestParseInstantResponse(t *testing.T) {
+	t.Run("vector should be parsed to a frame per sample", func(t *testing.T) {
+		value := p.Vector{
+			&p.Sample{Metric: p.Metric{"app": "Application"}, Value: 1, Timestamp: 1000},
+			&p.Sample{Metric: p.Metric{"app": "Other"}, Value: 2, Timestamp: 1000},
+		}
+		query := &PrometheusQuery{LegendFormat: "legend {{app}}"}
+		frames, err := parseInstantResponse(value, query)
+		require.NoError(t, err)
+
+		require.Len(t, frames, 2)
+		require.Equal(t, "legend Other", frames[1].Name)
+		require.Equal(t, "app=Other", frames[1].Fields[1].Labels.String())
+		require.Equal(t, time.Unix(1, 0).UTC(), frames[1].Fields[0].At(0))
+		require.Equal(t, 2.0, frames[1].Fields[1].At(0))
+	})
+
+	t.Run("scalar should be parsed to a frame without labels", func(t *testing.T) {
+		value := &p.Scalar{Value: 3, Timestamp: 1000}
+		frames, err := parseInstantResponse(value, &PrometheusQuery{Expr: "1 + 2"})
+		require.NoError(t, err)
+
+		require.Len(t, frames, 1)
+		require.Len(t, frames[0].Fields[1].Labels, 0)
+		require.Equal(t, 3.0, frames[0].Fields[1].At(0))
+	})
+
+	t.Run("matrix is not supported", func(t *testing.T) {
+		_, err := parseInstantResponse(p.Matrix{}, &PrometheusQuery{})
+		require.Error(t, err)
+	})
+}
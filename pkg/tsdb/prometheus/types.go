@@ -9,4 +9,9 @@ type PrometheusQuery struct {
 	Start        time.Time
 	End          time.Time
 	RefId        string
+	// RangeQuery, InstantQuery and ExemplarQuery select which Prometheus
+	// APIs are queried. Their frames are returned together.
+	RangeQuery    bool
+	InstantQuery  bool
+	ExemplarQuery bool
 }
@@ -0,0 +1,202 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// defaultSearchLimit is the maximum number of traces returned by a search without a limit.
+const defaultSearchLimit = 20
+
+type searchQuery struct {
+	ServiceName string
+	SpanName    string
+	// Tags are additional tags in logfmt format, e.g. `http.status_code=500 cluster="eu west"`.
+	Tags        string
+	MinDuration string
+	MaxDuration string
+	Limit       int
+	Start       time.Time
+	End         time.Time
+}
+
+type searchResponse struct {
+	Traces []traceSearchMetadata `json:"traces"`
+}
+
+type traceSearchMetadata struct {
+	TraceID           string `json:"traceID"`
+	RootServiceName   string `json:"rootServiceName"`
+	RootTraceName     string `json:"rootTraceName"`
+	StartTimeUnixNano string `json:"startTimeUnixNano"`
+	DurationMs        int64  `json:"durationMs"`
+}
+
+//nolint: staticcheck // plugins.DataSubQuery deprecated
+func parseSearchQuery(query plugins.DataSubQuery, timeRange *plugins.DataTimeRange) (*searchQuery, error) {
+	sq := &searchQuery{
+		ServiceName: query.Model.Get("serviceName").MustString(""),
+		SpanName:    query.Model.Get("spanName").MustString(""),
+		Tags:        strings.TrimSpace(query.Model.Get("search").MustString("")),
+		MinDuration: query.Model.Get("minDuration").MustString(""),
+		MaxDuration: query.Model.Get("maxDuration").MustString(""),
+		Limit:       query.Model.Get("limit").MustInt(defaultSearchLimit),
+	}
+
+	for _, d := range []string{sq.MinDuration, sq.MaxDuration} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(d); err != nil {
+			return nil, fmt.Errorf("failed to parse duration %q: %w", d, err)
+		}
+	}
+
+	if sq.Limit <= 0 {
+		return nil, fmt.Errorf("limit must be a positive number, got %d", sq.Limit)
+	}
+
+	if timeRange != nil {
+		var err error
+		if sq.Start, err = timeRange.ParseFrom(); err != nil {
+			return nil, err
+		}
+		if sq.End, err = timeRange.ParseTo(); err != nil {
+			return nil, err
+		}
+	}
+
+	return sq, nil
+}
+
+// tags returns the logfmt encoded tags Tempo searches for.
+func (sq *searchQuery) tags() string {
+	tags := make([]string, 0, 3)
+	if sq.ServiceName != "" {
+		tags = append(tags, "service.name="+strconv.Quote(sq.ServiceName))
+	}
+	if sq.SpanName != "" {
+		tags = append(tags, "name="+strconv.Quote(sq.SpanName))
+	}
+	if sq.Tags != "" {
+		tags = append(tags, sq.Tags)
+	}
+	return strings.Join(tags, " ")
+}
+
+func (sq *searchQuery) values() url.Values {
+	params := url.Values{}
+	if tags := sq.tags(); tags != "" {
+		params.Set("tags", tags)
+	}
+	if sq.MinDuration != "" {
+		params.Set("minDuration", sq.MinDuration)
+	}
+	if sq.MaxDuration != "" {
+		params.Set("maxDuration", sq.MaxDuration)
+	}
+	params.Set("limit", strconv.Itoa(sq.Limit))
+	if !sq.Start.IsZero() && !sq.End.IsZero() {
+		params.Set("start", strconv.FormatInt(sq.Start.Unix(), 10))
+		params.Set("end", strconv.FormatInt(sq.End.Unix(), 10))
+	}
+	return params
+}
+
+// search returns a table of the traces matching the query.
+//nolint: staticcheck // plugins.DataQueryResult deprecated
+func (e *tempoExecutor) search(ctx context.Context, dsInfo *models.DataSource, query plugins.DataSubQuery,
+	timeRange *plugins.DataTimeRange) (plugins.DataQueryResult, error) {
+	queryResult := plugins.DataQueryResult{}
+
+	sq, err := parseSearchQuery(query, timeRange)
+	if err != nil {
+		return queryResult, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", dsInfo.Url+"/api/search?"+sq.values().Encode(), nil)
+	if err != nil {
+		return queryResult, err
+	}
+
+	if dsInfo.BasicAuth {
+		req.SetBasicAuth(dsInfo.BasicAuthUser, dsInfo.DecryptedBasicAuthPassword())
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	tlog.Debug("Tempo search request", "url", req.URL.String())
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return queryResult, fmt.Errorf("failed search in tempo: %w", err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			tlog.Warn("failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return queryResult, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		queryResult.ErrorString = fmt.Sprintf("failed to search traces Status: %s Body: %s", resp.Status, string(body))
+		return queryResult, nil
+	}
+
+	var res searchResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return queryResult, fmt.Errorf("failed to decode tempo search response: %w", err)
+	}
+
+	frame, err := searchResponseToFrame(res)
+	if err != nil {
+		return queryResult, err
+	}
+	frame.RefID = query.RefID
+	queryResult.Dataframes = plugins.NewDecodedDataFrames(data.Frames{frame})
+
+	return queryResult, nil
+}
+
+// searchResponseToFrame returns a table frame with a row per trace.
+func searchResponseToFrame(res searchResponse) (*data.Frame, error) {
+	frame := data.NewFrame("Traces",
+		data.NewField("traceID", nil, []string{}),
+		data.NewField("traceName", nil, []string{}),
+		data.NewField("serviceName", nil, []string{}),
+		data.NewField("startTime", nil, []time.Time{}),
+		data.NewField("duration", nil, []float64{}),
+	)
+	frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeTable}
+
+	for _, trace := range res.Traces {
+		var startTime time.Time
+		if trace.StartTimeUnixNano != "" {
+			startNano, err := strconv.ParseInt(trace.StartTimeUnixNano, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse start time of trace %v: %w", trace.TraceID, err)
+			}
+			startTime = time.Unix(0, startNano).UTC()
+		}
+
+		frame.AppendRow(trace.TraceID, trace.RootTraceName, trace.RootServiceName, startTime, float64(trace.DurationMs))
+	}
+
+	return frame, nil
+}
@@ -35,21 +35,56 @@ var (
 	tlog = log.New("tsdb.tempo")
 )
 
+const (
+	// queryTypeTraceID fetches a single trace by its ID. It is the default query type.
+	queryTypeTraceID = "traceId"
+	// queryTypeSearch searches for traces matching tags, duration and time range.
+	queryTypeSearch = "search"
+)
+
 //nolint: staticcheck // plugins.DataQuery deprecated
 func (e *tempoExecutor) DataQuery(ctx context.Context, dsInfo *models.DataSource,
 	queryContext plugins.DataQuery) (plugins.DataResponse, error) {
-	refID := queryContext.Queries[0].RefID
+	result := plugins.DataResponse{
+		Results: map[string]plugins.DataQueryResult{},
+	}
+
+	for _, query := range queryContext.Queries {
+		var queryResult plugins.DataQueryResult
+		var err error
+
+		switch queryType := query.Model.Get("queryType").MustString(queryTypeTraceID); queryType {
+		case queryTypeTraceID:
+			queryResult, err = e.getTrace(ctx, dsInfo, query)
+		case queryTypeSearch:
+			queryResult, err = e.search(ctx, dsInfo, query, queryContext.TimeRange)
+		default:
+			err = fmt.Errorf("unsupported query type %q", queryType)
+		}
+		if err != nil {
+			return plugins.DataResponse{}, err
+		}
+
+		result.Results[query.RefID] = queryResult
+	}
+
+	return result, nil
+}
+
+// getTrace fetches a single trace by the ID in the query.
+//nolint: staticcheck // plugins.DataQueryResult deprecated
+func (e *tempoExecutor) getTrace(ctx context.Context, dsInfo *models.DataSource, query plugins.DataSubQuery) (plugins.DataQueryResult, error) {
 	queryResult := plugins.DataQueryResult{}
-	traceID := queryContext.Queries[0].Model.Get("query").MustString("")
+	traceID := query.Model.Get("query").MustString("")
 
 	req, err := e.createRequest(ctx, dsInfo, traceID)
 	if err != nil {
-		return plugins.DataResponse{}, err
+		return queryResult, err
 	}
 
 	resp, err := e.httpClient.Do(req)
 	if err != nil {
-		return plugins.DataResponse{}, fmt.Errorf("failed get to tempo: %w", err)
+		return queryResult, fmt.Errorf("failed get to tempo: %w", err)
 	}
 
 	defer func() {
@@ -60,38 +95,30 @@ func (e *tempoExecutor) DataQuery(ctx context.Context, dsInfo *models.DataSource
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return plugins.DataResponse{}, err
+		return queryResult, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		queryResult.ErrorString = fmt.Sprintf("failed to get trace with id: %s Status: %s Body: %s", traceID, resp.Status, string(body))
-		return plugins.DataResponse{
-			Results: map[string]plugins.DataQueryResult{
-				refID: queryResult,
-			},
-		}, nil
+		return queryResult, nil
 	}
 
 	otTrace := ot_pdata.NewTraces()
 	err = otTrace.FromOtlpProtoBytes(body)
 
 	if err != nil {
-		return plugins.DataResponse{}, fmt.Errorf("failed to convert tempo response to Otlp: %w", err)
+		return queryResult, fmt.Errorf("failed to convert tempo response to Otlp: %w", err)
 	}
 
 	frame, err := TraceToFrame(otTrace)
 	if err != nil {
-		return plugins.DataResponse{}, fmt.Errorf("failed to transform trace %v to data frame: %w", traceID, err)
+		return queryResult, fmt.Errorf("failed to transform trace %v to data frame: %w", traceID, err)
 	}
-	frame.RefID = refID
+	frame.RefID = query.RefID
 	frames := []*data.Frame{frame}
 	queryResult.Dataframes = plugins.NewDecodedDataFrames(frames)
 
-	return plugins.DataResponse{
-		Results: map[string]plugins.DataQueryResult{
-			refID: queryResult,
-		},
-	}, nil
+	return queryResult, nil
 }
 
 func (e *tempoExecutor) createRequest(ctx context.Context, dsInfo *models.DataSource, traceID string) (*http.Request, error) {
@@ -2,9 +2,16 @@ package tempo
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -20,4 +27,68 @@ func TestTempo(t *testing.T) {
 		assert.Equal(t, 2, len(req.Header))
 		assert.NotEqual(t, req.Header.Get("Authorization"), "")
 	})
+
+	t.Run("DataQuery should handle search and trace ID queries", func(t *testing.T) {
+		var searchParams url.Values
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/api/search":
+				searchParams = r.URL.Query()
+				_, _ = w.Write([]byte(`{"traces":[{"traceID":"2f3e0cee77ae5dc9c17ade3689eb2e54","rootServiceName":"loki-all",
+					"rootTraceName":"HTTP GET","startTimeUnixNano":"1616072924070497000","durationMs":8}]}`))
+			case strings.HasPrefix(r.URL.Path, "/api/traces/"):
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte("trace not found"))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}))
+		defer srv.Close()
+
+		dsInfo := &models.DataSource{Url: srv.URL}
+		plug, err := NewExecutor(dsInfo)
+		require.NoError(t, err)
+
+		search, err := simplejson.NewJson([]byte(`{"queryType": "search", "serviceName": "loki-all", "spanName": "HTTP GET",
+			"search": "http.status_code=500", "minDuration": "5ms", "maxDuration": "1s", "limit": 10}`))
+		require.NoError(t, err)
+		byID, err := simplejson.NewJson([]byte(`{"query": "unknown"}`))
+		require.NoError(t, err)
+
+		timeRange := plugins.NewDataTimeRange("1616072000000", "1616073000000")
+		res, err := plug.DataQuery(context.Background(), dsInfo, plugins.DataQuery{
+			TimeRange: &timeRange,
+			Queries: []plugins.DataSubQuery{
+				{RefID: "A", Model: search},
+				{RefID: "B", Model: byID},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, `service.name="loki-all" name="HTTP GET" http.status_code=500`, searchParams.Get("tags"))
+		assert.Equal(t, "5ms", searchParams.Get("minDuration"))
+		assert.Equal(t, "1s", searchParams.Get("maxDuration"))
+		assert.Equal(t, "10", searchParams.Get("limit"))
+		assert.Equal(t, "1616072000", searchParams.Get("start"))
+		assert.Equal(t, "1616073000", searchParams.Get("end"))
+
+		frames, err := res.Results["A"].Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, 1, frames[0].Rows())
+		assert.Equal(t, "2f3e0cee77ae5dc9c17ade3689eb2e54", frames[0].Fields[0].At(0))
+		assert.Equal(t, "HTTP GET", frames[0].Fields[1].At(0))
+		assert.Equal(t, "loki-all", frames[0].Fields[2].At(0))
+		assert.Equal(t, time.Unix(0, 1616072924070497000).UTC(), frames[0].Fields[3].At(0))
+		assert.Equal(t, 8.0, frames[0].Fields[4].At(0))
+
+		assert.Contains(t, res.Results["B"].ErrorString, "failed to get trace with id: unknown")
+	})
+
+	t.Run("search query with invalid duration should fail", func(t *testing.T) {
+		model, err := simplejson.NewJson([]byte(`{"queryType": "search", "minDuration": "5 apples"}`))
+		require.NoError(t, err)
+		_, err = parseSearchQuery(plugins.DataSubQuery{Model: model}, nil)
+		require.Error(t, err)
+	})
 }
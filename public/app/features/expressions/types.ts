@@ -21,6 +21,39 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.mean, label: 'Mean', description: 'Get the average value' },
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
+  { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: 'median', label: 'Median', description: 'Get the median value' },
+  { value: 'p90', label: '90th percentile', description: 'Get the 90th percentile value' },
+  { value: 'p95', label: '95th percentile', description: 'Get the 95th percentile value' },
+  { value: 'p99', label: '99th percentile', description: 'Get the 99th percentile value' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Get the standard deviation of all values' },
+  { value: ReducerID.diff, label: 'Difference', description: 'Get the difference between the last and first value' },
+  { value: 'count_non_null', label: 'Count non-null', description: 'Get the number of values that are not null' },
+  { value: ReducerID.range, label: 'Range', description: 'Get the difference between the max and min value' },
+];
+
+export enum ReducerMode {
+  Strict = '', // backend API wants an empty string to support "strict" mode
+  DropNonNumbers = 'dropNN',
+  ReplaceNonNumbers = 'replaceNN',
+}
+
+export const reducerMode: Array<SelectableValue<ReducerMode>> = [
+  {
+    value: ReducerMode.Strict,
+    label: 'Strict',
+    description: 'Result can be NaN if series contains non-numeric data',
+  },
+  {
+    value: ReducerMode.DropNonNumbers,
+    label: 'Drop Non-numeric Values',
+    description: 'Drop NaN, +/-Inf and null from input series before reducing',
+  },
+  {
+    value: ReducerMode.ReplaceNonNumbers,
+    label: 'Replace Non-numeric Values',
+    description: 'Replace NaN, +/-Inf and null with a constant value before reducing',
+  },
 ];
 
 export const downsamplingTypes: Array<SelectableValue<string>> = [
@@ -48,6 +81,12 @@ export interface ExpressionQuery extends DataQuery {
   downsampler?: string;
   upsampler?: string;
   conditions?: ClassicCondition[];
+  settings?: ExpressionQuerySettings;
+}
+
+export interface ExpressionQuerySettings {
+  mode?: ReducerMode;
+  replaceWithValue?: number;
 }
 export interface ClassicCondition {
   evaluator: {
@@ -133,7 +133,7 @@ export class TeamGroupSync extends PureComponent<Props, State> {
             buttonTitle="Add Group"
             proTip={headerTooltip}
             proTipLinkTitle="Learn more"
-            proTipLink="http://docs.grafana.org/auth/enhanced_ldap/"
+            proTipLink="http://docs.grafana.org/auth/team-sync/"
             proTipTarget="_blank"
           />
         )}
@@ -1,5 +1,4 @@
 import { Team, TeamPermissionLevel } from 'app/types';
-import config from 'app/core/config';
 import { NavModelItem, NavModel } from '@grafana/data';
 
 export function buildNavModel(team: Team): NavModelItem {
@@ -25,19 +24,16 @@ export function buildNavModel(team: Team): NavModelItem {
         text: 'Settings',
         url: `org/teams/edit/${team.id}/settings`,
       },
+      {
+        active: false,
+        icon: 'sync',
+        id: `team-groupsync-${team.id}`,
+        text: 'External group sync',
+        url: `org/teams/edit/${team.id}/groupsync`,
+      },
     ],
   };
 
-  if (config.licenseInfo.hasLicense) {
-    navModel.children.push({
-      active: false,
-      icon: 'sync',
-      id: `team-groupsync-${team.id}`,
-      text: 'External group sync',
-      url: `org/teams/edit/${team.id}/groupsync`,
-    });
-  }
-
   return navModel;
 }
 